@@ -0,0 +1,224 @@
+package hrtime
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// StableMinCount is the minimum number of laps measured by a stable
+// benchmark before checking the precision, estimates from fewer laps
+// are themselves too noisy.
+const StableMinCount = 10
+
+// stableZ is the two-sided critical value of the standard normal
+// distribution for the 95% confidence level.
+var stableZ = normalQuantile(0.975)
+
+// WithStableQuantile makes NewStableBenchmark target the precision of
+// quantile q, e.g. 0.99, instead of the mean.
+//
+// Quantile precision is checked after every 10% of additional laps,
+// since it requires sorting the laps.
+func WithStableQuantile(q float64) Option {
+	if !(q > 0 && q < 1) {
+		panic("quantile must be in range (0, 1)")
+	}
+	return func(opts *options) { opts.stableQuantile = q }
+}
+
+// NewStableBenchmark creates a benchmark that measures laps until the
+// 95% confidence interval of the mean is within target relative error,
+// e.g. 0.01 for ±1%, or until maxCount laps.
+//
+// It's similar to go test -benchtime, however instead of the duration
+// it targets the precision. Use Stable to check whether the precision
+// was reached and RelativeError for the achieved precision.
+func NewStableBenchmark(maxCount int, target float64, opts ...Option) *Benchmark {
+	bench := NewStreamingBenchmark(opts...)
+	if maxCount < StableMinCount {
+		return newMisusedBenchmark(bench.opts, bench.opts.misuse("must have max count at least StableMinCount"))
+	}
+	if !(target > 0) {
+		return newMisusedBenchmark(bench.opts, bench.opts.misuse("target relative error must be positive"))
+	}
+	bench.adaptive = &adaptiveCount{
+		maxCount: maxCount,
+		target:   target,
+		quantile: bench.opts.stableQuantile,
+	}
+	return bench
+}
+
+// NewStableBenchmarkTSC creates a benchmark using CPU counters that measures
+// laps until the precision target is reached, see NewStableBenchmark.
+func NewStableBenchmarkTSC(maxCount int, target float64, opts ...Option) *BenchmarkTSC {
+	bench := NewStreamingBenchmarkTSC(opts...)
+	if maxCount < StableMinCount {
+		return newMisusedBenchmarkTSC(bench.opts, bench.opts.misuse("must have max count at least StableMinCount"))
+	}
+	if !(target > 0) {
+		return newMisusedBenchmarkTSC(bench.opts, bench.opts.misuse("target relative error must be positive"))
+	}
+	bench.adaptive = &adaptiveCount{
+		maxCount: maxCount,
+		target:   target,
+		quantile: bench.opts.stableQuantile,
+	}
+	return bench
+}
+
+// RunUntilStable calls fn until the precision target is reached,
+// see NewStableBenchmark, and returns the completed benchmark.
+func RunUntilStable(maxCount int, target float64, fn func(), opts ...Option) *Benchmark {
+	bench := NewStableBenchmark(maxCount, target, opts...)
+	for bench.Next() {
+		fn()
+	}
+	return bench
+}
+
+// Stable returns whether a benchmark created with NewStableBenchmark
+// reached the precision target before the maximum count.
+func (bench *Benchmark) Stable() bool {
+	bench = bench.mustBeCompleted()
+	return bench.adaptive != nil && bench.adaptive.reached
+}
+
+// RelativeError returns the half-width of the 95% confidence interval
+// of the mean relative to the mean, or of the quantile targeted by
+// WithStableQuantile, e.g. 0.01 means ±1%.
+//
+// It returns +Inf when there are not enough laps to estimate it.
+func (bench *Benchmark) RelativeError() float64 {
+	bench = bench.mustBeCompleted()
+	return relativeError(bench.laps, bench.adaptive)
+}
+
+// Stable returns whether a benchmark created with NewStableBenchmarkTSC
+// reached the precision target before the maximum count.
+func (bench *BenchmarkTSC) Stable() bool {
+	bench = bench.mustBeCompleted()
+	return bench.adaptive != nil && bench.adaptive.reached
+}
+
+// RelativeError returns the relative half-width of the 95% confidence
+// interval, see Benchmark.RelativeError.
+func (bench *BenchmarkTSC) RelativeError() float64 {
+	bench = bench.mustBeCompleted()
+	return relativeError(bench.laps, bench.adaptive)
+}
+
+// relativeError returns the relative error of the quantile targeted by
+// adaptive, or of the mean of laps.
+func relativeError[T ~int64](laps []T, adaptive *adaptiveCount) float64 {
+	if adaptive != nil && adaptive.quantile > 0 {
+		durations := make([]time.Duration, len(laps))
+		for i, lap := range laps {
+			durations[i] = time.Duration(lap)
+		}
+		slices.Sort(durations)
+		return quantileRelativeError(durations, adaptive.quantile)
+	}
+	var stats runningStats
+	for _, lap := range laps {
+		stats.add(time.Duration(lap))
+	}
+	return stats.relativeError()
+}
+
+// adaptiveCount decides when a stable benchmark has measured enough laps.
+type adaptiveCount struct {
+	maxCount int
+	target   float64
+	quantile float64
+
+	stats runningStats
+	// laps contains measured laps, when targeting a quantile.
+	laps      []time.Duration
+	nextCheck int
+	reached   bool
+}
+
+// reset clears the measured laps.
+func (adaptive *adaptiveCount) reset() {
+	adaptive.stats = runningStats{}
+	adaptive.laps = adaptive.laps[:0]
+	adaptive.nextCheck = 0
+	adaptive.reached = false
+}
+
+// add adds a measured lap and returns whether to stop measuring.
+func (adaptive *adaptiveCount) add(lap time.Duration) bool {
+	adaptive.stats.add(lap)
+	count := adaptive.stats.count
+	if count >= adaptive.maxCount {
+		adaptive.reached = adaptive.relativeError() <= adaptive.target
+		return true
+	}
+	if count < StableMinCount {
+		if adaptive.quantile > 0 {
+			adaptive.laps = append(adaptive.laps, lap)
+		}
+		return false
+	}
+
+	if adaptive.quantile > 0 {
+		adaptive.laps = append(adaptive.laps, lap)
+		if count < adaptive.nextCheck {
+			return false
+		}
+		adaptive.nextCheck = count + max(count/10, 1)
+	}
+	adaptive.reached = adaptive.relativeError() <= adaptive.target
+	return adaptive.reached
+}
+
+// relativeError returns the relative error of the targeted statistic.
+func (adaptive *adaptiveCount) relativeError() float64 {
+	if adaptive.quantile > 0 {
+		return quantileRelativeError(sortedDurations(adaptive.laps), adaptive.quantile)
+	}
+	return adaptive.stats.relativeError()
+}
+
+// runningStats computes mean and variance incrementally using Welford's algorithm.
+type runningStats struct {
+	count int
+	mean  float64
+	m2    float64
+}
+
+// add adds a lap to the statistics.
+func (stats *runningStats) add(lap time.Duration) {
+	stats.count++
+	delta := float64(lap) - stats.mean
+	stats.mean += delta / float64(stats.count)
+	stats.m2 += delta * (float64(lap) - stats.mean)
+}
+
+// relativeError returns the half-width of the confidence interval of the mean relative to the mean.
+func (stats *runningStats) relativeError() float64 {
+	if stats.count < 2 || stats.mean <= 0 {
+		return math.Inf(1)
+	}
+	stddev := math.Sqrt(stats.m2 / float64(stats.count-1))
+	return stableZ * stddev / math.Sqrt(float64(stats.count)) / stats.mean
+}
+
+// quantileRelativeError returns the half-width of the distribution-free
+// confidence interval of quantile q, based on order statistics, relative to the quantile.
+func quantileRelativeError(sorted []time.Duration, q float64) float64 {
+	n := float64(len(sorted))
+	value := quantile(sorted, q)
+	if len(sorted) < 2 || value <= 0 {
+		return math.Inf(1)
+	}
+	spread := stableZ * math.Sqrt(n*q*(1-q))
+	lower := int(math.Floor(n*q - spread))
+	upper := int(math.Ceil(n*q + spread))
+	if lower < 0 || upper >= len(sorted) {
+		return math.Inf(1)
+	}
+	return float64(sorted[upper]-sorted[lower]) / 2 / float64(value)
+}
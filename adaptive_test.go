@@ -0,0 +1,51 @@
+package hrtime_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestStableBenchmark(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	rng := rand.New(rand.NewSource(1))
+	bench := hrtime.NewStableBenchmark(100000, 0.01, hrtime.WithClock(clock), hrtime.WithWarmup(5))
+	for bench.Next() {
+		clock.Advance(time.Microsecond + time.Duration(rng.Intn(200)))
+	}
+
+	count := len(bench.Laps())
+	if !bench.Stable() || count < hrtime.StableMinCount || count > 1000 {
+		t.Errorf("expected stable benchmark, got %v after %d laps", bench.Stable(), count)
+	}
+	if relerr := bench.RelativeError(); relerr > 0.01 {
+		t.Errorf("expected relative error within target, got %v", relerr)
+	}
+}
+
+func TestStableBenchmarkMaxCount(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	rng := rand.New(rand.NewSource(1))
+	bench := hrtime.RunUntilStable(50, 0.0001, func() {
+		clock.Advance(time.Duration(rng.Intn(1000)))
+	}, hrtime.WithClock(clock))
+
+	if bench.Stable() || len(bench.Laps()) != 50 {
+		t.Errorf("expected max count without stability, got %v after %d laps", bench.Stable(), len(bench.Laps()))
+	}
+}
+
+func TestStableBenchmarkQuantile(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	rng := rand.New(rand.NewSource(1))
+	bench := hrtime.RunUntilStable(100000, 0.05, func() {
+		clock.Advance(time.Microsecond + time.Duration(rng.ExpFloat64()*100))
+	}, hrtime.WithClock(clock), hrtime.WithStableQuantile(0.99))
+
+	count := len(bench.Laps())
+	if !bench.Stable() || count < 100 || bench.RelativeError() > 0.05 {
+		t.Errorf("expected stable p99, got %v after %d laps with error %v", bench.Stable(), count, bench.RelativeError())
+	}
+}
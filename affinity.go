@@ -0,0 +1,39 @@
+package hrtime
+
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrAffinityUnsupported is returned when CPU affinity can't be set on this platform.
+var ErrAffinityUnsupported = errors.New("cpu affinity not supported")
+
+// PinToCPU locks the calling goroutine to its OS thread and restricts
+// the thread to run only on cpu.
+//
+// Pinning avoids migrations between cores during measurement, which
+// cause cold caches and on some machines TSC differences. The returned
+// unpin restores the previous affinity and unlocks the thread, it must
+// be called from the same goroutine. Use AllowedCPUs to choose a cpu.
+//
+// It returns ErrAffinityUnsupported on platforms other than Linux.
+func PinToCPU(cpu int) (unpin func(), err error) {
+	if cpu < 0 {
+		panic("cpu must be non-negative")
+	}
+	runtime.LockOSThread()
+	restore, err := pinThread(cpu)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	return func() {
+		restore()
+		runtime.UnlockOSThread()
+	}, nil
+}
+
+// AllowedCPUs returns the CPUs the calling thread is allowed to run on.
+//
+// It returns ErrAffinityUnsupported on platforms other than Linux.
+func AllowedCPUs() ([]int, error) { return allowedCPUs() }
@@ -0,0 +1,61 @@
+package hrtime
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// cpuSet is the affinity mask used by sched_setaffinity, large enough for CPU_SETSIZE CPUs.
+type cpuSet [1024 / 64]uint64
+
+// getAffinity returns the affinity mask of the calling thread.
+func getAffinity() (cpuSet, error) {
+	var set cpuSet
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+	if errno != 0 {
+		return set, fmt.Errorf("sched_getaffinity: %w", errno)
+	}
+	return set, nil
+}
+
+// setAffinity sets the affinity mask of the calling thread.
+func setAffinity(set *cpuSet) error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(*set), uintptr(unsafe.Pointer(set)))
+	if errno != 0 {
+		return fmt.Errorf("sched_setaffinity: %w", errno)
+	}
+	return nil
+}
+
+// pinThread restricts the calling thread to cpu and returns a function restoring the previous mask.
+func pinThread(cpu int) (restore func(), err error) {
+	var set cpuSet
+	if cpu >= len(set)*64 {
+		return nil, fmt.Errorf("cpu %d out of range", cpu)
+	}
+	previous, err := getAffinity()
+	if err != nil {
+		return nil, err
+	}
+	set[cpu/64] |= 1 << (cpu % 64)
+	if err := setAffinity(&set); err != nil {
+		return nil, err
+	}
+	return func() { _ = setAffinity(&previous) }, nil
+}
+
+// allowedCPUs returns CPUs in the affinity mask of the calling thread.
+func allowedCPUs() ([]int, error) {
+	set, err := getAffinity()
+	if err != nil {
+		return nil, err
+	}
+	var cpus []int
+	for cpu := range len(set) * 64 {
+		if set[cpu/64]&(1<<(cpu%64)) != 0 {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package hrtime
+
+// pinThread returns ErrAffinityUnsupported, since affinity is only implemented on Linux.
+func pinThread(cpu int) (restore func(), err error) { return nil, ErrAffinityUnsupported }
+
+// allowedCPUs returns ErrAffinityUnsupported, since affinity is only implemented on Linux.
+func allowedCPUs() ([]int, error) { return nil, ErrAffinityUnsupported }
@@ -0,0 +1,32 @@
+package hrtime_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestPinToCPU(t *testing.T) {
+	cpus, err := hrtime.AllowedCPUs()
+	if errors.Is(err, hrtime.ErrAffinityUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil || len(cpus) == 0 {
+		t.Fatalf("unexpected allowed cpus %v %v", cpus, err)
+	}
+
+	cpu := cpus[len(cpus)-1]
+	unpin, err := hrtime.PinToCPU(cpu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned, _ := hrtime.AllowedCPUs(); !slices.Equal(pinned, []int{cpu}) {
+		t.Errorf("expected pinned to %d, got %v", cpu, pinned)
+	}
+	unpin()
+	if restored, _ := hrtime.AllowedCPUs(); !slices.Equal(restored, cpus) {
+		t.Errorf("expected restored %v, got %v", cpus, restored)
+	}
+}
@@ -0,0 +1,165 @@
+package hrtime
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// InputDelta compares laps measured with the same input in two runs.
+type InputDelta struct {
+	Input string
+	// OldCount and NewCount are the number of laps with the input in each run.
+	OldCount int
+	NewCount int
+	// Old and New are the median laps.
+	Old time.Duration
+	New time.Duration
+	// Change is the relative change of the median, where 0.1 means 10% slower.
+	Change float64
+	// PValue is the two-sided p-value of Mann-Whitney U test, which is
+	// only meaningful when the input was measured several times in both runs.
+	PValue float64
+}
+
+// Alignment pairs laps of two runs of a parametrized benchmark by their input.
+//
+// The aggregate distribution of all laps hides which inputs regressed,
+// e.g. a change affecting only large inputs. Alignment reports every input separately.
+type Alignment struct {
+	// Inputs contains inputs measured in both runs, in the order of first use in the old run.
+	Inputs []InputDelta
+	// OnlyOld and OnlyNew contain inputs measured in only one of the runs.
+	OnlyOld []string
+	OnlyNew []string
+}
+
+// AlignLaps pairs laps of old and new runs by input, where inputs
+// contain the input of the corresponding lap.
+func AlignLaps(oldInputs []string, old []time.Duration, newInputs []string, new []time.Duration) *Alignment {
+	if len(oldInputs) != len(old) || len(newInputs) != len(new) {
+		panic("must have an input for every lap")
+	}
+
+	oldOrder, oldLaps := groupByInput(oldInputs, old)
+	newOrder, newLaps := groupByInput(newInputs, new)
+
+	alignment := &Alignment{}
+	for _, input := range oldOrder {
+		newInput, ok := newLaps[input]
+		if !ok {
+			alignment.OnlyOld = append(alignment.OnlyOld, input)
+			continue
+		}
+		oldSorted, newSorted := sortedDurations(oldLaps[input]), sortedDurations(newInput)
+		delta := newDelta("p50", quantile(oldSorted, 0.5), quantile(newSorted, 0.5))
+		pvalue, _ := mannWhitneyU(oldSorted, newSorted)
+		alignment.Inputs = append(alignment.Inputs, InputDelta{
+			Input:    input,
+			OldCount: len(oldSorted),
+			NewCount: len(newSorted),
+			Old:      delta.Old,
+			New:      delta.New,
+			Change:   delta.Change,
+			PValue:   pvalue,
+		})
+	}
+	for _, input := range newOrder {
+		if _, ok := oldLaps[input]; !ok {
+			alignment.OnlyNew = append(alignment.OnlyNew, input)
+		}
+	}
+	return alignment
+}
+
+// Align pairs laps of completed benchmarks old and new by their labels,
+// i.e. the input of each lap is recorded with NextWithLabel.
+func Align(old, new *Benchmark) *Alignment {
+	old, new = old.mustBeCompleted(), new.mustBeCompleted()
+	oldInputs, newInputs := old.LapLabels(), new.LapLabels()
+	if oldInputs == nil {
+		oldInputs = make([]string, len(old.laps))
+	}
+	if newInputs == nil {
+		newInputs = make([]string, len(new.laps))
+	}
+	return AlignLaps(oldInputs, old.laps, newInputs, new.laps)
+}
+
+// AlignTSC pairs laps of completed TSC benchmarks old and new by their labels,
+// see Align. Laps are converted using Count.ApproxDuration.
+func AlignTSC(old, new *BenchmarkTSC) *Alignment {
+	old, new = old.mustBeCompleted(), new.mustBeCompleted()
+	oldLaps, newLaps := old.Laps(), new.Laps()
+	oldInputs, newInputs := old.LapLabels(), new.LapLabels()
+	if oldInputs == nil {
+		oldInputs = make([]string, len(oldLaps))
+	}
+	if newInputs == nil {
+		newInputs = make([]string, len(newLaps))
+	}
+	return AlignLaps(oldInputs, oldLaps, newInputs, newLaps)
+}
+
+// groupByInput groups laps by input, returning inputs in the order of first use.
+func groupByInput(inputs []string, laps []time.Duration) ([]string, map[string][]time.Duration) {
+	var order []string
+	byInput := map[string][]time.Duration{}
+	for i, input := range inputs {
+		if _, ok := byInput[input]; !ok {
+			order = append(order, input)
+		}
+		byInput[input] = append(byInput[input], laps[i])
+	}
+	return order, byInput
+}
+
+// Regressions returns inputs whose median became slower by more than threshold,
+// the most regressed first.
+//
+// threshold is relative, e.g. 0.05 reports inputs that are more than 5% slower.
+func (alignment *Alignment) Regressions(threshold float64) []InputDelta {
+	var regressions []InputDelta
+	for _, delta := range alignment.Inputs {
+		if delta.Change > threshold {
+			regressions = append(regressions, delta)
+		}
+	}
+	slices.SortStableFunc(regressions, func(a, b InputDelta) int { return cmp.Compare(b.Change, a.Change) })
+	return regressions
+}
+
+// WriteTo writes medians of every input to w.
+func (alignment *Alignment) WriteTo(w io.Writer) (int64, error) {
+	width := len("input")
+	for _, delta := range alignment.Inputs {
+		width = max(width, len(delta.Input))
+	}
+
+	var written int64
+	n, err := fmt.Fprintf(w, "%d inputs aligned, %d only in old, %d only in new\n  %-*s %10s    %10s  %8s %8s\n",
+		len(alignment.Inputs), len(alignment.OnlyOld), len(alignment.OnlyNew), width, "input", "old p50", "new p50", "change", "p")
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, delta := range alignment.Inputs {
+		n, err = fmt.Fprintf(w, "  %-*s %10v -> %10v  %+7.2f%% %8.3g\n",
+			width, delta.Input, formatStat(float64(delta.Old)), formatStat(float64(delta.New)), delta.Change*100, delta.PValue)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns medians of every input.
+func (alignment *Alignment) String() string {
+	var buffer strings.Builder
+	_, _ = alignment.WriteTo(&buffer)
+	return buffer.String()
+}
@@ -0,0 +1,78 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestAlignLaps(t *testing.T) {
+	oldInputs := []string{"small", "large", "small", "large", "removed"}
+	old := []time.Duration{100, 1000, 100, 1000, 50}
+	newInputs := []string{"large", "small", "large", "small", "added"}
+	new := []time.Duration{2000, 100, 2000, 105, 70}
+
+	alignment := hrtime.AlignLaps(oldInputs, old, newInputs, new)
+	if len(alignment.Inputs) != 2 || alignment.Inputs[0].Input != "small" || alignment.Inputs[1].OldCount != 2 {
+		t.Fatalf("unexpected inputs %+v", alignment.Inputs)
+	}
+	if len(alignment.OnlyOld) != 1 || alignment.OnlyOld[0] != "removed" || len(alignment.OnlyNew) != 1 || alignment.OnlyNew[0] != "added" {
+		t.Errorf("unexpected unaligned inputs %v %v", alignment.OnlyOld, alignment.OnlyNew)
+	}
+
+	regressions := alignment.Regressions(0.1)
+	if len(regressions) != 1 || regressions[0].Input != "large" || regressions[0].Change != 1 {
+		t.Errorf("expected only large input to regress, got %+v", regressions)
+	}
+	if out := alignment.String(); !strings.Contains(out, "2 inputs aligned") || !strings.Contains(out, "+100.00%") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestAlign(t *testing.T) {
+	run := func(slow time.Duration) *hrtime.Benchmark {
+		clock := hrtime.NewManualClock(0)
+		bench := hrtime.NewBenchmark(4, hrtime.WithClock(clock))
+		for i := 0; bench.NextWithLabel([]string{"a", "b"}[i%2]); i++ {
+			clock.Advance(100 + slow*time.Duration(i%2))
+		}
+		return bench
+	}
+
+	regressions := hrtime.Align(run(0), run(50)).Regressions(0)
+	if len(regressions) != 1 || regressions[0].Input != "b" || regressions[0].New != 150 {
+		t.Errorf("unexpected regressions %+v", regressions)
+	}
+}
+
+func TestAlignIncomplete(t *testing.T) {
+	incomplete := hrtime.NewBenchmark(4, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	incomplete.NextWithLabel("a")
+
+	complete := hrtime.NewBenchmark(2)
+	for complete.NextWithLabel("a") {
+	}
+
+	alignment := hrtime.Align(incomplete, complete)
+	if len(alignment.Inputs) != 0 || len(alignment.OnlyOld) != 0 || len(alignment.OnlyNew) != 1 {
+		t.Errorf("expected incomplete benchmark to be aligned as empty, got %+v", alignment)
+	}
+}
+
+func TestAlignTSC(t *testing.T) {
+	run := func(slow time.Duration) *hrtime.BenchmarkTSC {
+		clock := hrtime.NewManualClock(0)
+		bench := hrtime.NewBenchmarkTSC(4, hrtime.WithClock(clock))
+		for i := 0; bench.NextWithLabel([]string{"a", "b"}[i%2]); i++ {
+			clock.Advance((100 + slow*time.Duration(i%2)) * time.Microsecond)
+		}
+		return bench
+	}
+
+	regressions := hrtime.AlignTSC(run(0), run(50)).Regressions(0)
+	if len(regressions) != 1 || regressions[0].Input != "b" {
+		t.Errorf("unexpected regressions %+v", regressions)
+	}
+}
@@ -0,0 +1,91 @@
+package hrtime
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotation is an operational event, e.g. a deploy, recorded together with laps.
+//
+// Annotations allow aligning latency shifts with known events in timeline exports.
+type Annotation struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// Annotate records an event at the current time.
+func (rec *Recorder) Annotate(text string) {
+	rec.mu.Lock()
+	rec.annotations = append(rec.annotations, Annotation{Time: time.Now(), Text: text})
+	rec.mu.Unlock()
+}
+
+// Annotations returns a copy of recorded events.
+func (rec *Recorder) Annotations() []Annotation {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append(rec.annotations[:0:0], rec.annotations...)
+}
+
+// Annotate records an event at the current time.
+//
+// The event is included in Annotations of the segment containing it.
+func (rec *SegmentedRecorder) Annotate(text string) {
+	rec.AnnotateAt(time.Now(), text)
+}
+
+// AnnotateAt records an event at time at.
+func (rec *SegmentedRecorder) AnnotateAt(at time.Time, text string) {
+	rec.mu.Lock()
+	rec.annotations = append(rec.annotations, Annotation{Time: at, Text: text})
+	rec.mu.Unlock()
+}
+
+// Annotations returns a copy of all recorded events.
+func (rec *SegmentedRecorder) Annotations() []Annotation {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append(rec.annotations[:0:0], rec.annotations...)
+}
+
+// segmentAnnotations returns events within the segment starting at start.
+func (rec *SegmentedRecorder) segmentAnnotations(start time.Time) []Annotation {
+	var annotations []Annotation
+	end := start.Add(rec.length)
+	for _, annotation := range rec.annotations {
+		if !annotation.Time.Before(start) && annotation.Time.Before(end) {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// WriteCSV writes completed segments as a timeline to w with a header row.
+//
+// Durations are in nanoseconds, annotations of each segment are joined with "; ".
+func (rec *SegmentedRecorder) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"start", "count", "mean", "min", "p50", "p90", "p99", "p999", "max", "annotations"}); err != nil {
+		return err
+	}
+
+	for _, segment := range rec.Segments() {
+		texts := make([]string, len(segment.Annotations))
+		for i, annotation := range segment.Annotations {
+			texts[i] = annotation.Text
+		}
+		row := []string{segment.Start.Format(time.RFC3339Nano), strconv.Itoa(segment.Count)}
+		for _, d := range []time.Duration{segment.Mean, segment.Minimum, segment.P50, segment.P90, segment.P99, segment.P999, segment.Maximum} {
+			row = append(row, strconv.FormatInt(d.Nanoseconds(), 10))
+		}
+		row = append(row, strings.Join(texts, "; "))
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,79 @@
+package hrtime
+
+import (
+	"path"
+	"time"
+)
+
+// AnonymizeOptions configures which details are removed by JSONResult.Anonymize.
+type AnonymizeOptions struct {
+	// KeepHostname keeps the hostname in environment.
+	KeepHostname bool
+	// StripEnvironment removes the whole environment.
+	StripEnvironment bool
+	// StripMetadata contains path.Match patterns of metadata keys to remove,
+	// e.g. "ci.*" or "*".
+	StripMetadata []string
+	// Quantize rounds laps to multiples of Quantize, when positive.
+	// Statistics are recomputed from the quantized laps.
+	Quantize time.Duration
+	// DropLaps removes laps keeping only statistics.
+	DropLaps bool
+}
+
+// Anonymize returns a copy of result suitable for sharing publicly.
+//
+// By default it removes the hostname and container runtime, which
+// commonly identify the machine. Other details are removed as
+// configured by opts.
+func (result *JSONResult) Anonymize(opts AnonymizeOptions) *JSONResult {
+	shared := *result
+
+	if result.Environment != nil && !opts.StripEnvironment {
+		env := *result.Environment
+		if !opts.KeepHostname {
+			env.Hostname = ""
+		}
+		env.Container = ""
+		env.Warnings = append([]string(nil), env.Warnings...)
+		shared.Environment = &env
+	} else {
+		shared.Environment = nil
+	}
+
+	shared.Metadata = nil
+	for key, value := range result.Metadata {
+		if matchesAny(key, opts.StripMetadata) {
+			continue
+		}
+		if shared.Metadata == nil {
+			shared.Metadata = map[string]string{}
+		}
+		shared.Metadata[key] = value
+	}
+
+	shared.Laps = append([]int64(nil), result.Laps...)
+	if opts.Quantize > 0 && len(shared.Laps) > 0 {
+		laps := result.Durations()
+		for i, lap := range laps {
+			laps[i] = lap.Round(opts.Quantize)
+			shared.Laps[i] = int64(laps[i])
+		}
+		shared.Stats = newJSONStats(laps)
+	}
+	if opts.DropLaps {
+		shared.Laps = nil
+	}
+
+	return &shared
+}
+
+// matchesAny checks whether key matches any of the path.Match patterns.
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestAnonymize(t *testing.T) {
+	result := hrtime.NewJSONResult("bench", []time.Duration{1234, 5678})
+	result.Environment = &hrtime.Environment{Hostname: "secret-host", GOOS: "linux"}
+	result.Metadata = map[string]string{"ci.runner": "internal-7", "commit": "abc"}
+
+	shared := result.Anonymize(hrtime.AnonymizeOptions{
+		StripMetadata: []string{"ci.*"},
+		Quantize:      time.Microsecond,
+	})
+
+	if shared.Environment.Hostname != "" || shared.Environment.GOOS != "linux" {
+		t.Errorf("unexpected environment %+v", shared.Environment)
+	}
+	if _, ok := shared.Metadata["ci.runner"]; ok || shared.Metadata["commit"] != "abc" {
+		t.Errorf("unexpected metadata %v", shared.Metadata)
+	}
+	if shared.Laps[0] != 1000 || shared.Laps[1] != 6000 || shared.Stats.Maximum != 6000 {
+		t.Errorf("laps not quantized %v %+v", shared.Laps, shared.Stats)
+	}
+	if result.Environment.Hostname != "secret-host" || result.Laps[0] != 1234 {
+		t.Errorf("original modified")
+	}
+
+	dropped := result.Anonymize(hrtime.AnonymizeOptions{StripEnvironment: true, DropLaps: true})
+	if dropped.Environment != nil || dropped.Laps != nil || dropped.Stats.Maximum != 5678 {
+		t.Errorf("unexpected dropped result %+v", dropped)
+	}
+}
@@ -0,0 +1,56 @@
+package hrtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// Apdex is an Apdex-style quality score of durations.
+//
+// Durations up to Satisfied threshold count fully, durations up to
+// Tolerating threshold count half and slower durations don't count.
+type Apdex struct {
+	Satisfied  int
+	Tolerating int
+	Frustrated int
+	// Score is in range [0, 1], where 1 means all durations were satisfied.
+	Score float64
+}
+
+// NewApdex computes an Apdex score of laps.
+//
+// The conventional Apdex uses tolerating = 4 * satisfied.
+func NewApdex(laps []time.Duration, satisfied, tolerating time.Duration) Apdex {
+	if tolerating < satisfied {
+		panic("tolerating threshold must be at least satisfied threshold")
+	}
+
+	var apdex Apdex
+	for _, lap := range laps {
+		switch {
+		case lap <= satisfied:
+			apdex.Satisfied++
+		case lap <= tolerating:
+			apdex.Tolerating++
+		default:
+			apdex.Frustrated++
+		}
+	}
+	if len(laps) > 0 {
+		apdex.Score = (float64(apdex.Satisfied) + float64(apdex.Tolerating)/2) / float64(len(laps))
+	}
+	return apdex
+}
+
+// Apdex computes an Apdex score of recorded durations.
+func (rec *Recorder) Apdex(satisfied, tolerating time.Duration) Apdex {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return NewApdex(rec.laps, satisfied, tolerating)
+}
+
+// String returns the score with counts.
+func (apdex Apdex) String() string {
+	return fmt.Sprintf("apdex %.3f (satisfied %d, tolerating %d, frustrated %d)",
+		apdex.Score, apdex.Satisfied, apdex.Tolerating, apdex.Frustrated)
+}
@@ -0,0 +1,23 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestApdex(t *testing.T) {
+	rec := hrtime.NewRecorder()
+	for _, lap := range []time.Duration{10, 20, 50, 1000} {
+		rec.Record(lap)
+	}
+
+	apdex := rec.Apdex(20, 80)
+	if apdex.Satisfied != 2 || apdex.Tolerating != 1 || apdex.Frustrated != 1 {
+		t.Errorf("unexpected counts %v", apdex)
+	}
+	if apdex.Score != 0.625 {
+		t.Errorf("expected score 0.625, got %v", apdex.Score)
+	}
+}
@@ -0,0 +1,161 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BayesianDraws is the number of posterior draws used by CompareBayesian.
+const BayesianDraws = 1000
+
+// BayesianComparison contains the posterior of relative change of the mean
+// between two lap sets.
+//
+// It's computed with Bayesian bootstrap, which doesn't assume any
+// particular distribution of laps.
+type BayesianComparison struct {
+	// Name identifies the compared benchmark.
+	Name string
+	// OldCount and NewCount are the number of laps in each set.
+	OldCount int
+	NewCount int
+	// ProbFaster is the posterior probability that new mean is smaller than old,
+	// where equal means count as half.
+	ProbFaster float64
+	// Change is the posterior median of relative change of the mean,
+	// where 0.1 means 10% slower.
+	Change float64
+	// Low and High are the bounds of 95% credible interval of Change.
+	Low, High float64
+
+	changes []float64
+}
+
+// CompareLapsBayesian computes posterior of relative change of the mean between old and new laps.
+//
+// The cost is proportional to BayesianDraws times the number of laps.
+// It uses the default Resampler.
+func CompareLapsBayesian(name string, old, new []time.Duration) *BayesianComparison {
+	return Resampler{}.CompareLapsBayesian(name, old, new)
+}
+
+// CompareLapsBayesian computes posterior of relative change of the mean between old and new laps.
+func (resampler Resampler) CompareLapsBayesian(name string, old, new []time.Duration) *BayesianComparison {
+	comparison := &BayesianComparison{
+		Name:       name,
+		OldCount:   len(old),
+		NewCount:   len(new),
+		ProbFaster: 0.5,
+	}
+	if len(old) == 0 || len(new) == 0 {
+		return comparison
+	}
+
+	rng := resampler.rand(old, new)
+	changes := make([]float64, BayesianDraws)
+	faster := 0.0
+	for i := range changes {
+		oldMean, newMean := bayesianMean(rng, old), bayesianMean(rng, new)
+		switch {
+		case bayesianTie(oldMean, newMean):
+			// identical laps differ only by rounding of the weighted means
+			faster += 0.5
+			continue
+		case newMean < oldMean:
+			faster++
+		}
+		if oldMean != 0 {
+			changes[i] = (newMean - oldMean) / oldMean
+		}
+	}
+	slices.Sort(changes)
+
+	comparison.changes = changes
+	comparison.ProbFaster = faster / float64(len(changes))
+	comparison.Change = quantileFloat(changes, 0.5)
+	comparison.Low = quantileFloat(changes, 0.025)
+	comparison.High = quantileFloat(changes, 0.975)
+	return comparison
+}
+
+// CompareBayesian computes posterior of relative change of the mean
+// between completed benchmarks old and new.
+func CompareBayesian(old, new *Benchmark) *BayesianComparison {
+	return new.Result().CompareBayesian(old.Result())
+}
+
+// CompareBayesianTSC computes posterior of relative change of the mean
+// between completed TSC benchmarks old and new.
+func CompareBayesianTSC(old, new *BenchmarkTSC) *BayesianComparison {
+	return new.Result().CompareBayesian(old.Result())
+}
+
+// CompareBayesian computes posterior of relative change of the mean against baseline.
+func (result *Result) CompareBayesian(baseline *Result) *BayesianComparison {
+	return CompareLapsBayesian("", baseline.laps, result.laps)
+}
+
+// bayesianMean returns the mean of laps weighted with a draw from flat Dirichlet distribution.
+func bayesianMean(rng *rand.Rand, laps []time.Duration) float64 {
+	var total, weights float64
+	for _, lap := range laps {
+		weight := rng.ExpFloat64()
+		total += weight * float64(lap)
+		weights += weight
+	}
+	return total / weights
+}
+
+// bayesianTie returns whether means a and b are equal within floating-point error.
+func bayesianTie(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// ProbSlower returns the posterior probability that new mean is larger than or equal to old.
+func (comparison *BayesianComparison) ProbSlower() float64 {
+	return 1 - comparison.ProbFaster
+}
+
+// Regressed returns whether new is slower by more than threshold with at least
+// the given probability.
+//
+// It's intended for failing CI, e.g. Regressed(0.05, 0.95) fails when
+// it's at least 95% probable that the slowdown is more than 5%.
+func (comparison *BayesianComparison) Regressed(threshold, probability float64) bool {
+	return comparison.ProbChange(threshold) >= probability
+}
+
+// ProbChange returns the posterior probability that the relative change of the mean
+// is larger than threshold, e.g. ProbChange(0.05) for being more than 5% slower.
+func (comparison *BayesianComparison) ProbChange(threshold float64) float64 {
+	if len(comparison.changes) == 0 {
+		return 0
+	}
+	larger := 0
+	for _, change := range comparison.changes {
+		if change > threshold {
+			larger++
+		}
+	}
+	return float64(larger) / float64(len(comparison.changes))
+}
+
+// WriteTo writes textual comparison to w.
+func (comparison *BayesianComparison) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "%s: %d -> %d laps, P(faster)=%.3f, mean %+.2f%% [%+.2f%%, %+.2f%%]\n",
+		comparison.Name, comparison.OldCount, comparison.NewCount, comparison.ProbFaster,
+		comparison.Change*100, comparison.Low*100, comparison.High*100)
+	return int64(n), err
+}
+
+// String returns textual comparison.
+func (comparison *BayesianComparison) String() string {
+	var buffer strings.Builder
+	_, _ = comparison.WriteTo(&buffer)
+	return buffer.String()
+}
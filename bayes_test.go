@@ -0,0 +1,41 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestCompareBayesian(t *testing.T) {
+	var old, same, slower []time.Duration
+	for i := 0; i < 200; i++ {
+		old = append(old, time.Duration(1000+i%50))
+		same = append(same, time.Duration(1000+(i+25)%50))
+		slower = append(slower, time.Duration(1100+i%50))
+	}
+
+	if c := hrtime.CompareLapsBayesian("same", old, same); c.ProbFaster < 0.1 || c.ProbFaster > 0.9 || c.Regressed(0.01, 0.5) {
+		t.Errorf("expected no difference\n%v", c)
+	}
+
+	c := hrtime.CompareLapsBayesian("slower", old, slower)
+	if c.ProbFaster != 0 || c.Low > c.Change || c.Change > c.High {
+		t.Errorf("expected new to be slower\n%v", c)
+	}
+	if c.Change < 0.09 || c.Change > 0.11 {
+		t.Errorf("expected about 10%% slowdown, got %v", c.Change)
+	}
+	if !c.Regressed(0.05, 0.95) || c.Regressed(0.2, 0.05) {
+		t.Errorf("invalid regression check\n%v", c)
+	}
+}
+
+func TestCompareBayesianConstant(t *testing.T) {
+	for _, laps := range [][]time.Duration{{5}, {5, 5, 5}} {
+		c := hrtime.CompareLapsBayesian("constant", laps, laps)
+		if c.ProbFaster != 0.5 || c.Change != 0 || c.Regressed(0, 0.5) {
+			t.Errorf("expected a tie\n%v", c)
+		}
+	}
+}
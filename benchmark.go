@@ -1,12 +1,18 @@
 package hrtime
 
 import (
+	"errors"
+	"iter"
 	"math"
+	"strconv"
 	"time"
 )
 
 // MergeBenchmarks merge multiple Benchmark so we can use it in concurrent cases.
 // Each goroutine uses its Benchmark and we can merge the results into one Benchmark.
+//
+// The merged benchmark retains laps of each benchmark as a source,
+// see Sources and MergeBenchmarksNamed.
 func MergeBenchmarks(benchmarks ...*Benchmark) *Benchmark {
 	if len(benchmarks) == 0 {
 		return nil
@@ -15,9 +21,15 @@ func MergeBenchmarks(benchmarks ...*Benchmark) *Benchmark {
 	var start = time.Duration(math.MaxInt64)
 	var stop time.Duration
 	var laps []time.Duration
-	for _, b := range benchmarks {
-		b.mustBeCompleted()
+	var nonMonotonic int
+	var errs []error
+	sources := make([]benchmarkSource, 0, len(benchmarks))
+	for i, b := range benchmarks {
+		b = b.mustBeCompleted()
 		laps = append(laps, b.laps...)
+		sources = append(sources, benchmarkSource{name: strconv.Itoa(i), end: len(laps)})
+		nonMonotonic += b.nonMonotonic
+		errs = append(errs, b.err)
 		if b.start < start {
 			start = b.start
 		}
@@ -26,89 +38,406 @@ func MergeBenchmarks(benchmarks ...*Benchmark) *Benchmark {
 		}
 	}
 
-	return &Benchmark{
-		step:  len(laps),
-		laps:  laps,
-		start: start,
-		stop:  stop,
+	merged := &Benchmark{
+		lapCore: lapCore[time.Duration]{
+			step:  len(laps),
+			laps:  laps,
+			start: start,
+			stop:  stop,
+
+			nonMonotonic: nonMonotonic,
+			err:          errors.Join(errs...),
+			sources:      sources,
+		},
 	}
+	merged.done.Store(true)
+	tracef("merge", "%d benchmarks, %d laps, %d non-monotonic", len(benchmarks), len(laps), nonMonotonic)
+	return merged
 }
 
 // Benchmark helps benchmarking using time.
 type Benchmark struct {
-	step  int
-	laps  []time.Duration
-	start time.Duration
-	stop  time.Duration
+	lapCore[time.Duration]
+
+	onComplete []func(*Benchmark)
+	// result shares laps, when created by Result.
+	result *Result
+	// pool is the pool that created the benchmark, see BenchmarkPool.
+	pool *BenchmarkPool
 }
 
 // NewBenchmark creates a new benchmark using time.
 // Count defines the number of samples to measure.
-func NewBenchmark(count int) *Benchmark {
+func NewBenchmark(count int, opts ...Option) *Benchmark {
+	config := newOptions(opts)
 	if count <= 0 {
-		panic("must have count at least 1")
+		return newMisusedBenchmark(config, config.misuse("must have count at least 1"))
 	}
+	count += config.warmup
 
-	return &Benchmark{
-		step:  0,
-		laps:  make([]time.Duration, count),
-		start: 0,
-		stop:  0,
+	bench := &Benchmark{
+		lapCore: lapCore[time.Duration]{
+			step:  0,
+			laps:  make([]time.Duration, count),
+			start: 0,
+			stop:  0,
+			opts:  config,
+		},
+	}
+	if config.sampled() {
+		bench.laps = nil
+		bench.sampling = newLapSampler[time.Duration](&bench.opts, count)
 	}
+	if bench.opts.timestamps {
+		bench.timestamps = make([]time.Duration, count)
+	}
+	if bench.opts.memStats {
+		bench.memStart = readMemSnapshot()
+	}
+	return bench
 }
 
-// mustBeCompleted checks whether measurement has been completed.
-func (bench *Benchmark) mustBeCompleted() {
-	if bench.stop == 0 {
-		panic("benchmarking incomplete")
+// NewStreamingBenchmark creates a benchmark without a fixed number of laps.
+//
+// Next always returns true until Stop is called. Laps are appended
+// dynamically with amortized allocation, hence it's useful for loops whose
+// iteration count depends on external input.
+func NewStreamingBenchmark(opts ...Option) *Benchmark {
+	bench := &Benchmark{
+		lapCore: lapCore[time.Duration]{
+			unbounded: true,
+			opts:      newOptions(opts),
+		},
+	}
+	if bench.opts.sampled() {
+		bench.sampling = newLapSampler[time.Duration](&bench.opts, 0)
 	}
+	if bench.opts.timestamps {
+		bench.timestamps = []time.Duration{}
+	}
+	if bench.opts.memStats {
+		bench.memStart = readMemSnapshot()
+	}
+	return bench
 }
 
+// NewBenchmarkFor creates a benchmark that measures laps until budget is exhausted.
+//
+// The budget starts with the first call to Next, similarly to go test -benchtime.
+// Next returns false after the budget has elapsed. The lap in progress when
+// the budget is exhausted is included.
+func NewBenchmarkFor(budget time.Duration, opts ...Option) *Benchmark {
+	bench := NewStreamingBenchmark(opts...)
+	if budget <= 0 {
+		return newMisusedBenchmark(bench.opts, bench.opts.misuse("budget must be positive"))
+	}
+	bench.budget = budget
+	return bench
+}
+
+// Reset clears measurements and registered callbacks so that the benchmark
+// can measure again, reusing its lap storage.
+//
+// When Result has been called, laps are shared with the result and
+// Reset allocates new lap storage instead.
+func (bench *Benchmark) Reset() { bench.reset() }
+
+// reset clears measurements while keeping the lap storage.
+func (bench *Benchmark) reset() {
+	bench.resetLaps()
+	if bench.result != nil {
+		// laps are shared with the result
+		bench.laps = make([]time.Duration, cap(bench.laps))
+		bench.result = nil
+	}
+	if bench.unbounded {
+		bench.laps = bench.laps[:0]
+	} else {
+		bench.laps = bench.laps[:cap(bench.laps)]
+	}
+	bench.resetSamples()
+	bench.onComplete = nil
+}
+
+// mustBeCompleted checks whether measurement has been completed, waiting
+// for the completion started by another goroutine.
+//
+// Under MisuseError policy it returns an empty completed benchmark
+// reporting the misuse instead of panicking, otherwise it returns bench.
+func (bench *Benchmark) mustBeCompleted() *Benchmark {
+	if !bench.awaitCompleted() {
+		return newMisusedBenchmark(bench.opts, bench.opts.misuse("benchmarking incomplete"))
+	}
+	return bench
+}
+
+// Completed returns whether all measurements have been made.
+//
+// It is safe to call from any goroutine.
+func (bench *Benchmark) Completed() bool { return bench.done.Load() }
+
+// Done returns a channel that is closed when all measurements have been made.
+//
+// Next must be called from a single goroutine, however after Done is closed
+// the results can be read from any goroutine. Results read from another
+// goroutine while Next or Stop is completing the benchmark wait for
+// the completion, reading them earlier is misuse.
+func (bench *Benchmark) Done() <-chan struct{} { return bench.doneChannel() }
+
 // finalize calculates diffs for each lap.
-func (bench *Benchmark) finalize(last time.Duration) {
-	if bench.stop != 0 {
-		return
+//
+// It returns true when this call completed the benchmark.
+func (bench *Benchmark) finalize(last time.Duration) bool {
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+	if bench.done.Load() {
+		return false
+	}
+	bench.closeCollectors()
+	if bench.sampling != nil {
+		bench.finish(bench.finalizeSamples(last))
+		return true
+	}
+	warmup, measured := bench.finalizeLaps(last)
+	if measured {
+		bench.finish(warmup)
+	}
+	return true
+}
+
+// finish fixes and analyzes laps converted to durations and completes the benchmark.
+func (bench *Benchmark) finish(warmup int) {
+	bench.fixLaps(func(overhead *TimerOverhead) time.Duration { return overhead.Lap })
+	if bench.opts.gcPauses {
+		bench.gcPauses = readGCPauses(bench.start, bench.stop, time.Now(), bench.opts.now())
 	}
+	traceFinalize("benchmark", &bench.opts, len(bench.laps), warmup, bench.nonMonotonic, bench.err)
+	bench.completeLaps()
+}
 
-	bench.start = bench.laps[0]
-	for i := range bench.laps[:len(bench.laps)-1] {
-		bench.laps[i] = bench.laps[i+1] - bench.laps[i]
+// OnComplete registers fn to be called when the benchmark completes, either
+// when Next returns false for the first time or when Stop is called.
+//
+// Callbacks are called in the order they were registered, from the goroutine
+// calling Next or Stop. When the benchmark has already completed, fn is called immediately.
+func (bench *Benchmark) OnComplete(fn func(*Benchmark)) {
+	if bench.Completed() {
+		fn(bench)
+		return
 	}
-	bench.laps[len(bench.laps)-1] = last - bench.laps[len(bench.laps)-1]
-	bench.stop = last
+	bench.onComplete = append(bench.onComplete, fn)
+}
+
+// NonMonotonic returns the number of laps where the clock went backwards.
+func (bench *Benchmark) NonMonotonic() int {
+	bench = bench.mustBeCompleted()
+	return bench.nonMonotonic
+}
+
+// Err returns an error when measurement is not reliable.
+//
+// It reports ErrNonMonotonic when using NonMonotonicError policy and
+// the cause of the context, when stopped by NextCtx.
+func (bench *Benchmark) Err() error {
+	bench = bench.mustBeCompleted()
+	return bench.err
 }
 
 // Next starts measuring the next lap.
 // It will return false, when all measurements have been made.
 func (bench *Benchmark) Next() bool {
-	now := Now()
-	if bench.step >= len(bench.laps) {
-		bench.finalize(now)
+	if bench.sampling != nil {
+		return bench.nextSampled()
+	}
+	return bench.next(bench.opts.now())
+}
+
+// next ends the lap in progress at now and starts the next one.
+func (bench *Benchmark) next(now time.Duration) bool {
+	if bench.sampling != nil {
+		return bench.nextSampled()
+	}
+	bench.endPause(now)
+	if bench.opts.collectors != nil && bench.step > 0 && !bench.done.Load() {
+		bench.afterLap()
+	}
+	if bench.done.Load() {
 		return false
 	}
-	bench.laps[bench.step] = Now()
-	bench.step++
+	if !bench.proceed(now) {
+		bench.complete(now)
+		return false
+	}
+	if bench.opts.collectors != nil {
+		bench.beforeLap()
+	}
+	bench.startLap(bench.opts.now())
 	return true
 }
 
+// Stop finishes measuring, the lap in progress ends at the time of the call.
+//
+// It allows finishing a benchmark created with NewStreamingBenchmark, or
+// finishing a benchmark early. Laps that were not started are discarded.
+// After Stop, Next returns false.
+func (bench *Benchmark) Stop() {
+	bench.stopAt(bench.opts.now())
+}
+
+// stopAt finishes measuring with the lap in progress ending at now.
+func (bench *Benchmark) stopAt(now time.Duration) {
+	if bench.done.Load() {
+		return
+	}
+	if bench.recording {
+		now = bench.recordAt
+	}
+	bench.endPause(now)
+	if bench.opts.collectors != nil && bench.step > 0 && !bench.recording {
+		bench.afterLap()
+	}
+	if bench.sampling != nil {
+		bench.sampling.stop(now)
+	} else {
+		bench.laps = bench.laps[:bench.step]
+	}
+	bench.complete(now)
+}
+
+// Record adds an externally measured duration as the next lap.
+//
+// It allows using durations from callback based APIs or hardware timestamps
+// with all the reporting of Benchmark. Benchmark created with NewBenchmark
+// completes after count laps, other benchmarks must be finished with Stop.
+// Recorded laps are placed consecutively on the timeline starting from
+// the first call to Record. Record must not be mixed with Next.
+func (bench *Benchmark) Record(d time.Duration) {
+	if bench.done.Load() {
+		bench.misuse("benchmark already completed")
+		return
+	}
+	if bench.sampling != nil {
+		bench.misuse("cannot sample recorded laps")
+		return
+	}
+	if bench.record(d, bench.opts.now) {
+		bench.complete(bench.recordAt)
+	}
+}
+
+// complete finalizes the benchmark and calls OnComplete callbacks once.
+func (bench *Benchmark) complete(last time.Duration) {
+	bench.finishing.Store(true)
+	if bench.finalize(last) {
+		for _, fn := range bench.onComplete {
+			fn(bench)
+		}
+	}
+}
+
+// Timeline returns the time when the benchmark started and stopped.
+//
+// The values are comparable with Now and Sample.Time.
+func (bench *Benchmark) Timeline() (start, stop time.Duration) {
+	bench = bench.mustBeCompleted()
+	return bench.start, bench.stop
+}
+
+// Spans returns the time-span of each lap.
+//
+// When created WithTimestamps, spans use the retained lap start times.
+// Otherwise spans are reconstructed from the start time and consecutive laps,
+// hence they are not meaningful for benchmarks created by MergeBenchmarks
+// or when laps were removed using NonMonotonicDrop.
+func (bench *Benchmark) Spans() []Span {
+	bench = bench.mustBeCompleted()
+	return lapSpans(&bench.lapCore, func(at time.Duration) time.Duration { return at })
+}
+
+// lapSpans returns spans of laps in core, converting readings using duration.
+func lapSpans[T ~int64](core *lapCore[T], duration func(T) time.Duration) []Span {
+	spans := make([]Span, len(core.laps))
+	if core.timestamps != nil {
+		for i, lap := range core.laps {
+			start := core.timestamps[i]
+			spans[i] = Span{Start: duration(start), Finish: duration(start + lap)}
+		}
+		return spans
+	}
+
+	at := core.start
+	for i, lap := range core.laps {
+		spans[i] = Span{Start: duration(at), Finish: duration(at + lap)}
+		at += lap
+	}
+	return spans
+}
+
+// Timestamps returns the start time of each lap as returned by Now.
+//
+// It returns nil, unless the benchmark was created WithTimestamps.
+func (bench *Benchmark) Timestamps() []time.Duration {
+	bench = bench.mustBeCompleted()
+	if bench.timestamps == nil {
+		return nil
+	}
+	return append(bench.timestamps[:0:0], bench.timestamps...)
+}
+
+// WallTimes returns the wall-clock start time of each lap.
+//
+// Wall-clock times are derived from a single time.Now reading at the end of
+// the benchmark, hence they are not affected by clock adjustments during it.
+// It returns nil, unless the benchmark was created WithTimestamps.
+func (bench *Benchmark) WallTimes() []time.Time {
+	bench = bench.mustBeCompleted()
+	if bench.timestamps == nil {
+		return nil
+	}
+	times := make([]time.Time, len(bench.timestamps))
+	for i, at := range bench.timestamps {
+		times[i] = bench.wallStop.Add(at - bench.stop)
+	}
+	return times
+}
+
 // Laps returns timing for each lap.
 func (bench *Benchmark) Laps() []time.Duration {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 	return append(bench.laps[:0:0], bench.laps...)
 }
 
+// LapsUnsafe returns timing for each lap without copying.
+//
+// The returned slice shares memory with the benchmark and must be treated as read-only.
+// It avoids doubling peak memory when computing custom statistics over large benchmarks.
+func (bench *Benchmark) LapsUnsafe() []time.Duration {
+	bench = bench.mustBeCompleted()
+	return bench.laps
+}
+
+// All returns an iterator over lap indices and timings.
+//
+// It does not copy the laps, making it suitable for large benchmarks.
+func (bench *Benchmark) All() iter.Seq2[int, time.Duration] {
+	bench = bench.mustBeCompleted()
+	return func(yield func(int, time.Duration) bool) {
+		for i, lap := range bench.laps {
+			if !yield(i, lap) {
+				return
+			}
+		}
+	}
+}
+
 // Histogram creates an histogram of all the laps.
 //
 // It creates binCount bins to distribute the data and uses the
 // 99.9 percentile as the last bucket range. However, for a nicer output
 // it might choose a larger value.
 func (bench *Benchmark) Histogram(binCount int) *Histogram {
-	bench.mustBeCompleted()
-
-	opts := defaultOptions
-	opts.BinCount = binCount
-
-	return NewDurationHistogram(bench.laps, &opts)
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Histogram(binCount)
 }
 
 // HistogramClamp creates an historgram of all the laps clamping minimum and maximum time.
@@ -116,21 +445,17 @@ func (bench *Benchmark) Histogram(binCount int) *Histogram {
 // It creates binCount bins to distribute the data and uses the
 // maximum as the last bucket.
 func (bench *Benchmark) HistogramClamp(binCount int, min, max time.Duration) *Histogram {
-	bench.mustBeCompleted()
-
-	laps := make([]time.Duration, 0, len(bench.laps))
-	for _, lap := range bench.laps {
-		if lap < min {
-			laps = append(laps, min)
-		} else {
-			laps = append(laps, lap)
-		}
-	}
-
-	opts := defaultOptions
-	opts.BinCount = binCount
-	opts.ClampMaximum = float64(max.Nanoseconds())
-	opts.ClampPercentile = 0
+	bench = bench.mustBeCompleted()
+	return bench.analysis().HistogramClamp(binCount, min, max)
+}
 
-	return NewDurationHistogram(laps, &opts)
+// HistogramClampPercentile creates an histogram of all the laps clamping minimum time
+// and the maximum to the percentile of the data.
+//
+// Unlike HistogramClamp, the maximum adapts to the measurements, e.g. using 0.995
+// keeps outliers in the last bucket without hardcoding a maximum per machine.
+// Percentile must be in range (0, 1].
+func (bench *Benchmark) HistogramClampPercentile(binCount int, min time.Duration, percentile float64) *Histogram {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().HistogramClampPercentile(binCount, min, percentile)
 }
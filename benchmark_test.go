@@ -1,7 +1,11 @@
 package hrtime_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -41,3 +45,482 @@ func TestBenchmarkTSC(t *testing.T) {
 	}
 	t.Log(bench.Histogram(10))
 }
+
+func TestBenchmarkTSCQuantiles(t *testing.T) {
+	bench := hrtime.NewBenchmarkTSC(64)
+	for bench.Next() {
+	}
+
+	counts := bench.CountQuantiles(0, 0.5, 1)
+	if counts[0] > counts[1] || counts[1] > counts[2] {
+		t.Errorf("quantiles not ordered %v", counts)
+	}
+	if quantiles := bench.Quantiles(0.5); quantiles[0] != counts[1].ApproxDuration() {
+		t.Errorf("expected %v, got %v", counts[1].ApproxDuration(), quantiles[0])
+	}
+}
+
+func TestBenchmarkConcurrentFinalize(t *testing.T) {
+	bench := hrtime.NewBenchmark(64)
+	benchTSC := hrtime.NewBenchmarkTSC(64)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-bench.Done()
+			<-benchTSC.Done()
+			if len(bench.Laps()) != 64 || len(benchTSC.Counts()) != 64 {
+				t.Errorf("unexpected lap count")
+			}
+		}()
+	}
+
+	if bench.Completed() {
+		t.Errorf("should not be completed")
+	}
+	for bench.Next() {
+	}
+	for benchTSC.Next() {
+	}
+	// further calls must not finalize again
+	if bench.Next() || benchTSC.Next() {
+		t.Errorf("Next returned true after completion")
+	}
+	wg.Wait()
+
+	if !bench.Completed() || !benchTSC.Completed() {
+		t.Errorf("should be completed")
+	}
+	<-bench.Done()
+}
+
+func TestBenchmarkTimestamps(t *testing.T) {
+	bench := hrtime.NewBenchmark(8, hrtime.WithTimestamps())
+	for bench.Next() {
+		time.Sleep(time.Microsecond)
+	}
+
+	timestamps, walls, spans := bench.Timestamps(), bench.WallTimes(), bench.Spans()
+	if len(timestamps) != 8 || len(walls) != 8 {
+		t.Fatalf("unexpected lengths %d %d", len(timestamps), len(walls))
+	}
+	for i := 1; i < len(timestamps); i++ {
+		if timestamps[i] < timestamps[i-1] || walls[i].Sub(walls[i-1]) != timestamps[i]-timestamps[i-1] {
+			t.Errorf("inconsistent timestamps at %d", i)
+		}
+		if spans[i].Start != timestamps[i] {
+			t.Errorf("span %d doesn't use timestamp", i)
+		}
+	}
+	if time.Since(walls[0]) > time.Minute {
+		t.Errorf("wall time too far in the past %v", walls[0])
+	}
+
+	benchTSC := hrtime.NewBenchmarkTSC(1)
+	for benchTSC.Next() {
+	}
+	if benchTSC.Timestamps() != nil {
+		t.Errorf("expected no timestamps")
+	}
+}
+
+func TestBenchmarkOnComplete(t *testing.T) {
+	var calls []string
+	bench := hrtime.NewBenchmark(4)
+	bench.OnComplete(func(b *hrtime.Benchmark) {
+		calls = append(calls, fmt.Sprintf("first %d", len(b.Laps())))
+	})
+	bench.OnComplete(func(b *hrtime.Benchmark) { calls = append(calls, "second") })
+	for bench.Next() {
+	}
+	bench.Next()
+	bench.OnComplete(func(b *hrtime.Benchmark) { calls = append(calls, "late") })
+
+	if got := strings.Join(calls, ","); got != "first 4,second,late" {
+		t.Errorf("unexpected calls %q", got)
+	}
+}
+
+func TestStreamingBenchmark(t *testing.T) {
+	bench := hrtime.NewStreamingBenchmark()
+	completed := false
+	bench.OnComplete(func(*hrtime.Benchmark) { completed = true })
+
+	for i := 0; bench.Next(); i++ {
+		if i == 99 {
+			bench.Stop()
+		}
+	}
+	if !completed || len(bench.Laps()) != 100 {
+		t.Fatalf("unexpected result: completed %v, laps %d", completed, len(bench.Laps()))
+	}
+
+	early := hrtime.NewBenchmark(100)
+	for i := 0; early.Next(); i++ {
+		if i == 9 {
+			early.Stop()
+		}
+	}
+	if len(early.Laps()) != 10 {
+		t.Errorf("expected 10 laps, got %d", len(early.Laps()))
+	}
+
+	empty := hrtime.NewStreamingBenchmark()
+	empty.Stop()
+	if len(empty.Laps()) != 0 || empty.Next() {
+		t.Errorf("unexpected empty benchmark")
+	}
+}
+
+func TestBenchmarkFor(t *testing.T) {
+	bench := hrtime.NewBenchmarkFor(10 * time.Millisecond)
+	start := time.Now()
+	for bench.Next() {
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < 10*time.Millisecond {
+		t.Errorf("finished before budget %v", elapsed)
+	}
+	if laps := bench.Laps(); len(laps) == 0 || len(laps) > 11 {
+		t.Errorf("unexpected lap count %d", len(laps))
+	}
+}
+
+func TestBenchmarkTSCStats(t *testing.T) {
+	bench := hrtime.NewBenchmarkTSC(64)
+	for bench.Next() {
+	}
+
+	stats := bench.Stats()
+	counts := bench.CountQuantiles(0, 0.5, 0.9, 1)
+	if stats.Count != 64 || stats.Minimum != counts[0].ApproxDuration() || stats.Maximum != counts[3].ApproxDuration() {
+		t.Errorf("unexpected stats %v for %v", stats, counts)
+	}
+	if stats.P50 != counts[1].ApproxDuration() || stats.Percentile(0.9) != counts[2].ApproxDuration() {
+		t.Errorf("expected p50 %v and p90 %v, got %v and %v", counts[1].ApproxDuration(), counts[2].ApproxDuration(), stats.P50, stats.Percentile(0.9))
+	}
+	if hist := bench.Histogram(10); hist.Minimum > hist.P50 || hist.P50 > hist.Maximum {
+		t.Errorf("unexpected histogram %v", hist)
+	}
+}
+
+// finalizingCollector signals when the benchmark starts finalizing and
+// delays the finalization.
+type finalizingCollector struct{ closed chan struct{} }
+
+func (collector *finalizingCollector) BeforeLap()                                 {}
+func (collector *finalizingCollector) AfterLap(m []hrtime.Metric) []hrtime.Metric { return m }
+func (collector *finalizingCollector) Close() error {
+	close(collector.closed)
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestBenchmarkStatsDuringLastNext(t *testing.T) {
+	collector := &finalizingCollector{closed: make(chan struct{})}
+	bench := hrtime.NewBenchmark(64, hrtime.WithCollector(collector))
+
+	stats := make(chan *hrtime.Stats)
+	go func() {
+		// the last call to Next is finalizing the benchmark
+		<-collector.closed
+		stats <- bench.Stats()
+	}()
+
+	for bench.Next() {
+	}
+	if got := <-stats; got.Count != 64 {
+		t.Errorf("expected 64 laps, got %v", got.Count)
+	}
+}
+
+func TestBenchmarkTSCStatsDuringLastNext(t *testing.T) {
+	collector := &finalizingCollector{closed: make(chan struct{})}
+	bench := hrtime.NewBenchmarkTSC(64, hrtime.WithCollector(collector))
+
+	stats := make(chan *hrtime.Stats)
+	go func() {
+		<-collector.closed
+		stats <- bench.Stats()
+	}()
+
+	for bench.Next() {
+	}
+	if got := <-stats; got.Count != 64 {
+		t.Errorf("expected 64 laps, got %v", got.Count)
+	}
+}
+
+func TestBenchmarkLapsUnsafe(t *testing.T) {
+	bench := hrtime.NewBenchmark(8)
+	for bench.Next() {
+	}
+	laps, unsafe := bench.Laps(), bench.LapsUnsafe()
+	if len(laps) != len(unsafe) {
+		t.Fatalf("length mismatch: %d != %d", len(laps), len(unsafe))
+	}
+	for i := range laps {
+		if laps[i] != unsafe[i] {
+			t.Errorf("lap %d: %v != %v", i, laps[i], unsafe[i])
+		}
+	}
+}
+
+func TestBenchmarkAll(t *testing.T) {
+	bench := hrtime.NewBenchmark(8)
+	for bench.Next() {
+	}
+	laps := bench.Laps()
+	n := 0
+	for i, lap := range bench.All() {
+		if laps[i] != lap {
+			t.Errorf("lap %d: %v != %v", i, laps[i], lap)
+		}
+		n++
+	}
+	if n != len(laps) {
+		t.Errorf("iterated %d laps, expected %d", n, len(laps))
+	}
+}
+
+func TestBenchmarkWarmup(t *testing.T) {
+	bench := hrtime.NewBenchmark(5, hrtime.WithWarmup(3), hrtime.WithTimestamps())
+	iterations := 0
+	for bench.Next() {
+		iterations++
+		if iterations <= 3 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	if iterations != 8 {
+		t.Fatalf("expected 8 iterations, got %d", iterations)
+	}
+	laps := bench.Laps()
+	if len(laps) != 5 || len(bench.Timestamps()) != 5 {
+		t.Fatalf("expected 5 laps, got %d", len(laps))
+	}
+	for _, lap := range laps {
+		if lap >= 5*time.Millisecond {
+			t.Errorf("warmup lap %v included", lap)
+		}
+	}
+	start, _ := bench.Timeline()
+	if start != bench.Timestamps()[0] {
+		t.Errorf("start %v does not match first measured lap %v", start, bench.Timestamps()[0])
+	}
+
+	tsc := hrtime.NewBenchmarkTSC(5, hrtime.WithWarmup(2))
+	iterations = 0
+	for tsc.Next() {
+		iterations++
+	}
+	if iterations != 7 || len(tsc.Counts()) != 5 {
+		t.Errorf("expected 7 iterations and 5 counts, got %d and %d", iterations, len(tsc.Counts()))
+	}
+
+	streaming := hrtime.NewStreamingBenchmark(hrtime.WithWarmup(10))
+	for i := 0; i < 4 && streaming.Next(); i++ {
+	}
+	streaming.Stop()
+	if n := len(streaming.Laps()); n != 0 {
+		t.Errorf("expected all laps to be warmup, got %d", n)
+	}
+}
+
+func TestBenchmarkRecord(t *testing.T) {
+	bench := hrtime.NewBenchmark(3)
+	for _, d := range []time.Duration{time.Millisecond, 2 * time.Millisecond, -time.Millisecond} {
+		bench.Record(d)
+	}
+	if !bench.Completed() {
+		t.Fatal("expected benchmark to complete after count laps")
+	}
+	laps := bench.Laps()
+	if laps[0] != time.Millisecond || laps[1] != 2*time.Millisecond || laps[2] != 0 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+	if bench.NonMonotonic() != 1 {
+		t.Errorf("expected negative duration to be non-monotonic")
+	}
+	if start, stop := bench.Timeline(); stop-start != 2*time.Millisecond {
+		t.Errorf("unexpected timeline %v", stop-start)
+	}
+
+	streaming := hrtime.NewStreamingBenchmark()
+	for i := 1; i <= 5; i++ {
+		streaming.Record(time.Duration(i) * time.Microsecond)
+	}
+	streaming.Stop()
+	if laps := streaming.Laps(); len(laps) != 5 || laps[4] != 5*time.Microsecond {
+		t.Errorf("unexpected streaming laps %v", laps)
+	}
+}
+
+func TestBenchmarkTSCParity(t *testing.T) {
+	errFailed := errors.New("failed")
+	clock := hrtime.NewManualClock(0)
+
+	bench := hrtime.NewBenchmarkTSC(6, hrtime.WithWarmup(1), hrtime.WithClock(clock))
+	bench.SetBytes(100)
+	var err error
+	for i := 0; bench.NextErr(err); i++ {
+		err = nil
+		if i%2 == 1 {
+			clock.Advance(10 * time.Millisecond)
+			err = errFailed
+		} else {
+			clock.Advance(time.Millisecond)
+		}
+	}
+	if got := bench.Failures(); got != 3 {
+		t.Errorf("expected 3 failures, got %d", got)
+	}
+	if failed, succeeded := bench.Failed().Stats(), bench.Succeeded().Stats(); failed.Minimum <= succeeded.Maximum {
+		t.Errorf("failed laps not separated:\n%s", bench.StringOutcomeStats())
+	}
+	if metadata := bench.JSONResult("tsc").Metadata; metadata["failed"] != "3" {
+		t.Errorf("expected failures in metadata, got %v", metadata)
+	}
+	if throughput := bench.Throughput(); throughput.Laps != 6 || throughput.Bytes != 100 {
+		t.Errorf("unexpected throughput %+v", throughput)
+	}
+	if outliers := bench.Outliers(hrtime.OutlierCriterion{}); outliers.Count() != 0 {
+		t.Errorf("unexpected outliers %v", outliers.Laps)
+	}
+
+	labeled := hrtime.NewBenchmarkTSC(4, hrtime.WithClock(clock))
+	for i := 0; labeled.NextWithLabel([]string{"fast", "slow"}[i%2]); i++ {
+		clock.Advance(time.Duration(1+i%2) * time.Millisecond)
+	}
+	if labels := labeled.LapLabels(); len(labels) != 4 || labels[1] != "slow" {
+		t.Errorf("unexpected labels %q", labels)
+	}
+	if fast, slow := labeled.ForLabel("fast"), labeled.ForLabel("slow"); fast.Count() != 2 || slow.Stats().Minimum <= fast.Stats().Maximum {
+		t.Errorf("laps assigned to wrong labels:\n%s", labeled.StringLabelStats())
+	}
+	if comparison := hrtime.CompareTSC(bench, labeled); len(comparison.Deltas) == 0 {
+		t.Error("expected deltas")
+	}
+
+	stopped := hrtime.NewBenchmarkTSC(10, hrtime.WithWarmup(1), hrtime.WithClock(clock))
+	for i := 0; i < 3 && stopped.Next(); i++ {
+	}
+	stopped.Stop()
+	if stopped.Next() || len(stopped.Counts()) != 2 {
+		t.Errorf("expected 2 counts after Stop, got %d", len(stopped.Counts()))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	canceled := hrtime.NewBenchmarkTSC(10, hrtime.WithClock(clock))
+	for i := 0; canceled.NextCtx(ctx); i++ {
+		if i == 2 {
+			cancel()
+		}
+	}
+	if !canceled.Canceled() || !errors.Is(canceled.Err(), context.Canceled) || len(canceled.Counts()) != 3 {
+		t.Errorf("unexpected canceled benchmark %v %d", canceled.Err(), len(canceled.Counts()))
+	}
+}
+
+func TestBenchmarkTSCStreaming(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+
+	streaming := hrtime.NewStreamingBenchmarkTSC(hrtime.WithClock(clock))
+	for i := 0; streaming.Next(); i++ {
+		clock.Advance(time.Microsecond)
+		if i == 9 {
+			streaming.Stop()
+		}
+	}
+	if counts := streaming.Counts(); len(counts) != 10 || counts[9] != 1000 {
+		t.Errorf("unexpected streaming counts %v", counts)
+	}
+
+	recorded := hrtime.NewBenchmarkTSC(3, hrtime.WithClock(clock))
+	for _, count := range []hrtime.Count{100, 200, 300} {
+		recorded.Record(count)
+	}
+	if counts := recorded.Counts(); !recorded.Completed() || counts[2] != 300 {
+		t.Errorf("unexpected recorded counts %v", counts)
+	}
+	if start, stop := recorded.Timeline(); stop-start != 600 {
+		t.Errorf("unexpected timeline %v", stop-start)
+	}
+
+	recorded.Reset()
+	for recorded.Next() {
+		clock.Advance(50)
+	}
+	if counts := recorded.Counts(); len(counts) != 3 || counts[0] != 50 {
+		t.Errorf("unexpected counts after Reset %v", counts)
+	}
+
+	stable := hrtime.NewStableBenchmarkTSC(1000, 0.01, hrtime.WithClock(clock))
+	for stable.Next() {
+		clock.Advance(100)
+	}
+	if !stable.Stable() || stable.RelativeError() != 0 || len(stable.Counts()) != hrtime.StableMinCount {
+		t.Errorf("expected stable after %d laps, got %d with error %v", hrtime.StableMinCount, len(stable.Counts()), stable.RelativeError())
+	}
+
+	budget := hrtime.NewBenchmarkTSCFor(10 * time.Millisecond)
+	start := time.Now()
+	for budget.Next() {
+		time.Sleep(time.Millisecond)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond || len(budget.Counts()) == 0 {
+		t.Errorf("unexpected budget benchmark: %d laps in %v", len(budget.Counts()), elapsed)
+	}
+}
+
+func TestBenchmarkTSCAnalysis(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	measure := func() *hrtime.BenchmarkTSC {
+		bench := hrtime.NewBenchmarkTSC(4, hrtime.WithClock(clock), hrtime.WithTimestamps())
+		for i := 0; bench.Next(); i++ {
+			clock.Advance(time.Duration(i+1) * time.Millisecond)
+		}
+		return bench
+	}
+
+	bench := measure()
+	spans := bench.Spans()
+	if len(spans) != 4 || spans[1].Start != spans[0].Finish || spans[3].Duration() <= spans[0].Duration() {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+	if series := bench.TimeSeries(); len(series.Points) != 4 || series.Points[1].Elapsed != spans[1].Start {
+		t.Errorf("unexpected time series %+v", series.Points)
+	}
+	deadline := (spans[1].Duration() + spans[2].Duration()) / 2
+	if deadlines := bench.Deadlines(deadline); deadlines.Misses != 2 {
+		t.Errorf("expected 2 missed deadlines of %v, got %d", deadline, deadlines.Misses)
+	}
+	if n := bench.RequiredSamples(0.1, 0.8); n < 2 {
+		t.Errorf("unexpected required samples %d", n)
+	}
+
+	reps := bench.Repeat(3, func(bench *hrtime.BenchmarkTSC) {
+		for bench.Next() {
+			clock.Advance(time.Millisecond)
+		}
+	})
+	if len(reps.Runs) != 3 || reps.MinMean() == 0 {
+		t.Errorf("unexpected repetitions %v", reps.Means())
+	}
+
+	merged := hrtime.MergeBenchmarkTSCsNamed([]string{"a", "b"}, measure(), measure())
+	if sources := merged.Sources(); len(sources) != 2 || sources[1].Name != "b" || sources[1].Count != 4 {
+		t.Errorf("unexpected sources %+v", sources)
+	}
+	if laps := merged.SourceLaps(1); len(laps) != 4 {
+		t.Errorf("unexpected source laps %v", laps)
+	}
+	if result := merged.JSONResult("merged"); len(result.Sources) != 2 {
+		t.Errorf("expected sources in JSONResult, got %+v", result.Sources)
+	}
+}
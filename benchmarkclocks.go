@@ -0,0 +1,125 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClockBenchmark contains measured properties of a clock backend.
+type ClockBenchmark struct {
+	Name string
+	// Overhead is the median cost of a single read.
+	Overhead time.Duration
+	// Resolution is the smallest observed step in nanoseconds.
+	Resolution float64
+}
+
+// ClockBenchmarks is a comparison of clock backends.
+type ClockBenchmarks []ClockBenchmark
+
+// clockReader reads a clock value and converts a difference to nanoseconds.
+type clockReader struct {
+	name    string
+	read    func() int64
+	toNanos func(delta int64) float64
+}
+
+// availableClocks returns all clock backends usable on the current machine.
+func availableClocks() []clockReader {
+	nanos := func(delta int64) float64 { return float64(delta) }
+	clocks := []clockReader{
+		{name: "hrtime.Now", read: func() int64 { return int64(Now()) }, toNanos: nanos},
+		{name: "time.Now", read: func() int64 { return time.Now().UnixNano() }, toNanos: nanos},
+	}
+
+	// after CalibrateTSC falls back, TSC reads Now, which is already listed
+	if TSCSupported() && !tscFallback.Load() {
+		counts := func(delta int64) float64 {
+			return float64(Count(delta).ApproxDuration())
+		}
+		clocks = append(clocks, clockReader{name: "hrtime.TSC (" + counterName + ")", read: func() int64 { return int64(RDTSC()) }, toNanos: counts})
+		// only x86 has a separate serializing variant
+		if counterName == "TSC" {
+			clocks = append(clocks, clockReader{name: "hrtime.RDTSCP", read: func() int64 { return int64(RDTSCP()) }, toNanos: counts})
+		}
+	}
+
+	return clocks
+}
+
+// BenchmarkClocks measures overhead and resolution of every available clock backend.
+//
+// It takes several milliseconds to run.
+func BenchmarkClocks() ClockBenchmarks {
+	const rounds = 16
+
+	var results ClockBenchmarks
+	for _, clock := range availableClocks() {
+		overheads := make([]time.Duration, rounds)
+		for round := range overheads {
+			start := Now()
+			for i := 0; i < calibrationCalls; i++ {
+				clock.read()
+			}
+			overheads[round] = (Now() - start) / calibrationCalls
+		}
+		sort.Slice(overheads, func(i, k int) bool { return overheads[i] < overheads[k] })
+
+		resolution := -1.0
+		for round := 0; round < calibrationCalls; round++ {
+			start := clock.read()
+			next := clock.read()
+			for next == start {
+				next = clock.read()
+			}
+			step := clock.toNanos(next - start)
+			if resolution < 0 || step < resolution {
+				resolution = step
+			}
+		}
+
+		results = append(results, ClockBenchmark{
+			Name:       clock.name,
+			Overhead:   overheads[rounds/2],
+			Resolution: resolution,
+		})
+	}
+
+	return results
+}
+
+// WriteTo writes comparison table to w.
+func (clocks ClockBenchmarks) WriteTo(w io.Writer) (int64, error) {
+	nameLength := len("clock")
+	for _, clock := range clocks {
+		if len(clock.Name) > nameLength {
+			nameLength = len(clock.Name)
+		}
+	}
+
+	var written int64
+	n, err := fmt.Fprintf(w, "%-[1]*[2]s %10s %12s\n", nameLength, "clock", "overhead", "resolution")
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for _, clock := range clocks {
+		n, err = fmt.Fprintf(w, "%-[1]*[2]s %10v %10.2fns\n", nameLength, clock.Name, clock.Overhead, clock.Resolution)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns comparison table.
+func (clocks ClockBenchmarks) String() string {
+	var buffer strings.Builder
+	_, _ = clocks.WriteTo(&buffer)
+	return buffer.String()
+}
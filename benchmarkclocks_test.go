@@ -0,0 +1,20 @@
+package hrtime_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkClocks(t *testing.T) {
+	clocks := hrtime.BenchmarkClocks()
+	if len(clocks) < 2 {
+		t.Fatalf("expected at least two clocks, got %v", clocks)
+	}
+	for _, clock := range clocks {
+		if clock.Resolution <= 0 {
+			t.Errorf("%s: invalid resolution %v", clock.Name, clock.Resolution)
+		}
+	}
+	t.Log("\n" + clocks.String())
+}
@@ -1,12 +1,18 @@
 package hrtime
 
 import (
+	"errors"
+	"iter"
 	"math"
+	"strconv"
 	"time"
 )
 
 // MergeBenchmarkTSCs merge multiple BenchmarkTSC so we can use it in concurrent cases.
 // Each goroutine uses its BenchmarkTSC and we can merge the results into one BenchmarkTSC.
+//
+// The merged benchmark retains laps of each benchmark as a source,
+// see Sources and MergeBenchmarkTSCsNamed.
 func MergeBenchmarkTSCs(benchmarks ...*BenchmarkTSC) *BenchmarkTSC {
 	if len(benchmarks) == 0 {
 		return nil
@@ -15,9 +21,15 @@ func MergeBenchmarkTSCs(benchmarks ...*BenchmarkTSC) *BenchmarkTSC {
 	var start = Count(math.MaxInt64)
 	var stop Count
 	var counts []Count
-	for _, b := range benchmarks {
-		b.mustBeCompleted()
-		counts = append(counts, b.counts...)
+	var nonMonotonic int
+	var errs []error
+	sources := make([]benchmarkSource, 0, len(benchmarks))
+	for i, b := range benchmarks {
+		b = b.mustBeCompleted()
+		counts = append(counts, b.laps...)
+		sources = append(sources, benchmarkSource{name: strconv.Itoa(i), end: len(counts)})
+		nonMonotonic += b.nonMonotonic
+		errs = append(errs, b.err)
 		if b.start < start {
 			start = b.start
 		}
@@ -26,101 +38,403 @@ func MergeBenchmarkTSCs(benchmarks ...*BenchmarkTSC) *BenchmarkTSC {
 		}
 	}
 
-	return &BenchmarkTSC{
-		step:   len(counts),
-		counts: counts,
-		start:  start,
-		stop:   stop,
+	merged := &BenchmarkTSC{
+		lapCore: lapCore[Count]{
+			step:  len(counts),
+			laps:  counts,
+			start: start,
+			stop:  stop,
+
+			nonMonotonic: nonMonotonic,
+			err:          errors.Join(errs...),
+			sources:      sources,
+		},
 	}
+	merged.done.Store(true)
+	tracef("merge", "%d tsc benchmarks, %d laps, %d non-monotonic", len(benchmarks), len(counts), nonMonotonic)
+	return merged
 }
 
 // BenchmarkTSC helps benchmarking using CPU counters.
 type BenchmarkTSC struct {
-	step   int
-	counts []Count
-	start  Count
-	stop   Count
+	lapCore[Count]
+
+	onComplete []func(*BenchmarkTSC)
+	// result is created lazily by Result.
+	result *Result
 }
 
 // NewBenchmarkTSC creates a new benchmark using CPU counters.
 // Count defines the number of samples to measure.
-func NewBenchmarkTSC(count int) *BenchmarkTSC {
+func NewBenchmarkTSC(count int, opts ...Option) *BenchmarkTSC {
+	config := newOptions(opts)
 	if count <= 0 {
-		panic("must have count at least 1")
+		return newMisusedBenchmarkTSC(config, config.misuse("must have count at least 1"))
+	}
+	count += config.warmup
+
+	bench := &BenchmarkTSC{
+		lapCore: lapCore[Count]{
+			step:  0,
+			laps:  make([]Count, count),
+			start: 0,
+			stop:  0,
+			opts:  config,
+		},
+	}
+	if config.sampled() {
+		bench.laps = nil
+		bench.sampling = newLapSampler[Count](&bench.opts, count)
+	}
+	if bench.opts.timestamps {
+		bench.timestamps = make([]Count, count)
+	}
+	if bench.opts.memStats {
+		bench.memStart = readMemSnapshot()
+	}
+	return bench
+}
+
+// NewStreamingBenchmarkTSC creates a benchmark using CPU counters
+// without a fixed number of laps, see NewStreamingBenchmark.
+func NewStreamingBenchmarkTSC(opts ...Option) *BenchmarkTSC {
+	bench := &BenchmarkTSC{
+		lapCore: lapCore[Count]{
+			unbounded: true,
+			opts:      newOptions(opts),
+		},
+	}
+	if bench.opts.sampled() {
+		bench.sampling = newLapSampler[Count](&bench.opts, 0)
+	}
+	if bench.opts.timestamps {
+		bench.timestamps = []Count{}
+	}
+	if bench.opts.memStats {
+		bench.memStart = readMemSnapshot()
 	}
+	return bench
+}
 
-	return &BenchmarkTSC{
-		step:   0,
-		counts: make([]Count, count),
-		start:  0,
-		stop:   0,
+// NewBenchmarkTSCFor creates a benchmark using CPU counters that measures
+// laps until budget is exhausted, see NewBenchmarkFor.
+//
+// The budget is converted to counts using the approximate TSC frequency.
+func NewBenchmarkTSCFor(budget time.Duration, opts ...Option) *BenchmarkTSC {
+	bench := NewStreamingBenchmarkTSC(opts...)
+	if budget <= 0 {
+		return newMisusedBenchmarkTSC(bench.opts, bench.opts.misuse("budget must be positive"))
 	}
+	bench.budget = loadTSCRatio().approxCount(budget)
+	return bench
 }
 
-// mustBeCompleted checks whether measurement has been completed.
-func (bench *BenchmarkTSC) mustBeCompleted() {
-	if bench.stop == 0 {
-		panic("benchmarking incomplete")
+// Reset clears measurements and registered callbacks so that the benchmark
+// can measure again, reusing its lap storage.
+func (bench *BenchmarkTSC) Reset() {
+	bench.resetLaps()
+	if bench.unbounded {
+		bench.laps = bench.laps[:0]
+	} else {
+		bench.laps = bench.laps[:cap(bench.laps)]
 	}
+	bench.resetSamples()
+	bench.result = nil
+	bench.onComplete = nil
 }
 
+// mustBeCompleted checks whether measurement has been completed, waiting
+// for the completion started by another goroutine.
+//
+// Under MisuseError policy it returns an empty completed benchmark
+// reporting the misuse instead of panicking, otherwise it returns bench.
+func (bench *BenchmarkTSC) mustBeCompleted() *BenchmarkTSC {
+	if !bench.awaitCompleted() {
+		return newMisusedBenchmarkTSC(bench.opts, bench.opts.misuse("benchmarking incomplete"))
+	}
+	return bench
+}
+
+// Completed returns whether all measurements have been made.
+//
+// It is safe to call from any goroutine.
+func (bench *BenchmarkTSC) Completed() bool { return bench.done.Load() }
+
+// Done returns a channel that is closed when all measurements have been made.
+//
+// Next must be called from a single goroutine, however after Done is closed
+// the results can be read from any goroutine. Results read from another
+// goroutine while Next or Stop is completing the benchmark wait for
+// the completion, reading them earlier is misuse.
+func (bench *BenchmarkTSC) Done() <-chan struct{} { return bench.doneChannel() }
+
 // finalize calculates diffs for each lap.
-func (bench *BenchmarkTSC) finalize(last Count) {
-	if bench.stop != 0 {
-		return
+//
+// It returns true when this call completed the benchmark.
+func (bench *BenchmarkTSC) finalize(last Count) bool {
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+	if bench.done.Load() {
+		return false
+	}
+	bench.closeCollectors()
+	if bench.sampling != nil {
+		bench.finish(bench.finalizeSamples(last))
+		return true
 	}
+	warmup, measured := bench.finalizeLaps(last)
+	if measured {
+		bench.finish(warmup)
+	}
+	return true
+}
 
-	bench.start = bench.counts[0]
-	bench.stop = last
-	for i := range bench.counts[:len(bench.counts)-1] {
-		bench.counts[i] = bench.counts[i+1] - bench.counts[i]
+// finish fixes and analyzes laps converted to counts and completes the benchmark.
+func (bench *BenchmarkTSC) finish(warmup int) {
+	bench.fixLaps(func(overhead *TimerOverhead) Count { return overhead.LapTSC })
+	if bench.opts.gcPauses {
+		bench.gcPauses = readGCPauses(bench.start.ApproxDuration(), bench.stop.ApproxDuration(), time.Now(), bench.opts.tsc().ApproxDuration())
+	}
+	traceFinalize("benchmark tsc", &bench.opts, len(bench.laps), warmup, bench.nonMonotonic, bench.err)
+	bench.completeLaps()
+}
+
+// OnComplete registers fn to be called when the benchmark completes, either
+// when Next returns false for the first time or when Stop is called.
+//
+// Callbacks are called in the order they were registered, from the goroutine
+// calling Next or Stop. When the benchmark has already completed, fn is called immediately.
+func (bench *BenchmarkTSC) OnComplete(fn func(*BenchmarkTSC)) {
+	if bench.Completed() {
+		fn(bench)
+		return
 	}
-	bench.counts[len(bench.counts)-1] = bench.stop - bench.counts[len(bench.counts)-1]
+	bench.onComplete = append(bench.onComplete, fn)
+}
+
+// NonMonotonic returns the number of laps where the counter went backwards.
+func (bench *BenchmarkTSC) NonMonotonic() int {
+	bench = bench.mustBeCompleted()
+	return bench.nonMonotonic
+}
+
+// Err returns an error when measurement is not reliable.
+//
+// It reports ErrNonMonotonic when using NonMonotonicError policy and
+// the cause of the context, when stopped by NextCtx.
+func (bench *BenchmarkTSC) Err() error {
+	bench = bench.mustBeCompleted()
+	return bench.err
 }
 
 // Next starts measuring the next lap.
 // It will return false, when all measurements have been made.
 func (bench *BenchmarkTSC) Next() bool {
-	now := TSC()
-	if bench.step >= len(bench.counts) {
-		bench.finalize(now)
+	if bench.sampling != nil {
+		return bench.nextSampled()
+	}
+	return bench.next(bench.opts.tsc())
+}
+
+// next starts measuring the next lap, the lap in progress ends at now.
+func (bench *BenchmarkTSC) next(now Count) bool {
+	if bench.sampling != nil {
+		return bench.nextSampled()
+	}
+	bench.endPause(now)
+	if bench.opts.collectors != nil && bench.step > 0 && !bench.done.Load() {
+		bench.afterLap()
+	}
+	if bench.done.Load() {
 		return false
 	}
-	bench.counts[bench.step] = TSC()
-	bench.step++
+	if !bench.proceed(now) {
+		bench.complete(now)
+		return false
+	}
+	if bench.opts.collectors != nil {
+		bench.beforeLap()
+	}
+	bench.startLap(bench.opts.tsc())
 	return true
 }
 
+// Stop finishes measuring, the lap in progress ends at the time of the call.
+//
+// It allows finishing a benchmark created with NewStreamingBenchmarkTSC, or
+// finishing a benchmark early. Laps that were not started are discarded.
+// After Stop, Next returns false.
+func (bench *BenchmarkTSC) Stop() {
+	bench.stopAt(bench.opts.tsc())
+}
+
+// stopAt finishes measuring with the lap in progress ending at now.
+func (bench *BenchmarkTSC) stopAt(now Count) {
+	if bench.done.Load() {
+		return
+	}
+	if bench.recording {
+		now = bench.recordAt
+	}
+	bench.endPause(now)
+	if bench.opts.collectors != nil && bench.step > 0 && !bench.recording {
+		bench.afterLap()
+	}
+	if bench.sampling != nil {
+		bench.sampling.stop(now)
+	} else {
+		bench.laps = bench.laps[:bench.step]
+	}
+	bench.complete(now)
+}
+
+// Record adds an externally measured count as the next lap, see Benchmark.Record.
+func (bench *BenchmarkTSC) Record(count Count) {
+	if bench.done.Load() {
+		bench.misuse("benchmark already completed")
+		return
+	}
+	if bench.sampling != nil {
+		bench.misuse("cannot sample recorded laps")
+		return
+	}
+	if bench.record(count, bench.opts.tsc) {
+		bench.complete(bench.recordAt)
+	}
+}
+
+// complete finalizes the benchmark and calls OnComplete callbacks once.
+func (bench *BenchmarkTSC) complete(last Count) {
+	bench.finishing.Store(true)
+	if bench.finalize(last) {
+		for _, fn := range bench.onComplete {
+			fn(bench)
+		}
+	}
+}
+
+// Timeline returns the counter values when the benchmark started and stopped.
+func (bench *BenchmarkTSC) Timeline() (start, stop Count) {
+	bench = bench.mustBeCompleted()
+	return bench.start, bench.stop
+}
+
 // Counts returns counts for each lap.
 func (bench *BenchmarkTSC) Counts() []Count {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
+
+	return append(bench.laps[:0:0], bench.laps...)
+}
+
+// CountsUnsafe returns counts for each lap without copying.
+//
+// The returned slice shares memory with the benchmark and must be treated as read-only.
+// It avoids doubling peak memory when computing custom statistics over large benchmarks.
+func (bench *BenchmarkTSC) CountsUnsafe() []Count {
+	bench = bench.mustBeCompleted()
+	return bench.laps
+}
+
+// Spans returns the approximate time-span of each lap, see Benchmark.Spans.
+//
+// Counts are converted using Count.ApproxDuration.
+func (bench *BenchmarkTSC) Spans() []Span {
+	bench = bench.mustBeCompleted()
+	return lapSpans(&bench.lapCore, Count.ApproxDuration)
+}
 
-	return append(bench.counts[:0:0], bench.counts...)
+// Timestamps returns the start count of each lap as returned by TSC.
+//
+// It returns nil, unless the benchmark was created WithTimestamps.
+func (bench *BenchmarkTSC) Timestamps() []Count {
+	bench = bench.mustBeCompleted()
+	if bench.timestamps == nil {
+		return nil
+	}
+	return append(bench.timestamps[:0:0], bench.timestamps...)
+}
+
+// WallTimes returns the approximate wall-clock start time of each lap.
+//
+// Counts are converted using Count.ApproxDuration relative to a single
+// time.Now reading at the end of the benchmark.
+// It returns nil, unless the benchmark was created WithTimestamps.
+func (bench *BenchmarkTSC) WallTimes() []time.Time {
+	bench = bench.mustBeCompleted()
+	if bench.timestamps == nil {
+		return nil
+	}
+	times := make([]time.Time, len(bench.timestamps))
+	for i, at := range bench.timestamps {
+		times[i] = bench.wallStop.Add((at - bench.stop).ApproxDuration())
+	}
+	return times
 }
 
 // Laps returns timing for each lap using the approximate conversion of Count.
 func (bench *BenchmarkTSC) Laps() []time.Duration {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
-	laps := make([]time.Duration, len(bench.counts))
-	for i, v := range bench.counts {
+	laps := make([]time.Duration, len(bench.laps))
+	for i, v := range bench.laps {
 		laps[i] = v.ApproxDuration()
 	}
 	return laps
 }
 
+// All returns an iterator over lap indices and approximate timings.
+//
+// Counts are converted lazily using Count.ApproxDuration.
+func (bench *BenchmarkTSC) All() iter.Seq2[int, time.Duration] {
+	bench = bench.mustBeCompleted()
+	return func(yield func(int, time.Duration) bool) {
+		for i, count := range bench.laps {
+			if !yield(i, count.ApproxDuration()) {
+				return
+			}
+		}
+	}
+}
+
+// CountQuantiles returns q-th quantiles of lap counts for each of qs.
+//
+// Quantiles use linear interpolation and q is clamped to range [0, 1].
+func (bench *BenchmarkTSC) CountQuantiles(qs ...float64) []Count {
+	bench = bench.mustBeCompleted()
+
+	sorted := sortedCounts(bench.laps)
+	result := make([]Count, len(qs))
+	for i, q := range qs {
+		result[i] = quantile(sorted, q)
+	}
+	return result
+}
+
+// Quantiles returns q-th quantiles of laps for each of qs.
+//
+// Quantiles are computed on raw counts and only the results are converted
+// using Count.ApproxDuration, which avoids converting every lap.
+func (bench *BenchmarkTSC) Quantiles(qs ...float64) []time.Duration {
+	counts := bench.CountQuantiles(qs...)
+	result := make([]time.Duration, len(counts))
+	for i, count := range counts {
+		result[i] = count.ApproxDuration()
+	}
+	return result
+}
+
 // Histogram creates an histogram of all the laps.
 //
 // It creates binCount bins to distribute the data and uses the
 // 99.9 percentile as the last bucket range. However, for a nicer output
 // it might choose a larger value.
 func (bench *BenchmarkTSC) Histogram(binCount int) *Histogram {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	opts := defaultOptions
 	opts.BinCount = binCount
 
-	return NewDurationHistogram(bench.Laps(), &opts)
+	return NewHistogram(countNanos(bench.laps), &opts)
 }
 
 // HistogramClamp creates an historgram of all the laps clamping minimum and maximum time.
@@ -128,22 +442,17 @@ func (bench *BenchmarkTSC) Histogram(binCount int) *Histogram {
 // It creates binCount bins to distribute the data and uses the
 // maximum as the last bucket.
 func (bench *BenchmarkTSC) HistogramClamp(binCount int, min, max time.Duration) *Histogram {
-	bench.mustBeCompleted()
-
-	laps := make([]time.Duration, 0, len(bench.counts))
-	for _, count := range bench.counts {
-		lap := count.ApproxDuration()
-		if lap < min {
-			laps = append(laps, min)
-		} else {
-			laps = append(laps, lap)
-		}
-	}
-
-	opts := defaultOptions
-	opts.BinCount = binCount
-	opts.ClampMaximum = float64(max.Nanoseconds())
-	opts.ClampPercentile = 0
+	bench = bench.mustBeCompleted()
+	return newClampHistogram(clampNanos(countNanos(bench.laps), min), binCount, max)
+}
 
-	return NewDurationHistogram(laps, &opts)
+// HistogramClampPercentile creates an histogram of all the laps clamping minimum time
+// and the maximum to the percentile of the data.
+//
+// Unlike HistogramClamp, the maximum adapts to the measurements, e.g. using 0.995
+// keeps outliers in the last bucket without hardcoding a maximum per machine.
+// Percentile must be in range (0, 1].
+func (bench *BenchmarkTSC) HistogramClampPercentile(binCount int, min time.Duration, percentile float64) *Histogram {
+	bench = bench.mustBeCompleted()
+	return newPercentileHistogram(clampNanos(countNanos(bench.laps), min), binCount, percentile)
 }
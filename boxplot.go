@@ -0,0 +1,317 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Box contains five-number summary of a distribution.
+type Box struct {
+	Name string
+
+	Minimum time.Duration
+	Q1      time.Duration
+	Median  time.Duration
+	Q3      time.Duration
+	Maximum time.Duration
+
+	// LowerWhisker and UpperWhisker are the most extreme values within 1.5 IQR.
+	LowerWhisker time.Duration
+	UpperWhisker time.Duration
+	// Outliers is the number of values outside of whiskers.
+	Outliers int
+
+	// Density is the estimated density of laps at evenly spaced points
+	// from LowerWhisker to UpperWhisker relative to its maximum,
+	// which is drawn as the outline of a violin plot.
+	Density []float64
+}
+
+// boxDensityPoints is the number of points where Box.Density is estimated.
+const boxDensityPoints = 32
+
+// defaultBoxPlotWidth is used, when BoxPlot.Width is not positive.
+const defaultBoxPlotWidth = 60
+
+// NewBox calculates box plot summary of laps.
+func NewBox(name string, laps []time.Duration) Box {
+	box := Box{Name: name}
+	if len(laps) == 0 {
+		return box
+	}
+
+	sorted := sortedDurations(laps)
+	box.Minimum = sorted[0]
+	box.Q1 = quantile(sorted, 0.25)
+	box.Median = quantile(sorted, 0.5)
+	box.Q3 = quantile(sorted, 0.75)
+	box.Maximum = sorted[len(sorted)-1]
+
+	fence := time.Duration(1.5 * float64(box.Q3-box.Q1))
+	low, high := box.Q1-fence, box.Q3+fence
+	box.LowerWhisker, box.UpperWhisker = box.Maximum, box.Minimum
+	for _, lap := range sorted {
+		if lap < low || lap > high {
+			box.Outliers++
+			continue
+		}
+		if lap < box.LowerWhisker {
+			box.LowerWhisker = lap
+		}
+		if lap > box.UpperWhisker {
+			box.UpperWhisker = lap
+		}
+	}
+	box.Density = boxDensity(sorted, box.LowerWhisker, box.UpperWhisker, box.Q3-box.Q1)
+
+	return box
+}
+
+// boxDensity estimates density of sorted laps between low and high using
+// a Gaussian kernel with bandwidth given by Silverman's rule of thumb.
+func boxDensity(sorted []time.Duration, low, high, iqr time.Duration) []float64 {
+	var mean, m2 float64
+	for i, lap := range sorted {
+		delta := float64(lap) - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (float64(lap) - mean)
+	}
+	spread := math.Sqrt(m2 / float64(len(sorted)))
+	if iqr > 0 {
+		spread = math.Min(spread, float64(iqr)/1.34)
+	}
+	bandwidth := 0.9 * spread * math.Pow(float64(len(sorted)), -0.2)
+	if bandwidth <= 0 || high <= low {
+		return nil
+	}
+
+	density := make([]float64, boxDensityPoints)
+	peak := 0.0
+	for i := range density {
+		at := float64(low) + float64(high-low)*float64(i)/float64(boxDensityPoints-1)
+		for _, lap := range sorted {
+			z := (float64(lap) - at) / bandwidth
+			density[i] += math.Exp(-z * z / 2)
+		}
+		peak = math.Max(peak, density[i])
+	}
+	for i := range density {
+		density[i] /= peak
+	}
+	return density
+}
+
+// densityAt returns the density of box at fraction p in range [0, 1] of
+// the range between whiskers.
+func (box *Box) densityAt(p float64) float64 {
+	if len(box.Density) == 0 {
+		return 1
+	}
+	return box.Density[int(math.Round(p*float64(len(box.Density)-1)))]
+}
+
+// BoxPlot compares distributions of several benchmarks side by side.
+type BoxPlot struct {
+	Boxes []Box
+
+	// for pretty printing
+	Width int
+}
+
+// NewBoxPlot creates an empty box plot.
+func NewBoxPlot() *BoxPlot {
+	return &BoxPlot{Width: defaultBoxPlotWidth}
+}
+
+// width returns Width or the default, when Width is not positive.
+func (plot *BoxPlot) width() int {
+	if plot.Width <= 0 {
+		return defaultBoxPlotWidth
+	}
+	return plot.Width
+}
+
+// Add adds a new distribution to the plot.
+func (plot *BoxPlot) Add(name string, laps []time.Duration) {
+	plot.Boxes = append(plot.Boxes, NewBox(name, laps))
+}
+
+// axis returns the range covering all whiskers.
+func (plot *BoxPlot) axis() (low, high time.Duration) {
+	if len(plot.Boxes) == 0 {
+		return 0, 0
+	}
+	low, high = plot.Boxes[0].LowerWhisker, plot.Boxes[0].UpperWhisker
+	for _, box := range plot.Boxes[1:] {
+		if box.LowerWhisker < low {
+			low = box.LowerWhisker
+		}
+		if box.UpperWhisker > high {
+			high = box.UpperWhisker
+		}
+	}
+	return low, high
+}
+
+// WriteTo writes box plots as text to w.
+//
+// Each row looks like `├──▒▒▒┃▒▒──┤`, where `▒` spans the interquartile range,
+// `┃` is the median and the whiskers extend up to 1.5 IQR. It's followed by
+// a row like `▁▃▇█▆▃▁` showing the density of laps between whiskers.
+func (plot *BoxPlot) WriteTo(w io.Writer) (int64, error) {
+	low, high := plot.axis()
+	span := float64(high - low)
+	width := plot.width()
+
+	nameLength := 0
+	for _, box := range plot.Boxes {
+		if len(box.Name) > nameLength {
+			nameLength = len(box.Name)
+		}
+	}
+
+	position := func(v time.Duration) int {
+		if span <= 0 {
+			return 0
+		}
+		p := int(math.Round(float64(v-low) / span * float64(width-1)))
+		if p < 0 {
+			return 0
+		}
+		if p >= width {
+			return width - 1
+		}
+		return p
+	}
+
+	const levels = " ▁▂▃▄▅▆▇█"
+	shades := []rune(levels)
+
+	var written int64
+	for _, box := range plot.Boxes {
+		row := []rune(strings.Repeat(" ", width))
+		lw, q1, med, q3, uw := position(box.LowerWhisker), position(box.Q1), position(box.Median), position(box.Q3), position(box.UpperWhisker)
+		for i := lw; i <= uw; i++ {
+			row[i] = '─'
+		}
+		for i := q1; i <= q3; i++ {
+			row[i] = '▒'
+		}
+		row[lw], row[uw] = '├', '┤'
+		row[med] = '┃'
+
+		n, err := fmt.Fprintf(w, "%-[1]*[2]s |%[3]s| p50 %[4]v, IQR %[5]v-%[6]v, outliers %[7]d\n",
+			nameLength, box.Name, string(row),
+			formatStat(float64(box.Median)),
+			formatStat(float64(box.Q1)),
+			formatStat(float64(box.Q3)),
+			box.Outliers)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		violin := []rune(strings.Repeat(" ", width))
+		for i := lw; i <= uw; i++ {
+			p := 0.0
+			if uw > lw {
+				p = float64(i-lw) / float64(uw-lw)
+			}
+			violin[i] = shades[int(math.Round(box.densityAt(p)*float64(len(shades)-1)))]
+		}
+		n, err = fmt.Fprintf(w, "%-[1]*[2]s |%[3]s|\n", nameLength, "", string(violin))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	lowLabel, highLabel := low.String(), high.String()
+	n, err := fmt.Fprintf(w, "%-[1]*[2]s  %-[3]*[4]s%[5]s\n", nameLength, "", width-utf8.RuneCountInString(highLabel), lowLabel, highLabel)
+	written += int64(n)
+	return written, err
+}
+
+// String returns box plots as text.
+func (plot *BoxPlot) String() string {
+	var buffer strings.Builder
+	_, _ = plot.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// WriteSVG writes vertical violin plots with box plots inside in SVG format to w.
+func (plot *BoxPlot) WriteSVG(w io.Writer) (int64, error) {
+	const (
+		boxWidth = 60
+		innerBox = 16
+		spacing  = 40
+		height   = 300
+		margin   = 30
+	)
+
+	low, high := plot.axis()
+	span := float64(high - low)
+	y := func(v time.Duration) float64 {
+		if span <= 0 {
+			return height / 2
+		}
+		return margin + (1-float64(v-low)/span)*(height-2*margin)
+	}
+
+	width := margin + len(plot.Boxes)*(boxWidth+spacing)
+
+	var written int64
+	n, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", width, height+margin)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for i, box := range plot.Boxes {
+		x := float64(margin + i*(boxWidth+spacing))
+		mid := x + boxWidth/2
+
+		var outline strings.Builder
+		for k := 0; k <= 2*boxDensityPoints; k++ {
+			// right side from the lower to the upper whisker, then back on the left side
+			p, side := float64(k)/boxDensityPoints, 1.0
+			if k > boxDensityPoints {
+				p, side = float64(2*boxDensityPoints-k)/boxDensityPoints, -1
+			}
+			at := box.LowerWhisker + time.Duration(p*float64(box.UpperWhisker-box.LowerWhisker))
+			command := "L"
+			if k == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(&outline, "%s%.1f %.1f ", command, mid+side*box.densityAt(p)*boxWidth/2, y(at))
+		}
+		n, err = fmt.Fprintf(w, `<path d="%sZ" fill="#9ecae9" stroke="#4c78a8"/>`+"\n", outline.String())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		x = mid - innerBox/2
+		n, err = fmt.Fprintf(w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black"/>`+"\n"+
+			`<rect x="%.1f" y="%.1f" width="%d" height="%.1f" fill="#4c78a8" stroke="black"/>`+"\n"+
+			`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="white" stroke-width="2"/>`+"\n"+
+			`<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			mid, y(box.LowerWhisker), mid, y(box.UpperWhisker),
+			x, y(box.Q3), innerBox, y(box.Q1)-y(box.Q3),
+			x, y(box.Median), x+innerBox, y(box.Median),
+			mid, height+margin/2, box.Name)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	n, err = fmt.Fprintf(w, `<text x="2" y="%d">%v</text>`+"\n"+`<text x="2" y="%d">%v</text>`+"\n</svg>\n",
+		margin-5, high, height-margin+15, low)
+	written += int64(n)
+	return written, err
+}
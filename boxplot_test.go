@@ -0,0 +1,45 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestNewBox(t *testing.T) {
+	laps := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 100}
+	box := hrtime.NewBox("a", laps)
+	if box.Median != 5 {
+		t.Errorf("median: got %v", box.Median)
+	}
+	if box.Outliers != 1 || box.UpperWhisker != 9 {
+		t.Errorf("outliers: got %v, upper whisker %v", box.Outliers, box.UpperWhisker)
+	}
+}
+
+func TestBoxPlot(t *testing.T) {
+	plot := hrtime.NewBoxPlot()
+	plot.Add("fast", []time.Duration{10, 20, 30, 40, 50})
+	plot.Add("slow", []time.Duration{30, 40, 50, 60, 70})
+
+	out := plot.String()
+	if strings.Count(out, "┃") != 2 {
+		t.Errorf("expected two medians:\n%s", out)
+	}
+	t.Log("\n" + out)
+
+	var svg strings.Builder
+	if _, err := plot.WriteSVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(svg.String(), "<path") {
+		t.Errorf("expected violin outlines:\n%s", svg.String())
+	}
+
+	plot.Width = 0
+	if out := plot.String(); !strings.Contains(out, "█") {
+		t.Errorf("expected violin with default width:\n%s", out)
+	}
+}
@@ -0,0 +1,108 @@
+package hrtime
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// BurnInOptions configures BurnIn.
+type BurnInOptions struct {
+	// Window is the number of probe calls per window.
+	Window int
+	// Tolerance is the maximum relative change of median between windows.
+	Tolerance float64
+	// StableWindows is the number of consecutive stable windows required.
+	StableWindows int
+	// MaxDuration stops burn-in when it takes longer.
+	MaxDuration time.Duration
+}
+
+var defaultBurnInOptions = BurnInOptions{
+	Window:        32,
+	Tolerance:     0.05,
+	StableWindows: 3,
+	MaxDuration:   10 * time.Second,
+}
+
+// BurnInResult describes how long it took for probe latency to stabilize.
+type BurnInResult struct {
+	// Iterations is the number of probe calls made.
+	Iterations int
+	// Duration is the total time spent on burn-in.
+	Duration time.Duration
+	// Median is the median probe latency of the last window.
+	Median time.Duration
+	// Stable reports whether latency stabilized before MaxDuration.
+	Stable bool
+}
+
+// String returns a short description of burn-in, suitable for metadata.
+func (result BurnInResult) String() string {
+	return fmt.Sprintf("burn-in %v (%d iterations), stable %v, median %v", result.Duration, result.Iterations, result.Stable, result.Median)
+}
+
+// BurnIn calls probe repeatedly until its latency stabilizes.
+//
+// It's intended for warming up external dependencies, such as database
+// connection pools and caches, before the actual benchmark starts.
+// Latency is considered stable when the medians of StableWindows consecutive
+// windows stay within Tolerance of the window preceding them.
+//
+// When opts is nil, default options are used.
+func BurnIn(probe func(), opts *BurnInOptions) BurnInResult {
+	config := defaultBurnInOptions
+	if opts != nil {
+		config = *opts
+	}
+	if config.Window <= 0 {
+		panic("window must be at least 1")
+	}
+
+	var result BurnInResult
+	window := make([]time.Duration, config.Window)
+
+	start := Now()
+	reference := time.Duration(-1)
+	stable := 0
+	for {
+		for i := range window {
+			lapStart := Now()
+			probe()
+			window[i] = Since(lapStart)
+		}
+		result.Iterations += len(window)
+		result.Median = quantile(sortedDurations(window), 0.5)
+
+		// compare against the first window of the stable streak,
+		// such that slow gradual drift is not considered stable
+		if reference >= 0 && relativeChange(reference, result.Median) <= config.Tolerance {
+			stable++
+		} else {
+			reference = result.Median
+			stable = 0
+		}
+
+		if stable >= config.StableWindows {
+			result.Stable = true
+			break
+		}
+		if config.MaxDuration > 0 && Since(start) > config.MaxDuration {
+			break
+		}
+	}
+	result.Duration = Since(start)
+
+	return result
+}
+
+// relativeChange returns |b-a|/a.
+func relativeChange(a, b time.Duration) float64 {
+	if a == 0 {
+		if b == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Abs(float64(b-a)) / float64(a)
+}
@@ -0,0 +1,35 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBurnIn(t *testing.T) {
+	calls := 0
+	result := hrtime.BurnIn(func() {
+		calls++
+		// simulate a dependency getting faster during the first 100 calls
+		spin := time.Microsecond
+		if calls < 100 {
+			spin += time.Duration(100-calls) * 10 * time.Microsecond
+		}
+		for start := hrtime.Now(); hrtime.Since(start) < spin; {
+		}
+	}, &hrtime.BurnInOptions{
+		Window:        10,
+		Tolerance:     0.1,
+		StableWindows: 2,
+		MaxDuration:   5 * time.Second,
+	})
+
+	if !result.Stable {
+		t.Fatalf("expected stable result: %v", result)
+	}
+	if result.Iterations < 100 {
+		t.Errorf("expected at least 100 iterations: %v", result)
+	}
+	t.Log(result)
+}
@@ -0,0 +1,152 @@
+package hrtime
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrSyscallCountsUnsupported is returned when syscall counts can't be read on this platform.
+var ErrSyscallCountsUnsupported = errors.New("syscall counts not supported")
+
+// Names of metrics reported by call counting collectors.
+const (
+	MetricCgoCalls            = "cgo.calls"
+	MetricSyscallsRead        = "syscalls.read"
+	MetricSyscallsWrite       = "syscalls.write"
+	MetricVoluntarySwitches   = "ctxswitch.voluntary"
+	MetricInvoluntarySwitches = "ctxswitch.involuntary"
+)
+
+// WithCallCounts records the number of cgo calls and, where obtainable,
+// syscalls and context switches during every lap, see NewCgoCollector
+// and NewSyscallCollector.
+//
+// The counts help attributing latency differences in mixed Go and C
+// workloads, e.g. whether a slower lap made more calls or blocked in the kernel.
+func WithCallCounts() Option {
+	return func(opts *options) {
+		opts.collectors = append(opts.collectors, NewCgoCollector())
+		if collector, err := NewSyscallCollector(); err == nil {
+			opts.collectors = append(opts.collectors, collector)
+		}
+	}
+}
+
+// cgoCollector counts cgo calls made by the process.
+type cgoCollector struct {
+	start int64
+}
+
+// NewCgoCollector creates a collector reporting the number of cgo calls during
+// a lap as MetricCgoCalls.
+//
+// Calls are counted for the whole process, including other goroutines.
+func NewCgoCollector() Collector { return &cgoCollector{} }
+
+// BeforeLap implements Collector.
+func (collector *cgoCollector) BeforeLap() { collector.start = runtime.NumCgoCall() }
+
+// AfterLap implements Collector.
+func (collector *cgoCollector) AfterLap(metrics []Metric) []Metric {
+	calls := runtime.NumCgoCall() - collector.start
+	return append(metrics, Metric{Name: MetricCgoCalls, Value: float64(calls)})
+}
+
+// syscallCounts are cumulative counters of the process.
+type syscallCounts struct {
+	read, write            int64
+	voluntary, involuntary int64
+}
+
+// since returns the counters since start.
+func (counts syscallCounts) since(start syscallCounts) syscallCounts {
+	return syscallCounts{
+		read:        counts.read - start.read,
+		write:       counts.write - start.write,
+		voluntary:   counts.voluntary - start.voluntary,
+		involuntary: counts.involuntary - start.involuntary,
+	}
+}
+
+// syscallCollector counts syscalls and context switches of the process.
+type syscallCollector struct {
+	reader syscallReader
+	// overhead are counts caused by reading the counters.
+	overhead syscallCounts
+	start    syscallCounts
+}
+
+// NewSyscallCollector creates a collector reporting the number of read and
+// write syscalls and context switches during a lap, as MetricSyscallsRead,
+// MetricSyscallsWrite, MetricVoluntarySwitches and MetricInvoluntarySwitches.
+//
+// Counts are for the whole process, with the syscalls made by the
+// collector itself subtracted. The collector keeps /proc/self/io open
+// until the benchmark completes. Only read-like and write-like syscalls
+// are counted, since the kernel doesn't account others.
+//
+// It returns ErrSyscallCountsUnsupported on platforms other than Linux.
+func NewSyscallCollector() (Collector, error) {
+	reader, err := openSyscallReader()
+	if err != nil {
+		return nil, err
+	}
+	collector := &syscallCollector{reader: reader}
+	first, err := reader.read()
+	if err != nil {
+		return nil, err
+	}
+	second, err := reader.read()
+	if err != nil {
+		return nil, err
+	}
+	collector.overhead = second.since(first)
+	collector.overhead.voluntary, collector.overhead.involuntary = 0, 0
+	return collector, nil
+}
+
+// BeforeLap implements Collector.
+func (collector *syscallCollector) BeforeLap() {
+	// on failure the lap reports zero counts
+	_ = collector.reader.open()
+	collector.start, _ = collector.reader.read()
+}
+
+// AfterLap implements Collector.
+func (collector *syscallCollector) AfterLap(metrics []Metric) []Metric {
+	end, err := collector.reader.read()
+	if err != nil {
+		return metrics
+	}
+	counts := end.since(collector.start).since(collector.overhead)
+	return append(metrics,
+		Metric{Name: MetricSyscallsRead, Value: float64(max(counts.read, 0))},
+		Metric{Name: MetricSyscallsWrite, Value: float64(max(counts.write, 0))},
+		Metric{Name: MetricVoluntarySwitches, Value: float64(max(counts.voluntary, 0))},
+		Metric{Name: MetricInvoluntarySwitches, Value: float64(max(counts.involuntary, 0))},
+	)
+}
+
+// Close implements io.Closer, closing procfs until the next BeforeLap.
+func (collector *syscallCollector) Close() error { return collector.reader.close() }
+
+// parseProcIO reads syscall counts from the content of /proc/self/io.
+func parseProcIO(content string) (read, write int64, ok bool) {
+	var found int
+	for _, line := range strings.Split(content, "\n") {
+		key, value, _ := strings.Cut(line, ":")
+		count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "syscr":
+			read, found = count, found+1
+		case "syscw":
+			write, found = count, found+1
+		}
+	}
+	return read, write, found == 2
+}
@@ -0,0 +1,75 @@
+package hrtime
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// syscallReader reads syscall counters of the process from procfs.
+type syscallReader struct {
+	file   *os.File
+	buffer []byte
+}
+
+// openSyscallReader opens /proc/self/io, which requires task I/O accounting.
+func openSyscallReader() (syscallReader, error) {
+	reader := syscallReader{buffer: make([]byte, 512)}
+	if err := reader.open(); err != nil {
+		return syscallReader{}, err
+	}
+	return reader, nil
+}
+
+// open opens /proc/self/io, unless it's already open.
+func (reader *syscallReader) open() error {
+	if reader.file != nil {
+		return nil
+	}
+	file, err := os.Open("/proc/self/io")
+	if err != nil {
+		return ErrSyscallCountsUnsupported
+	}
+	reader.file = file
+	return nil
+}
+
+// close closes /proc/self/io, it's reopened by the next open.
+func (reader *syscallReader) close() error {
+	if reader.file == nil {
+		return nil
+	}
+	err := reader.file.Close()
+	reader.file = nil
+	return err
+}
+
+// read returns the current counters.
+//
+// Every read makes three syscalls, seek and read of procfs and getrusage,
+// the kernel counts only the read, which the collector subtracts as overhead.
+func (reader *syscallReader) read() (syscallCounts, error) {
+	var counts syscallCounts
+	if reader.file == nil {
+		return counts, os.ErrClosed
+	}
+	if _, err := reader.file.Seek(0, io.SeekStart); err != nil {
+		return counts, err
+	}
+	n, err := reader.file.Read(reader.buffer)
+	if err != nil {
+		return counts, err
+	}
+	var ok bool
+	counts.read, counts.write, ok = parseProcIO(string(reader.buffer[:n]))
+	if !ok {
+		return counts, ErrSyscallCountsUnsupported
+	}
+
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return counts, err
+	}
+	counts.voluntary, counts.involuntary = int64(usage.Nvcsw), int64(usage.Nivcsw)
+	return counts, nil
+}
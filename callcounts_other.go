@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package hrtime
+
+// syscallReader is not implemented on this platform.
+type syscallReader struct{}
+
+// openSyscallReader returns ErrSyscallCountsUnsupported, since syscall counts are only implemented on Linux.
+func openSyscallReader() (syscallReader, error) { return syscallReader{}, ErrSyscallCountsUnsupported }
+
+// open returns ErrSyscallCountsUnsupported.
+func (reader *syscallReader) open() error { return ErrSyscallCountsUnsupported }
+
+// close does nothing.
+func (reader *syscallReader) close() error { return nil }
+
+// read returns ErrSyscallCountsUnsupported.
+func (reader *syscallReader) read() (syscallCounts, error) {
+	return syscallCounts{}, ErrSyscallCountsUnsupported
+}
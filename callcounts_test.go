@@ -0,0 +1,65 @@
+package hrtime_test
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkCallCounts(t *testing.T) {
+	file, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	buffer := make([]byte, 16)
+	bench := hrtime.NewBenchmark(20, hrtime.WithCallCounts())
+	for bench.Next() {
+		for range 3 {
+			_, _ = file.Read(buffer)
+		}
+	}
+
+	metrics := bench.Metrics()
+	if !slices.Contains(metrics, hrtime.MetricCgoCalls) {
+		t.Errorf("expected cgo calls, got %v", metrics)
+	}
+	if _, err := hrtime.NewSyscallCollector(); errors.Is(err, hrtime.ErrSyscallCountsUnsupported) {
+		t.Skip(err)
+	}
+	reads := bench.MetricValues(hrtime.MetricSyscallsRead)
+	if len(reads) != 20 {
+		t.Fatalf("expected syscall counts of every lap, got %v", metrics)
+	}
+	// other goroutines of the process may make syscalls
+	slices.Sort(reads)
+	if median := reads[len(reads)/2]; median != 3 {
+		t.Errorf("expected 3 read syscalls per lap, got %v", reads)
+	}
+	t.Log("\n" + bench.StringMetricStats())
+}
+
+func TestSyscallCollectorReset(t *testing.T) {
+	collector, err := hrtime.NewSyscallCollector()
+	if errors.Is(err, hrtime.ErrSyscallCountsUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the collector is closed on completion and reopened after Reset
+	bench := hrtime.NewBenchmark(5, hrtime.WithCollector(collector))
+	for range 2 {
+		bench.Reset()
+		for bench.Next() {
+		}
+		if reads := bench.MetricValues(hrtime.MetricSyscallsRead); len(reads) != 5 {
+			t.Errorf("expected syscall counts of every lap, got %v", reads)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package hrtime
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupCPUPaths returns candidate directories of the cpu controller
+// for the current process, for cgroup v2 and v1 respectively.
+func cgroupCPUPaths() (v2, v1 []string) {
+	v2 = []string{"/sys/fs/cgroup"}
+	v1 = []string{"/sys/fs/cgroup/cpu", "/sys/fs/cgroup/cpu,cpuacct"}
+
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return v2, v1
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 || fields[2] == "/" {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			v2 = append([]string{filepath.Join("/sys/fs/cgroup", fields[2])}, v2...)
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			if controller == "cpu" {
+				v1 = append([]string{filepath.Join("/sys/fs/cgroup/cpu", fields[2])}, v1...)
+			}
+		}
+	}
+	return v2, v1
+}
+
+// readCgroupCPUFile reads a file of the cpu controller,
+// trying cgroup v2 name first and then cgroup v1 name.
+func readCgroupCPUFile(v2name, v1name string) (content string, v2 bool, ok bool) {
+	v2dirs, v1dirs := cgroupCPUPaths()
+	for _, dir := range v2dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, v2name)); err == nil {
+			return string(data), true, true
+		}
+	}
+	for _, dir := range v1dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, v1name)); err == nil {
+			return string(data), false, true
+		}
+	}
+	return "", false, false
+}
+
+// cpuQuota returns the cgroup CPU limit in number of CPUs, or 0 when unlimited.
+func cpuQuota() float64 {
+	if content, v2, ok := readCgroupCPUFile("cpu.max", "cpu.cfs_quota_us"); ok {
+		if v2 {
+			// format: "$MAX $PERIOD", where $MAX can be "max"
+			fields := strings.Fields(content)
+			if len(fields) != 2 || fields[0] == "max" {
+				return 0
+			}
+			return parseQuota(fields[0], fields[1])
+		}
+
+		period, _, ok := readCgroupCPUFile("", "cpu.cfs_period_us")
+		if !ok {
+			return 0
+		}
+		return parseQuota(strings.TrimSpace(content), strings.TrimSpace(period))
+	}
+	return 0
+}
+
+// parseQuota converts quota and period into number of CPUs.
+func parseQuota(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+// containerRuntime returns the name of the container runtime, when detected.
+func containerRuntime() string {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return "docker"
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return "podman"
+	}
+	if data, err := os.ReadFile("/proc/1/environ"); err == nil {
+		for _, env := range strings.Split(string(data), "\x00") {
+			if strings.HasPrefix(env, "container=") {
+				return strings.TrimPrefix(env, "container=")
+			}
+		}
+	}
+	if data, err := os.ReadFile("/proc/self/cgroup"); err == nil {
+		content := string(data)
+		for _, name := range []string{"docker", "kubepods", "containerd", "lxc"} {
+			if strings.Contains(content, name) {
+				return name
+			}
+		}
+	}
+	return ""
+}
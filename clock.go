@@ -0,0 +1,143 @@
+package hrtime
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of monotonic time.
+//
+// It allows driving benchmarks by an external time source, e.g. a
+// virtual clock of a deterministic simulation scheduler or ManualClock
+// in unit tests, using WithClock.
+type Clock interface {
+	// Now returns the current time, similarly to Now.
+	Now() time.Duration
+	// TSC returns the current counter value, similarly to TSC.
+	TSC() Count
+	// Overhead returns the timer overhead used by WithOverheadSubtraction.
+	Overhead() TimerOverhead
+}
+
+// SystemClock is the default clock using Now and TSC.
+var SystemClock Clock = systemClock{}
+
+// systemClock reads time from the platform counters.
+type systemClock struct{}
+
+// Now implements Clock.
+func (systemClock) Now() time.Duration { return Now() }
+
+// TSC implements Clock.
+func (systemClock) TSC() Count { return TSC() }
+
+// Overhead implements Clock, the overhead is calibrated on first use.
+func (systemClock) Overhead() TimerOverhead { return *currentOverhead() }
+
+// ClockFunc adapts a function to Clock.
+//
+// A discrete-event scheduler can advance virtual time on demand in the
+// function, e.g. by running pending events, before returning the time.
+//
+// TSC returns the time in nanoseconds and the overhead is zero.
+type ClockFunc func() time.Duration
+
+// Now implements Clock.
+func (fn ClockFunc) Now() time.Duration { return fn() }
+
+// TSC implements Clock.
+func (fn ClockFunc) TSC() Count { return Count(fn()) }
+
+// Overhead implements Clock.
+func (fn ClockFunc) Overhead() TimerOverhead { return TimerOverhead{} }
+
+// ManualClock is a clock for deterministic tests, which only moves
+// when advanced explicitly or by the configured step.
+//
+// TSC returns the time in nanoseconds. ManualClock is safe for concurrent use.
+type ManualClock struct {
+	mu       sync.Mutex
+	now      time.Duration
+	step     time.Duration
+	overhead TimerOverhead
+}
+
+// NewManualClock creates a manual clock starting at start.
+func NewManualClock(start time.Duration) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now implements Clock.
+//
+// It returns the current time and then advances the clock by step.
+func (clock *ManualClock) Now() time.Duration {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	now := clock.now
+	clock.now += clock.step
+	return now
+}
+
+// TSC implements Clock.
+func (clock *ManualClock) TSC() Count { return Count(clock.Now()) }
+
+// Overhead implements Clock.
+func (clock *ManualClock) Overhead() TimerOverhead {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.overhead
+}
+
+// Advance moves the clock forward by d.
+func (clock *ManualClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	clock.now += d
+	clock.mu.Unlock()
+}
+
+// Set sets the current time.
+func (clock *ManualClock) Set(now time.Duration) {
+	clock.mu.Lock()
+	clock.now = now
+	clock.mu.Unlock()
+}
+
+// SetStep makes every clock read advance the clock by step,
+// which mimics a timer with constant overhead.
+func (clock *ManualClock) SetStep(step time.Duration) {
+	clock.mu.Lock()
+	clock.step = step
+	clock.mu.Unlock()
+}
+
+// SetOverhead sets the overhead returned by Overhead.
+func (clock *ManualClock) SetOverhead(overhead TimerOverhead) {
+	clock.mu.Lock()
+	clock.overhead = overhead
+	clock.mu.Unlock()
+}
+
+// WithClock makes benchmarks and stopwatches read time from clock instead of Now and TSC.
+//
+// Time from clock is used for lap durations and timelines, while WallTimes
+// is still anchored to the wall-clock time at the end of the benchmark.
+// WithOverheadSubtraction uses the overhead reported by clock.
+func WithClock(clock Clock) Option {
+	return func(opts *options) { opts.clock = clock }
+}
+
+// now returns the time from the configured clock.
+func (opts *options) now() time.Duration {
+	if opts.clock != nil {
+		return opts.clock.Now()
+	}
+	return Now()
+}
+
+// tsc returns the counter value from the configured clock.
+func (opts *options) tsc() Count {
+	if opts.clock != nil {
+		return opts.clock.TSC()
+	}
+	return TSC()
+}
@@ -0,0 +1,229 @@
+package hrtime
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FrequencyTolerance is the relative difference between the measured and
+// the reported clock frequency, above which MitigatedClock.Calibrate
+// corrects the frequency.
+const FrequencyTolerance = 0.01
+
+// MitigationCalibration is the interval over which WithClockMitigation
+// calibrates the clock before the benchmark starts.
+const MitigationCalibration = 10 * time.Millisecond
+
+// ClockIncidentKind is the kind of anomaly detected by MitigatedClock.
+type ClockIncidentKind byte
+
+const (
+	// ClockBackward is reported when the clock reads an earlier time than before.
+	ClockBackward ClockIncidentKind = iota + 1
+	// ClockFrequency is reported when the clock runs at a different rate
+	// than the monotonic wall-clock, i.e. the counter frequency is misreported.
+	ClockFrequency
+)
+
+// String returns the name of the kind.
+func (kind ClockIncidentKind) String() string {
+	switch kind {
+	case ClockBackward:
+		return "backward"
+	case ClockFrequency:
+		return "frequency"
+	default:
+		return "unknown"
+	}
+}
+
+// ClockIncident describes a clock anomaly detected by MitigatedClock.
+type ClockIncident struct {
+	Kind ClockIncidentKind
+	// At is the clock reading when the incident was detected.
+	At time.Duration
+	// Jump is the size of the backward jump of ClockBackward.
+	Jump time.Duration
+	// Ratio is the measured clock rate relative to wall-clock of ClockFrequency.
+	Ratio float64
+}
+
+// String returns a description of the incident.
+func (incident ClockIncident) String() string {
+	if incident.Kind == ClockFrequency {
+		return fmt.Sprintf("%v at %v: clock rate %.4fx of wall-clock", incident.Kind, incident.At, incident.Ratio)
+	}
+	return fmt.Sprintf("%v at %v: jumped back %v", incident.Kind, incident.At, incident.Jump)
+}
+
+// MitigatedClock guards a clock against anomalies, which happen with QPC
+// under certain hypervisors on Windows, but also with other clocks.
+//
+// Reads never go backwards: when the underlying clock jumps back, the
+// jump is compensated in subsequent reads and a ClockBackward incident
+// is recorded, hence the lap containing the jump becomes zero instead
+// of negative and later laps are unaffected. Calibrate compares the clock
+// against the monotonic wall-clock and corrects a misreported frequency.
+//
+// TSC reads the underlying clock without mitigation.
+// MitigatedClock is safe for concurrent use, however reads racing between
+// goroutines may be detected as backward jumps.
+type MitigatedClock struct {
+	clock Clock
+	last  atomic.Int64
+	// offset compensates backward jumps.
+	offset atomic.Int64
+	// scale corrects the frequency, when not nil.
+	scale atomic.Pointer[clockScale]
+
+	mu        sync.Mutex
+	incidents []ClockIncident
+}
+
+// clockScale maps raw clock readings after raw to corrected ones after base.
+type clockScale struct {
+	raw   time.Duration
+	base  time.Duration
+	ratio float64
+}
+
+// NewMitigatedClock creates a mitigated clock reading from clock,
+// when clock is nil SystemClock is used.
+func NewMitigatedClock(clock Clock) *MitigatedClock {
+	if clock == nil {
+		clock = SystemClock
+	}
+	mitigated := &MitigatedClock{clock: clock}
+	mitigated.last.Store(math.MinInt64)
+	return mitigated
+}
+
+// WithClockMitigation guards the clock used by benchmarks with MitigatedClock.
+// Unless the clock already is a MitigatedClock, it's calibrated over
+// MitigationCalibration when creating the benchmark.
+//
+// Clock anomalies are reported by Benchmark.ClockIncidents and in the
+// JSONResult metadata, instead of silently corrupting laps.
+func WithClockMitigation() Option {
+	return func(opts *options) { opts.mitigate = true }
+}
+
+// Now implements Clock.
+func (clock *MitigatedClock) Now() time.Duration {
+	raw := clock.corrected(clock.clock.Now())
+	for {
+		now, last := int64(raw)+clock.offset.Load(), clock.last.Load()
+		if now >= last {
+			if clock.last.CompareAndSwap(last, now) {
+				return time.Duration(now)
+			}
+			continue
+		}
+
+		clock.mu.Lock()
+		now, last = int64(raw)+clock.offset.Load(), clock.last.Load()
+		if now < last {
+			clock.offset.Add(last - now)
+			incident := ClockIncident{
+				Kind: ClockBackward,
+				At:   time.Duration(last),
+				Jump: time.Duration(last - now),
+			}
+			clock.incidents = append(clock.incidents, incident)
+			tracef("clock", "%v", incident)
+		}
+		clock.mu.Unlock()
+		return time.Duration(last)
+	}
+}
+
+// TSC implements Clock.
+func (clock *MitigatedClock) TSC() Count { return clock.clock.TSC() }
+
+// Overhead implements Clock.
+func (clock *MitigatedClock) Overhead() TimerOverhead { return clock.clock.Overhead() }
+
+// corrected applies frequency correction to a raw reading.
+func (clock *MitigatedClock) corrected(raw time.Duration) time.Duration {
+	scale := clock.scale.Load()
+	if scale == nil {
+		return raw
+	}
+	return scale.base + time.Duration(float64(raw-scale.raw)/scale.ratio)
+}
+
+// Calibrate measures the clock rate against the monotonic wall-clock
+// over interval and returns the ratio of the two.
+//
+// When the ratio differs from 1 by more than FrequencyTolerance,
+// subsequent reads are corrected and a ClockFrequency incident is recorded.
+// A clock that doesn't advance during interval is left uncorrected.
+func (clock *MitigatedClock) Calibrate(interval time.Duration) float64 {
+	wallStart, start := time.Now(), clock.clock.Now()
+	time.Sleep(interval)
+	wall, elapsed := time.Since(wallStart), clock.clock.Now()-start
+
+	ratio := float64(elapsed) / float64(wall)
+	if ratio > 0 && math.Abs(ratio-1) > FrequencyTolerance {
+		raw := clock.clock.Now()
+		base := clock.corrected(raw)
+		clock.scale.Store(&clockScale{raw: raw, base: base, ratio: ratio})
+		clock.mu.Lock()
+		incident := ClockIncident{Kind: ClockFrequency, At: base, Ratio: ratio}
+		clock.incidents = append(clock.incidents, incident)
+		clock.mu.Unlock()
+		tracef("clock", "%v", incident)
+	}
+	return ratio
+}
+
+// Incidents returns a copy of detected incidents.
+func (clock *MitigatedClock) Incidents() []ClockIncident {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return append(clock.incidents[:0:0], clock.incidents...)
+}
+
+// ClockIncidents returns clock anomalies detected during the benchmark,
+// when measuring WithClockMitigation. Frequency corrections made before
+// the benchmark are included, since they affect all of its laps.
+func (bench *Benchmark) ClockIncidents() []ClockIncident {
+	bench = bench.mustBeCompleted()
+	clock, ok := bench.opts.clock.(*MitigatedClock)
+	if !ok {
+		return nil
+	}
+	var incidents []ClockIncident
+	for _, incident := range clock.Incidents() {
+		if incident.At <= bench.stop && (incident.At >= bench.start || incident.Kind == ClockFrequency) {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents
+}
+
+// clockIncidentsMetadata summarizes incidents for JSONResult metadata.
+func clockIncidentsMetadata(incidents []ClockIncident) map[string]string {
+	if len(incidents) == 0 {
+		return nil
+	}
+	var backward int
+	var maxJump time.Duration
+	metadata := map[string]string{}
+	for _, incident := range incidents {
+		switch incident.Kind {
+		case ClockBackward:
+			backward++
+			maxJump = max(maxJump, incident.Jump)
+		case ClockFrequency:
+			metadata["clockFrequencyRatio"] = fmt.Sprintf("%.4f", incident.Ratio)
+		}
+	}
+	if backward > 0 {
+		metadata["clockBackward"] = fmt.Sprintf("%d (max %v)", backward, maxJump)
+	}
+	return metadata
+}
@@ -0,0 +1,87 @@
+package hrtime_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestClockMitigationBackward(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	mitigated := hrtime.NewMitigatedClock(clock)
+
+	bench := hrtime.NewBenchmark(3, hrtime.WithClock(mitigated), hrtime.WithNonMonotonic(hrtime.NonMonotonicError))
+	jumps := []time.Duration{100, -50, 100}
+	for i := 0; bench.Next(); i++ {
+		clock.Advance(jumps[i])
+	}
+
+	if err := bench.Err(); err != nil {
+		t.Errorf("expected no negative laps, got %v", err)
+	}
+	if laps := bench.Laps(); laps[0] != 100 || laps[1] != 0 || laps[2] != 100 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+
+	incidents := bench.ClockIncidents()
+	if len(incidents) != 1 || incidents[0].Kind != hrtime.ClockBackward || incidents[0].Jump != 50 {
+		t.Fatalf("unexpected incidents %v", incidents)
+	}
+	if metadata := bench.JSONResult("jump").Metadata; metadata["clockBackward"] != "1 (max 50ns)" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+}
+
+func TestClockMitigationFrequency(t *testing.T) {
+	start := time.Now()
+	// the clock runs twice as fast as the wall-clock
+	fast := hrtime.ClockFunc(func() time.Duration { return 2 * time.Since(start) })
+
+	mitigated := hrtime.NewMitigatedClock(fast)
+	ratio := mitigated.Calibrate(20 * time.Millisecond)
+	if math.Abs(ratio-2) > 0.2 {
+		t.Fatalf("expected ratio close to 2, got %v", ratio)
+	}
+
+	before, wallBefore := mitigated.Now(), time.Now()
+	time.Sleep(20 * time.Millisecond)
+	elapsed, wall := mitigated.Now()-before, time.Since(wallBefore)
+	if math.Abs(float64(elapsed)/float64(wall)-1) > 0.2 {
+		t.Errorf("expected corrected clock to follow wall-clock, got %v for %v", elapsed, wall)
+	}
+
+	incidents := mitigated.Incidents()
+	if len(incidents) != 1 || incidents[0].Kind != hrtime.ClockFrequency {
+		t.Errorf("unexpected incidents %v", incidents)
+	}
+}
+
+func TestWithClockMitigationCalibrates(t *testing.T) {
+	start := time.Now()
+	fast := hrtime.ClockFunc(func() time.Duration { return 2 * time.Since(start) })
+
+	bench := hrtime.NewBenchmark(3, hrtime.WithClock(fast), hrtime.WithClockMitigation())
+	for bench.Next() {
+	}
+
+	incidents := bench.ClockIncidents()
+	if len(incidents) != 1 || incidents[0].Kind != hrtime.ClockFrequency {
+		t.Errorf("unexpected incidents %v", incidents)
+	}
+}
+
+func TestWithClockMitigationManualClock(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmark(2, hrtime.WithClock(clock), hrtime.WithClockMitigation())
+	for bench.Next() {
+		clock.Advance(100)
+	}
+	if laps := bench.Laps(); laps[0] != 100 || laps[1] != 100 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+	if incidents := bench.ClockIncidents(); len(incidents) != 0 {
+		t.Errorf("unexpected incidents %v", incidents)
+	}
+}
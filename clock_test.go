@@ -0,0 +1,72 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// scheduler is a minimal discrete-event simulation.
+type scheduler struct {
+	now time.Duration
+}
+
+// sleep runs simulated work by moving virtual time forward.
+func (sim *scheduler) sleep(d time.Duration) { sim.now += d }
+
+func TestWithClock(t *testing.T) {
+	sim := &scheduler{now: time.Second}
+	bench := hrtime.NewBenchmark(3, hrtime.WithClock(hrtime.ClockFunc(func() time.Duration { return sim.now })))
+	for i := 1; bench.Next(); i++ {
+		sim.sleep(time.Duration(i) * time.Millisecond)
+	}
+
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	laps := bench.Laps()
+	for i := range expected {
+		if laps[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, laps)
+		}
+	}
+	if start, stop := bench.Timeline(); start != time.Second || stop != time.Second+6*time.Millisecond {
+		t.Errorf("unexpected timeline %v %v", start, stop)
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	clock.SetOverhead(hrtime.TimerOverhead{Lap: 10, LapTSC: 10})
+
+	bench := hrtime.NewBenchmark(3, hrtime.WithClock(clock), hrtime.WithOverheadSubtraction())
+	for bench.Next() {
+		clock.Advance(100)
+	}
+	for _, lap := range bench.Laps() {
+		if lap != 90 {
+			t.Fatalf("expected laps of 90ns after overhead subtraction, got %v", bench.Laps())
+		}
+	}
+
+	// Next reads the clock twice, except for the last call
+	clock.SetStep(10)
+	benchTSC := hrtime.NewBenchmarkTSC(3, hrtime.WithClock(clock))
+	for benchTSC.Next() {
+	}
+	if counts := benchTSC.Counts(); counts[0] != 20 || counts[1] != 20 || counts[2] != 10 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	clock.SetStep(0)
+	stopwatch := hrtime.NewStopwatch(2, hrtime.WithClock(clock))
+	first := stopwatch.Start()
+	clock.Advance(time.Millisecond)
+	second := stopwatch.Start()
+	clock.Advance(time.Millisecond)
+	stopwatch.Stop(first)
+	stopwatch.Stop(second)
+	stopwatch.Wait()
+	if durations := stopwatch.Durations(); durations[0] != 2*time.Millisecond || durations[1] != time.Millisecond {
+		t.Errorf("unexpected durations %v", durations)
+	}
+}
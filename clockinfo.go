@@ -0,0 +1,67 @@
+package hrtime
+
+import (
+	"runtime"
+	"time"
+)
+
+// BackendInfo describes a single clock backend.
+type BackendInfo struct {
+	// Name is the name of the backend, e.g. "QPC", "clock_gettime (vDSO)" or "TSC".
+	Name string `json:"name"`
+	// Available reports whether the backend gives meaningful values.
+	Available bool `json:"available"`
+	// Resolution is the smallest measurable step in nanoseconds.
+	Resolution float64 `json:"resolution"`
+	// Overhead is the approximate cost of reading the clock.
+	Overhead time.Duration `json:"overhead"`
+	// Frequency is the counter frequency in Hz, zero when not applicable.
+	Frequency float64 `json:"frequency,omitempty"`
+	// Fallback describes why a better backend is not used, empty otherwise.
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// Clocks describes the backends used by Now and TSC.
+type Clocks struct {
+	Now BackendInfo `json:"now"`
+	TSC BackendInfo `json:"tsc"`
+}
+
+// ClockInfo describes which clock backends are in use.
+//
+// The first call detects the clock path and, when TSC is supported,
+// does TSC calibration, hence it can take several milliseconds.
+func ClockInfo() Clocks {
+	var clocks Clocks
+
+	checkClockPathOnce()
+	clocks.Now.Name, clocks.Now.Fallback = nowBackendInfo()
+	clocks.Now.Available = true
+	clocks.Now.Resolution = NowPrecision()
+	clocks.Now.Overhead = Overhead()
+
+	clocks.TSC.Name = counterName
+	switch {
+	case counterName == "":
+		clocks.TSC.Fallback = "no supported hardware counter on " + runtime.GOARCH + "/" + runtime.Compiler
+	case counterName == "Now":
+		clocks.TSC.Fallback = "built with purego tag, TSC uses Now"
+	case tscFallback.Load():
+		clocks.TSC.Name = "Now"
+		clocks.TSC.Available = true
+		clocks.TSC.Overhead = Overhead()
+		clocks.TSC.Resolution = NowPrecision()
+		if reason := tscFallbackReason.Load(); reason != nil {
+			clocks.TSC.Fallback = *reason + ", TSC uses Now"
+		}
+	case !TSCSupported():
+		clocks.TSC.Fallback = counterName + " is not invariant"
+	default:
+		clocks.TSC.Available = true
+		clocks.TSC.Overhead = TSCOverhead().ApproxDuration()
+		clocks.TSC.Frequency = loadTSCRatio().frequency()
+		clocks.TSC.Resolution = float64(time.Second) / clocks.TSC.Frequency
+	}
+
+	return clocks
+}
@@ -0,0 +1,21 @@
+package hrtime_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestClockInfo(t *testing.T) {
+	clocks := hrtime.ClockInfo()
+	if !clocks.Now.Available || clocks.Now.Name == "" {
+		t.Errorf("Now backend must be available: %+v", clocks.Now)
+	}
+	if clocks.TSC.Available != hrtime.TSCSupported() {
+		t.Errorf("TSC availability mismatch: %+v", clocks.TSC)
+	}
+	if !clocks.TSC.Available && clocks.TSC.Fallback == "" {
+		t.Errorf("TSC fallback reason missing: %+v", clocks.TSC)
+	}
+	t.Logf("%+v", clocks)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime/hrtimebundle"
+)
+
+// bundle implements "hrtime bundle".
+func bundle(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ExitOnError)
+	var annotations, metadata stringList
+	flags.Var(&annotations, "annotate", "annotation to add when creating, can be repeated")
+	flags.Var(&metadata, "meta", "key=value metadata to add when creating, can be repeated")
+	_ = flags.Parse(args)
+
+	args = flags.Args()
+	if len(args) != 2 {
+		return errors.New("usage: hrtime bundle [flags] create|show|extract FILE")
+	}
+	path := args[1]
+
+	switch args[0] {
+	case "create":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		results, err := decodeResults(data)
+		if err != nil {
+			return err
+		}
+
+		b := hrtimebundle.New()
+		b.Results = results
+		for _, text := range annotations {
+			b.Annotate(text)
+		}
+		for _, pair := range metadata {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("bundle: invalid metadata %q, expected key=value", pair)
+			}
+			if b.Metadata == nil {
+				b.Metadata = map[string]string{}
+			}
+			b.Metadata[key] = value
+		}
+		return hrtimebundle.WriteFile(path, b)
+	case "show":
+		b, err := hrtimebundle.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created %v\n", b.Created)
+		if b.Environment != nil {
+			fmt.Printf("environment %s %s/%s %s\n", b.Environment.Hostname, b.Environment.GOOS, b.Environment.GOARCH, b.Environment.CPUModel)
+		}
+		keys := make([]string, 0, len(b.Metadata))
+		for key := range b.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("%s=%s\n", key, b.Metadata[key])
+		}
+		for _, result := range b.Results {
+			fmt.Printf("%s: %d laps, mean %v, p99 %v\n", result.Name, result.Count,
+				time.Duration(result.Stats.Mean), time.Duration(result.Stats.P99))
+		}
+		for _, annotation := range b.Annotations {
+			fmt.Printf("%s %s\n", annotation.Time.Format("2006-01-02T15:04:05Z07:00"), annotation.Text)
+		}
+	case "extract":
+		b, err := hrtimebundle.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(b.Results)
+	default:
+		return fmt.Errorf("bundle: unknown subcommand %q", args[0])
+	}
+	return nil
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (list *stringList) String() string { return strings.Join(*list, ",") }
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/loov/hrtime"
+)
+
+func init() {
+	hrtime.RegisterExporter("exec", newExecExporter)
+}
+
+// export implements "hrtime export".
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	var specs exporterSpecs
+	flags.Var(&specs, "to", "exporter as name[=config], can be repeated")
+	list := flags.Bool("list", false, "list registered exporters")
+	_ = flags.Parse(args)
+
+	if *list {
+		for _, name := range hrtime.Exporters() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+	if len(specs) == 0 {
+		return errors.New("export: no exporters, use -to name[=config]")
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	results, err := decodeResults(data)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return exportResults(specs, results)
+}
+
+// exportResults sends results to exporters created from specs.
+func exportResults(specs exporterSpecs, results []*hrtime.JSONResult) error {
+	var errs []error
+	for _, spec := range specs {
+		name, config, _ := strings.Cut(spec, "=")
+		exporter, err := hrtime.NewExporter(name, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("export: %w", err))
+			continue
+		}
+		for _, result := range results {
+			if err := exporter.Export(result); err != nil {
+				errs = append(errs, fmt.Errorf("export %s to %s: %w", result.Name, name, err))
+			}
+		}
+		if closer, ok := exporter.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("export: %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// exporterSpecs collects repeated name[=config] flags.
+type exporterSpecs []string
+
+// String implements flag.Value.
+func (specs *exporterSpecs) String() string { return strings.Join(*specs, ",") }
+
+// Set implements flag.Value.
+func (specs *exporterSpecs) Set(spec string) error {
+	if name, _, _ := strings.Cut(spec, "="); name == "" {
+		return errors.New("missing exporter name")
+	}
+	*specs = append(*specs, spec)
+	return nil
+}
+
+// execExporter implements the "exec" exporter, which runs a command for
+// every result with the result as JSON on stdin.
+//
+// It allows shipping exporters as separate programs, e.g. scripts uploading
+// to an object store, without building a custom hrtime command.
+type execExporter struct {
+	command []string
+}
+
+// newExecExporter creates an exporter running the command in config,
+// split into arguments at spaces.
+func newExecExporter(config string) (hrtime.Exporter, error) {
+	command := strings.Fields(config)
+	if len(command) == 0 {
+		return nil, errors.New("exec: missing command")
+	}
+	return &execExporter{command: command}, nil
+}
+
+// Export implements hrtime.Exporter.
+func (exporter *execExporter) Export(result *hrtime.JSONResult) error {
+	var input bytes.Buffer
+	if err := result.Encode(&input); err != nil {
+		return err
+	}
+	cmd := exec.Command(exporter.command[0], exporter.command[1:]...)
+	cmd.Stdin = &input
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimeimport"
+	"github.com/loov/hrtime/hrtimereport"
+)
+
+// gotest implements "hrtime gotest".
+func gotest(args []string) error {
+	flags := flag.NewFlagSet("gotest", flag.ExitOnError)
+	bench := flags.String("bench", ".", "regexp of benchmarks to run")
+	count := flags.Int("count", 10, "number of runs of each benchmark, the mean of each run is a sample")
+	benchtime := flags.String("benchtime", "", "go test -benchtime")
+	format := flags.String("format", "text", "report format: text, markdown, html or json")
+	output := flags.String("o", "", "write the report to file instead of stdout")
+	var exports exporterSpecs
+	flags.Var(&exports, "export", "additionally export results as name[=config], can be repeated")
+	_ = flags.Parse(args)
+
+	// arguments before "--" are packages, the rest are passed to go test
+	packages, extra := flags.Args(), []string(nil)
+	for i, arg := range packages {
+		if arg == "--" {
+			packages, extra = packages[:i], packages[i+1:]
+			break
+		}
+	}
+
+	cmdArgs := []string{"test", "-run", "^$", "-bench", *bench, "-count", strconv.Itoa(*count)}
+	if *benchtime != "" {
+		cmdArgs = append(cmdArgs, "-benchtime", *benchtime)
+	}
+	cmdArgs = append(cmdArgs, extra...)
+	cmdArgs = append(cmdArgs, packages...)
+
+	// go test output is shown as progress, while it's captured for the report
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", cmdArgs...)
+	cmd.Stdout = io.MultiWriter(&stdout, os.Stderr)
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+
+	results, err := hrtimeimport.GoTestBench(&stdout)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		if runErr != nil {
+			return fmt.Errorf("gotest: %w", runErr)
+		}
+		return errors.New("gotest: no benchmark results")
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	if err := writeGoTestReport(out, *format, results); err != nil {
+		return err
+	}
+	if err := exportResults(exports, results); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("gotest: %w", runErr)
+	}
+	return nil
+}
+
+// writeGoTestReport writes results grouped by package in the specified format.
+func writeGoTestReport(w io.Writer, format string, results []*hrtime.JSONResult) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(results)
+	}
+
+	report := hrtimereport.New("go test -bench")
+	sections := map[string]*hrtimereport.Section{}
+	for _, result := range results {
+		pkg := result.Metadata["pkg"]
+		section, ok := sections[pkg]
+		if !ok {
+			section = report.Add(pkg)
+			section.Note("laps are per-run means: each lap is ns/op of a single go test run, not of a single operation")
+			sections[pkg] = section
+		}
+		if ops := result.Metadata[hrtimeimport.OpsPerLapKey]; ops != "" {
+			section.Note(fmt.Sprintf("%s: each lap is the time of %s operations", result.Name, ops))
+		}
+		section.Results = append(section.Results, result)
+	}
+
+	switch format {
+	case "text":
+		return report.WriteText(w)
+	case "markdown":
+		return report.WriteMarkdown(w)
+	case "html":
+		return report.WriteHTML(w)
+	}
+	return fmt.Errorf("gotest: unknown format %q", format)
+}
@@ -0,0 +1,86 @@
+// hrtime is a command for working with hrtime results.
+//
+// Usage:
+//
+//	hrtime watch [flags] -- command [args...]
+//	hrtime store [flags] save|list|tag|compare [args...]
+//	hrtime bundle [flags] create|show|extract FILE
+//	hrtime verify-clock [-d 10s] [-threads N] [-tsc]
+//	hrtime gotest [flags] [packages] [-- go test flags]
+//	hrtime export [-list] -to name[=config]...
+//
+// watch re-runs a suite command whenever source files change and shows
+// deltas against the previous run. The command must write results as JSON
+// to stdout, either as an array or as a stream of hrtime.JSONResult values.
+//
+// store saves results read from stdin as runs, tags runs with names
+// such as "baseline-v1.2" and compares runs against a tag or the previous run.
+//
+// bundle creates a session bundle from results read from stdin, shows
+// its summary or extracts its results as JSON, e.g. for "hrtime store save".
+//
+// verify-clock reads the clock from multiple threads and reports backward
+// steps, skew between threads and jumps, when the platform timer is suspect.
+//
+// gotest runs go test -bench with -count runs of every benchmark and reports
+// the distribution of per-run mean ns/op across runs, grouped by package.
+// Benchmarks are not re-timed, hence the laps are not individual operations;
+// benchmarks using hrtimetest.WrapB additionally report percentiles of
+// individual iterations as metadata.
+//
+// export sends results read from stdin to exporters registered with
+// hrtime.RegisterExporter, e.g. -to json=results.json. The exec exporter
+// pipes each result as JSON to a command, e.g. -to "exec=./upload.sh bucket",
+// which allows exporting to other systems without changes to hrtime.
+// gotest accepts the same exporters with -export.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "watch":
+		err = watch(os.Args[2:])
+	case "store":
+		err = store(os.Args[2:])
+	case "bundle":
+		err = bundle(os.Args[2:])
+	case "verify-clock":
+		err = verifyClock(os.Args[2:])
+	case "gotest":
+		err = gotest(os.Args[2:])
+	case "export":
+		err = export(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  hrtime watch [flags] -- command [args...]")
+	fmt.Fprintln(os.Stderr, "  hrtime store [flags] save|list|tag|compare [args...]")
+	fmt.Fprintln(os.Stderr, "  hrtime bundle [flags] create|show|extract FILE")
+	fmt.Fprintln(os.Stderr, "  hrtime verify-clock [-d 10s] [-threads N] [-tsc]")
+	fmt.Fprintln(os.Stderr, "  hrtime gotest [flags] [packages] [-- go test flags]")
+	fmt.Fprintln(os.Stderr, "  hrtime export [-list] -to name[=config]...")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestDecodeResults(t *testing.T) {
+	array, err := decodeResults([]byte(`[{"schemaVersion": 1, "name": "a", "laps": [1, 2]}]`))
+	if err != nil || len(array) != 1 || array[0].Name != "a" {
+		t.Fatalf("unexpected array results %v %v", array, err)
+	}
+
+	stream, err := decodeResults([]byte("{\"name\": \"a\", \"laps\": [1]}\n{\"name\": \"b\", \"laps\": [2]}\n"))
+	if err != nil || len(stream) != 2 || stream[1].Name != "b" {
+		t.Fatalf("unexpected stream results %v %v", stream, err)
+	}
+
+	comparisons := hrtime.CompareResults(array, stream)
+	if len(comparisons) != 2 || comparisons[0].OldCount != 2 || comparisons[1].OldCount != 1 {
+		t.Errorf("unexpected comparisons %v", comparisons)
+	}
+}
+
+func TestScanSources(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := scanSources(dir, []string{".go"})
+	if err != nil || before.files != 1 {
+		t.Fatalf("unexpected state %+v %v", before, err)
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	after, err := scanSources(dir, []string{".go"})
+	if err != nil || after == before {
+		t.Errorf("change not detected %+v %+v", before, after)
+	}
+}
+
+func TestWriteWatchRun(t *testing.T) {
+	current := []*hrtime.JSONResult{hrtime.NewJSONResult("a", []time.Duration{100, 200})}
+
+	var first strings.Builder
+	writeWatchRun(&first, nil, current, 0.05)
+	if !strings.Contains(first.String(), "baseline") || strings.Contains(first.String(), "->") {
+		t.Errorf("expected baseline without comparison, got %q", first.String())
+	}
+
+	var second strings.Builder
+	writeWatchRun(&second, current, current, 0.05)
+	if strings.Contains(second.String(), "baseline") || strings.Contains(second.String(), "REGRESSION") {
+		t.Errorf("expected comparison without regressions, got %q", second.String())
+	}
+}
+
+func TestExportResults(t *testing.T) {
+	var specs exporterSpecs
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := specs.Set("json=" + path); err != nil {
+		t.Fatal(err)
+	}
+	if err := specs.Set("=missing"); err == nil {
+		t.Error("expected error for missing exporter name")
+	}
+
+	results := []*hrtime.JSONResult{hrtime.NewJSONResult("a", []time.Duration{1, 2})}
+	if err := exportResults(specs, results); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := decodeResults(data); err != nil || len(decoded) != 1 || decoded[0].Name != "a" {
+		t.Errorf("unexpected exported results %v %v", decoded, err)
+	}
+
+	if err := exportResults(exporterSpecs{"missing"}, results); !errors.Is(err, hrtime.ErrUnknownExporter) {
+		t.Errorf("expected unknown exporter, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/loov/hrtime"
+)
+
+// decodeResults reads a JSON array or a stream of results.
+func decodeResults(data []byte) ([]*hrtime.JSONResult, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var results []*hrtime.JSONResult
+		err := json.Unmarshal(data, &results)
+		return results, err
+	}
+
+	var results []*hrtime.JSONResult
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var result hrtime.JSONResult
+		err := dec.Decode(&result)
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+		results = append(results, &result)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/loov/hrtime/hrtimestore"
+)
+
+// store implements "hrtime store".
+func store(args []string) error {
+	flags := flag.NewFlagSet("store", flag.ExitOnError)
+	dir := flags.String("dir", ".hrtime", "store directory")
+	baseline := flags.String("baseline", hrtimestore.Previous, "baseline run or tag for compare")
+	_ = flags.Parse(args)
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return errors.New("store: missing subcommand (save, list, tag, compare)")
+	}
+
+	s, err := hrtimestore.Open(*dir)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "save":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		results, err := decodeResults(data)
+		if err != nil {
+			return err
+		}
+		id, err := s.Save(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+	case "list":
+		runs, err := s.Runs()
+		if err != nil {
+			return err
+		}
+		tags, err := s.Tags()
+		if err != nil {
+			return err
+		}
+		tagsByRun := map[string][]string{}
+		for name, id := range tags {
+			tagsByRun[id] = append(tagsByRun[id], name)
+		}
+		for _, id := range runs {
+			sort.Strings(tagsByRun[id])
+			fmt.Println(id, tagsByRun[id])
+		}
+	case "tag":
+		if len(args) < 2 || len(args) > 3 {
+			return errors.New("usage: hrtime store tag NAME [RUN]")
+		}
+		ref := hrtimestore.Latest
+		if len(args) == 3 {
+			ref = args[2]
+		}
+		return s.Tag(args[1], ref)
+	case "compare":
+		ref := hrtimestore.Latest
+		if len(args) > 1 {
+			ref = args[1]
+		}
+		comparisons, err := s.Compare(*baseline, ref)
+		if err != nil {
+			return err
+		}
+		for _, comparison := range comparisons {
+			fmt.Print(comparison)
+		}
+	default:
+		return fmt.Errorf("store: unknown subcommand %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// verifyClock implements "hrtime verify-clock".
+func verifyClock(args []string) error {
+	flags := flag.NewFlagSet("verify-clock", flag.ExitOnError)
+	duration := flags.Duration("d", 10*time.Second, "duration of the verification")
+	threads := flags.Int("threads", 0, "number of threads reading the clock, GOMAXPROCS when zero")
+	tsc := flags.Bool("tsc", false, "verify TSC instead of Now")
+	jump := flags.Duration("jump", time.Millisecond, "forward step reported as a jump")
+	_ = flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := hrtime.VerifyClock(ctx, hrtime.VerifyClockOptions{
+		Duration:      *duration,
+		Threads:       *threads,
+		TSC:           *tsc,
+		JumpThreshold: *jump,
+	})
+	fmt.Print(report)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	if !report.OK() {
+		return errors.New("verify-clock: clock went backwards")
+	}
+	return nil
+}
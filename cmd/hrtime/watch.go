@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// watch implements "hrtime watch".
+func watch(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory to watch")
+	exts := flags.String("ext", ".go", "comma separated file extensions to watch")
+	interval := flags.Duration("interval", 500*time.Millisecond, "polling interval")
+	threshold := flags.Float64("threshold", 0.05, "relative slowdown highlighted as regression")
+	_ = flags.Parse(args)
+
+	command := flags.Args()
+	if len(command) == 0 {
+		return errors.New("watch: missing suite command")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	extensions := strings.Split(*exts, ",")
+	var previous []*hrtime.JSONResult
+	var lastState sourceState
+	for {
+		state, err := scanSources(*dir, extensions)
+		if err != nil {
+			return err
+		}
+
+		if state != lastState {
+			lastState = state
+			fmt.Printf("--- %s running %s\n", time.Now().Format("15:04:05"), strings.Join(command, " "))
+
+			current, err := runSuite(ctx, command)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			} else {
+				writeWatchRun(os.Stdout, previous, current, *threshold)
+				previous = current
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// writeWatchRun writes results of the current run to w, compared against
+// the previous run, when there is one.
+func writeWatchRun(w io.Writer, previous, current []*hrtime.JSONResult, threshold float64) {
+	if len(previous) == 0 {
+		for _, result := range current {
+			fmt.Fprintf(w, "%s: %d laps, p50 %v, p99 %v (baseline)\n", result.Name, result.Count,
+				hrtime.RoundDuration(time.Duration(result.Stats.P50)),
+				hrtime.RoundDuration(time.Duration(result.Stats.P99)))
+		}
+		return
+	}
+
+	for _, comparison := range hrtime.CompareResults(previous, current) {
+		fmt.Fprint(w, comparison)
+		for _, delta := range comparison.Regressions(threshold) {
+			fmt.Fprintf(w, "  REGRESSION %s %+.2f%%\n", delta.Metric, delta.Change*100)
+		}
+	}
+}
+
+// runSuite runs command and decodes results from stdout.
+func runSuite(ctx context.Context, command []string) ([]*hrtime.JSONResult, error) {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("suite failed: %w", err)
+	}
+	return decodeResults(output)
+}
+
+// sourceState summarizes watched files for change detection.
+type sourceState struct {
+	files    int
+	size     int64
+	modified time.Time
+}
+
+// scanSources scans files with the specified extensions in dir.
+//
+// Hidden directories, vendor and testdata directories are skipped.
+func scanSources(dir string, extensions []string) (sourceState, error) {
+	var state sourceState
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !hasExtension(name, extensions) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		state.files++
+		state.size += info.Size()
+		if info.ModTime().After(state.modified) {
+			state.modified = info.ModTime()
+		}
+		return nil
+	})
+	return state, err
+}
+
+// hasExtension checks whether name has any of the extensions.
+func hasExtension(name string, extensions []string) bool {
+	for _, ext := range extensions {
+		if ext != "" && strings.HasSuffix(name, strings.TrimSpace(ext)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Metric is a named value measured during a lap.
+type Metric struct {
+	Name  string
+	Value float64
+}
+
+// Collector measures custom metrics of every lap, e.g. hardware
+// performance counters, GPU timers or application counters.
+//
+// BeforeLap is called right before the lap starts and AfterLap right
+// after it ends, outside of the measured durations. AfterLap appends
+// values measured during the lap to metrics and returns the result,
+// which avoids allocating in the benchmark loop.
+//
+// Collectors implementing io.Closer are closed when the benchmark completes,
+// a collector reused after Reset must reacquire its resources in BeforeLap.
+type Collector interface {
+	BeforeLap()
+	AfterLap(metrics []Metric) []Metric
+}
+
+// WithCollector calls collectors around every lap of Benchmark and BenchmarkTSC.
+//
+// BeforeLap of collectors is called in order and AfterLap in reverse order,
+// hence the first collector measures the others as well.
+// Collectors are not called for sampled benchmarks and recorded laps.
+func WithCollector(collectors ...Collector) Option {
+	return func(opts *options) { opts.collectors = append(opts.collectors, collectors...) }
+}
+
+// lapMetric is the value of a metric during a lap.
+type lapMetric struct {
+	lap    int
+	metric int
+	value  float64
+}
+
+// lapMetrics contains values reported by collectors, when using WithCollector.
+type lapMetrics struct {
+	metrics      []lapMetric
+	metricNames  []string
+	metricBuffer []Metric
+}
+
+// beforeLap notifies collectors about the lap starting.
+func (core *lapCore[T]) beforeLap() {
+	for _, collector := range core.opts.collectors {
+		collector.BeforeLap()
+	}
+}
+
+// closeCollectors releases resources of collectors implementing io.Closer.
+func (core *lapCore[T]) closeCollectors() {
+	for _, collector := range core.opts.collectors {
+		if closer, ok := collector.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+// afterLap collects metrics of the lap in progress.
+func (core *lapCore[T]) afterLap() {
+	values := core.metricBuffer[:0]
+	for _, collector := range slices.Backward(core.opts.collectors) {
+		values = collector.AfterLap(values)
+	}
+	for _, value := range values {
+		index := slices.Index(core.metricNames, value.Name)
+		if index < 0 {
+			index = len(core.metricNames)
+			core.metricNames = append(core.metricNames, value.Name)
+		}
+		core.metrics = append(core.metrics, lapMetric{lap: core.step - 1, metric: index, value: value.Value})
+	}
+	core.metricBuffer = values
+}
+
+// dropWarmupMetrics removes metrics of warmup laps.
+func (metrics *lapMetrics) dropWarmupMetrics(warmup int) {
+	metrics.metrics = slices.DeleteFunc(metrics.metrics, func(metric lapMetric) bool {
+		return metric.lap < warmup
+	})
+	for i := range metrics.metrics {
+		metrics.metrics[i].lap -= warmup
+	}
+}
+
+// keepMonotonicMetrics removes metrics of negative laps, similarly to NonMonotonicDrop.
+func (core *lapCore[T]) keepMonotonicMetrics() {
+	kept := make([]int, len(core.laps))
+	index := 0
+	for i, lap := range core.laps {
+		kept[i] = -1
+		if lap >= 0 {
+			kept[i] = index
+			index++
+		}
+	}
+	core.metrics = slices.DeleteFunc(core.metrics, func(metric lapMetric) bool {
+		return kept[metric.lap] < 0
+	})
+	for i := range core.metrics {
+		core.metrics[i].lap = kept[core.metrics[i].lap]
+	}
+}
+
+// reportedMetrics returns names of reported metrics in order of first appearance.
+func (metrics *lapMetrics) reportedMetrics() []string {
+	var names []string
+	for index, name := range metrics.metricNames {
+		if slices.ContainsFunc(metrics.metrics, func(metric lapMetric) bool { return metric.metric == index }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// metricValues returns values of metric name in the order of laps.
+func (metrics *lapMetrics) metricValues(name string) []float64 {
+	var values []float64
+	for _, metric := range metrics.metrics {
+		if metrics.metricNames[metric.metric] == name {
+			values = append(values, metric.value)
+		}
+	}
+	return values
+}
+
+// metricSummaries returns statistics of every reported metric.
+func (core *lapCore[T]) metricSummaries() []MetricSummary {
+	var summaries []MetricSummary
+	for _, name := range core.reportedMetrics() {
+		var values, laps []float64
+		for _, metric := range core.metrics {
+			if core.metricNames[metric.metric] == name {
+				values = append(values, metric.value)
+				laps = append(laps, float64(core.laps[metric.lap]))
+			}
+		}
+		summaries = append(summaries, newMetricSummary(name, values, laps))
+	}
+	return summaries
+}
+
+// Metrics returns names of metrics reported by collectors in order of first appearance.
+func (bench *Benchmark) Metrics() []string {
+	bench = bench.mustBeCompleted()
+	return bench.reportedMetrics()
+}
+
+// MetricValues returns values of metric name in the order of laps.
+//
+// Laps where no collector reported the metric are skipped.
+func (bench *Benchmark) MetricValues(name string) []float64 {
+	bench = bench.mustBeCompleted()
+	return bench.metricValues(name)
+}
+
+// Metrics returns names of metrics reported by collectors in order of first appearance.
+func (bench *BenchmarkTSC) Metrics() []string {
+	bench = bench.mustBeCompleted()
+	return bench.reportedMetrics()
+}
+
+// MetricValues returns values of metric name in the order of laps.
+//
+// Laps where no collector reported the metric are skipped.
+func (bench *BenchmarkTSC) MetricValues(name string) []float64 {
+	bench = bench.mustBeCompleted()
+	return bench.metricValues(name)
+}
+
+// MetricSummary contains statistics of a metric reported by collectors.
+type MetricSummary struct {
+	Name  string
+	Count int
+
+	Mean    float64
+	StdDev  float64
+	Minimum float64
+	Maximum float64
+
+	P50, P90, P99 float64
+
+	// Correlation is Pearson's correlation coefficient between the metric
+	// and lap durations, e.g. close to 1 when cache misses explain slow laps.
+	Correlation float64
+}
+
+// MetricSummaries returns statistics of every metric reported by collectors.
+func (bench *Benchmark) MetricSummaries() []MetricSummary {
+	bench = bench.mustBeCompleted()
+	return bench.metricSummaries()
+}
+
+// MetricSummaries returns statistics of every metric reported by collectors.
+//
+// Correlation with lap counts is the same as with their durations.
+func (bench *BenchmarkTSC) MetricSummaries() []MetricSummary {
+	bench = bench.mustBeCompleted()
+	return bench.metricSummaries()
+}
+
+// newMetricSummary calculates statistics of values measured during laps.
+func newMetricSummary(name string, values, laps []float64) MetricSummary {
+	summary := MetricSummary{Name: name, Count: len(values)}
+	sorted := slices.Sorted(slices.Values(values))
+	summary.Minimum, summary.Maximum = sorted[0], sorted[len(sorted)-1]
+	summary.P50 = quantile(sorted, 0.5)
+	summary.P90 = quantile(sorted, 0.9)
+	summary.P99 = quantile(sorted, 0.99)
+
+	// Welford's algorithm extended to covariance
+	var meanValue, meanLap, m2Value, m2Lap, covariance float64
+	for i, value := range values {
+		n := float64(i + 1)
+		deltaValue, deltaLap := value-meanValue, laps[i]-meanLap
+		meanValue += deltaValue / n
+		meanLap += deltaLap / n
+		m2Value += deltaValue * (value - meanValue)
+		m2Lap += deltaLap * (laps[i] - meanLap)
+		covariance += deltaValue * (laps[i] - meanLap)
+	}
+	summary.Mean = meanValue
+	if len(values) > 1 {
+		summary.StdDev = math.Sqrt(m2Value / float64(len(values)-1))
+	}
+	if m2Value > 0 && m2Lap > 0 {
+		summary.Correlation = covariance / math.Sqrt(m2Value*m2Lap)
+	}
+	return summary
+}
+
+// WriteMetricStatsTo writes statistics of every metric reported by collectors to w.
+func (bench *Benchmark) WriteMetricStatsTo(w io.Writer) (int64, error) {
+	return writeMetricStats(w, bench.MetricSummaries())
+}
+
+// StringMetricStats returns statistics of every metric reported by collectors.
+func (bench *Benchmark) StringMetricStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteMetricStatsTo(&buffer)
+	return buffer.String()
+}
+
+// WriteMetricStatsTo writes statistics of every metric reported by collectors to w.
+func (bench *BenchmarkTSC) WriteMetricStatsTo(w io.Writer) (int64, error) {
+	return writeMetricStats(w, bench.MetricSummaries())
+}
+
+// StringMetricStats returns statistics of every metric reported by collectors.
+func (bench *BenchmarkTSC) StringMetricStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteMetricStatsTo(&buffer)
+	return buffer.String()
+}
+
+// writeMetricStats writes summaries to w.
+func writeMetricStats(w io.Writer, summaries []MetricSummary) (int64, error) {
+	var written int64
+	for _, summary := range summaries {
+		n, err := fmt.Fprintf(w, "%s:\n  count %d  avg %.4g ± %.4g  min %.4g  max %.4g\n  p50 %.4g  p90 %.4g  p99 %.4g  correlation %+.2f\n",
+			summary.Name, summary.Count, summary.Mean, summary.StdDev, summary.Minimum, summary.Maximum,
+			summary.P50, summary.P90, summary.P99, summary.Correlation)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// metricsMetadata summarizes metrics for JSONResult metadata.
+func (core *lapCore[T]) metricsMetadata() map[string]string {
+	summaries := core.metricSummaries()
+	if len(summaries) == 0 {
+		return nil
+	}
+	metadata := map[string]string{}
+	for _, summary := range summaries {
+		metadata["metric."+summary.Name] = fmt.Sprintf("avg %.4g p50 %.4g p99 %.4g", summary.Mean, summary.P50, summary.P99)
+	}
+	return metadata
+}
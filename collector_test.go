@@ -0,0 +1,85 @@
+package hrtime_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// missCounter reports a fake cache miss counter, which explains slow laps.
+type missCounter struct {
+	lap    int
+	misses float64
+	calls  []string
+}
+
+func (counter *missCounter) BeforeLap() {
+	counter.calls = append(counter.calls, "before")
+	counter.misses = float64(counter.lap % 4)
+}
+
+func (counter *missCounter) AfterLap(metrics []hrtime.Metric) []hrtime.Metric {
+	counter.calls = append(counter.calls, "after")
+	counter.lap++
+	return append(metrics, hrtime.Metric{Name: "misses", Value: counter.misses}, hrtime.Metric{Name: "lap", Value: float64(counter.lap)})
+}
+
+func TestBenchmarkCollector(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	counter := &missCounter{}
+	bench := hrtime.NewBenchmark(8, hrtime.WithWarmup(1), hrtime.WithClock(clock), hrtime.WithCollector(counter))
+	for bench.Next() {
+		clock.Advance(time.Duration(100 + 100*counter.misses))
+	}
+
+	if len(counter.calls) != 18 || counter.calls[0] != "before" || counter.calls[1] != "after" {
+		t.Fatalf("unexpected calls %v", counter.calls)
+	}
+	if metrics := bench.Metrics(); !slices.Equal(metrics, []string{"misses", "lap"}) {
+		t.Errorf("unexpected metrics %v", metrics)
+	}
+	// the first lap was used as warmup
+	if values := bench.MetricValues("lap"); len(values) != 8 || values[0] != 2 {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	summaries := bench.MetricSummaries()
+	misses := summaries[0]
+	if misses.Count != 8 || misses.Maximum != 3 || misses.Mean != 1.5 {
+		t.Errorf("unexpected summary %+v", misses)
+	}
+	if misses.Correlation < 0.99 {
+		t.Errorf("expected misses to explain laps, got correlation %v", misses.Correlation)
+	}
+	if stats := bench.StringMetricStats(); !strings.Contains(stats, "misses:") {
+		t.Errorf("unexpected stats:\n%s", stats)
+	}
+	if metadata := bench.JSONResult("").Metadata; metadata["metric.misses"] == "" {
+		t.Errorf("expected metric metadata, got %v", metadata)
+	}
+}
+
+func TestBenchmarkTSCCollector(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	counter := &missCounter{}
+	bench := hrtime.NewBenchmarkTSC(8, hrtime.WithWarmup(1), hrtime.WithClock(clock), hrtime.WithCollector(counter))
+	for bench.Next() {
+		clock.Advance(time.Duration(100+100*counter.misses) * time.Microsecond)
+	}
+
+	if len(counter.calls) != 18 {
+		t.Fatalf("unexpected calls %v", counter.calls)
+	}
+	if values := bench.MetricValues("lap"); len(values) != 8 || values[0] != 2 {
+		t.Errorf("unexpected values %v", values)
+	}
+	if misses := bench.MetricSummaries()[0]; misses.Count != 8 || misses.Correlation < 0.99 {
+		t.Errorf("unexpected summary %+v", misses)
+	}
+	if metadata := bench.JSONResult("").Metadata; metadata["metric.misses"] == "" {
+		t.Errorf("expected metric metadata, got %v", metadata)
+	}
+}
@@ -0,0 +1,264 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Delta describes change of a single statistic between two lap sets.
+type Delta struct {
+	// Metric is the name of the statistic, e.g. "mean" or "p99".
+	Metric string
+	Old    time.Duration
+	New    time.Duration
+	// Change is the relative change, where 0.1 means 10% slower.
+	Change float64
+}
+
+// Comparison contains differences between two lap sets.
+type Comparison struct {
+	// Name identifies the compared benchmark.
+	Name string
+	// OldCount and NewCount are the number of laps in each set.
+	OldCount int
+	NewCount int
+	// Deltas contains changes of mean and key percentiles.
+	Deltas []Delta
+	// PValue is the two-sided p-value of Mann-Whitney U test,
+	// small values mean that the lap distributions differ.
+	PValue float64
+	// CohensD is the difference of means divided by the pooled standard deviation,
+	// positive values mean that new is slower.
+	CohensD float64
+	// CliffsDelta is P(new > old) - P(new < old) in range [-1, 1],
+	// positive values mean that new is slower.
+	CliffsDelta float64
+}
+
+// compareMetrics lists percentiles included in comparisons.
+var compareMetrics = []struct {
+	name string
+	q    float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p99", 0.99},
+	{"p999", 0.999},
+}
+
+// CompareLaps compares mean and key percentiles of old and new laps.
+func CompareLaps(name string, old, new []time.Duration) *Comparison {
+	comparison := &Comparison{
+		Name:     name,
+		OldCount: len(old),
+		NewCount: len(new),
+		PValue:   1,
+	}
+	if len(old) == 0 || len(new) == 0 {
+		return comparison
+	}
+
+	oldSorted, newSorted := sortedDurations(old), sortedDurations(new)
+	var u float64
+	comparison.PValue, u = mannWhitneyU(oldSorted, newSorted)
+	comparison.CliffsDelta = 1 - 2*u/(float64(len(old))*float64(len(new)))
+	comparison.CohensD = cohensD(oldSorted, newSorted)
+	comparison.Deltas = append(comparison.Deltas,
+		newDelta("mean", meanDuration(oldSorted), meanDuration(newSorted)))
+	for _, metric := range compareMetrics {
+		comparison.Deltas = append(comparison.Deltas,
+			newDelta(metric.name, quantile(oldSorted, metric.q), quantile(newSorted, metric.q)))
+	}
+	return comparison
+}
+
+// Compare compares laps of completed benchmarks old and new.
+func Compare(old, new *Benchmark) *Comparison {
+	return new.Result().CompareTo(old.Result())
+}
+
+// CompareTSC compares laps of completed TSC benchmarks old and new.
+func CompareTSC(old, new *BenchmarkTSC) *Comparison {
+	return new.Result().CompareTo(old.Result())
+}
+
+// CompareResults compares results with the same name in old and new.
+//
+// Results in new without a matching old result are compared against themselves.
+func CompareResults(old, new []*JSONResult) []*Comparison {
+	byName := map[string]*JSONResult{}
+	for _, result := range old {
+		byName[result.Name] = result
+	}
+
+	var comparisons []*Comparison
+	for _, result := range new {
+		baseline, ok := byName[result.Name]
+		if !ok {
+			baseline = result
+		}
+		comparisons = append(comparisons, CompareLaps(result.Name, baseline.Durations(), result.Durations()))
+	}
+	return comparisons
+}
+
+// newDelta creates a delta between old and new value.
+func newDelta(metric string, old, new time.Duration) Delta {
+	delta := Delta{Metric: metric, Old: old, New: new}
+	if old != 0 {
+		delta.Change = float64(new-old) / float64(old)
+	}
+	return delta
+}
+
+// mannWhitneyU returns two-sided p-value and U statistic of a
+// Mann-Whitney U test using normal approximation with tie correction.
+func mannWhitneyU(a, b []time.Duration) (p, u float64) {
+	n1, n2 := float64(len(a)), float64(len(b))
+	n := n1 + n2
+
+	// rank sum of a, ties get the average rank
+	var rankSum, ties float64
+	i, k := 0, 0
+	for i < len(a) || k < len(b) {
+		var value time.Duration
+		if k >= len(b) || (i < len(a) && a[i] <= b[k]) {
+			value = a[i]
+		} else {
+			value = b[k]
+		}
+
+		first := float64(i + k + 1)
+		inA := 0
+		for i < len(a) && a[i] == value {
+			i++
+			inA++
+		}
+		for k < len(b) && b[k] == value {
+			k++
+		}
+		last := float64(i + k)
+		rankSum += float64(inA) * (first + last) / 2
+
+		tied := last - first + 1
+		ties += tied*tied*tied - tied
+	}
+
+	u = rankSum - n1*(n1+1)/2
+	mu := n1 * n2 / 2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if !(sigma > 0) {
+		return 1, u
+	}
+
+	// continuity correction
+	z := math.Max(math.Abs(u-mu)-0.5, 0) / sigma
+	return math.Erfc(z / math.Sqrt2), u
+}
+
+// cohensD returns difference of means of b and a divided by pooled standard deviation.
+func cohensD(a, b []time.Duration) float64 {
+	if len(a)+len(b) <= 2 {
+		return 0
+	}
+	meanA, meanB := float64(meanDuration(a)), float64(meanDuration(b))
+	var sum float64
+	for _, d := range a {
+		sum += (float64(d) - meanA) * (float64(d) - meanA)
+	}
+	for _, d := range b {
+		sum += (float64(d) - meanB) * (float64(d) - meanB)
+	}
+	pooled := math.Sqrt(sum / float64(len(a)+len(b)-2))
+	if pooled == 0 {
+		return 0
+	}
+	return (meanB - meanA) / pooled
+}
+
+// meanDuration returns the mean of durations.
+func meanDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total float64
+	for _, d := range durations {
+		total += float64(d)
+	}
+	return time.Duration(total / float64(len(durations)))
+}
+
+// Regressions returns deltas that became slower by more than threshold.
+//
+// threshold is relative, e.g. 0.05 reports metrics that are more than 5% slower.
+func (comparison *Comparison) Regressions(threshold float64) []Delta {
+	var regressions []Delta
+	for _, delta := range comparison.Deltas {
+		if delta.Change > threshold {
+			regressions = append(regressions, delta)
+		}
+	}
+	return regressions
+}
+
+// Significant returns whether the distributions differ at significance level alpha, e.g. 0.05.
+func (comparison *Comparison) Significant(alpha float64) bool {
+	return comparison.PValue < alpha
+}
+
+// Regressed returns whether some metric became slower by more than threshold
+// and the difference is significant at level alpha.
+//
+// It's intended for failing CI, e.g. Regressed(0.05, 0.01).
+func (comparison *Comparison) Regressed(threshold, alpha float64) bool {
+	return comparison.Significant(alpha) && len(comparison.Regressions(threshold)) > 0
+}
+
+// Magnitude describes the magnitude of CliffsDelta as
+// "negligible", "small", "medium" or "large".
+//
+// It uses thresholds from Romano et al: 0.147, 0.33 and 0.474.
+func (comparison *Comparison) Magnitude() string {
+	switch delta := math.Abs(comparison.CliffsDelta); {
+	case delta < 0.147:
+		return "negligible"
+	case delta < 0.33:
+		return "small"
+	case delta < 0.474:
+		return "medium"
+	default:
+		return "large"
+	}
+}
+
+// WriteTo writes textual comparison to w.
+func (comparison *Comparison) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "%s: %d -> %d laps, p=%.3g, d=%+.2f, delta=%+.2f (%s)\n",
+		comparison.Name, comparison.OldCount, comparison.NewCount,
+		comparison.PValue, comparison.CohensD, comparison.CliffsDelta, comparison.Magnitude())
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for _, delta := range comparison.Deltas {
+		n, err = fmt.Fprintf(w, "  %-5s %10v -> %10v  %+7.2f%%\n",
+			delta.Metric, formatStat(float64(delta.Old)), formatStat(float64(delta.New)), delta.Change*100)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns textual comparison.
+func (comparison *Comparison) String() string {
+	var buffer strings.Builder
+	_, _ = comparison.WriteTo(&buffer)
+	return buffer.String()
+}
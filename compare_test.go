@@ -0,0 +1,78 @@
+package hrtime_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestCompareLaps(t *testing.T) {
+	old := []time.Duration{100, 100, 100, 100}
+	new := []time.Duration{100, 100, 100, 200}
+
+	comparison := hrtime.CompareLaps("bench", old, new)
+	if len(comparison.Deltas) == 0 || comparison.Deltas[0].Metric != "mean" {
+		t.Fatalf("unexpected deltas %v", comparison.Deltas)
+	}
+	if change := comparison.Deltas[0].Change; change != 0.25 {
+		t.Errorf("expected mean change 0.25, got %v", change)
+	}
+
+	regressions := comparison.Regressions(0.1)
+	for _, delta := range regressions {
+		if delta.Metric == "p50" {
+			t.Errorf("p50 should not regress")
+		}
+	}
+	if len(regressions) == 0 {
+		t.Errorf("expected regressions")
+	}
+}
+
+func TestComparePValue(t *testing.T) {
+	var old, same, slower []time.Duration
+	for i := 0; i < 200; i++ {
+		old = append(old, time.Duration(1000+i%50))
+		same = append(same, time.Duration(1000+(i+25)%50))
+		slower = append(slower, time.Duration(1100+i%50))
+	}
+
+	if c := hrtime.CompareLaps("same", old, same); c.Significant(0.05) {
+		t.Errorf("expected no significant difference, p=%v", c.PValue)
+	}
+	c := hrtime.CompareLaps("slower", old, slower)
+	if !c.Significant(0.001) || !c.Regressed(0.05, 0.001) {
+		t.Errorf("expected significant regression, p=%v\n%v", c.PValue, c)
+	}
+
+	a, b := hrtime.NewBenchmark(100), hrtime.NewBenchmark(100)
+	for a.Next() {
+	}
+	for b.Next() {
+	}
+	if c := hrtime.Compare(a, b); c.OldCount != 100 || c.PValue < 0 || c.PValue > 1 {
+		t.Errorf("invalid comparison %v", c)
+	}
+}
+
+func TestCompareEffectSize(t *testing.T) {
+	var old, same, slower []time.Duration
+	for i := 0; i < 200; i++ {
+		old = append(old, time.Duration(1000+i%50))
+		same = append(same, time.Duration(1000+(i+25)%50))
+		slower = append(slower, time.Duration(1100+i%50))
+	}
+
+	if c := hrtime.CompareLaps("same", old, same); math.Abs(c.CohensD) > 0.01 || c.Magnitude() != "negligible" {
+		t.Errorf("expected negligible effect, d=%v delta=%v", c.CohensD, c.CliffsDelta)
+	}
+	c := hrtime.CompareLaps("slower", old, slower)
+	if c.CohensD < 1 || c.CliffsDelta != 1 || c.Magnitude() != "large" {
+		t.Errorf("expected large effect, d=%v delta=%v", c.CohensD, c.CliffsDelta)
+	}
+	if c := hrtime.CompareLaps("faster", slower, old); c.CliffsDelta != -1 {
+		t.Errorf("expected delta -1, got %v", c.CliffsDelta)
+	}
+}
@@ -0,0 +1,208 @@
+package hrtime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrentBenchmark measures laps from multiple goroutines.
+//
+// Laps are recorded into shards padded to separate cache lines, which
+// reduces contention between goroutines. After Finalize the results are
+// available as a single Benchmark.
+type ConcurrentBenchmark struct {
+	opts      options
+	shards    []concurrentShard
+	finalized atomic.Bool
+	// err reports unsupported options and misuse of Run, under MisuseError policy.
+	err error
+
+	merging sync.Mutex
+	merged  *Benchmark
+}
+
+// concurrentShard contains laps recorded by a subset of goroutines.
+type concurrentShard struct {
+	mu    sync.Mutex
+	laps  []time.Duration
+	first time.Duration
+	last  time.Duration
+	// padding avoids false sharing between shards.
+	_ [64]byte
+}
+
+// ConcurrentLap is a lap in progress, started with ConcurrentBenchmark.Start.
+type ConcurrentLap struct {
+	bench *ConcurrentBenchmark
+	start time.Duration
+}
+
+// NewConcurrentBenchmark creates a benchmark for concurrent measurements.
+//
+// WithWarmup, WithTimestamps, WithSampling, WithReservoir, WithCollector,
+// WithMemStats and WithGCPauses are not supported, since laps of different
+// goroutines overlap, and using them is reported as misuse.
+func NewConcurrentBenchmark(opts ...Option) *ConcurrentBenchmark {
+	bench := &ConcurrentBenchmark{
+		opts:   newOptions(opts),
+		shards: make([]concurrentShard, runtime.GOMAXPROCS(0)),
+	}
+	config := &bench.opts
+	if config.warmup > 0 || config.timestamps || config.sampled() || config.collectors != nil || config.memStats || config.gcPauses {
+		bench.err = config.misuse("concurrent benchmark does not support warmup, timestamps, sampling, collectors, memory stats and GC pauses")
+	}
+	return bench
+}
+
+// Start starts measuring a lap, it's safe to call from multiple goroutines.
+func (bench *ConcurrentBenchmark) Start() ConcurrentLap {
+	return ConcurrentLap{bench: bench, start: bench.opts.now()}
+}
+
+// Finish finishes measuring the lap.
+//
+// The lap is recorded into a random shard.
+func (lap ConcurrentLap) Finish() {
+	finish := lap.bench.opts.now()
+	lap.bench.record(rand.IntN(len(lap.bench.shards)), lap.start, finish)
+}
+
+// record adds a lap to the specified shard.
+func (bench *ConcurrentBenchmark) record(index int, start, finish time.Duration) {
+	shard := &bench.shards[index]
+	shard.mu.Lock()
+	// checked under the lock, otherwise the lap could be added
+	// after Finalize has copied the shard
+	if bench.finalized.Load() {
+		shard.mu.Unlock()
+		bench.opts.misuse("benchmark already finalized")
+		return
+	}
+	if len(shard.laps) == 0 || start < shard.first {
+		shard.first = start
+	}
+	if finish > shard.last {
+		shard.last = finish
+	}
+	shard.laps = append(shard.laps, finish-start)
+	shard.mu.Unlock()
+}
+
+// Run calls fn count times in total from the specified number of goroutines,
+// measuring each call, and waits for all of them to finish.
+//
+// Goroutine i records into shard i modulo GOMAXPROCS, hence every goroutine
+// has a dedicated shard only when goroutines is at most GOMAXPROCS.
+func (bench *ConcurrentBenchmark) Run(goroutines, count int, fn func()) {
+	if goroutines <= 0 {
+		err := bench.opts.misuse("must have at least one goroutine")
+		bench.merging.Lock()
+		bench.err = errors.Join(bench.err, err)
+		bench.merging.Unlock()
+		return
+	}
+
+	var remaining atomic.Int64
+	remaining.Store(int64(count))
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			for remaining.Add(-1) >= 0 {
+				start := bench.opts.now()
+				fn()
+				bench.record(index, start, bench.opts.now())
+			}
+		}(i % len(bench.shards))
+	}
+	wg.Wait()
+}
+
+// Finalize merges all shards into a single Benchmark.
+//
+// Every shard is retained as a source of the Benchmark, see Benchmark.Sources.
+// When using only Run with at most GOMAXPROCS goroutines, every shard contains
+// laps of a single goroutine.
+//
+// Laps recorded after calling Finalize are reported as misuse and dropped.
+// Calling Finalize multiple times returns the same Benchmark.
+func (bench *ConcurrentBenchmark) Finalize() *Benchmark {
+	bench.merging.Lock()
+	defer bench.merging.Unlock()
+	if bench.merged != nil {
+		return bench.merged
+	}
+	bench.finalized.Store(true)
+
+	start, stop := time.Duration(math.MaxInt64), time.Duration(0)
+	var laps []time.Duration
+	var nonMonotonic int
+	sources := make([]benchmarkSource, 0, len(bench.shards))
+	for i := range bench.shards {
+		shard := &bench.shards[i]
+		shard.mu.Lock()
+		if len(shard.laps) > 0 {
+			offset := len(laps)
+			laps = append(laps, shard.laps...)
+			fixed, count, _ := fixNonMonotonic(laps[offset:], bench.opts.nonMonotonic)
+			laps = laps[:offset+len(fixed)]
+			if bench.opts.overhead != nil {
+				subtractOverhead(laps[offset:], bench.opts.overhead.Lap)
+			}
+			nonMonotonic += count
+			if shard.first < start {
+				start = shard.first
+			}
+			if shard.last > stop {
+				stop = shard.last
+			}
+		}
+		sources = append(sources, benchmarkSource{name: "shard " + strconv.Itoa(i), end: len(laps)})
+		shard.mu.Unlock()
+	}
+	if len(laps) == 0 {
+		start = stop
+	}
+
+	merged := &Benchmark{
+		lapCore: lapCore[time.Duration]{
+			step:         len(laps),
+			laps:         laps,
+			start:        start,
+			stop:         stop,
+			opts:         bench.opts,
+			nonMonotonic: nonMonotonic,
+			sources:      sources,
+		},
+	}
+	if nonMonotonic > 0 && bench.opts.nonMonotonic == NonMonotonicError {
+		merged.err = fmt.Errorf("%w: %d laps", ErrNonMonotonic, nonMonotonic)
+	}
+	if bench.err != nil {
+		merged.err = errors.Join(bench.err, merged.err)
+	}
+	merged.done.Store(true)
+	tracef("merge", "%d shards, %d laps, %d non-monotonic", len(bench.shards), len(laps), nonMonotonic)
+
+	bench.merged = merged
+	return merged
+}
+
+// Laps returns timing for each lap, it finalizes the benchmark.
+func (bench *ConcurrentBenchmark) Laps() []time.Duration {
+	return bench.Finalize().Laps()
+}
+
+// Histogram creates an histogram of all the laps, it finalizes the benchmark.
+func (bench *ConcurrentBenchmark) Histogram(binCount int) *Histogram {
+	return bench.Finalize().Histogram(binCount)
+}
@@ -0,0 +1,47 @@
+package hrtime_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestConcurrentBenchmark(t *testing.T) {
+	bench := hrtime.NewConcurrentBenchmark()
+	bench.Run(4, 100, func() { time.Sleep(time.Microsecond) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 25; k++ {
+				lap := bench.Start()
+				lap.Finish()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if laps := bench.Laps(); len(laps) != 200 {
+		t.Errorf("expected 200 laps, got %d", len(laps))
+	}
+	if bench.Finalize() != bench.Finalize() {
+		t.Errorf("Finalize should return the same benchmark")
+	}
+	t.Log(bench.Histogram(5))
+}
+
+func TestConcurrentBenchmarkUnsupportedOptions(t *testing.T) {
+	bench := hrtime.NewConcurrentBenchmark(hrtime.WithWarmup(2), hrtime.WithMisusePolicy(hrtime.MisuseError))
+	bench.Run(2, 10, func() {})
+	if err := bench.Finalize().Err(); !errors.Is(err, hrtime.ErrMisuse) {
+		t.Errorf("expected ErrMisuse for warmup, got %v", err)
+	}
+	if laps := bench.Laps(); len(laps) != 10 {
+		t.Errorf("expected 10 laps, got %d", len(laps))
+	}
+}
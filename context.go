@@ -0,0 +1,53 @@
+package hrtime
+
+import "context"
+
+// NextCtx starts measuring the next lap, similarly to Next, unless ctx is done.
+//
+// When ctx is canceled or its deadline is exceeded, the lap in progress ends
+// and the benchmark completes with the laps measured so far, as with Stop.
+// Err then reports the cause of ctx cancellation. The context is checked
+// between laps, hence checking is not included in the measured laps.
+func (bench *Benchmark) NextCtx(ctx context.Context) bool {
+	now := bench.opts.now()
+	if bench.done.Load() {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		bench.cause = context.Cause(ctx)
+		bench.stopAt(now)
+		return false
+	default:
+		return bench.next(now)
+	}
+}
+
+// Canceled returns whether the benchmark was stopped by NextCtx.
+func (bench *Benchmark) Canceled() bool {
+	bench = bench.mustBeCompleted()
+	return bench.cause != nil
+}
+
+// NextCtx starts measuring the next lap, similarly to Next, unless ctx is done,
+// see Benchmark.NextCtx.
+func (bench *BenchmarkTSC) NextCtx(ctx context.Context) bool {
+	now := bench.opts.tsc()
+	if bench.done.Load() {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		bench.cause = context.Cause(ctx)
+		bench.stopAt(now)
+		return false
+	default:
+		return bench.next(now)
+	}
+}
+
+// Canceled returns whether the benchmark was stopped by NextCtx.
+func (bench *BenchmarkTSC) Canceled() bool {
+	bench = bench.mustBeCompleted()
+	return bench.cause != nil
+}
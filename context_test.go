@@ -0,0 +1,58 @@
+package hrtime_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkNextCtx(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(10, hrtime.WithClock(clock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	laps := 0
+	for bench.NextCtx(ctx) {
+		clock.Advance(100)
+		laps++
+		if laps == 3 {
+			cancel()
+		}
+	}
+
+	if !bench.Canceled() || !errors.Is(bench.Err(), context.Canceled) {
+		t.Errorf("expected canceled benchmark, got %v", bench.Err())
+	}
+	if got := bench.Laps(); len(got) != 3 || got[0] != 100 || got[2] != 100 {
+		t.Errorf("unexpected laps %v", got)
+	}
+	if bench.NextCtx(context.Background()) {
+		t.Error("expected completed benchmark")
+	}
+}
+
+func TestBenchmarkNextCtxDeadline(t *testing.T) {
+	bench := hrtime.NewStreamingBenchmark()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	for bench.NextCtx(ctx) {
+		time.Sleep(100 * time.Microsecond)
+	}
+	if !errors.Is(bench.Err(), context.DeadlineExceeded) || len(bench.Laps()) == 0 {
+		t.Errorf("expected laps until deadline, got %d laps %v", len(bench.Laps()), bench.Err())
+	}
+
+	bench.Reset()
+	for laps := 0; bench.NextCtx(context.Background()); laps++ {
+		if laps == 2 {
+			bench.Stop()
+		}
+	}
+	if bench.Canceled() || bench.Err() != nil {
+		t.Errorf("expected reset to clear cancellation, got %v", bench.Err())
+	}
+}
@@ -0,0 +1,126 @@
+// Package diskprobe measures write+fsync and read latency distributions
+// on a target directory.
+//
+// Durations are collected into hrtime.Recorder, hence they can be
+// analyzed with the same histogram and reporting pipeline.
+package diskprobe
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+
+	"github.com/loov/hrtime"
+)
+
+// Options configures a probe.
+type Options struct {
+	// BlockSize is the size of a single write or read, 4096 by default.
+	BlockSize int
+	// Count is the number of operations, 1000 by default.
+	Count int
+}
+
+// withDefaults returns options with defaults filled in.
+func (opts Options) withDefaults() Options {
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = 4096
+	}
+	if opts.Count <= 0 {
+		opts.Count = 1000
+	}
+	return opts
+}
+
+// WriteSync measures latency of writing a block followed by fsync.
+//
+// It writes to a temporary file in dir, which is removed afterwards.
+func WriteSync(dir string, opts Options) (*hrtime.Recorder, error) {
+	opts = opts.withDefaults()
+
+	file, err := os.CreateTemp(dir, "hrtime-diskprobe-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	block := make([]byte, opts.BlockSize)
+	for i := range block {
+		block[i] = byte(i)
+	}
+
+	rec := hrtime.NewRecorder()
+	for i := 0; i < opts.Count; i++ {
+		start := hrtime.Now()
+		if _, err := file.Write(block); err != nil {
+			return rec, errors.Join(err, file.Close())
+		}
+		if err := file.Sync(); err != nil {
+			return rec, errors.Join(err, file.Close())
+		}
+		rec.Record(hrtime.Since(start))
+	}
+	return rec, file.Close()
+}
+
+// Read measures latency of reading blocks at random offsets.
+//
+// It creates a temporary file in dir of Count blocks, which is removed afterwards.
+// Reads are likely served from the page cache, unless the file is larger
+// than available memory.
+func Read(dir string, opts Options) (*hrtime.Recorder, error) {
+	opts = opts.withDefaults()
+
+	file, err := os.CreateTemp(dir, "hrtime-diskprobe-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	block := make([]byte, opts.BlockSize)
+	for i := 0; i < opts.Count; i++ {
+		if _, err := file.Write(block); err != nil {
+			return nil, err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+
+	return ReadFile(file.Name(), opts)
+}
+
+// ReadFile measures latency of reading blocks at random offsets of an existing file.
+//
+// opts.Count is the number of reads.
+func ReadFile(path string, opts Options) (*hrtime.Recorder, error) {
+	opts = opts.withDefaults()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	blocks := info.Size() / int64(opts.BlockSize)
+	if blocks <= 0 {
+		return nil, errors.New("file smaller than block size")
+	}
+
+	block := make([]byte, opts.BlockSize)
+	rec := hrtime.NewRecorder()
+	for i := 0; i < opts.Count; i++ {
+		offset := rand.Int64N(blocks) * int64(opts.BlockSize)
+		start := hrtime.Now()
+		if _, err := file.ReadAt(block, offset); err != nil {
+			return rec, err
+		}
+		rec.Record(hrtime.Since(start))
+	}
+	return rec, nil
+}
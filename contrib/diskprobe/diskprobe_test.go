@@ -0,0 +1,28 @@
+package diskprobe_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime/contrib/diskprobe"
+)
+
+func TestProbes(t *testing.T) {
+	opts := diskprobe.Options{BlockSize: 512, Count: 16}
+
+	writes, err := diskprobe.WriteSync(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writes.Count() != 16 {
+		t.Errorf("expected 16 writes, got %d", writes.Count())
+	}
+
+	reads, err := diskprobe.Read(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reads.Count() != 16 {
+		t.Errorf("expected 16 reads, got %d", reads.Count())
+	}
+	t.Log("\n" + reads.Histogram(5).String())
+}
@@ -0,0 +1,122 @@
+// Package httpprofile records time-to-first-byte style checkpoints of
+// HTTP client requests.
+//
+// Each request is traced through DNS lookup, connecting, TLS handshake,
+// writing the request, receiving the first response byte and reading
+// the whole body. The per-segment distributions are aggregated by
+// hrtime.Profiler.
+package httpprofile
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/loov/hrtime"
+)
+
+// Checkpoint indices in the profiler.
+const (
+	DNS = iota
+	Connect
+	TLS
+	WroteRequest
+	FirstByte
+	Done
+)
+
+// Checkpoints contains names of checkpoints in the profiler.
+var Checkpoints = []string{"dns", "connect", "tls", "request", "firstByte", "done"}
+
+// NewProfiler creates a profiler with Checkpoints.
+func NewProfiler() *hrtime.Profiler {
+	return hrtime.NewProfiler(Checkpoints...)
+}
+
+// Transport records checkpoints of requests into Profiler.
+//
+// Checkpoints not happening for a request, such as DNS lookup when
+// reusing a connection, are not recorded.
+type Transport struct {
+	// Base is used for making requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+	// Profiler must be created by NewProfiler.
+	Profiler *hrtime.Profiler
+}
+
+// RoundTrip implements http.RoundTripper.
+func (transport *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := transport.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	trace := &requestTrace{trace: transport.Profiler.Start()}
+	clientTrace := &httptrace.ClientTrace{
+		DNSDone:              func(httptrace.DNSDoneInfo) { trace.checkpoint(DNS) },
+		ConnectDone:          func(string, string, error) { trace.checkpoint(Connect) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { trace.checkpoint(TLS) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { trace.checkpoint(WroteRequest) },
+		GotFirstResponseByte: func() { trace.checkpoint(FirstByte) },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), clientTrace))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &tracedBody{ReadCloser: resp.Body, trace: trace}
+	return resp, nil
+}
+
+// requestTrace guards hrtime.Trace, since httptrace callbacks may
+// be called from different goroutines.
+type requestTrace struct {
+	mu       sync.Mutex
+	trace    hrtime.Trace
+	finished bool
+}
+
+// checkpoint marks reaching a checkpoint.
+func (trace *requestTrace) checkpoint(index int) {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	if !trace.finished {
+		trace.trace.Checkpoint(index)
+	}
+}
+
+// finish marks done and records the request.
+func (trace *requestTrace) finish() {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	if trace.finished {
+		return
+	}
+	trace.finished = true
+	trace.trace.Checkpoint(Done)
+	trace.trace.Finish()
+}
+
+// tracedBody finishes the trace when body is fully read or closed.
+type tracedBody struct {
+	io.ReadCloser
+	trace *requestTrace
+}
+
+// Read implements io.Reader.
+func (body *tracedBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if err == io.EOF {
+		body.trace.finish()
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (body *tracedBody) Close() error {
+	body.trace.finish()
+	return body.ReadCloser.Close()
+}
@@ -0,0 +1,37 @@
+package httpprofile_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/loov/hrtime/contrib/httpprofile"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	profiler := httpprofile.NewProfiler()
+	client := &http.Client{Transport: &httpprofile.Transport{Profiler: profiler}}
+
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	if profiler.Total().Count() != 4 || profiler.Recorder("firstByte").Count() != 4 {
+		t.Errorf("unexpected counts:\n%v", profiler)
+	}
+	if connects := profiler.Recorder("connect").Count(); connects != 1 {
+		t.Errorf("expected a single connect with keep-alive, got %d", connects)
+	}
+	t.Log("\n" + profiler.String())
+}
@@ -0,0 +1,185 @@
+// Package membench measures memory copy bandwidth and pointer-chasing
+// latency at several working-set sizes.
+//
+// The measurements form a rough hardware profile, which can be attached
+// to results to make them easier to compare across machines.
+package membench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// DefaultSizes are working-set sizes roughly matching L1, L2, L3 and main memory.
+var DefaultSizes = []int{16 << 10, 256 << 10, 4 << 20, 64 << 20}
+
+// cacheLine is the assumed cache line size in bytes.
+const cacheLine = 64
+
+// Result contains measurements for a single working-set size.
+type Result struct {
+	// Size is the working-set size in bytes.
+	Size int
+	// CopyBandwidth is the memory copy bandwidth in bytes per second.
+	CopyBandwidth float64
+	// PointerLatency is the latency of a single dependent load.
+	PointerLatency time.Duration
+}
+
+// Profile contains results for multiple working-set sizes.
+type Profile []Result
+
+// Measure measures all sizes, using DefaultSizes when sizes is empty.
+func Measure(sizes ...int) Profile {
+	if len(sizes) == 0 {
+		sizes = DefaultSizes
+	}
+	profile := make(Profile, 0, len(sizes))
+	for _, size := range sizes {
+		profile = append(profile, Result{
+			Size:           size,
+			CopyBandwidth:  CopyBandwidth(size, 0),
+			PointerLatency: PointerChase(size, 0),
+		})
+	}
+	return profile
+}
+
+// CopyBandwidth measures copying between two buffers of size bytes.
+//
+// It copies at least 64MB or repeat times, whichever is larger,
+// and returns bandwidth in bytes per second using the best repetition.
+func CopyBandwidth(size, repeat int) float64 {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	if min := (64 << 20) / size; repeat < min {
+		repeat = min
+	}
+	if repeat < 1 {
+		repeat = 1
+	}
+
+	src, dst := make([]byte, size), make([]byte, size)
+	for i := range src {
+		src[i] = byte(i)
+	}
+	copy(dst, src) // warmup
+
+	bench := hrtime.NewBenchmark(repeat)
+	for bench.Next() {
+		copy(dst, src)
+	}
+	best := time.Duration(math.MaxInt64)
+	for _, lap := range bench.LapsUnsafe() {
+		if lap < best {
+			best = lap
+		}
+	}
+	if best <= 0 {
+		best = 1
+	}
+	return float64(size) / best.Seconds()
+}
+
+// PointerChase measures latency of dependent loads in a working set of size bytes.
+//
+// The working set is a random cycle through cache lines, which defeats
+// hardware prefetching. It performs at least steps loads, using 1M when
+// steps is not positive.
+func PointerChase(size, steps int) time.Duration {
+	if size < 2*cacheLine {
+		panic("size must be at least two cache lines")
+	}
+	if steps <= 0 {
+		steps = 1 << 20
+	}
+
+	const stride = cacheLine / 8
+	lines := size / cacheLine
+	next := make([]uint64, lines*stride)
+
+	// Sattolo's algorithm produces a single cycle through all lines.
+	order := make([]int, lines)
+	for i := range order {
+		order[i] = i
+	}
+	for i := lines - 1; i > 0; i-- {
+		k := rand.IntN(i)
+		order[i], order[k] = order[k], order[i]
+	}
+	for i, line := range order {
+		next[line*stride] = uint64(order[(i+1)%lines] * stride)
+	}
+
+	p := uint64(0)
+	for i := 0; i < lines; i++ { // warmup
+		p = next[p]
+	}
+
+	start := hrtime.Now()
+	for i := 0; i < steps; i++ {
+		p = next[p]
+	}
+	elapsed := hrtime.Since(start)
+	sink = p
+
+	return elapsed / time.Duration(steps)
+}
+
+// sink prevents the pointer chasing loop from being optimized away.
+var sink uint64
+
+// Metadata formats profile as result metadata.
+func (profile Profile) Metadata() map[string]string {
+	metadata := make(map[string]string, 2*len(profile))
+	for _, result := range profile {
+		size := formatSize(result.Size)
+		metadata["mem.copy."+size] = fmt.Sprintf("%.2fGB/s", result.CopyBandwidth/1e9)
+		metadata["mem.latency."+size] = hrtime.RoundDuration(result.PointerLatency).String()
+	}
+	return metadata
+}
+
+// WriteTo writes profile as a table to w.
+func (profile Profile) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "%10s %12s %10s\n", "size", "copy", "latency")
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, result := range profile {
+		n, err = fmt.Fprintf(w, "%10s %8.2fGB/s %10v\n",
+			formatSize(result.Size), result.CopyBandwidth/1e9, hrtime.RoundDuration(result.PointerLatency))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns profile as a table.
+func (profile Profile) String() string {
+	var buffer strings.Builder
+	_, _ = profile.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// formatSize formats size in bytes using binary units.
+func formatSize(size int) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMiB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKiB", size>>10)
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,25 @@
+package membench_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime/contrib/membench"
+)
+
+func TestMeasure(t *testing.T) {
+	profile := membench.Measure(16<<10, 64<<10)
+	if len(profile) != 2 {
+		t.Fatalf("unexpected profile %v", profile)
+	}
+	for _, result := range profile {
+		if result.CopyBandwidth <= 0 || result.PointerLatency <= 0 {
+			t.Errorf("invalid result %+v", result)
+		}
+	}
+
+	metadata := profile.Metadata()
+	if _, ok := metadata["mem.copy.16KiB"]; !ok {
+		t.Errorf("missing metadata %v", metadata)
+	}
+	t.Log("\n" + profile.String())
+}
@@ -0,0 +1,118 @@
+// Package netprobe measures network round-trip time distributions
+// using TCP connect or echo requests.
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// Options configures a probe.
+type Options struct {
+	// Count is the number of probes, 100 by default.
+	Count int
+	// Interval paces probes to start at fixed intervals.
+	// When zero, probes are sent back-to-back.
+	Interval time.Duration
+	// CorrectOmission measures from the intended start of a paced probe
+	// instead of the actual start. This includes the delay caused by
+	// previous slow probes, which otherwise hides tail latency.
+	CorrectOmission bool
+	// Timeout limits a single probe, 5s by default.
+	Timeout time.Duration
+	// Payload is the size of an echo request, 64 bytes by default.
+	Payload int
+}
+
+// withDefaults returns options with defaults filled in.
+func (opts Options) withDefaults() Options {
+	if opts.Count <= 0 {
+		opts.Count = 100
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Payload <= 0 {
+		opts.Payload = 64
+	}
+	return opts
+}
+
+// TCPConnect measures the time to establish a TCP connection to address.
+func TCPConnect(ctx context.Context, address string, opts Options) (*hrtime.Recorder, error) {
+	opts = opts.withDefaults()
+	dialer := net.Dialer{Timeout: opts.Timeout}
+
+	return run(ctx, opts, func() error {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// TCPEcho measures round trips of Payload bytes over a single TCP connection.
+//
+// The target must echo back the received data.
+func TCPEcho(ctx context.Context, address string, opts Options) (*hrtime.Recorder, error) {
+	opts = opts.withDefaults()
+	dialer := net.Dialer{Timeout: opts.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetNoDelay(true)
+	}
+
+	request := make([]byte, opts.Payload)
+	for i := range request {
+		request[i] = byte(i)
+	}
+	response := make([]byte, opts.Payload)
+
+	return run(ctx, opts, func() error {
+		if err := conn.SetDeadline(time.Now().Add(opts.Timeout)); err != nil {
+			return err
+		}
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		_, err := io.ReadFull(conn, response)
+		return err
+	})
+}
+
+// run paces and measures probe opts.Count times.
+func run(ctx context.Context, opts Options, probe func() error) (*hrtime.Recorder, error) {
+	rec := hrtime.NewRecorder()
+	start := hrtime.Now()
+	for i := 0; i < opts.Count; i++ {
+		if err := ctx.Err(); err != nil {
+			return rec, err
+		}
+
+		intended := start + time.Duration(i)*opts.Interval
+		if wait := intended - hrtime.Now(); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		begin := hrtime.Now()
+		if opts.CorrectOmission && opts.Interval > 0 {
+			begin = intended
+		}
+		if err := probe(); err != nil {
+			return rec, errors.Join(errors.New("probe failed"), err)
+		}
+		rec.Record(hrtime.Since(begin))
+	}
+	return rec, nil
+}
@@ -0,0 +1,47 @@
+package netprobe_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime/contrib/netprobe"
+)
+
+func TestTCPProbes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	ctx := context.Background()
+	opts := netprobe.Options{Count: 8, Interval: time.Millisecond, CorrectOmission: true}
+
+	connects, err := netprobe.TCPConnect(ctx, listener.Addr().String(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	echoes, err := netprobe.TCPEcho(ctx, listener.Addr().String(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connects.Count() != 8 || echoes.Count() != 8 {
+		t.Errorf("unexpected counts %d %d", connects.Count(), echoes.Count())
+	}
+	t.Log("\n" + echoes.Histogram(5).String())
+}
@@ -0,0 +1,96 @@
+// Package osbench measures operating system overheads, such as syscall
+// latency and thread wake-up round trips.
+//
+// These quantify how much the OS contributes to tail latency.
+package osbench
+
+import (
+	"runtime"
+	"syscall"
+
+	"github.com/loov/hrtime"
+)
+
+// NewSuite creates a suite measuring OS overheads, each benchmark runs count laps.
+//
+// It contains:
+//
+//   - "syscall/getpid": a minimal syscall;
+//   - "wake/goroutine": round trip between two goroutines;
+//   - "wake/thread": round trip between two goroutines locked to separate
+//     OS threads, which requires futex wake and sleep on Linux.
+func NewSuite(count int) *hrtime.Suite {
+	suite := hrtime.NewSuite()
+	suite.Add("syscall/getpid", count, func() { syscall.Getpid() })
+	suite.AddSetup("wake/goroutine", count, pingPongSetup(false))
+	suite.AddSetup("wake/thread", count, pingPongSetup(true))
+	return suite
+}
+
+// pingPong bounces a token between the caller and a partner goroutine.
+//
+// The partner runs for the duration of a single run of the benchmark.
+type pingPong struct {
+	lockThread bool
+
+	ping chan struct{}
+	pong chan struct{}
+	// done stops the partner and exited is closed, when it has stopped.
+	done   chan struct{}
+	exited chan struct{}
+}
+
+// pingPongSetup returns a Suite setup starting a partner for every run.
+func pingPongSetup(lockThread bool) func() (fn, teardown func()) {
+	return func() (fn, teardown func()) {
+		pp := &pingPong{
+			lockThread: lockThread,
+			ping:       make(chan struct{}),
+			pong:       make(chan struct{}),
+			done:       make(chan struct{}),
+			exited:     make(chan struct{}),
+		}
+		pp.start()
+		return pp.roundTrip, pp.stop
+	}
+}
+
+// start starts the partner, locking the caller to its thread when needed.
+func (pp *pingPong) start() {
+	if pp.lockThread {
+		runtime.LockOSThread()
+	}
+	go pp.partner()
+}
+
+// stop stops the partner and unlocks the caller from its thread.
+func (pp *pingPong) stop() {
+	if pp.lockThread {
+		defer runtime.UnlockOSThread()
+	}
+	close(pp.done)
+	<-pp.exited
+}
+
+// roundTrip sends a token to the partner and waits for the reply.
+func (pp *pingPong) roundTrip() {
+	pp.ping <- struct{}{}
+	<-pp.pong
+}
+
+// partner replies to pings until stopped.
+func (pp *pingPong) partner() {
+	defer close(pp.exited)
+	if pp.lockThread {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	for {
+		select {
+		case <-pp.ping:
+			pp.pong <- struct{}{}
+		case <-pp.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package osbench_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loov/hrtime/contrib/osbench"
+)
+
+func TestSuite(t *testing.T) {
+	suite := osbench.NewSuite(64)
+	// the second run checks that partners are restarted
+	if _, err := suite.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	results, err := suite.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("unexpected results %v", results)
+	}
+	for _, result := range results {
+		if len(result.Benchmark.Laps()) != 64 {
+			t.Errorf("%s: unexpected lap count", result.Name)
+		}
+		t.Logf("%s\n%v", result.Name, result.Benchmark.Histogram(5))
+	}
+}
@@ -0,0 +1,137 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LapCorrelation describes sampled values around a single lap.
+type LapCorrelation struct {
+	Lap  int
+	Span Span
+	// Before is the last sampled value before the lap started.
+	Before float64
+	// After is the first sampled value after the lap finished.
+	After float64
+}
+
+// Changed returns whether the sampled value changed during the lap.
+func (lap *LapCorrelation) Changed() bool { return lap.Before != lap.After }
+
+// Correlation summarizes how sampled values relate to the slowest laps.
+type Correlation struct {
+	// Slowest contains the slowest laps ordered by descending duration.
+	Slowest []LapCorrelation
+	// Total is the number of analyzed laps.
+	Total int
+
+	// ChangedSlowest is the fraction of slowest laps where the value changed.
+	ChangedSlowest float64
+	// ChangedAll is the fraction of all laps where the value changed.
+	ChangedAll float64
+
+	// MeanSlowest is the mean value before the slowest laps.
+	MeanSlowest float64
+	// MeanAll is the mean value before all laps.
+	MeanAll float64
+}
+
+// Correlate finds which sampled values coincide with the top slowest spans.
+//
+// Samples are expected to be ordered by time, as returned by Sampler.Samples.
+// Monotonic counters (e.g. number of GC cycles) show up as changes during laps,
+// while levels (e.g. queue depth) show up as differences in mean values.
+// Negative top is treated as zero.
+func Correlate(spans []Span, samples []Sample, top int) *Correlation {
+	result := &Correlation{Total: len(spans)}
+	if len(spans) == 0 || len(samples) == 0 {
+		return result
+	}
+	top = max(0, min(top, len(spans)))
+
+	all := make([]LapCorrelation, len(spans))
+	for i, span := range spans {
+		all[i] = LapCorrelation{
+			Lap:    i,
+			Span:   span,
+			Before: sampleBefore(samples, span.Start),
+			After:  sampleAfter(samples, span.Finish),
+		}
+	}
+
+	result.ChangedAll, result.MeanAll = summarizeCorrelations(all)
+
+	sort.SliceStable(all, func(i, k int) bool {
+		return all[i].Span.Duration() > all[k].Span.Duration()
+	})
+	result.Slowest = all[:top:top]
+	result.ChangedSlowest, result.MeanSlowest = summarizeCorrelations(result.Slowest)
+
+	return result
+}
+
+// summarizeCorrelations calculates fraction of changed laps and mean value before laps.
+func summarizeCorrelations(laps []LapCorrelation) (changed, mean float64) {
+	if len(laps) == 0 {
+		return 0, 0
+	}
+	for i := range laps {
+		if laps[i].Changed() {
+			changed++
+		}
+		mean += laps[i].Before
+	}
+	return changed / float64(len(laps)), mean / float64(len(laps))
+}
+
+// sampleBefore returns the last sample value at or before t.
+func sampleBefore(samples []Sample, t time.Duration) float64 {
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].Time > t })
+	if i == 0 {
+		return samples[0].Value
+	}
+	return samples[i-1].Value
+}
+
+// sampleAfter returns the first sample value at or after t.
+func sampleAfter(samples []Sample, t time.Duration) float64 {
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].Time >= t })
+	if i == len(samples) {
+		return samples[len(samples)-1].Value
+	}
+	return samples[i].Value
+}
+
+// WriteTo writes textual correlation summary to w.
+func (corr *Correlation) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "slowest %d of %d laps:\n  value changed during %.1f%% of slowest laps vs %.1f%% of all laps\n  mean value %.4g before slowest laps vs %.4g overall\n",
+		len(corr.Slowest), corr.Total,
+		corr.ChangedSlowest*100, corr.ChangedAll*100,
+		corr.MeanSlowest, corr.MeanAll,
+	)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for _, lap := range corr.Slowest {
+		n, err = fmt.Fprintf(w, "  lap %6d %10v  value %.4g -> %.4g\n",
+			lap.Lap, formatStat(float64(lap.Span.Duration())), lap.Before, lap.After)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns textual correlation summary.
+func (corr *Correlation) String() string {
+	var buffer strings.Builder
+	_, _ = corr.WriteTo(&buffer)
+	return buffer.String()
+}
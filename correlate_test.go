@@ -0,0 +1,53 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestCorrelate(t *testing.T) {
+	spans := []hrtime.Span{
+		{Start: 0, Finish: 10},
+		{Start: 10, Finish: 100},
+		{Start: 100, Finish: 110},
+		{Start: 110, Finish: 120},
+	}
+	samples := []hrtime.Sample{
+		{Time: 0, Value: 1},
+		{Time: 10, Value: 1},
+		{Time: 50, Value: 2},
+		{Time: 105, Value: 2},
+		{Time: 200, Value: 2},
+	}
+
+	corr := hrtime.Correlate(spans, samples, 1)
+	if len(corr.Slowest) != 1 || corr.Slowest[0].Lap != 1 {
+		t.Fatalf("expected lap 1 as slowest, got %+v", corr.Slowest)
+	}
+	if !corr.Slowest[0].Changed() {
+		t.Errorf("expected value change during slowest lap")
+	}
+	if corr.ChangedSlowest != 1 || corr.ChangedAll != 0.25 {
+		t.Errorf("changed: slowest %v, all %v", corr.ChangedSlowest, corr.ChangedAll)
+	}
+	t.Log(corr)
+
+	if none := hrtime.Correlate(spans, samples, -1); len(none.Slowest) != 0 {
+		t.Errorf("expected no slowest laps for negative top, got %+v", none.Slowest)
+	}
+}
+
+func TestBenchmarkSpans(t *testing.T) {
+	bench := hrtime.NewBenchmark(8)
+	for bench.Next() {
+		time.Sleep(1000 * time.Nanosecond)
+	}
+
+	start, stop := bench.Timeline()
+	spans := bench.Spans()
+	if spans[0].Start != start || spans[len(spans)-1].Finish != stop {
+		t.Errorf("spans %v do not match timeline %v-%v", spans, start, stop)
+	}
+}
@@ -0,0 +1,109 @@
+package hrtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CPUFrequency is the frequency scaling state of a single CPU.
+type CPUFrequency struct {
+	CPU int
+	// CurrentKHz, MinKHz and MaxKHz are the current frequency and the
+	// limits of frequency scaling in kHz.
+	CurrentKHz int64
+	MinKHz     int64
+	MaxKHz     int64
+	// Governor is the frequency scaling governor, e.g. "performance" or "powersave".
+	Governor string
+}
+
+// FrequencyState describes CPU frequency scaling of the machine.
+type FrequencyState struct {
+	CPUs []CPUFrequency
+	// Turbo reports whether turbo boost is enabled, when TurboKnown.
+	Turbo      bool
+	TurboKnown bool
+}
+
+// sysfsCPU is the sysfs directory with CPU information.
+const sysfsCPU = "/sys/devices/system/cpu"
+
+// ReadFrequencyState reads CPU frequency scaling state from cpufreq in sysfs.
+//
+// It returns false when the state is not available, e.g. on non-Linux
+// platforms or in virtual machines without cpufreq.
+func ReadFrequencyState() (*FrequencyState, bool) { return readFrequencyState(sysfsCPU) }
+
+// readFrequencyState reads frequency state from sysfs CPU directory root.
+func readFrequencyState(root string) (*FrequencyState, bool) {
+	state := &FrequencyState{}
+	dirs, _ := filepath.Glob(filepath.Join(root, "cpu[0-9]*", "cpufreq"))
+	for _, dir := range dirs {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(dir)), "cpu"))
+		if err != nil {
+			continue
+		}
+		freq := CPUFrequency{
+			CPU:        cpu,
+			CurrentKHz: readSysfsInt(filepath.Join(dir, "scaling_cur_freq")),
+			MinKHz:     readSysfsInt(filepath.Join(dir, "scaling_min_freq")),
+			MaxKHz:     readSysfsInt(filepath.Join(dir, "scaling_max_freq")),
+			Governor:   readSysfsString(filepath.Join(dir, "scaling_governor")),
+		}
+		state.CPUs = append(state.CPUs, freq)
+	}
+	slices.SortFunc(state.CPUs, func(a, b CPUFrequency) int { return a.CPU - b.CPU })
+
+	// intel_pstate reports disabled turbo, acpi-cpufreq reports enabled boost
+	if value := readSysfsString(filepath.Join(root, "intel_pstate", "no_turbo")); value != "" {
+		state.Turbo, state.TurboKnown = value == "0", true
+	} else if value := readSysfsString(filepath.Join(root, "cpufreq", "boost")); value != "" {
+		state.Turbo, state.TurboKnown = value == "1", true
+	}
+
+	return state, len(state.CPUs) > 0 || state.TurboKnown
+}
+
+// Governors returns distinct governors in the order of CPUs.
+func (state *FrequencyState) Governors() []string {
+	var governors []string
+	for _, cpu := range state.CPUs {
+		if cpu.Governor != "" && !slices.Contains(governors, cpu.Governor) {
+			governors = append(governors, cpu.Governor)
+		}
+	}
+	return governors
+}
+
+// Warnings returns frequency scaling conditions that are likely to distort measurements.
+func (state *FrequencyState) Warnings() []string {
+	var warnings []string
+	for _, governor := range state.Governors() {
+		if governor != "performance" {
+			warnings = append(warnings, fmt.Sprintf("CPU frequency governor %s scales frequency with load, use the performance governor", governor))
+		}
+	}
+	if state.TurboKnown && state.Turbo {
+		warnings = append(warnings, "turbo boost is enabled, CPU frequency depends on temperature and the number of active cores")
+	}
+	return warnings
+}
+
+// readSysfsString reads a trimmed sysfs value, "" when it can't be read.
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysfsInt reads an integer sysfs value, 0 when it can't be read.
+func readSysfsInt(path string) int64 {
+	value, _ := strconv.ParseInt(readSysfsString(path), 10, 64)
+	return value
+}
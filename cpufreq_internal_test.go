@@ -0,0 +1,43 @@
+package hrtime
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadFrequencyState(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, value string) {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, cpu := range []string{"cpu0", "cpu1", "cpu10"} {
+		write(cpu+"/cpufreq/scaling_cur_freq", "2400000")
+		write(cpu+"/cpufreq/scaling_max_freq", "3600000")
+		write(cpu+"/cpufreq/scaling_governor", "performance")
+	}
+	write("cpu1/cpufreq/scaling_governor", "powersave")
+	write("intel_pstate/no_turbo", "0")
+
+	state, ok := readFrequencyState(root)
+	if !ok || len(state.CPUs) != 3 || state.CPUs[2].CPU != 10 || state.CPUs[0].MaxKHz != 3600000 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if governors := state.Governors(); !slices.Equal(governors, []string{"performance", "powersave"}) {
+		t.Errorf("unexpected governors %v", governors)
+	}
+	if warnings := state.Warnings(); len(warnings) != 2 || !state.Turbo {
+		t.Errorf("expected powersave and turbo warnings, got %v", warnings)
+	}
+
+	if _, ok := readFrequencyState(t.TempDir()); ok {
+		t.Error("expected missing cpufreq")
+	}
+}
@@ -0,0 +1,106 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Deadlines summarizes durations of a soft real-time loop against a deadline.
+type Deadlines struct {
+	Deadline time.Duration
+	// Count is the number of durations.
+	Count int
+	// Misses is the number of durations exceeding the deadline.
+	Misses int
+	// LongestMissRun is the largest number of consecutive misses.
+	LongestMissRun int
+	// WCET is the worst-case observed duration.
+	WCET time.Duration
+	// P9999 is the 99.99th percentile of durations.
+	P9999 time.Duration
+}
+
+// NewDeadlines counts durations, e.g. execution times of each cycle,
+// exceeding deadline.
+func NewDeadlines(durations []time.Duration, deadline time.Duration) *Deadlines {
+	if deadline <= 0 {
+		panic("deadline must be positive")
+	}
+
+	deadlines := &Deadlines{Deadline: deadline, Count: len(durations)}
+	run := 0
+	for _, d := range durations {
+		if d > deadline {
+			deadlines.Misses++
+			run++
+			if run > deadlines.LongestMissRun {
+				deadlines.LongestMissRun = run
+			}
+		} else {
+			run = 0
+		}
+		if d > deadlines.WCET {
+			deadlines.WCET = d
+		}
+	}
+	deadlines.P9999 = quantile(sortedDurations(durations), 0.9999)
+	return deadlines
+}
+
+// MissRatio returns the fraction of durations exceeding the deadline.
+func (deadlines *Deadlines) MissRatio() float64 {
+	if deadlines.Count == 0 {
+		return 0
+	}
+	return float64(deadlines.Misses) / float64(deadlines.Count)
+}
+
+// Deadlines counts ticks that happened later than deadline after
+// their ideal time in the schedule.
+//
+// The first tick defines the schedule and is not counted.
+func (jitter *Jitter) Deadlines(deadline time.Duration) *Deadlines {
+	lateness := make([]time.Duration, len(jitter.Deviations))
+	var offset time.Duration
+	for i, deviation := range jitter.Deviations {
+		offset += deviation
+		lateness[i] = max(offset, 0)
+	}
+	return NewDeadlines(lateness, deadline)
+}
+
+// Deadlines counts laps exceeding deadline and reports worst-case execution time.
+func (result *Result) Deadlines(deadline time.Duration) *Deadlines {
+	return NewDeadlines(result.laps, deadline)
+}
+
+// Deadlines counts laps exceeding deadline and reports worst-case execution time.
+func (bench *Benchmark) Deadlines(deadline time.Duration) *Deadlines {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Deadlines(deadline)
+}
+
+// Deadlines counts laps exceeding deadline and reports worst-case execution time.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) Deadlines(deadline time.Duration) *Deadlines {
+	bench = bench.mustBeCompleted()
+	return bench.Result().Deadlines(deadline)
+}
+
+// WriteTo writes deadline statistics to w.
+func (deadlines *Deadlines) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "  deadline %v;  misses %d/%d (%.3f%%);  longest run %d;\n  wcet %v;  p9999 %v;\n",
+		deadlines.Deadline, deadlines.Misses, deadlines.Count, deadlines.MissRatio()*100, deadlines.LongestMissRun,
+		formatStat(float64(deadlines.WCET)), formatStat(float64(deadlines.P9999)))
+	return int64(n), err
+}
+
+// String returns deadline statistics as a string.
+func (deadlines *Deadlines) String() string {
+	var buffer strings.Builder
+	_, _ = deadlines.WriteTo(&buffer)
+	return buffer.String()
+}
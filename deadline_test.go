@@ -0,0 +1,29 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestDeadlines(t *testing.T) {
+	ms := time.Millisecond
+	cycles := []time.Duration{ms, 3 * ms, 4 * ms, ms, 5 * ms, ms}
+
+	deadlines := hrtime.NewDeadlines(cycles, 2*ms)
+	if deadlines.Misses != 3 || deadlines.LongestMissRun != 2 || deadlines.WCET != 5*ms {
+		t.Errorf("unexpected deadlines %+v", deadlines)
+	}
+	if ratio := deadlines.MissRatio(); ratio != 0.5 {
+		t.Errorf("expected miss ratio 0.5, got %v", ratio)
+	}
+
+	// ticks are scheduled every 10ms, the third one is 3ms late
+	jitter := hrtime.NewJitter([]time.Duration{0, 10 * ms, 23 * ms, 30 * ms}, 10*ms)
+	late := jitter.Deadlines(2 * ms)
+	if late.Misses != 1 || late.WCET != 3*ms {
+		t.Errorf("unexpected tick deadlines %+v", late)
+	}
+	t.Log("\n" + late.String())
+}
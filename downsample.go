@@ -0,0 +1,59 @@
+package hrtime
+
+import "time"
+
+// LapPoint is a single lap in a downsampled series.
+type LapPoint struct {
+	// Index is the index of the lap in the original series.
+	Index    int
+	Duration time.Duration
+}
+
+// DownsampleLaps reduces laps to at most targetPoints points for plotting.
+//
+// It uses min/max bucketing: laps are split into targetPoints/2 buckets and
+// the minimum and maximum of each bucket are kept in their original order.
+// Unlike averaging, this preserves spikes, which are usually the
+// interesting part of latency series.
+func DownsampleLaps(laps []time.Duration, targetPoints int) []LapPoint {
+	if targetPoints <= 0 {
+		panic("targetPoints must be positive")
+	}
+	if len(laps) <= targetPoints || targetPoints < 2 {
+		if targetPoints < 2 && len(laps) > targetPoints {
+			laps = laps[:targetPoints]
+		}
+		points := make([]LapPoint, len(laps))
+		for i, lap := range laps {
+			points[i] = LapPoint{Index: i, Duration: lap}
+		}
+		return points
+	}
+
+	buckets := targetPoints / 2
+	points := make([]LapPoint, 0, buckets*2)
+	for bucket := 0; bucket < buckets; bucket++ {
+		low := bucket * len(laps) / buckets
+		high := (bucket + 1) * len(laps) / buckets
+
+		min, max := low, low
+		for i := low + 1; i < high; i++ {
+			if laps[i] < laps[min] {
+				min = i
+			}
+			if laps[i] > laps[max] {
+				max = i
+			}
+		}
+
+		first, second := min, max
+		if first > second {
+			first, second = second, first
+		}
+		points = append(points, LapPoint{Index: first, Duration: laps[first]})
+		if second != first {
+			points = append(points, LapPoint{Index: second, Duration: laps[second]})
+		}
+	}
+	return points
+}
@@ -0,0 +1,38 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestDownsampleLaps(t *testing.T) {
+	laps := make([]time.Duration, 1000)
+	for i := range laps {
+		laps[i] = 100
+	}
+	laps[567] = 10000
+
+	points := hrtime.DownsampleLaps(laps, 20)
+	if len(points) > 20 {
+		t.Fatalf("too many points %d", len(points))
+	}
+
+	found := false
+	for i, point := range points {
+		if i > 0 && point.Index <= points[i-1].Index {
+			t.Errorf("points not ordered at %d", i)
+		}
+		if point.Index == 567 && point.Duration == 10000 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("spike not preserved")
+	}
+
+	if short := hrtime.DownsampleLaps(laps[:5], 20); len(short) != 5 {
+		t.Errorf("expected all laps, got %d", len(short))
+	}
+}
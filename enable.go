@@ -0,0 +1,16 @@
+package hrtime
+
+import "sync/atomic"
+
+// disabled globally disables recording, when set.
+var disabled atomic.Bool
+
+// SetEnabled enables or disables recording globally.
+//
+// When disabled, Recorder.Record and Profiler traces return immediately,
+// which allows toggling instrumentation, e.g. from an admin endpoint.
+// Recording is enabled by default.
+func SetEnabled(enabled bool) { disabled.Store(!enabled) }
+
+// Enabled returns whether recording is enabled globally.
+func Enabled() bool { return !disabled.Load() }
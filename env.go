@@ -0,0 +1,170 @@
+package hrtime
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment describes the machine and clock used for measurements.
+type Environment struct {
+	Hostname   string `json:"hostname"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	GoVersion  string `json:"goVersion"`
+	NumCPU     int    `json:"numCPU"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	// CPUModel is the processor model name, when it can be determined.
+	CPUModel string `json:"cpuModel,omitempty"`
+
+	// Overhead is the approximate cost of a single Now call.
+	Overhead time.Duration `json:"overhead"`
+	// Precision is the maximum precision of Now in nanoseconds.
+	Precision float64 `json:"precision"`
+	// TSCSupported reports whether invariant TSC is available.
+	TSCSupported bool `json:"tscSupported"`
+
+	// ClockSource is the kernel clock source, when it can be determined.
+	ClockSource string `json:"clockSource,omitempty"`
+	// ClockSyscall reports whether reading the clock goes through a syscall
+	// instead of a fast user-space path, such as the Linux vDSO.
+	ClockSyscall bool `json:"clockSyscall"`
+	// SyscallOverhead is the approximate cost of reading the clock via a syscall.
+	// It is zero when it was not measured.
+	SyscallOverhead time.Duration `json:"syscallOverhead,omitempty"`
+
+	// Hypervisor is the hypervisor vendor, when running in a virtual machine.
+	Hypervisor string `json:"hypervisor,omitempty"`
+	// Container is the container runtime, when running in a container.
+	Container string `json:"container,omitempty"`
+	// CPUQuota is the CPU limit in number of CPUs, zero when unlimited.
+	CPUQuota float64 `json:"cpuQuota,omitempty"`
+	// Governors contains CPU frequency scaling governors, when they can be determined.
+	Governors []string `json:"governors,omitempty"`
+
+	// Clocks describes the clock backends in use.
+	Clocks Clocks `json:"clocks"`
+
+	// Warnings contains conditions that are likely to distort measurements.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// clockPathOnce runs checkClockPath on first use instead of in init,
+// since it reads sysfs and makes many clock syscalls.
+var clockPathOnce sync.Once
+
+// checkClockPathOnce detects the clock path, when not done already.
+func checkClockPathOnce() { clockPathOnce.Do(checkClockPath) }
+
+// CaptureEnv captures the current measurement environment.
+func CaptureEnv() *Environment {
+	checkClockPathOnce()
+	env := &Environment{
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GoVersion:  runtime.Version(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+
+		Overhead:     Overhead(),
+		Precision:    NowPrecision(),
+		TSCSupported: TSCSupported(),
+
+		ClockSource:     clockSource,
+		ClockSyscall:    clockSyscall,
+		SyscallOverhead: clockSyscallOverhead,
+
+		Clocks: ClockInfo(),
+	}
+	env.Hostname, _ = os.Hostname()
+
+	env.CPUModel = cpuModel()
+	env.Hypervisor = hypervisorVendor()
+	env.Container = containerRuntime()
+	env.CPUQuota = cpuQuota()
+
+	if env.ClockSyscall {
+		env.Warnings = append(env.Warnings, fmt.Sprintf("clock reads fall back to a syscall (clock source %s), Now overhead is %v", quoteOrUnknown(env.ClockSource), env.Overhead))
+	}
+
+	if env.Hypervisor != "" {
+		env.Warnings = append(env.Warnings, fmt.Sprintf("running in a virtual machine (%s), clocks and TSC may be unreliable", env.Hypervisor))
+	}
+	if env.Container != "" {
+		env.Warnings = append(env.Warnings, fmt.Sprintf("running in a container (%s)", env.Container))
+	}
+	if env.CPUQuota > 0 {
+		env.Warnings = append(env.Warnings, fmt.Sprintf("CPU quota of %.2f CPUs, throttling may cause multi-millisecond outliers", env.CPUQuota))
+	}
+	if frequency, ok := ReadFrequencyState(); ok {
+		env.Governors = frequency.Governors()
+		env.Warnings = append(env.Warnings, frequency.Warnings()...)
+	}
+
+	return env
+}
+
+// WriteTo writes a short description of the environment and its warnings to w.
+func (env *Environment) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "%s %s/%s, %d CPUs (GOMAXPROCS %d), Now: %s (overhead %v)\n",
+		env.GoVersion, env.GOOS, env.GOARCH, env.NumCPU, env.GOMAXPROCS,
+		env.Clocks.Now.Name, env.Overhead)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for _, warning := range env.Warnings {
+		n, err = fmt.Fprintf(w, "  warning: %s\n", warning)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns a short description of the environment.
+func (env *Environment) String() string {
+	var buffer strings.Builder
+	_, _ = env.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// hypervisorVendor returns the hypervisor vendor using CPUID.
+//
+// It returns "" when not running under a hypervisor or when
+// CPUID is not supported.
+func hypervisorVendor() string {
+	_, _, ecx, _ := cpuid(0x1, 0x0)
+	if ecx&(1<<31) == 0 {
+		return ""
+	}
+
+	_, ebx, ecx, edx := cpuid(0x40000000, 0x0)
+	var vendor [12]byte
+	binary.LittleEndian.PutUint32(vendor[0:], ebx)
+	binary.LittleEndian.PutUint32(vendor[4:], ecx)
+	binary.LittleEndian.PutUint32(vendor[8:], edx)
+
+	name := strings.TrimRight(string(vendor[:]), "\x00")
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
+
+// quoteOrUnknown returns quoted s or "unknown" when s is empty.
+func quoteOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strconv.Quote(s)
+}
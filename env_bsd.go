@@ -0,0 +1,42 @@
+//go:build freebsd || netbsd || openbsd
+// +build freebsd netbsd openbsd
+
+package hrtime
+
+import (
+	"syscall"
+	"time"
+)
+
+var (
+	clockSource          string
+	clockSyscall         bool
+	clockSyscallOverhead time.Duration
+)
+
+// nowBackendInfo returns the clock used by Now.
+func nowBackendInfo() (name, fallback string) { return "clock_gettime", "" }
+
+// containerRuntime returns "jail" when running inside a FreeBSD jail.
+func containerRuntime() string {
+	if jailed, ok := sysctlUint64("security.jail.jailed"); ok && jailed != 0 {
+		return "jail"
+	}
+	return ""
+}
+
+// cpuModel returns the processor model from hw.model sysctl.
+func cpuModel() string {
+	model, _ := syscall.Sysctl("hw.model")
+	return model
+}
+
+// cpuQuota returns 0, since CPU quotas are not detected.
+func cpuQuota() float64 { return 0 }
+
+// checkClockPath reads the kernel timecounter used for clock reads.
+func checkClockPath() {
+	if source, err := syscall.Sysctl("kern.timecounter.hardware"); err == nil {
+		clockSource = source
+	}
+}
@@ -0,0 +1,82 @@
+package hrtime
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	clockSource          string
+	clockSyscall         bool
+	clockSyscallOverhead time.Duration
+)
+
+// vdsoClockSources lists kernel clock sources that support vDSO reads.
+var vdsoClockSources = map[string]bool{
+	"tsc":                         true,
+	"kvm-clock":                   true,
+	"arch_sys_counter":            true,
+	"hyperv_clocksource_tsc_page": true,
+	"riscv_clocksource":           true,
+	"timebase":                    true,
+}
+
+// nowBackendInfo returns the clock used by Now and why a fallback occurred.
+func nowBackendInfo() (name, fallback string) {
+	if clockSyscall {
+		return "clock_gettime (syscall)", fmt.Sprintf("clock source %s does not support vDSO", quoteOrUnknown(clockSource))
+	}
+	return "clock_gettime (vDSO)", ""
+}
+
+// checkClockPath detects whether clock_gettime goes through the vDSO.
+//
+// Under some hypervisors or clock sources (e.g. hpet, acpi_pm, xen)
+// the kernel falls back to a real syscall for every clock read.
+func checkClockPath() {
+	if data, err := os.ReadFile("/sys/devices/system/clocksource/clocksource0/current_clocksource"); err == nil {
+		clockSource = strings.TrimSpace(string(data))
+	}
+
+	const calls = calibrationCalls / 16
+
+	var ts syscall.Timespec
+	start := Now()
+	for i := 0; i < calls; i++ {
+		_, _, _ = syscall.RawSyscall(syscall.SYS_CLOCK_GETTIME, 1 /* CLOCK_MONOTONIC */, uintptr(unsafe.Pointer(&ts)), 0)
+	}
+	stop := Now()
+	clockSyscallOverhead = (stop - start - Overhead()) / calls
+
+	if clockSource != "" {
+		clockSyscall = !vdsoClockSources[clockSource]
+	} else {
+		// without knowing the clock source, a fast path should be clearly cheaper than a syscall
+		clockSyscall = Overhead()*2 > clockSyscallOverhead
+	}
+}
+
+// cpuModel returns the processor model from /proc/cpuinfo.
+func cpuModel() string {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "model name", "Model", "cpu", "uarch":
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
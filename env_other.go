@@ -0,0 +1,27 @@
+//go:build !linux && !freebsd && !netbsd && !openbsd
+// +build !linux,!freebsd,!netbsd,!openbsd
+
+package hrtime
+
+import "time"
+
+var (
+	clockSource          string
+	clockSyscall         bool
+	clockSyscallOverhead time.Duration
+)
+
+// nowBackendInfo returns the clock used by Now.
+func nowBackendInfo() (name, fallback string) { return nowBackend, "" }
+
+// containerRuntime returns "", since containers are not detected.
+func containerRuntime() string { return "" }
+
+// cpuModel returns "", since the processor model is not detected.
+func cpuModel() string { return "" }
+
+// cpuQuota returns 0, since CPU quotas are not detected.
+func cpuQuota() float64 { return 0 }
+
+// checkClockPath is a no-op on platforms without vDSO.
+func checkClockPath() {}
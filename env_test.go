@@ -0,0 +1,16 @@
+package hrtime_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestCaptureEnv(t *testing.T) {
+	env := hrtime.CaptureEnv()
+	if env.GOOS != runtime.GOOS || env.GOARCH != runtime.GOARCH {
+		t.Errorf("unexpected platform %v/%v", env.GOOS, env.GOARCH)
+	}
+	t.Log(env)
+}
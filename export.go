@@ -0,0 +1,140 @@
+package hrtime
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writeLapsCSV writes laps to w with a header row.
+func writeLapsCSV(w io.Writer, laps []time.Duration) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"lap", "ns"}); err != nil {
+		return err
+	}
+	row := make([]string, 2)
+	for i, lap := range laps {
+		row[0] = strconv.Itoa(i)
+		row[1] = strconv.FormatInt(lap.Nanoseconds(), 10)
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeGoBench writes a go test -bench style result line to w.
+func writeGoBench(w io.Writer, name string, laps []time.Duration, throughput Throughput, mem *MemStats) error {
+	if !strings.HasPrefix(name, "Benchmark") {
+		name = "Benchmark" + name
+	}
+	name = strings.Join(strings.Fields(name), "_")
+
+	var total float64
+	for _, lap := range laps {
+		total += float64(lap)
+	}
+	mean := 0.0
+	if len(laps) > 0 {
+		mean = total / float64(len(laps))
+	}
+
+	line := fmt.Sprintf("%s\t%d\t%s ns/op", name, len(laps), strconv.FormatFloat(mean, 'f', 2, 64))
+	if throughput.Bytes > 0 {
+		line += fmt.Sprintf("\t%.2f MB/s", throughput.MBPerSecond())
+	}
+	if mem != nil {
+		line += "\t" + mem.String()
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
+
+// JSONResult returns named laps and summary statistics,
+// including sources of merged benchmarks, failed laps, sampling, metrics
+// of collectors and clock incidents as metadata, when measuring
+// WithClockMitigation.
+func (bench *Benchmark) JSONResult(name string) *JSONResult {
+	bench = bench.mustBeCompleted()
+	result := NewJSONResult(name, bench.laps)
+	result.Sources = jsonSources(bench.laps, bench.sources)
+	result.Metadata = clockIncidentsMetadata(bench.ClockIncidents())
+	for _, metadata := range []map[string]string{bench.failuresMetadata(bench.laps), bench.samplingMetadata(), bench.metricsMetadata()} {
+		for key, value := range metadata {
+			if result.Metadata == nil {
+				result.Metadata = map[string]string{}
+			}
+			result.Metadata[key] = value
+		}
+	}
+	return result
+}
+
+// WriteJSON writes laps and summary statistics to w as JSONResult.
+func (bench *Benchmark) WriteJSON(w io.Writer) error {
+	return bench.JSONResult("").Encode(w)
+}
+
+// WriteCSV writes laps in nanoseconds to w with a header row.
+func (bench *Benchmark) WriteCSV(w io.Writer) error {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().WriteCSV(w)
+}
+
+// WriteGoBench writes the mean lap in go test -bench format to w,
+// e.g. "BenchmarkName	1000	12.34 ns/op", which can be read by benchstat.
+//
+// name is prefixed with "Benchmark" when necessary and spaces are replaced with underscores.
+func (bench *Benchmark) WriteGoBench(w io.Writer, name string) error {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().WriteGoBench(w, name)
+}
+
+// JSONResult returns named laps and summary statistics,
+// including sources of merged benchmarks, failed laps, sampling and
+// metrics of collectors as metadata.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) JSONResult(name string) *JSONResult {
+	bench = bench.mustBeCompleted()
+	laps := bench.Result().laps
+	result := NewJSONResult(name, laps)
+	result.Sources = jsonSources(laps, bench.sources)
+	for _, metadata := range []map[string]string{bench.failuresMetadata(laps), bench.samplingMetadata(), bench.metricsMetadata()} {
+		for key, value := range metadata {
+			if result.Metadata == nil {
+				result.Metadata = map[string]string{}
+			}
+			result.Metadata[key] = value
+		}
+	}
+	return result
+}
+
+// WriteJSON writes laps and summary statistics to w as JSONResult.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) WriteJSON(w io.Writer) error {
+	return bench.JSONResult("").Encode(w)
+}
+
+// WriteCSV writes laps in nanoseconds to w with a header row.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) WriteCSV(w io.Writer) error {
+	bench = bench.mustBeCompleted()
+	return bench.Result().WriteCSV(w)
+}
+
+// WriteGoBench writes the mean lap in go test -bench format to w,
+// e.g. "BenchmarkName	1000	12.34 ns/op", which can be read by benchstat.
+//
+// name is prefixed with "Benchmark" when necessary and spaces are replaced with underscores.
+func (bench *BenchmarkTSC) WriteGoBench(w io.Writer, name string) error {
+	bench = bench.mustBeCompleted()
+	return bench.Result().WriteGoBench(w, name)
+}
@@ -0,0 +1,48 @@
+package hrtime_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkExport(t *testing.T) {
+	bench := hrtime.NewBenchmark(10)
+	for bench.Next() {
+	}
+
+	var js strings.Builder
+	if err := bench.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	result, err := hrtime.DecodeJSONResult(strings.NewReader(js.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Count != 10 || len(result.Laps) != 10 {
+		t.Errorf("invalid json result: %s", js.String())
+	}
+
+	var c strings.Builder
+	if err := bench.WriteCSV(&c); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(c.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 11 || records[0][1] != "ns" {
+		t.Errorf("invalid csv:\n%s", c.String())
+	}
+
+	var gobench strings.Builder
+	if err := bench.WriteGoBench(&gobench, "Empty loop"); err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(gobench.String())
+	if len(fields) != 4 || fields[0] != "BenchmarkEmpty_loop" || fields[1] != "10" || fields[3] != "ns/op" {
+		t.Errorf("invalid go bench line: %q", gobench.String())
+	}
+}
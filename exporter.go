@@ -0,0 +1,159 @@
+package hrtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownExporter is returned by NewExporter for names that were not registered.
+var ErrUnknownExporter = errors.New("unknown exporter")
+
+// Exporter sends results to an external destination, e.g. a database,
+// an object store or an HTTP API.
+//
+// Exporters returned by NewExporter, which implement io.Closer,
+// must be closed after the last result.
+type Exporter interface {
+	Export(result *JSONResult) error
+}
+
+// ExporterFunc adapts a function to Exporter.
+type ExporterFunc func(result *JSONResult) error
+
+// Export implements Exporter.
+func (fn ExporterFunc) Export(result *JSONResult) error { return fn(result) }
+
+// ExporterFactory creates an exporter from a configuration string,
+// whose meaning is specific to the exporter, e.g. a path or an URL.
+type ExporterFactory func(config string) (Exporter, error)
+
+var (
+	exportersMu sync.Mutex
+	exporters   = map[string]ExporterFactory{}
+)
+
+func init() {
+	RegisterExporter("json", openJSONExporter)
+}
+
+// RegisterExporter makes an exporter available by name to NewExporter,
+// and hence to the hrtime command.
+//
+// It's intended to be called from init of the package implementing the exporter.
+// RegisterExporter panics when the name is registered twice or factory is nil.
+func RegisterExporter(name string, factory ExporterFactory) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	if factory == nil {
+		panic("exporter factory is nil")
+	}
+	if _, exists := exporters[name]; exists {
+		panic("exporter " + name + " registered twice")
+	}
+	exporters[name] = factory
+}
+
+// NewExporter creates a registered exporter with config.
+func NewExporter(name, config string) (Exporter, error) {
+	exportersMu.Lock()
+	factory, ok := exporters[name]
+	exportersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownExporter, name)
+	}
+	return factory(config)
+}
+
+// Exporters returns sorted names of registered exporters.
+func Exporters() []string {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	return slices.Sorted(maps.Keys(exporters))
+}
+
+// WithExporter exports the result of every benchmark as soon as it completes.
+//
+// Export failures don't stop the suite, they are returned by Run together
+// with all results.
+func WithExporter(exporters ...Exporter) SuiteOption {
+	return func(config *suiteConfig) { config.exporters = append(config.exporters, exporters...) }
+}
+
+// export sends result to all exporters.
+func (config *suiteConfig) export(result *SuiteResult) error {
+	if len(config.exporters) == 0 {
+		return nil
+	}
+	exported := result.JSONResult()
+	var errs []error
+	for _, exporter := range config.exporters {
+		if err := exporter.Export(exported); err != nil {
+			errs = append(errs, fmt.Errorf("export %s: %w", result.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// JSONResult returns the named result of the benchmark, including metadata
+// of how the suite was run.
+func (result *SuiteResult) JSONResult() *JSONResult {
+	exported := result.Benchmark.JSONResult(result.Name)
+	for key, value := range result.Metadata {
+		if exported.Metadata == nil {
+			exported.Metadata = map[string]string{}
+		}
+		exported.Metadata[key] = value
+	}
+	return exported
+}
+
+// jsonExporter writes results as a stream of JSON values.
+type jsonExporter struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewJSONExporter creates an exporter, which writes every result as
+// a JSON value on a single line to w. The output can be read by
+// "hrtime store save" and other hrtime commands.
+//
+// It's safe for concurrent use.
+func NewJSONExporter(w io.Writer) Exporter {
+	return &jsonExporter{enc: json.NewEncoder(w)}
+}
+
+// openJSONExporter implements the "json" exporter, which writes to
+// the file at path config or to stdout when config is "" or "-".
+func openJSONExporter(config string) (Exporter, error) {
+	if config == "" || config == "-" {
+		return NewJSONExporter(os.Stdout), nil
+	}
+	file, err := os.Create(config)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonExporter{enc: json.NewEncoder(file), closer: file}, nil
+}
+
+// Export implements Exporter.
+func (exporter *jsonExporter) Export(result *JSONResult) error {
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	result.SchemaVersion = SchemaVersion
+	return exporter.enc.Encode(result)
+}
+
+// Close closes the file opened by the "json" exporter.
+func (exporter *jsonExporter) Close() error {
+	if exporter.closer == nil {
+		return nil
+	}
+	return exporter.closer.Close()
+}
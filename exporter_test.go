@@ -0,0 +1,97 @@
+package hrtime_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSuiteExporter(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("a", 4, func() {})
+	suite.Add("b", 4, func() {})
+
+	var exported []string
+	errFull := errors.New("disk full")
+	collect := hrtime.ExporterFunc(func(result *hrtime.JSONResult) error {
+		exported = append(exported, result.Name)
+		if result.Name == "a" {
+			return errFull
+		}
+		return nil
+	})
+	var stream bytes.Buffer
+
+	results, err := suite.Run(context.Background(),
+		hrtime.WithExporter(collect, hrtime.NewJSONExporter(&stream)),
+		hrtime.WithGCDisabled(false))
+	if !errors.Is(err, errFull) || !strings.Contains(err.Error(), "export a") {
+		t.Errorf("expected export failure, got %v", err)
+	}
+	if len(results) != 2 || !slices.Equal(exported, []string{"a", "b"}) {
+		t.Fatalf("export failure stopped the suite: %v %v", results, exported)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stream.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per result:\n%s", stream.String())
+	}
+	result, err := hrtime.DecodeJSONResult(strings.NewReader(lines[1]))
+	if err != nil || result.Name != "b" || len(result.Laps) != 4 || result.Metadata["gc"] != "off" {
+		t.Errorf("unexpected exported result %+v %v", result, err)
+	}
+}
+
+// registerAttempts makes exporter names unique when tests run repeatedly.
+var registerAttempts atomic.Int32
+
+func TestRegisterExporter(t *testing.T) {
+	// exporters cannot be unregistered
+	name := fmt.Sprintf("test-register-%d", registerAttempts.Add(1))
+
+	var got string
+	hrtime.RegisterExporter(name, func(config string) (hrtime.Exporter, error) {
+		return hrtime.ExporterFunc(func(result *hrtime.JSONResult) error {
+			got = config + ":" + result.Name
+			return nil
+		}), nil
+	})
+	if names := hrtime.Exporters(); !slices.Contains(names, name) || !slices.Contains(names, "json") {
+		t.Errorf("unexpected exporters %v", names)
+	}
+
+	exporter, err := hrtime.NewExporter(name, "cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(hrtime.NewJSONResult("x", nil)); err != nil || got != "cfg:x" {
+		t.Errorf("unexpected export %q %v", got, err)
+	}
+	if _, err := hrtime.NewExporter("missing", ""); !errors.Is(err, hrtime.ErrUnknownExporter) {
+		t.Errorf("expected unknown exporter, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	file, err := hrtime.NewExporter("json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Export(hrtime.NewJSONResult("y", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if closer, ok := file.(interface{ Close() error }); !ok || closer.Close() != nil {
+		t.Errorf("expected closable json exporter")
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"name":"y"`) {
+		t.Errorf("unexpected file %q %v", data, err)
+	}
+}
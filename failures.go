@@ -0,0 +1,253 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lapFailures marks failed laps, it's shared by Benchmark and BenchmarkTSC.
+type lapFailures struct {
+	// failed marks failed laps, when using Fail or NextErr.
+	failed []bool
+}
+
+// Fail marks the lap in progress as failed.
+//
+// Failed laps, e.g. calls that returned an error, usually take a different
+// code path than successful ones. Use Succeeded and Failed to analyze them
+// separately. Laps, Stats and other results still include all laps.
+func (bench *Benchmark) Fail() {
+	if bench.done.Load() {
+		bench.misuse("benchmark already completed")
+		return
+	}
+	if bench.step == 0 {
+		bench.misuse("fail called before Next")
+		return
+	}
+	if bench.unsupportedBySampling("failures") {
+		return
+	}
+	bench.markFailed(bench.step, len(bench.laps))
+}
+
+// Fail marks the lap in progress as failed, see Benchmark.Fail.
+func (bench *BenchmarkTSC) Fail() {
+	if bench.done.Load() {
+		bench.misuse("benchmark already completed")
+		return
+	}
+	if bench.step == 0 {
+		bench.misuse("fail called before Next")
+		return
+	}
+	if bench.unsupportedBySampling("failures") {
+		return
+	}
+	bench.markFailed(bench.step, len(bench.laps))
+}
+
+// markFailed marks the lap started at step-1 as failed, when there are count laps.
+func (failures *lapFailures) markFailed(step, count int) {
+	if failures.failed == nil {
+		failures.failed = make([]bool, count)
+	}
+	for len(failures.failed) < step {
+		failures.failed = append(failures.failed, false)
+	}
+	failures.failed[step-1] = true
+}
+
+// NextErr marks the lap in progress as failed when err is not nil,
+// and starts measuring the next lap similarly to Next.
+//
+// It allows measuring calls returning errors:
+//
+//	var err error
+//	for bench.NextErr(err) {
+//		err = call()
+//	}
+func (bench *Benchmark) NextErr(err error) bool {
+	// marked before reading time to keep it out of the lap
+	if err != nil && bench.step > 0 && !bench.done.Load() {
+		bench.Fail()
+	}
+	return bench.Next()
+}
+
+// NextErr marks the lap in progress as failed when err is not nil,
+// and starts measuring the next lap, see Benchmark.NextErr.
+func (bench *BenchmarkTSC) NextErr(err error) bool {
+	if err != nil && bench.step > 0 && !bench.done.Load() {
+		bench.Fail()
+	}
+	return bench.Next()
+}
+
+// alignFailed ensures there's exactly one failure flag for each of count laps.
+func (failures *lapFailures) alignFailed(count int) {
+	if len(failures.failed) > count {
+		failures.failed = failures.failed[:count]
+	}
+	for len(failures.failed) < count {
+		failures.failed = append(failures.failed, false)
+	}
+}
+
+// failures returns the number of failed laps.
+func (failures *lapFailures) failures() int {
+	count := 0
+	for _, failed := range failures.failed {
+		if failed {
+			count++
+		}
+	}
+	return count
+}
+
+// lapsWithOutcome returns laps whose failure flag is failed.
+func (failures *lapFailures) lapsWithOutcome(laps []time.Duration, failed bool) []time.Duration {
+	var matching []time.Duration
+	for i, lap := range laps {
+		if (i < len(failures.failed) && failures.failed[i]) == failed {
+			matching = append(matching, lap)
+		}
+	}
+	return matching
+}
+
+// failuresMetadata describes failed laps for JSONResult metadata.
+func (failures *lapFailures) failuresMetadata(laps []time.Duration) map[string]string {
+	count := failures.failures()
+	if count == 0 {
+		return nil
+	}
+	failed := &Result{laps: failures.lapsWithOutcome(laps, true)}
+	return map[string]string{
+		"failed":    strconv.Itoa(count),
+		"failedP50": RoundDuration(failed.Stats().P50).String(),
+	}
+}
+
+// LapFailed returns whether each lap failed.
+//
+// It returns nil, unless some lap was marked with Fail or NextErr.
+func (bench *Benchmark) LapFailed() []bool {
+	bench = bench.mustBeCompleted()
+	if bench.failed == nil {
+		return nil
+	}
+	return append(bench.failed[:0:0], bench.failed...)
+}
+
+// LapFailed returns whether each lap failed.
+//
+// It returns nil, unless some lap was marked with Fail or NextErr.
+func (bench *BenchmarkTSC) LapFailed() []bool {
+	bench = bench.mustBeCompleted()
+	if bench.failed == nil {
+		return nil
+	}
+	return append(bench.failed[:0:0], bench.failed...)
+}
+
+// Failures returns the number of failed laps.
+func (bench *Benchmark) Failures() int {
+	bench = bench.mustBeCompleted()
+	return bench.failures()
+}
+
+// Failures returns the number of failed laps.
+func (bench *BenchmarkTSC) Failures() int {
+	bench = bench.mustBeCompleted()
+	return bench.failures()
+}
+
+// Succeeded returns the result of laps that did not fail.
+func (bench *Benchmark) Succeeded() *Result {
+	bench = bench.mustBeCompleted()
+	return bench.forOutcome(false)
+}
+
+// Succeeded returns the result of laps that did not fail.
+func (bench *BenchmarkTSC) Succeeded() *Result {
+	bench = bench.mustBeCompleted()
+	return bench.forOutcome(false)
+}
+
+// Failed returns the result of failed laps.
+func (bench *Benchmark) Failed() *Result {
+	bench = bench.mustBeCompleted()
+	return bench.forOutcome(true)
+}
+
+// Failed returns the result of failed laps.
+func (bench *BenchmarkTSC) Failed() *Result {
+	bench = bench.mustBeCompleted()
+	return bench.forOutcome(true)
+}
+
+// forOutcome returns the result of laps whose failure flag is failed.
+func (bench *Benchmark) forOutcome(failed bool) *Result {
+	result := bench.analysis()
+	result.laps = bench.lapsWithOutcome(bench.laps, failed)
+	return result
+}
+
+// forOutcome returns the result of laps whose failure flag is failed.
+func (bench *BenchmarkTSC) forOutcome(failed bool) *Result {
+	result := *bench.Result()
+	result.laps = bench.lapsWithOutcome(result.laps, failed)
+	return &result
+}
+
+// WriteOutcomeStatsTo writes summary statistics of successful and failed laps to w.
+func (bench *Benchmark) WriteOutcomeStatsTo(w io.Writer) (int64, error) {
+	bench = bench.mustBeCompleted()
+	return writeOutcomeStats(w, bench.forOutcome)
+}
+
+// WriteOutcomeStatsTo writes summary statistics of successful and failed laps to w.
+func (bench *BenchmarkTSC) WriteOutcomeStatsTo(w io.Writer) (int64, error) {
+	bench = bench.mustBeCompleted()
+	return writeOutcomeStats(w, bench.forOutcome)
+}
+
+// writeOutcomeStats writes summary statistics of results of forOutcome to w.
+func writeOutcomeStats(w io.Writer, forOutcome func(failed bool) *Result) (int64, error) {
+	var written int64
+	for _, outcome := range []string{"succeeded", "failed"} {
+		result := forOutcome(outcome == "failed")
+		n, err := fmt.Fprintf(w, "%s: %d laps\n", outcome, len(result.laps))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if len(result.laps) == 0 {
+			continue
+		}
+		m, err := result.Stats().WriteTo(w)
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// StringOutcomeStats returns summary statistics of successful and failed laps.
+func (bench *Benchmark) StringOutcomeStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteOutcomeStatsTo(&buffer)
+	return buffer.String()
+}
+
+// StringOutcomeStats returns summary statistics of successful and failed laps.
+func (bench *BenchmarkTSC) StringOutcomeStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteOutcomeStatsTo(&buffer)
+	return buffer.String()
+}
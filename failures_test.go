@@ -0,0 +1,74 @@
+package hrtime_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkFailures(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	clock := hrtime.NewManualClock(0)
+
+	bench := hrtime.NewBenchmark(6, hrtime.WithWarmup(1), hrtime.WithClock(clock))
+	var err error
+	for i := 0; bench.NextErr(err); i++ {
+		err = nil
+		if i%2 == 0 {
+			// the warmup lap fails as well
+			clock.Advance(10 * time.Millisecond)
+			err = errTimeout
+		} else {
+			clock.Advance(time.Millisecond)
+		}
+	}
+
+	failed := bench.LapFailed()
+	if len(failed) != 6 || failed[0] || !failed[1] || failed[4] || !failed[5] {
+		t.Fatalf("failures not aligned after warmup: %v", failed)
+	}
+	if bench.Failures() != 3 {
+		t.Errorf("expected 3 failures, got %d", bench.Failures())
+	}
+	if succeeded := bench.Succeeded(); succeeded.Count() != 3 || succeeded.Stats().Maximum != time.Millisecond {
+		t.Errorf("unexpected successful laps %v", succeeded.Laps())
+	}
+	if failures := bench.Failed(); failures.Count() != 3 || failures.Stats().Minimum != 10*time.Millisecond {
+		t.Errorf("unexpected failed laps %v", failures.Laps())
+	}
+	if len(bench.Laps()) != 6 {
+		t.Errorf("expected all laps, got %v", bench.Laps())
+	}
+
+	stats := bench.StringOutcomeStats()
+	if !strings.Contains(stats, "succeeded: 3 laps") || !strings.Contains(stats, "failed: 3 laps") {
+		t.Errorf("unexpected outcome stats:\n%s", stats)
+	}
+	if metadata := bench.JSONResult("call").Metadata; metadata["failed"] != "3" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+
+	streaming := hrtime.NewStreamingBenchmark()
+	for i := 0; i < 3 && streaming.Next(); i++ {
+		if i == 1 {
+			streaming.Fail()
+		}
+	}
+	streaming.Stop()
+	if failed := streaming.LapFailed(); len(failed) != 3 || !failed[1] || failed[2] {
+		t.Errorf("unexpected streaming failures %v", failed)
+	}
+
+	clean := hrtime.NewBenchmark(3)
+	for clean.Next() {
+	}
+	if clean.LapFailed() != nil || clean.Failures() != 0 || clean.Failed().Count() != 0 {
+		t.Errorf("unexpected failures without Fail")
+	}
+	if _, ok := clean.JSONResult("clean").Metadata["failed"]; ok {
+		t.Errorf("unexpected failure metadata")
+	}
+}
@@ -0,0 +1,131 @@
+package hrtime
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Rounding defines how durations are rounded in formatted output.
+type Rounding byte
+
+const (
+	// RoundTruncate truncates durations to significant digits.
+	RoundTruncate Rounding = iota
+	// RoundNearest rounds durations to the nearest value with significant digits.
+	RoundNearest
+	// RoundExact formats durations with full nanosecond precision.
+	RoundExact
+)
+
+// FormatPolicy defines how durations are formatted in textual output.
+//
+// Formatting uses integer arithmetic, hence the same results always
+// render byte-identically, regardless of the platform.
+type FormatPolicy struct {
+	// Digits is the number of significant digits.
+	Digits int
+	// Rounding is used for statistics, such as averages and percentiles.
+	// Histogram bin boundaries are always rounded to nearest, unless RoundExact is used.
+	Rounding Rounding
+}
+
+// DefaultFormatPolicy is the default formatting of durations.
+var DefaultFormatPolicy = FormatPolicy{Digits: 3, Rounding: RoundTruncate}
+
+var (
+	// formatPolicy is the policy set by SetFormatPolicy.
+	formatPolicy atomic.Pointer[FormatPolicy]
+	// initialFormatPolicy is used until SetFormatPolicy is called.
+	initialFormatPolicy = DefaultFormatPolicy
+)
+
+// SetFormatPolicy sets how durations are formatted in textual output.
+//
+// It's intended to be called during initialization, e.g. in golden-file tests.
+// It's safe to call concurrently with formatting, however output formatted
+// concurrently may use either policy.
+func SetFormatPolicy(policy FormatPolicy) {
+	if policy.Digits <= 0 {
+		panic("digits must be at least 1")
+	}
+	formatPolicy.Store(&policy)
+}
+
+// loadFormatPolicy returns the current format policy.
+func loadFormatPolicy() FormatPolicy {
+	if policy := formatPolicy.Load(); policy != nil {
+		return *policy
+	}
+	return initialFormatPolicy
+}
+
+// RoundDuration rounds d according to the format policy.
+//
+// It allows other packages to format durations consistently with hrtime output.
+func RoundDuration(d time.Duration) time.Duration {
+	return formatStat(float64(d))
+}
+
+// formatStat rounds a statistic in nanoseconds according to the format policy.
+func formatStat(nanos float64) time.Duration {
+	policy := loadFormatPolicy()
+	return roundDuration(nanos, policy.Digits, policy.Rounding)
+}
+
+// formatBin rounds a bin boundary in nanoseconds according to the format policy.
+func formatBin(nanos float64) time.Duration {
+	policy := loadFormatPolicy()
+	if policy.Rounding == RoundExact {
+		return roundDuration(nanos, policy.Digits, RoundExact)
+	}
+	return roundDuration(nanos, policy.Digits, RoundNearest)
+}
+
+// roundDuration converts nanoseconds to duration with specified significant digits.
+func roundDuration(nanos float64, digits int, rounding Rounding) time.Duration {
+	if math.IsNaN(nanos) || math.IsInf(nanos, 0) {
+		return 0
+	}
+
+	// truncating must not round sub-nanosecond fractions first,
+	// otherwise e.g. 999.6ns would be shown as 1µs
+	v := int64(math.Round(nanos))
+	if rounding == RoundTruncate {
+		v = int64(math.Trunc(nanos))
+	}
+	if rounding == RoundExact || digits <= 0 || v == 0 {
+		return time.Duration(v)
+	}
+
+	negative := v < 0
+	if negative {
+		v = -v
+	}
+
+	scale := int64(1)
+	for limit := pow10(digits); v/scale >= limit; {
+		scale *= 10
+	}
+
+	switch rounding {
+	case RoundNearest:
+		v = (v + scale/2) / scale * scale
+	default:
+		v = v / scale * scale
+	}
+
+	if negative {
+		v = -v
+	}
+	return time.Duration(v)
+}
+
+// pow10 returns 10^n.
+func pow10(n int) int64 {
+	v := int64(1)
+	for i := 0; i < n && v < math.MaxInt64/10; i++ {
+		v *= 10
+	}
+	return v
+}
@@ -0,0 +1,62 @@
+package hrtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundDuration(t *testing.T) {
+	tests := []struct {
+		nanos    float64
+		rounding Rounding
+		expect   time.Duration
+	}{
+		{1234567, RoundTruncate, 1230000},
+		{1235567, RoundNearest, 1240000},
+		{1234567.4, RoundExact, 1234567},
+		{999, RoundTruncate, 999},
+		{999.6, RoundTruncate, 999},
+		{-999.6, RoundTruncate, -999},
+		{-1234567, RoundTruncate, -1230000},
+		{0, RoundNearest, 0},
+	}
+
+	for _, test := range tests {
+		got := roundDuration(test.nanos, 3, test.rounding)
+		if got != test.expect {
+			t.Errorf("roundDuration(%v, 3, %v) = %v, expected %v", test.nanos, test.rounding, got, test.expect)
+		}
+	}
+}
+
+func TestSetFormatPolicy(t *testing.T) {
+	defer SetFormatPolicy(DefaultFormatPolicy)
+
+	hist := NewDurationHistogram([]time.Duration{1234567, 2345678}, &HistogramOptions{BinCount: 2})
+	SetFormatPolicy(FormatPolicy{Digits: 3, Rounding: RoundExact})
+	first := hist.String()
+	second := hist.String()
+	if first != second {
+		t.Errorf("output differs:\n%s\n%s", first, second)
+	}
+	if formatStat(hist.Minimum) != 1234567 {
+		t.Errorf("expected exact minimum, got %v", formatStat(hist.Minimum))
+	}
+}
+
+func TestSetFormatPolicyConcurrent(t *testing.T) {
+	defer SetFormatPolicy(DefaultFormatPolicy)
+
+	hist := NewDurationHistogram([]time.Duration{1234567, 2345678}, &HistogramOptions{BinCount: 2})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = hist.String()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		SetFormatPolicy(FormatPolicy{Digits: 1 + i%5, Rounding: RoundNearest})
+	}
+	<-done
+}
@@ -0,0 +1,131 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// FrameTimer measures frame durations of game or render loops.
+//
+// Tick is called once per frame, the duration between consecutive ticks
+// is the frame time. Frames longer than the budget are counted as slow and
+// every missed budget interval is counted as a dropped frame.
+//
+// FrameTimer is not safe for concurrent use.
+type FrameTimer struct {
+	budget  time.Duration
+	opts    options
+	started bool
+	last    time.Duration
+	frames  []time.Duration
+}
+
+// NewFrameTimer creates a frame timer with target frame budget,
+// e.g. time.Second/60 for 60 FPS.
+//
+// Only WithClock option is used.
+func NewFrameTimer(budget time.Duration, opts ...Option) *FrameTimer {
+	if budget <= 0 {
+		panic("frame budget must be positive")
+	}
+	return &FrameTimer{
+		budget: budget,
+		opts:   newOptions(opts),
+	}
+}
+
+// Tick marks the end of the previous frame and the start of the next one.
+//
+// The first call only starts measuring.
+func (timer *FrameTimer) Tick() {
+	now := timer.opts.now()
+	if timer.started {
+		timer.frames = append(timer.frames, now-timer.last)
+	}
+	timer.started = true
+	timer.last = now
+}
+
+// Budget returns the target frame budget.
+func (timer *FrameTimer) Budget() time.Duration { return timer.budget }
+
+// Frames returns a copy of measured frame durations.
+func (timer *FrameTimer) Frames() []time.Duration {
+	return append(timer.frames[:0:0], timer.frames...)
+}
+
+// Slow returns the number of frames that exceeded the budget.
+func (timer *FrameTimer) Slow() int {
+	slow := 0
+	for _, frame := range timer.frames {
+		if frame > timer.budget {
+			slow++
+		}
+	}
+	return slow
+}
+
+// Dropped returns the number of budget intervals missed by slow frames,
+// i.e. how many frames would have been dropped with vertical sync.
+func (timer *FrameTimer) Dropped() int {
+	dropped := 0
+	for _, frame := range timer.frames {
+		if frame > timer.budget {
+			dropped += int((frame - 1) / timer.budget)
+		}
+	}
+	return dropped
+}
+
+// FPS returns the average frames per second.
+func (timer *FrameTimer) FPS() float64 {
+	mean := meanDuration(timer.frames)
+	if mean <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(mean)
+}
+
+// FPSPercentile returns the q-th quantile of frame rate,
+// e.g. FPSPercentile(0.01) returns the "1% low" FPS.
+func (timer *FrameTimer) FPSPercentile(q float64) float64 {
+	frame := quantile(sortedDurations(timer.frames), 1-q)
+	if frame <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(frame)
+}
+
+// Histogram creates an histogram of frame durations.
+//
+// It creates binCount bins to distribute the data and uses the
+// 99.9 percentile as the last bucket range.
+func (timer *FrameTimer) Histogram(binCount int) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	return NewDurationHistogram(timer.frames, &opts)
+}
+
+// Reset removes all measured frames, the next Tick starts measuring again.
+func (timer *FrameTimer) Reset() {
+	timer.started = false
+	timer.frames = timer.frames[:0]
+}
+
+// WriteTo writes frame statistics to w.
+func (timer *FrameTimer) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "  frames %d;  fps avg %.1f;  1%% low %.1f;  0.1%% low %.1f;\n  budget %v;  slow %d;  dropped %d;\n",
+		len(timer.frames), timer.FPS(), timer.FPSPercentile(0.01), timer.FPSPercentile(0.001),
+		timer.budget, timer.Slow(), timer.Dropped())
+	return int64(n), err
+}
+
+// String returns frame statistics as a string.
+func (timer *FrameTimer) String() string {
+	var buffer strings.Builder
+	_, _ = timer.WriteTo(&buffer)
+	return buffer.String()
+}
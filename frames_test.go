@@ -0,0 +1,37 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestFrameTimer(t *testing.T) {
+	var now time.Duration
+	clock := hrtime.ClockFunc(func() time.Duration { return now })
+
+	budget := 10 * time.Millisecond
+	timer := hrtime.NewFrameTimer(budget, hrtime.WithClock(clock))
+	timer.Tick()
+	for i := 0; i < 99; i++ {
+		now += 10 * time.Millisecond
+		timer.Tick()
+	}
+	now += 35 * time.Millisecond
+	timer.Tick()
+
+	if n := len(timer.Frames()); n != 100 {
+		t.Fatalf("expected 100 frames, got %d", n)
+	}
+	if timer.Slow() != 1 || timer.Dropped() != 3 {
+		t.Errorf("expected 1 slow and 3 dropped frames, got %d and %d", timer.Slow(), timer.Dropped())
+	}
+	if fps := timer.FPSPercentile(0.5); fps != 100 {
+		t.Errorf("expected median 100 fps, got %v", fps)
+	}
+	if low := timer.FPSPercentile(0); low > 30 {
+		t.Errorf("expected slowest frame fps below 30, got %v", low)
+	}
+	t.Log("\n" + timer.String())
+}
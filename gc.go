@@ -0,0 +1,101 @@
+package hrtime
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// DisableGC disables the garbage collector until restore is called.
+//
+// When collect is true, it runs a garbage collection first, so the
+// measurement starts with a clean heap. Memory is not reclaimed while
+// the garbage collector is disabled, hence it's only suitable for
+// micro-benchmarks with bounded allocations.
+func DisableGC(collect bool) (restore func()) {
+	if collect {
+		runtime.GC()
+	}
+	percent := debug.SetGCPercent(-1)
+	return func() { debug.SetGCPercent(percent) }
+}
+
+// Ballast allocates a heap ballast of size bytes until restore is called.
+//
+// A ballast increases the heap size used for GC pacing, making garbage
+// collections less frequent without disabling them. The ballast memory
+// is never written, hence it's not backed by physical memory on most
+// operating systems.
+func Ballast(size int) (restore func()) {
+	if size < 0 {
+		panic("ballast size must not be negative")
+	}
+	ballast := make([]byte, size)
+	return func() { runtime.KeepAlive(ballast) }
+}
+
+// WithGCPercent sets the garbage collection target percentage,
+// similarly to GOGC, for the duration of the suite.
+//
+// The setting is recorded in SuiteResult.Metadata as "gc.percent".
+func WithGCPercent(percent int) SuiteOption {
+	return func(config *suiteConfig) {
+		config.gcPercent = percent
+		config.gcPercentSet = true
+	}
+}
+
+// WithBallast keeps a heap ballast of size bytes for the duration of the suite.
+//
+// The setting is recorded in SuiteResult.Metadata as "gc.ballast".
+// See Ballast for details.
+func WithBallast(size int) SuiteOption {
+	return func(config *suiteConfig) { config.ballast = size }
+}
+
+// applyGC applies suite-wide garbage collector settings and returns a func to restore them.
+func (config *suiteConfig) applyGC() (restore func()) {
+	var restores []func()
+	if config.gcPercentSet {
+		percent := debug.SetGCPercent(config.gcPercent)
+		restores = append(restores, func() { debug.SetGCPercent(percent) })
+	}
+	if config.ballast > 0 {
+		restores = append(restores, Ballast(config.ballast))
+	}
+	return func() {
+		for i := len(restores) - 1; i >= 0; i-- {
+			restores[i]()
+		}
+	}
+}
+
+// WithGCDisabled disables the garbage collector while measuring each benchmark.
+//
+// When collect is true, a garbage collection is run before each benchmark.
+// The choice is recorded in SuiteResult.Metadata as "gc" and "gc.collect".
+func WithGCDisabled(collect bool) SuiteOption {
+	return func(config *suiteConfig) {
+		config.gcDisabled = true
+		config.gcCollect = collect
+	}
+}
+
+// gcMetadata returns metadata describing garbage collector configuration.
+func (config *suiteConfig) gcMetadata() map[string]string {
+	if !config.gcDisabled && !config.gcPercentSet && config.ballast <= 0 {
+		return nil
+	}
+	metadata := map[string]string{}
+	if config.gcDisabled {
+		metadata["gc"] = "off"
+		metadata["gc.collect"] = strconv.FormatBool(config.gcCollect)
+	}
+	if config.gcPercentSet {
+		metadata["gc.percent"] = strconv.Itoa(config.gcPercent)
+	}
+	if config.ballast > 0 {
+		metadata["gc.ballast"] = strconv.Itoa(config.ballast)
+	}
+	return metadata
+}
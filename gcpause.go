@@ -0,0 +1,143 @@
+package hrtime
+
+import (
+	"runtime"
+	"time"
+)
+
+// GCPause is a stop-the-world garbage collector pause on the hrtime timeline.
+type GCPause struct {
+	Span
+	// Cycle is the number of the garbage collection cycle.
+	Cycle uint32
+}
+
+// AnnotatedLap is a lap together with the garbage collector pauses it overlapped.
+type AnnotatedLap struct {
+	Span
+	// GCPause is the time the lap overlapped stop-the-world pauses.
+	GCPause time.Duration
+	// GCCycles is the number of garbage collection cycles that paused the lap.
+	GCCycles int
+}
+
+// GCAffected returns whether the lap overlapped a garbage collector pause.
+func (lap *AnnotatedLap) GCAffected() bool { return lap.GCCycles > 0 }
+
+// WithGCPauses records stop-the-world garbage collector pauses during the
+// benchmark, which allows flagging laps affected by them using Annotated.
+//
+// Pauses are read from runtime.MemStats when the benchmark completes, which
+// stops the world briefly, and only the latest 256 pauses are available.
+// It's not meaningful together with WithClock.
+func WithGCPauses() Option {
+	return func(opts *options) { opts.gcPauses = true }
+}
+
+// readGCPauses returns pauses overlapping the timeline, using the
+// pair of wall-clock and hrtime readings at the same moment as an anchor.
+func readGCPauses(start, stop time.Duration, wall time.Time, now time.Duration) []GCPause {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	var pauses []GCPause
+	count := min(stats.NumGC, uint32(len(stats.PauseEnd)))
+	for i := uint32(0); i < count; i++ {
+		cycle := stats.NumGC - i
+		index := (stats.NumGC + uint32(len(stats.PauseEnd)) - 1 - i) % uint32(len(stats.PauseEnd))
+
+		end := now + time.Duration(int64(stats.PauseEnd[index])-wall.UnixNano())
+		pause := GCPause{
+			Span:  Span{Start: end - time.Duration(stats.PauseNs[index]), Finish: end},
+			Cycle: cycle,
+		}
+		if pause.Finish < start {
+			break
+		}
+		if pause.Start <= stop {
+			pauses = append(pauses, pause)
+		}
+	}
+	// order from the oldest pause
+	for i, j := 0, len(pauses)-1; i < j; i, j = i+1, j-1 {
+		pauses[i], pauses[j] = pauses[j], pauses[i]
+	}
+	return pauses
+}
+
+// GCPauses returns garbage collector pauses during the benchmark,
+// when measured WithGCPauses.
+func (bench *Benchmark) GCPauses() []GCPause {
+	bench = bench.mustBeCompleted()
+	return append(bench.gcPauses[:0:0], bench.gcPauses...)
+}
+
+// Annotated returns laps with garbage collector pauses they overlapped,
+// when measured WithGCPauses.
+//
+// It explains tail latency spikes caused by the garbage collector,
+// without manual correlation with GODEBUG=gctrace=1 output.
+func (bench *Benchmark) Annotated() []AnnotatedLap {
+	return annotateLaps(bench.Spans(), bench.gcPauses)
+}
+
+// ResultWithoutGC returns the result of laps that did not overlap
+// a garbage collector pause, when measured WithGCPauses.
+func (bench *Benchmark) ResultWithoutGC() *Result {
+	return withoutGC(bench.analysis(), bench.Annotated())
+}
+
+// GCPauses returns garbage collector pauses during the benchmark,
+// when measured WithGCPauses.
+//
+// Pauses are placed on the timeline of Spans, which converts counts
+// using Count.ApproxDuration.
+func (bench *BenchmarkTSC) GCPauses() []GCPause {
+	bench = bench.mustBeCompleted()
+	return append(bench.gcPauses[:0:0], bench.gcPauses...)
+}
+
+// Annotated returns laps with garbage collector pauses they overlapped,
+// see Benchmark.Annotated.
+func (bench *BenchmarkTSC) Annotated() []AnnotatedLap {
+	return annotateLaps(bench.Spans(), bench.gcPauses)
+}
+
+// ResultWithoutGC returns the result of laps that did not overlap
+// a garbage collector pause, when measured WithGCPauses.
+func (bench *BenchmarkTSC) ResultWithoutGC() *Result {
+	return withoutGC(bench.analysis(), bench.Annotated())
+}
+
+// annotateLaps adds pauses overlapping each of the ordered spans.
+func annotateLaps(spans []Span, pauses []GCPause) []AnnotatedLap {
+	laps := make([]AnnotatedLap, len(spans))
+	next := 0
+	for i, span := range spans {
+		laps[i].Span = span
+		// spans are ordered, hence pauses before this span can be skipped
+		for next < len(pauses) && pauses[next].Finish < span.Start {
+			next++
+		}
+		for _, pause := range pauses[next:] {
+			if pause.Start > span.Finish {
+				break
+			}
+			overlap := min(span.Finish, pause.Finish) - max(span.Start, pause.Start)
+			laps[i].GCPause += max(overlap, 0)
+			laps[i].GCCycles++
+		}
+	}
+	return laps
+}
+
+// withoutGC replaces laps of result with laps not affected by a pause.
+func withoutGC(result *Result, laps []AnnotatedLap) *Result {
+	result.laps = nil
+	for _, lap := range laps {
+		if !lap.GCAffected() {
+			result.laps = append(result.laps, lap.Duration())
+		}
+	}
+	return result
+}
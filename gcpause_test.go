@@ -0,0 +1,59 @@
+package hrtime_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkGCPauses(t *testing.T) {
+	bench := hrtime.NewBenchmark(8, hrtime.WithGCPauses())
+	for i := 0; bench.Next(); i++ {
+		if i == 4 {
+			runtime.GC()
+		}
+	}
+
+	if pauses := bench.GCPauses(); len(pauses) == 0 {
+		t.Fatalf("expected garbage collector pauses")
+	}
+
+	laps := bench.Annotated()
+	if len(laps) != 8 {
+		t.Fatalf("expected 8 laps, got %d", len(laps))
+	}
+	if !laps[4].GCAffected() || laps[4].GCPause <= 0 {
+		t.Errorf("expected lap with runtime.GC to be affected, got %+v", laps[4])
+	}
+	affected := 0
+	for _, lap := range laps {
+		if lap.GCAffected() {
+			affected++
+		}
+	}
+
+	if result := bench.ResultWithoutGC(); result.Count() != 8-affected {
+		t.Errorf("expected %d laps without GC, got %d", 8-affected, result.Count())
+	}
+}
+
+func TestBenchmarkTSCGCPauses(t *testing.T) {
+	bench := hrtime.NewBenchmarkTSC(8, hrtime.WithGCPauses())
+	for i := 0; bench.Next(); i++ {
+		if i == 4 {
+			runtime.GC()
+		}
+	}
+
+	if pauses := bench.GCPauses(); len(pauses) == 0 {
+		t.Fatalf("expected garbage collector pauses")
+	}
+	laps := bench.Annotated()
+	if len(laps) != 8 || !laps[4].GCAffected() {
+		t.Fatalf("expected lap with runtime.GC to be affected, got %+v", laps)
+	}
+	if result := bench.ResultWithoutGC(); result.Count() >= 8 {
+		t.Errorf("expected affected laps to be excluded, got %d", result.Count())
+	}
+}
@@ -0,0 +1,187 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// heatmapShades are characters for increasing densities of text heatmaps.
+const heatmapShades = " .:-=+*#%@"
+
+// Heatmap counts laps by the time they started and by their latency.
+//
+// Unlike a time series of quantiles, it shows the whole distribution in
+// each window, which makes time-localized incidents such as GC storms
+// or noisy neighbors visible, even when they don't move the median.
+type Heatmap struct {
+	// Times are the bounds of time windows since the start, hence
+	// there is one more bound than there are columns.
+	Times []time.Duration
+	// Latencies are the bounds of latency rows from the fastest,
+	// spaced logarithmically between the fastest and the slowest lap.
+	Latencies []time.Duration
+	// Counts contains the number of laps in each row and column.
+	Counts [][]int
+}
+
+// Heatmap splits the series into columns windows of equal duration and
+// laps in each window into rows latency buckets.
+func (series *TimeSeries) Heatmap(columns, rows int) *Heatmap {
+	buckets, laps := series.buckets(columns)
+	times := make([]time.Duration, columns+1)
+	for i, bucket := range buckets {
+		times[i], times[i+1] = bucket.Start, bucket.End
+	}
+	return newHeatmap(times, laps, rows)
+}
+
+// NewSegmentHeatmap creates a heatmap with a column for each segment.
+//
+// Only raw laps are counted, hence the segments should be recorded
+// by a SegmentedRecorder that keeps laps.
+func NewSegmentHeatmap(segments []Segment, length time.Duration, rows int) *Heatmap {
+	if len(segments) == 0 {
+		panic("must have count at least 1")
+	}
+	times := make([]time.Duration, len(segments)+1)
+	laps := make([][]time.Duration, len(segments))
+	for i, segment := range segments {
+		times[i] = segment.Start.Sub(segments[0].Start)
+		laps[i] = segment.Laps
+	}
+	times[len(segments)] = times[len(segments)-1] + length
+	return newHeatmap(times, laps, rows)
+}
+
+// newHeatmap counts laps of each column into rows latency buckets.
+func newHeatmap(times []time.Duration, laps [][]time.Duration, rows int) *Heatmap {
+	if rows <= 0 {
+		panic("must have count at least 1")
+	}
+
+	fastest, slowest := time.Duration(math.MaxInt64), time.Duration(0)
+	for _, column := range laps {
+		for _, lap := range column {
+			fastest, slowest = min(fastest, lap), max(slowest, lap)
+		}
+	}
+	fastest = max(min(fastest, slowest), 1)
+	slowest = max(slowest, fastest) + 1
+
+	heatmap := &Heatmap{
+		Times:     times,
+		Latencies: make([]time.Duration, rows+1),
+		Counts:    make([][]int, rows),
+	}
+	scale := math.Log(float64(slowest) / float64(fastest))
+	for i := range heatmap.Latencies {
+		heatmap.Latencies[i] = time.Duration(float64(fastest) * math.Exp(scale*float64(i)/float64(rows)))
+	}
+	heatmap.Latencies[0], heatmap.Latencies[rows] = fastest, slowest
+	for i := range heatmap.Counts {
+		heatmap.Counts[i] = make([]int, len(laps))
+	}
+	for column, columnLaps := range laps {
+		for _, lap := range columnLaps {
+			row := int(float64(rows) * math.Log(float64(max(lap, fastest))/float64(fastest)) / scale)
+			heatmap.Counts[min(max(row, 0), rows-1)][column]++
+		}
+	}
+	return heatmap
+}
+
+// Columns returns the number of time windows.
+func (heatmap *Heatmap) Columns() int { return len(heatmap.Times) - 1 }
+
+// density returns the fraction of laps in the column that are in the row.
+//
+// Densities are relative to each column, hence windows with fewer laps
+// are as visible as busy ones.
+func (heatmap *Heatmap) density(row, column int) float64 {
+	total := 0
+	for _, counts := range heatmap.Counts {
+		total += counts[column]
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(heatmap.Counts[row][column]) / float64(total)
+}
+
+// WriteTo writes the heatmap as text to w, with the slowest latencies
+// at the top and each character showing the fraction of laps in a window.
+func (heatmap *Heatmap) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for row := len(heatmap.Counts) - 1; row >= 0; row-- {
+		var cells strings.Builder
+		for column := range heatmap.Columns() {
+			shade := 0
+			if density := heatmap.density(row, column); density > 0 {
+				// any lap is visible
+				shade = 1 + int(density*float64(len(heatmapShades)-2))
+			}
+			cells.WriteByte(heatmapShades[shade])
+		}
+		n, err := fmt.Fprintf(w, "%10v |%s|\n", formatStat(float64(heatmap.Latencies[row])), cells.String())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	end := RoundDuration(heatmap.Times[len(heatmap.Times)-1]).String()
+	n, err := fmt.Fprintf(w, "%10s  %-*s%s\n", "", max(heatmap.Columns()-len(end), 3), "0s", end)
+	written += int64(n)
+	return written, err
+}
+
+// String returns the heatmap as text.
+func (heatmap *Heatmap) String() string {
+	var buffer strings.Builder
+	_, _ = heatmap.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// WriteSVG writes the heatmap in SVG format to w, which can be embedded in HTML.
+func (heatmap *Heatmap) WriteSVG(w io.Writer) (int64, error) {
+	const (
+		width       = 600
+		height      = 200
+		labelHeight = 20
+	)
+
+	columns, rows := heatmap.Columns(), len(heatmap.Counts)
+	cellWidth, cellHeight := float64(width)/float64(columns), float64(height)/float64(rows)
+
+	var written int64
+	n, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n",
+		width, height+labelHeight)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for row := range rows {
+		for column := range columns {
+			density := heatmap.density(row, column)
+			if density == 0 {
+				continue
+			}
+			n, err = fmt.Fprintf(w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#4c78a8" fill-opacity="%.2f"><title>%v: %d laps %v-%v</title></rect>`+"\n",
+				float64(column)*cellWidth, float64(rows-row-1)*cellHeight, cellWidth, cellHeight, 0.1+0.9*density,
+				RoundDuration(heatmap.Times[column]), heatmap.Counts[row][column],
+				formatStat(float64(heatmap.Latencies[row])), formatStat(float64(heatmap.Latencies[row+1])))
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+	n, err = fmt.Fprintf(w, `<text x="2" y="12">%v</text><text x="2" y="%d">0s</text><text x="%d" y="%d" text-anchor="end">%v</text>`+"\n</svg>\n",
+		formatStat(float64(heatmap.Latencies[rows])), height+labelHeight-5, width-2, height+labelHeight-5,
+		RoundDuration(heatmap.Times[len(heatmap.Times)-1]))
+	written += int64(n)
+	return written, err
+}
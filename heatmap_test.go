@@ -0,0 +1,69 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestHeatmap(t *testing.T) {
+	laps := make([]time.Duration, 1000)
+	for i := range laps {
+		laps[i] = time.Microsecond
+		if i >= 500 && i < 550 {
+			// incident in the middle of the run
+			laps[i] = 4 * time.Microsecond
+		}
+	}
+
+	heatmap := hrtime.NewTimeSeries(laps).Heatmap(40, 10)
+	if heatmap.Columns() != 40 || len(heatmap.Counts) != 10 || len(heatmap.Latencies) != 11 {
+		t.Fatalf("unexpected dimensions %d %d %d", heatmap.Columns(), len(heatmap.Counts), len(heatmap.Latencies))
+	}
+	total, slow, slowColumns := 0, 0, map[int]bool{}
+	for row, counts := range heatmap.Counts {
+		for column, count := range counts {
+			total += count
+			if row == len(heatmap.Counts)-1 && count > 0 {
+				slow += count
+				slowColumns[column] = true
+			}
+		}
+	}
+	if total != len(laps) || slow != 50 {
+		t.Errorf("unexpected counts total %d slow %d", total, slow)
+	}
+	if slowColumns[0] || slowColumns[heatmap.Columns()-1] || len(slowColumns) == 0 {
+		t.Errorf("incident not localized in time: %v", slowColumns)
+	}
+
+	text := heatmap.String()
+	if rows := strings.Split(strings.TrimSuffix(text, "\n"), "\n"); len(rows) != 11 || !strings.Contains(rows[0], "@") {
+		t.Errorf("unexpected heatmap:\n%s", text)
+	}
+	t.Log("\n" + text)
+
+	var svg strings.Builder
+	if _, err := heatmap.WriteSVG(&svg); err != nil || !strings.Contains(svg.String(), "<rect") {
+		t.Errorf("unexpected svg %v:\n%s", err, svg.String())
+	}
+}
+
+func TestSegmentHeatmap(t *testing.T) {
+	rec := hrtime.NewSegmentedRecorder(time.Second, true)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 30 {
+		rec.RecordAt(start.Add(time.Duration(i)*100*time.Millisecond), time.Duration(i+1)*time.Microsecond)
+	}
+	rec.Flush()
+
+	heatmap := hrtime.NewSegmentHeatmap(rec.Segments(), time.Second, 5)
+	if heatmap.Columns() != 3 || heatmap.Times[3] != 3*time.Second {
+		t.Fatalf("unexpected times %v", heatmap.Times)
+	}
+	if heatmap.Counts[0][0] == 0 || heatmap.Counts[4][0] != 0 || heatmap.Counts[4][2] == 0 {
+		t.Errorf("unexpected counts %v", heatmap.Counts)
+	}
+}
@@ -1,6 +1,7 @@
 package hrtime
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -17,8 +18,53 @@ type HistogramOptions struct {
 	// Clamp values to either percentile or to a specific ns value.
 	ClampMaximum    float64
 	ClampPercentile float64
+	// Invalid specifies how NaN, infinite and negative values are handled.
+	Invalid InvalidPolicy
+	// Backend specifies how percentiles and bins are computed.
+	Backend HistogramBackend
+	// SignificantDigits is the precision of HistogramHDR backend, 2 when zero.
+	SignificantDigits int
+	// LogBins uses logarithmically spaced bins over the full range of values,
+	// clamping is ignored. HistogramHDR backend always uses logarithmic bins.
+	LogBins bool
 }
 
+// HistogramBackend specifies how histograms compute percentiles and bins.
+type HistogramBackend int
+
+const (
+	// HistogramLinear uses equally sized bins over the clamped range.
+	HistogramLinear HistogramBackend = iota
+	// HistogramHDR records values into an HDRHistogram and uses
+	// logarithmic bins, which keeps resolution when values span
+	// several orders of magnitude.
+	HistogramHDR
+)
+
+// InvalidPolicy specifies how histograms handle NaN, infinite and negative values.
+//
+// Regardless of the policy, constructing a histogram from arbitrary values
+// never panics nor produces NaN bins, which makes it safe to use on
+// untrusted imported data.
+type InvalidPolicy int
+
+const (
+	// InvalidSkip ignores invalid values.
+	InvalidSkip InvalidPolicy = iota
+	// InvalidClamp clamps negative values to 0 and +Inf to the largest finite value.
+	// NaN values are skipped.
+	InvalidClamp
+	// InvalidError makes NewHistogramChecked return ErrInvalidValue.
+	InvalidError
+)
+
+// ErrInvalidValue is returned by NewHistogramChecked when the input contains
+// NaN, infinite or negative values and InvalidError policy is used.
+var ErrInvalidValue = errors.New("invalid histogram value")
+
+// ErrInvalidBinCount is returned by NewHistogramChecked when BinCount is not positive.
+var ErrInvalidBinCount = errors.New("binCount must be larger than 0")
+
 var defaultOptions = HistogramOptions{
 	BinCount:        10,
 	NiceRange:       true,
@@ -36,8 +82,18 @@ type Histogram struct {
 
 	Bins []HistogramBin
 
+	// Invalid is the number of NaN, infinite or negative values in the input.
+	Invalid int
+	// HDR contains all values when using HistogramHDR backend.
+	HDR *HDRHistogram
+
 	// for pretty printing
 	Width int
+	// LogScale uses logarithmic scale for bar lengths,
+	// which keeps rare tail bins visible next to a dominant mode.
+	LogScale bool
+	// ASCII draws bars using ASCII characters instead of Unicode blocks.
+	ASCII bool
 }
 
 // HistogramBin is a single bin in histogram
@@ -50,27 +106,111 @@ type HistogramBin struct {
 
 // NewDurationHistogram creates a histogram from time.Duration-s.
 func NewDurationHistogram(durations []time.Duration, opts *HistogramOptions) *Histogram {
+	return NewHistogram(durationNanos(durations), opts)
+}
+
+// durationNanos converts durations to nanoseconds.
+func durationNanos(durations []time.Duration) []float64 {
 	nanos := make([]float64, len(durations))
 	for i, d := range durations {
 		nanos[i] = float64(d.Nanoseconds())
 	}
-	return NewHistogram(nanos, opts)
+	return nanos
+}
+
+// countNanos converts counts to nanoseconds using the approximate TSC frequency.
+//
+// Counts are scaled by a single ratio, instead of converting each count with
+// Count.ApproxDuration.
+func countNanos(counts []Count) []float64 {
+	ratio := loadTSCRatio()
+	nanosPerCount := float64(ratio.nano) / float64(ratio.count)
+
+	nanos := make([]float64, len(counts))
+	for i, count := range counts {
+		nanos[i] = float64(count) * nanosPerCount
+	}
+	return nanos
+}
+
+// clampNanos replaces nanoseconds below min with min.
+func clampNanos(nanos []float64, min time.Duration) []float64 {
+	for i, x := range nanos {
+		nanos[i] = math.Max(x, float64(min.Nanoseconds()))
+	}
+	return nanos
+}
+
+// newClampHistogram creates a histogram of nanoseconds using max
+// as the last bucket.
+func newClampHistogram(nanos []float64, binCount int, max time.Duration) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+	opts.ClampMaximum = float64(max.Nanoseconds())
+	opts.ClampPercentile = 0
+
+	return NewHistogram(nanos, &opts)
+}
+
+// newPercentileHistogram creates a histogram of nanoseconds using
+// the percentile of the data as the last bucket.
+func newPercentileHistogram(nanos []float64, binCount int, percentile float64) *Histogram {
+	if !(percentile > 0 && percentile <= 1) {
+		panic("percentile must be in range (0, 1]")
+	}
+
+	opts := defaultOptions
+	opts.BinCount = binCount
+	opts.ClampMaximum = 0
+	opts.ClampPercentile = percentile
+
+	return NewHistogram(nanos, &opts)
 }
 
 // NewHistogram creates a new histogram from the specified nanosecond values.
+//
+// When opts is nil, default options are used.
+// It panics when BinCount is not positive or when using InvalidError policy
+// and input contains invalid values, use NewHistogramChecked instead.
 func NewHistogram(nanoseconds []float64, opts *HistogramOptions) *Histogram {
+	hist, err := NewHistogramChecked(nanoseconds, opts)
+	if err != nil {
+		panic(err)
+	}
+	return hist
+}
+
+// NewHistogramChecked creates a new histogram from the specified nanosecond values.
+//
+// It returns ErrInvalidBinCount when BinCount is not positive and
+// ErrInvalidValue when using InvalidError policy and the input
+// contains NaN, infinite or negative values.
+func NewHistogramChecked(nanoseconds []float64, opts *HistogramOptions) (*Histogram, error) {
+	if opts == nil {
+		opts = &defaultOptions
+	}
 	if opts.BinCount <= 0 {
-		panic("binCount must be larger than 0")
+		return nil, ErrInvalidBinCount
 	}
 
 	hist := &Histogram{}
 	hist.Width = 40
 	hist.Bins = make([]HistogramBin, opts.BinCount)
+
+	nanoseconds, hist.Invalid = sanitizeValues(nanoseconds, opts.Invalid)
+	if hist.Invalid > 0 && opts.Invalid == InvalidError {
+		return hist, fmt.Errorf("%w: found %d", ErrInvalidValue, hist.Invalid)
+	}
+	if opts.Backend == HistogramHDR {
+		digits := opts.SignificantDigits
+		if digits == 0 {
+			digits = 2
+		}
+		hist.HDR = NewHDRHistogram(digits)
+	}
 	if len(nanoseconds) == 0 {
-		return hist
+		return hist, nil
 	}
-
-	nanoseconds = append(nanoseconds[:0:0], nanoseconds...)
 	sort.Float64s(nanoseconds)
 
 	hist.Minimum = nanoseconds[0]
@@ -95,6 +235,18 @@ func NewHistogram(nanoseconds []float64, opts *HistogramOptions) *Histogram {
 
 	hist.P50, hist.P90, hist.P99, hist.P999, hist.P9999 = p(0.50), p(0.90), p(0.99), p(0.999), p(0.9999)
 
+	if hist.HDR != nil {
+		for _, x := range nanoseconds {
+			hist.HDR.Record(int64(math.Round(x)))
+		}
+		hist.rebinLog(nanoseconds)
+		return hist, nil
+	}
+	if opts.LogBins {
+		hist.rebinLog(nanoseconds)
+		return hist, nil
+	}
+
 	clampMaximum := hist.Maximum
 	if opts.ClampPercentile > 0 {
 		clampMaximum = p(opts.ClampPercentile)
@@ -110,6 +262,10 @@ func NewHistogram(nanoseconds []float64, opts *HistogramOptions) *Histogram {
 	} else {
 		minimum, spacing = calculateSteps(hist.Minimum, clampMaximum, opts.BinCount)
 	}
+	// all values are equal or the range is degenerate
+	if !(spacing > 0) || math.IsInf(spacing, 0) || math.IsNaN(minimum) {
+		minimum, spacing = hist.Minimum, 1
+	}
 
 	for i := range hist.Bins {
 		hist.Bins[i].Start = spacing*float64(i) + minimum
@@ -140,7 +296,38 @@ func NewHistogram(nanoseconds []float64, opts *HistogramOptions) *Histogram {
 		bin.Width = float64(bin.Count) / float64(maxBin)
 	}
 
-	return hist
+	return hist, nil
+}
+
+// sanitizeValues returns a copy of values with invalid values handled
+// according to policy and the number of invalid values.
+func sanitizeValues(values []float64, policy InvalidPolicy) ([]float64, int) {
+	largest := 0.0
+	for _, x := range values {
+		if x > largest && !math.IsInf(x, 1) {
+			largest = x
+		}
+	}
+
+	invalid := 0
+	result := make([]float64, 0, len(values))
+	for _, x := range values {
+		if !math.IsNaN(x) && !math.IsInf(x, 0) && x >= 0 {
+			result = append(result, x)
+			continue
+		}
+
+		invalid++
+		if policy != InvalidClamp || math.IsNaN(x) {
+			continue
+		}
+		if math.IsInf(x, 1) {
+			result = append(result, largest)
+		} else {
+			result = append(result, 0)
+		}
+	}
+	return result, invalid
 }
 
 // Divide divides histogram by number of repetitions for the tests.
@@ -163,17 +350,22 @@ func (hist *Histogram) Divide(n int) {
 // WriteStatsTo writes formatted statistics to w.
 func (hist *Histogram) WriteStatsTo(w io.Writer) (int64, error) {
 	n, err := fmt.Fprintf(w, "  avg %v;  min %v;  p50 %v;  max %v;\n  p90 %v;  p99 %v;  p999 %v;  p9999 %v;\n",
-		time.Duration(truncate(hist.Average, 3)),
-		time.Duration(truncate(hist.Minimum, 3)),
-		time.Duration(truncate(hist.P50, 3)),
-		time.Duration(truncate(hist.Maximum, 3)),
-
-		time.Duration(truncate(hist.P90, 3)),
-		time.Duration(truncate(hist.P99, 3)),
-		time.Duration(truncate(hist.P999, 3)),
-		time.Duration(truncate(hist.P9999, 3)),
+		formatStat(hist.Average),
+		formatStat(hist.Minimum),
+		formatStat(hist.P50),
+		formatStat(hist.Maximum),
+
+		formatStat(hist.P90),
+		formatStat(hist.P99),
+		formatStat(hist.P999),
+		formatStat(hist.P9999),
 	)
-	return int64(n), err
+	if err != nil || hist.HDR == nil || hist.HDR.Count() == 0 {
+		return int64(n), err
+	}
+
+	m, err := fmt.Fprintf(w, "  percentiles ±%.3g%%\n", hist.HDR.RelativeError()*100)
+	return int64(n + m), err
 }
 
 // WriteTo writes formatted statistics and histogram to w.
@@ -184,20 +376,16 @@ func (hist *Histogram) WriteTo(w io.Writer) (int64, error) {
 	}
 
 	// TODO: use consistently single unit instead of multiple
-	maxCountLength := 3
-	for i := range hist.Bins {
-		x := (int)(math.Ceil(math.Log10(float64(hist.Bins[i].Count + 1))))
-		if x > maxCountLength {
-			maxCountLength = x
-		}
-	}
+	maxCountLength := hist.countLength()
+
+	widths := hist.barWidths()
 
 	var n int
-	for _, bin := range hist.Bins {
+	for i, bin := range hist.Bins {
 		if bin.andAbove {
-			n, err = fmt.Fprintf(w, " %10v+[%[2]*[3]v] ", time.Duration(round(bin.Start, 3)), maxCountLength, bin.Count)
+			n, err = fmt.Fprintf(w, " %10v+[%[2]*[3]v] ", formatBin(bin.Start), maxCountLength, bin.Count)
 		} else {
-			n, err = fmt.Fprintf(w, " %10v [%[2]*[3]v] ", time.Duration(round(bin.Start, 3)), maxCountLength, bin.Count)
+			n, err = fmt.Fprintf(w, " %10v [%[2]*[3]v] ", formatBin(bin.Start), maxCountLength, bin.Count)
 		}
 
 		written += int64(n)
@@ -205,23 +393,12 @@ func (hist *Histogram) WriteTo(w io.Writer) (int64, error) {
 			return written, err
 		}
 
-		width := float64(hist.Width) * bin.Width
-		frac := width - math.Trunc(width)
-
-		n, err = io.WriteString(w, strings.Repeat("█", int(width)))
+		n, err = io.WriteString(w, hist.bar(widths[i], hist.Width))
 		written += int64(n)
 		if err != nil {
 			return written, err
 		}
 
-		if frac > 0.5 {
-			n, err = io.WriteString(w, `▌`)
-			written += int64(n)
-			if err != nil {
-				return written, err
-			}
-		}
-
 		n, err = fmt.Fprintf(w, "\n")
 		written += int64(n)
 		if err != nil {
@@ -231,6 +408,32 @@ func (hist *Histogram) WriteTo(w io.Writer) (int64, error) {
 	return written, nil
 }
 
+// barWidths returns relative bar lengths in range [0, 1] for each bin.
+func (hist *Histogram) barWidths() []float64 {
+	widths := make([]float64, len(hist.Bins))
+	if !hist.LogScale {
+		for i, bin := range hist.Bins {
+			widths[i] = bin.Width
+		}
+		return widths
+	}
+
+	maxBin := 0
+	for _, bin := range hist.Bins {
+		if bin.Count > maxBin {
+			maxBin = bin.Count
+		}
+	}
+	if maxBin == 0 {
+		return widths
+	}
+
+	for i, bin := range hist.Bins {
+		widths[i] = math.Log1p(float64(bin.Count)) / math.Log1p(float64(maxBin))
+	}
+	return widths
+}
+
 // StringStats returns a string representation of the histogram stats.
 func (hist *Histogram) StringStats() string {
 	var buffer strings.Builder
@@ -0,0 +1,130 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// countLength returns the width of the widest bin count.
+func (hist *Histogram) countLength() int {
+	length := 3
+	for _, bin := range hist.Bins {
+		length = max(length, int(math.Ceil(math.Log10(float64(bin.Count+1)))))
+	}
+	return length
+}
+
+// bar returns a bar of relative length in range [0, 1] scaled to width characters.
+func (hist *Histogram) bar(length float64, width int) string {
+	full, half := "█", "▌"
+	if hist.ASCII {
+		full, half = "#", "+"
+	}
+
+	size := float64(width) * length
+	bar := strings.Repeat(full, int(size))
+	if size-math.Trunc(size) > 0.5 {
+		bar += half
+	}
+	return bar
+}
+
+// FitWidth sets Width such that lines written by WriteTo fit into columns characters,
+// e.g. the width of the terminal.
+func (hist *Histogram) FitWidth(columns int) {
+	// bin label, count in brackets and the spaces around them
+	prefix := 1 + 10 + 2 + hist.countLength() + 2
+	hist.Width = max(columns-prefix-1, 1)
+}
+
+// NewHistogramPair creates histograms of old and new durations with identical bins,
+// such that they can be compared with WriteOverlay.
+//
+// Bins are computed from the combined durations.
+func NewHistogramPair(old, new []time.Duration, opts *HistogramOptions) (*Histogram, *Histogram) {
+	combined := NewDurationHistogram(slices.Concat(old, new), opts)
+	return newHistogramWithBins(old, combined, opts), newHistogramWithBins(new, combined, opts)
+}
+
+// newHistogramWithBins creates a histogram of durations using bins of layout.
+func newHistogramWithBins(durations []time.Duration, layout *Histogram, opts *HistogramOptions) *Histogram {
+	hist := NewDurationHistogram(durations, opts)
+	hist.Bins = make([]HistogramBin, len(layout.Bins))
+	for i, bin := range layout.Bins {
+		hist.Bins[i] = HistogramBin{Start: bin.Start, andAbove: bin.andAbove}
+	}
+	for _, d := range durations {
+		x := float64(d.Nanoseconds())
+		k, _ := slices.BinarySearchFunc(hist.Bins[1:], x, func(bin HistogramBin, x float64) int {
+			if bin.Start <= x {
+				return -1
+			}
+			return 1
+		})
+		hist.Bins[k].Count++
+	}
+	hist.updateWidths()
+	return hist
+}
+
+// WriteOverlay writes old and new histograms side by side to w, for example
+// to compare results before and after a change in a terminal.
+//
+// Both histograms must have identical bins, see NewHistogramPair,
+// otherwise ErrIncompatibleHistogram is returned. Bars are scaled to the same
+// maximum count and drawn according to Width, LogScale and ASCII of old.
+func WriteOverlay(w io.Writer, old, new *Histogram) (int64, error) {
+	if len(old.Bins) != len(new.Bins) {
+		return 0, ErrIncompatibleHistogram
+	}
+	for i := range old.Bins {
+		if old.Bins[i].Start != new.Bins[i].Start {
+			return 0, ErrIncompatibleHistogram
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(" old:\n")
+	_, _ = old.WriteStatsTo(&b)
+	b.WriteString(" new:\n")
+	_, _ = new.WriteStatsTo(&b)
+
+	maxCount := 0
+	for i := range old.Bins {
+		maxCount = max(maxCount, old.Bins[i].Count, new.Bins[i].Count)
+	}
+	scale := func(count int) float64 {
+		switch {
+		case maxCount == 0:
+			return 0
+		case old.LogScale:
+			return math.Log1p(float64(count)) / math.Log1p(float64(maxCount))
+		default:
+			return float64(count) / float64(maxCount)
+		}
+	}
+
+	countLength := max(old.countLength(), new.countLength())
+	separator := " │ "
+	if old.ASCII {
+		separator = " | "
+	}
+	for i, bin := range old.Bins {
+		above := " "
+		if bin.andAbove || new.Bins[i].andAbove {
+			above = "+"
+		}
+		oldBar := old.bar(scale(bin.Count), old.Width)
+		fmt.Fprintf(&b, " %10v%s[%[3]*[4]v %[3]*[5]v] %s%s%s%s\n",
+			formatBin(bin.Start), above, countLength, bin.Count, new.Bins[i].Count,
+			oldBar, strings.Repeat(" ", old.Width+1-len([]rune(oldBar))), separator,
+			old.bar(scale(new.Bins[i].Count), old.Width))
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
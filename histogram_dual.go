@@ -0,0 +1,180 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DualHistogram shows the body and the tail of a distribution separately.
+//
+// A single binning either hides the tail or squeezes the body into a few bins,
+// hence body uses fine linear bins up to the split percentile and
+// tail uses logarithmic bins from the split to the maximum.
+type DualHistogram struct {
+	// Percentile used for splitting the data.
+	Percentile float64
+	// Split is the value in nanoseconds separating body and tail.
+	Split float64
+
+	Body *Histogram
+	Tail *Histogram
+}
+
+// NewDurationDualHistogram creates a dual histogram from time.Duration-s.
+func NewDurationDualHistogram(durations []time.Duration, binCount int, percentile float64) *DualHistogram {
+	nanos := make([]float64, len(durations))
+	for i, d := range durations {
+		nanos[i] = float64(d.Nanoseconds())
+	}
+	return NewDualHistogram(nanos, binCount, percentile)
+}
+
+// NewDualHistogram creates a dual histogram from the specified nanosecond values.
+//
+// Values up to percentile, e.g. 0.95, are in the body and the rest in the tail.
+// Body and tail use binCount bins each. Invalid values are skipped.
+func NewDualHistogram(nanoseconds []float64, binCount int, percentile float64) *DualHistogram {
+	if binCount <= 0 {
+		panic("binCount must be larger than 0")
+	}
+	if !(percentile > 0 && percentile < 1) {
+		panic("percentile must be in range (0, 1)")
+	}
+
+	values, _ := sanitizeValues(nanoseconds, InvalidSkip)
+	sort.Float64s(values)
+
+	dual := &DualHistogram{Percentile: percentile}
+	split := 0
+	if len(values) > 0 {
+		split = int(math.Round(percentile * float64(len(values))))
+		if split >= len(values) {
+			split = len(values) - 1
+		}
+		dual.Split = values[split]
+		// keep equal values in the body
+		for split < len(values) && values[split] <= dual.Split {
+			split++
+		}
+	}
+
+	bodyOpts := HistogramOptions{BinCount: binCount, NiceRange: true}
+	dual.Body = NewHistogram(values[:split], &bodyOpts)
+
+	tailOpts := HistogramOptions{BinCount: binCount}
+	dual.Tail = NewHistogram(values[split:], &tailOpts)
+	dual.Tail.rebinLog(values[split:])
+
+	return dual
+}
+
+// rebinLog replaces bins with logarithmically spaced bins of sorted values.
+func (hist *Histogram) rebinLog(sorted []float64) {
+	hist.rebinLogCounts(func(add func(x float64, count int)) {
+		for _, x := range sorted {
+			add(x, 1)
+		}
+	})
+}
+
+// rebinLogCounts replaces bins with logarithmically spaced bins of values
+// with counts, which are passed to add by each.
+func (hist *Histogram) rebinLogCounts(each func(add func(x float64, count int))) {
+	for i := range hist.Bins {
+		hist.Bins[i] = HistogramBin{}
+	}
+
+	low, high := math.Max(hist.Minimum, 1), math.Max(hist.Maximum, 1)
+	ratio := math.Pow(high/low, 1/float64(len(hist.Bins)))
+	if !(ratio > 1) || math.IsInf(ratio, 0) {
+		// all values are equal, use consecutive bins from minimum
+		for i := range hist.Bins {
+			hist.Bins[i].Start = hist.Minimum + float64(i)
+		}
+		each(func(x float64, count int) { hist.Bins[0].Count += count })
+	} else {
+		for i := range hist.Bins {
+			hist.Bins[i].Start = low * math.Pow(ratio, float64(i))
+		}
+		hist.Bins[0].Start = hist.Minimum
+
+		each(func(x float64, count int) {
+			k := 0
+			if x > low {
+				k = int(math.Log(x/low) / math.Log(ratio))
+			}
+			if k >= len(hist.Bins) {
+				k = len(hist.Bins) - 1
+			}
+			hist.Bins[k].Count += count
+		})
+	}
+
+	hist.updateWidths()
+}
+
+// updateWidths updates relative bar widths of bins.
+func (hist *Histogram) updateWidths() {
+	maxBin := 0
+	for _, bin := range hist.Bins {
+		if bin.Count > maxBin {
+			maxBin = bin.Count
+		}
+	}
+	for k := range hist.Bins {
+		hist.Bins[k].Width = 0
+		if maxBin > 0 {
+			hist.Bins[k].Width = float64(hist.Bins[k].Count) / float64(maxBin)
+		}
+	}
+}
+
+// WriteTo writes body and tail histograms to w.
+func (dual *DualHistogram) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	write := func(header string, hist *Histogram) error {
+		n, err := io.WriteString(w, header)
+		written += int64(n)
+		if err != nil {
+			return err
+		}
+		m, err := hist.WriteTo(w)
+		written += m
+		return err
+	}
+
+	if err := write(fmt.Sprintf(" body ≤ p%v (%v)\n", dual.Percentile*100, formatStat(dual.Split)), dual.Body); err != nil {
+		return written, err
+	}
+	if err := write(fmt.Sprintf(" tail > p%v, log bins\n", dual.Percentile*100), dual.Tail); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// String returns a string representation of body and tail histograms.
+func (dual *DualHistogram) String() string {
+	var buffer strings.Builder
+	_, _ = dual.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// DualHistogram creates body and tail histograms of all the laps split at p95.
+//
+// It creates binCount bins for both the body and the tail.
+func (bench *Benchmark) DualHistogram(binCount int) *DualHistogram {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().DualHistogram(binCount)
+}
+
+// DualHistogram creates body and tail histograms of all the laps split at p95.
+//
+// It creates binCount bins for both the body and the tail.
+func (bench *BenchmarkTSC) DualHistogram(binCount int) *DualHistogram {
+	bench = bench.mustBeCompleted()
+	return bench.Result().DualHistogram(binCount)
+}
@@ -0,0 +1,234 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// HDRHistogram is a high dynamic range histogram of nanosecond values.
+//
+// It uses logarithmic buckets that are linearly divided into sub-buckets,
+// which keeps the relative error of each value within the configured number
+// of significant decimal digits regardless of magnitude. Memory usage depends
+// on the range of values, not on the number of recorded values.
+//
+// HDRHistogram is not safe for concurrent use, however histograms recorded
+// by separate goroutines can be combined using Merge.
+type HDRHistogram struct {
+	digits   int
+	subBits  int
+	halfSize int
+
+	counts  []int64
+	total   int64
+	minimum int64
+	maximum int64
+}
+
+// NewHDRHistogram creates a histogram with significantDigits precision.
+//
+// significantDigits must be in range [1, 5].
+func NewHDRHistogram(significantDigits int) *HDRHistogram {
+	if significantDigits < 1 || significantDigits > 5 {
+		panic("significant digits must be in range [1, 5]")
+	}
+
+	// sub-buckets must resolve 1 part in 10^digits of the bucket start value
+	resolution := 2 * uint64(math.Pow10(significantDigits))
+	subBits := bits.Len64(resolution - 1)
+	return &HDRHistogram{
+		digits:   significantDigits,
+		subBits:  subBits,
+		halfSize: 1 << (subBits - 1),
+		minimum:  math.MaxInt64,
+	}
+}
+
+// SignificantDigits returns the precision of the histogram.
+func (hdr *HDRHistogram) SignificantDigits() int { return hdr.digits }
+
+// RelativeError returns the configured bound of relative error of
+// reported values, e.g. 0.01 for 2 significant digits.
+func (hdr *HDRHistogram) RelativeError() float64 { return math.Pow10(-hdr.digits) }
+
+// index returns the bucket index of value.
+func (hdr *HDRHistogram) index(value int64) int {
+	shift := bits.Len64(uint64(value)) - hdr.subBits
+	if shift < 0 {
+		shift = 0
+	}
+	return shift*hdr.halfSize + int(value>>shift)
+}
+
+// bucketRange returns the lowest and highest value in the bucket at index.
+func (hdr *HDRHistogram) bucketRange(index int) (low, high int64) {
+	if index < 2*hdr.halfSize {
+		return int64(index), int64(index)
+	}
+	shift := index/hdr.halfSize - 1
+	sub := int64(index - shift*hdr.halfSize)
+	low = sub << shift
+	return low, low + 1<<shift - 1
+}
+
+// Record adds a nanosecond value to the histogram.
+//
+// Negative values are recorded as 0.
+func (hdr *HDRHistogram) Record(value int64) { hdr.RecordN(value, 1) }
+
+// RecordN adds a nanosecond value count times to the histogram.
+func (hdr *HDRHistogram) RecordN(value int64, count int64) {
+	if count <= 0 {
+		return
+	}
+	if value < 0 {
+		value = 0
+	}
+
+	index := hdr.index(value)
+	if index >= len(hdr.counts) {
+		hdr.counts = append(hdr.counts, make([]int64, index+1-len(hdr.counts))...)
+	}
+	hdr.counts[index] += count
+	hdr.total += count
+	if value < hdr.minimum {
+		hdr.minimum = value
+	}
+	if value > hdr.maximum {
+		hdr.maximum = value
+	}
+}
+
+// RecordDuration adds a duration to the histogram.
+func (hdr *HDRHistogram) RecordDuration(d time.Duration) { hdr.Record(d.Nanoseconds()) }
+
+// Merge adds all values recorded in other to hdr.
+//
+// Both histograms must use the same number of significant digits.
+func (hdr *HDRHistogram) Merge(other *HDRHistogram) {
+	if hdr.digits != other.digits {
+		panic("merging histograms with different significant digits")
+	}
+	if len(other.counts) > len(hdr.counts) {
+		hdr.counts = append(hdr.counts, make([]int64, len(other.counts)-len(hdr.counts))...)
+	}
+	for i, count := range other.counts {
+		hdr.counts[i] += count
+	}
+	hdr.total += other.total
+	if other.minimum < hdr.minimum {
+		hdr.minimum = other.minimum
+	}
+	if other.maximum > hdr.maximum {
+		hdr.maximum = other.maximum
+	}
+}
+
+// Count returns the number of recorded values.
+func (hdr *HDRHistogram) Count() int64 { return hdr.total }
+
+// Min returns the smallest recorded value.
+func (hdr *HDRHistogram) Min() int64 {
+	if hdr.total == 0 {
+		return 0
+	}
+	return hdr.minimum
+}
+
+// Max returns the largest recorded value.
+func (hdr *HDRHistogram) Max() int64 { return hdr.maximum }
+
+// Mean returns the approximate mean of recorded values.
+func (hdr *HDRHistogram) Mean() float64 {
+	if hdr.total == 0 {
+		return 0
+	}
+	var total float64
+	for i, count := range hdr.counts {
+		if count == 0 {
+			continue
+		}
+		low, high := hdr.bucketRange(i)
+		total += float64(count) * (float64(low) + float64(high-low)/2)
+	}
+	return total / float64(hdr.total)
+}
+
+// ValueAtPercentile returns the value at q-th quantile, e.g. 0.999 for p99.9.
+//
+// The result is the highest value equivalent to the bucket containing the
+// quantile, hence it is within the configured precision of the true value.
+// q is clamped to range [0, 1].
+func (hdr *HDRHistogram) ValueAtPercentile(q float64) int64 {
+	_, high := hdr.PercentileBounds(q)
+	return high
+}
+
+// PercentileBounds returns the range of values equivalent to the bucket
+// containing q-th quantile, i.e. the true quantile is within [low, high].
+//
+// q is clamped to range [0, 1].
+func (hdr *HDRHistogram) PercentileBounds(q float64) (low, high int64) {
+	if hdr.total == 0 {
+		return 0, 0
+	}
+	if q <= 0 {
+		return hdr.minimum, hdr.minimum
+	}
+	if q >= 1 {
+		return hdr.maximum, hdr.maximum
+	}
+
+	target := int64(math.Ceil(q * float64(hdr.total)))
+	if target < 1 {
+		target = 1
+	}
+	var seen int64
+	for i, count := range hdr.counts {
+		seen += count
+		if seen >= target {
+			low, high = hdr.bucketRange(i)
+			return min(max(low, hdr.minimum), hdr.maximum), min(max(high, hdr.minimum), hdr.maximum)
+		}
+	}
+	return hdr.maximum, hdr.maximum
+}
+
+// DurationAtPercentile returns the duration at q-th quantile.
+func (hdr *HDRHistogram) DurationAtPercentile(q float64) time.Duration {
+	return time.Duration(hdr.ValueAtPercentile(q))
+}
+
+// DurationPercentileBounds returns the range of durations containing q-th quantile.
+func (hdr *HDRHistogram) DurationPercentileBounds(q float64) (low, high time.Duration) {
+	l, h := hdr.PercentileBounds(q)
+	return time.Duration(l), time.Duration(h)
+}
+
+// WriteTo writes percentiles with their relative error to w.
+func (hdr *HDRHistogram) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "  count %d;  min %v;  p50 %v;  max %v;\n  p90 %v;  p99 %v;  p999 %v;  p9999 %v;  error ±%.3g%%;\n",
+		hdr.total,
+		formatStat(float64(hdr.Min())),
+		formatStat(float64(hdr.ValueAtPercentile(0.5))),
+		formatStat(float64(hdr.Max())),
+
+		formatStat(float64(hdr.ValueAtPercentile(0.9))),
+		formatStat(float64(hdr.ValueAtPercentile(0.99))),
+		formatStat(float64(hdr.ValueAtPercentile(0.999))),
+		formatStat(float64(hdr.ValueAtPercentile(0.9999))),
+		hdr.RelativeError()*100,
+	)
+	return int64(n), err
+}
+
+// String returns percentiles as a string.
+func (hdr *HDRHistogram) String() string {
+	var buffer strings.Builder
+	_, _ = hdr.WriteTo(&buffer)
+	return buffer.String()
+}
@@ -0,0 +1,133 @@
+package hrtime_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestHDRHistogram(t *testing.T) {
+	hdr := hrtime.NewHDRHistogram(3)
+	for i := int64(1); i <= 1000; i++ {
+		hdr.Record(i * 1000)
+	}
+	hdr.Record(int64(time.Second))
+
+	check := func(q float64, expected int64) {
+		t.Helper()
+		got := hdr.ValueAtPercentile(q)
+		if math.Abs(float64(got-expected)) > float64(expected)/1000 {
+			t.Errorf("p%v: expected %d, got %d", q*100, expected, got)
+		}
+	}
+	check(0.5, 501000)
+	check(0.99, 991000)
+	check(1, int64(time.Second))
+	if hdr.Min() != 1000 || hdr.Count() != 1001 {
+		t.Errorf("invalid min %d or count %d", hdr.Min(), hdr.Count())
+	}
+
+	other := hrtime.NewHDRHistogram(3)
+	for i := 0; i < 1001; i++ {
+		other.Record(10)
+	}
+	hdr.Merge(other)
+	if hdr.Count() != 2002 || hdr.Min() != 10 {
+		t.Errorf("invalid merge: count %d min %d", hdr.Count(), hdr.Min())
+	}
+	if got := hdr.ValueAtPercentile(0.5); got != 10 {
+		t.Errorf("expected merged median 10, got %d", got)
+	}
+	t.Log("\n" + hdr.String())
+}
+
+func TestHistogramHDRBackend(t *testing.T) {
+	laps := []float64{}
+	for i := 0; i < 1000; i++ {
+		laps = append(laps, float64(100+i%10))
+	}
+	laps = append(laps, 5e6)
+
+	hist := hrtime.NewHistogram(laps, &hrtime.HistogramOptions{BinCount: 10, Backend: hrtime.HistogramHDR})
+	if hist.HDR == nil || hist.HDR.Count() != 1001 {
+		t.Fatalf("missing hdr histogram")
+	}
+	if hist.Bins[1].Start > 1000 {
+		t.Errorf("expected logarithmic bins resolving small values:\n%v", hist)
+	}
+	t.Log("\n" + hist.String())
+}
+
+func TestHistogramMerge(t *testing.T) {
+	opts := &hrtime.HistogramOptions{BinCount: 10, Backend: hrtime.HistogramHDR, SignificantDigits: 3}
+
+	var fast, slow []float64
+	for i := 0; i < 1000; i++ {
+		fast = append(fast, float64(1000+i))
+		slow = append(slow, float64(100000+i))
+	}
+	merged := hrtime.NewHistogram(nil, opts)
+	if err := merged.Merge(hrtime.NewHistogram(fast, opts)); err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Merge(hrtime.NewHistogram(slow, opts)); err != nil {
+		t.Fatal(err)
+	}
+	if merged.HDR.Count() != 2000 || merged.Minimum != 1000 || math.Abs(merged.P90-100800) > 100 {
+		t.Errorf("unexpected merge:\n%v", merged)
+	}
+
+	if err := merged.Subtract(hrtime.NewHistogram(slow, opts)); err != nil {
+		t.Fatal(err)
+	}
+	if merged.HDR.Count() != 1000 || merged.Maximum > 2000 {
+		t.Errorf("unexpected subtract:\n%v", merged)
+	}
+
+	merged.Scale(2)
+	if merged.HDR.Count() != 2000 {
+		t.Errorf("unexpected scale %d", merged.HDR.Count())
+	}
+
+	linear := hrtime.NewHistogram(fast, nil)
+	if err := linear.Merge(merged); err == nil {
+		t.Errorf("expected incompatible histograms")
+	}
+	if err := linear.Merge(hrtime.NewHistogram(fast, nil)); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, bin := range linear.Bins {
+		total += bin.Count
+	}
+	if total != 2000 || math.Abs(linear.P50-1500) > 100 {
+		t.Errorf("unexpected linear merge:\n%v", linear)
+	}
+	t.Log("\n" + merged.String())
+}
+
+func TestHDRHistogramErrorBounds(t *testing.T) {
+	hdr := hrtime.NewHDRHistogram(2)
+	if hdr.RelativeError() != 0.01 {
+		t.Errorf("expected relative error 0.01, got %v", hdr.RelativeError())
+	}
+	for i := int64(1); i <= 100000; i++ {
+		hdr.Record(i)
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		exact := int64(q * 100000)
+		low, high := hdr.PercentileBounds(q)
+		if exact < low || exact > high || hdr.ValueAtPercentile(q) != high {
+			t.Errorf("p%v: expected %d within [%d, %d]", q*100, exact, low, high)
+		}
+		if float64(high-low) > hdr.RelativeError()*float64(exact) {
+			t.Errorf("p%v: bounds [%d, %d] wider than relative error", q*100, low, high)
+		}
+	}
+	if !strings.Contains(hdr.String(), "±1%") {
+		t.Errorf("expected error bound in output:\n%s", hdr.String())
+	}
+}
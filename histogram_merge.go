@@ -0,0 +1,219 @@
+package hrtime
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrIncompatibleHistogram is returned when combining histograms
+// with different backends or bin layouts.
+var ErrIncompatibleHistogram = errors.New("incompatible histograms")
+
+// Subtract removes values recorded in other from hdr, e.g. to compute
+// the difference between two snapshots of the same histogram.
+//
+// It returns ErrIncompatibleHistogram when other contains values not in hdr.
+func (hdr *HDRHistogram) Subtract(other *HDRHistogram) error {
+	if hdr.digits != other.digits || len(other.counts) > len(hdr.counts) && !allZero(other.counts[len(hdr.counts):]) {
+		return ErrIncompatibleHistogram
+	}
+	for i, count := range other.counts {
+		if count > hdr.counts[i] {
+			return ErrIncompatibleHistogram
+		}
+	}
+	for i, count := range other.counts {
+		hdr.counts[i] -= count
+	}
+	hdr.total -= other.total
+	hdr.updateRange()
+	return nil
+}
+
+// Scale multiplies all counts by factor, rounding to the nearest integer.
+//
+// It's useful for weighting histograms, e.g. compensating for sampling.
+func (hdr *HDRHistogram) Scale(factor float64) {
+	if !(factor >= 0) || math.IsInf(factor, 0) {
+		panic("scale factor must be finite and non-negative")
+	}
+	hdr.total = 0
+	for i, count := range hdr.counts {
+		hdr.counts[i] = int64(math.Round(float64(count) * factor))
+		hdr.total += hdr.counts[i]
+	}
+	hdr.updateRange()
+}
+
+// updateRange recalculates minimum and maximum from non-empty buckets.
+func (hdr *HDRHistogram) updateRange() {
+	minimum, maximum := hdr.minimum, hdr.maximum
+	hdr.minimum, hdr.maximum = math.MaxInt64, 0
+	for i, count := range hdr.counts {
+		if count == 0 {
+			continue
+		}
+		low, high := hdr.bucketRange(i)
+		hdr.minimum = min(hdr.minimum, max(low, minimum))
+		hdr.maximum = max(hdr.maximum, min(high, maximum))
+	}
+}
+
+// allZero returns whether all counts are zero.
+func allZero(counts []int64) bool {
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge adds values of other to hist.
+//
+// Histograms using HistogramHDR backend are merged exactly and
+// their statistics are recalculated within the configured precision.
+// Otherwise both histograms must have the same bins, e.g. created using
+// the same HistogramClamp range, then bin counts are added and percentiles
+// are approximated from bins. It returns ErrIncompatibleHistogram when
+// histograms cannot be merged.
+func (hist *Histogram) Merge(other *Histogram) error {
+	return hist.combine(other, 1)
+}
+
+// Subtract removes values of other from hist, e.g. to compute the histogram
+// of a measurement window from two cumulative snapshots.
+//
+// The same restrictions apply as for Merge.
+func (hist *Histogram) Subtract(other *Histogram) error {
+	return hist.combine(other, -1)
+}
+
+// Scale multiplies all counts by factor.
+//
+// Percentiles and the average are not affected, except for rounding of counts.
+func (hist *Histogram) Scale(factor float64) {
+	if !(factor >= 0) || math.IsInf(factor, 0) {
+		panic("scale factor must be finite and non-negative")
+	}
+	if hist.HDR != nil {
+		hist.HDR.Scale(factor)
+		hist.updateFromHDR()
+		return
+	}
+	for i := range hist.Bins {
+		hist.Bins[i].Count = int(math.Round(float64(hist.Bins[i].Count) * factor))
+	}
+	hist.updateWidths()
+}
+
+// combine adds sign * other to hist.
+func (hist *Histogram) combine(other *Histogram, sign int) error {
+	if (hist.HDR == nil) != (other.HDR == nil) {
+		return ErrIncompatibleHistogram
+	}
+
+	if hist.HDR != nil {
+		if other.HDR.SignificantDigits() != hist.HDR.SignificantDigits() {
+			return ErrIncompatibleHistogram
+		}
+		if sign > 0 {
+			hist.HDR.Merge(other.HDR)
+		} else if err := hist.HDR.Subtract(other.HDR); err != nil {
+			return err
+		}
+		hist.Invalid += sign * other.Invalid
+		hist.updateFromHDR()
+		return nil
+	}
+
+	if len(hist.Bins) != len(other.Bins) {
+		return ErrIncompatibleHistogram
+	}
+	for i := range hist.Bins {
+		if hist.Bins[i].Start != other.Bins[i].Start && i > 0 {
+			return ErrIncompatibleHistogram
+		}
+		if hist.Bins[i].Count+sign*other.Bins[i].Count < 0 {
+			return ErrIncompatibleHistogram
+		}
+	}
+
+	count, otherCount := hist.count(), other.count()
+	for i := range hist.Bins {
+		hist.Bins[i].Count += sign * other.Bins[i].Count
+		hist.Bins[i].andAbove = hist.Bins[i].andAbove || other.Bins[i].andAbove
+	}
+	total := count + sign*otherCount
+	if total > 0 {
+		hist.Average = (hist.Average*float64(count) + float64(sign)*other.Average*float64(otherCount)) / float64(total)
+	} else {
+		hist.Average = 0
+	}
+	if sign > 0 {
+		if count == 0 || other.Minimum < hist.Minimum {
+			hist.Minimum = other.Minimum
+			hist.Bins[0].Start = other.Minimum
+		}
+		hist.Maximum = math.Max(hist.Maximum, other.Maximum)
+	}
+	hist.Invalid += sign * other.Invalid
+
+	hist.P50, hist.P90, hist.P99, hist.P999, hist.P9999 =
+		hist.binQuantile(0.5), hist.binQuantile(0.9), hist.binQuantile(0.99), hist.binQuantile(0.999), hist.binQuantile(0.9999)
+	hist.updateWidths()
+	return nil
+}
+
+// count returns the number of values in bins.
+func (hist *Histogram) count() int {
+	total := 0
+	for _, bin := range hist.Bins {
+		total += bin.Count
+	}
+	return total
+}
+
+// binQuantile approximates q-th quantile by interpolating within bins.
+func (hist *Histogram) binQuantile(q float64) float64 {
+	total := hist.count()
+	if total == 0 {
+		return 0
+	}
+
+	target := q * float64(total)
+	seen := 0.0
+	for i, bin := range hist.Bins {
+		if bin.Count == 0 || seen+float64(bin.Count) < target {
+			seen += float64(bin.Count)
+			continue
+		}
+		end := hist.Maximum
+		if i+1 < len(hist.Bins) && !bin.andAbove {
+			end = math.Min(hist.Bins[i+1].Start, hist.Maximum)
+		}
+		frac := (target - seen) / float64(bin.Count)
+		return bin.Start + frac*(end-bin.Start)
+	}
+	return hist.Maximum
+}
+
+// updateFromHDR recalculates statistics and bins from the HDR histogram.
+func (hist *Histogram) updateFromHDR() {
+	hdr := hist.HDR
+	hist.Minimum = float64(hdr.Min())
+	hist.Maximum = float64(hdr.Max())
+	hist.Average = hdr.Mean()
+
+	at := func(q float64) float64 { return float64(hdr.ValueAtPercentile(q)) }
+	hist.P50, hist.P90, hist.P99, hist.P999, hist.P9999 = at(0.50), at(0.90), at(0.99), at(0.999), at(0.9999)
+
+	hist.rebinLogCounts(func(add func(x float64, count int)) {
+		for i, count := range hdr.counts {
+			if count > 0 {
+				low, _ := hdr.bucketRange(i)
+				add(math.Max(float64(low), hist.Minimum), int(count))
+			}
+		}
+	})
+}
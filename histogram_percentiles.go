@@ -0,0 +1,53 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// WritePercentilesTo writes p50, p90, p99, p999 and maximum as bars on a logarithmic time axis to w.
+//
+// It's a compact alternative to WriteTo for comparing many histograms.
+func (hist *Histogram) WritePercentilesTo(w io.Writer) (int64, error) {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"p50", hist.P50},
+		{"p90", hist.P90},
+		{"p99", hist.P99},
+		{"p999", hist.P999},
+		{"max", hist.Maximum},
+	}
+
+	// axis starts at the power of ten below minimum
+	low := 1.0
+	if hist.Minimum > 1 {
+		low = math.Pow(10, math.Floor(math.Log10(hist.Minimum)))
+	}
+	span := math.Log(hist.Maximum / low)
+
+	var written int64
+	for _, v := range values {
+		width := 0.0
+		if span > 0 && v.value > low {
+			width = float64(hist.Width) * math.Log(v.value/low) / span
+		}
+
+		n, err := fmt.Fprintf(w, " %4s %10v |%s\n", v.name, formatStat(v.value), strings.Repeat("█", int(math.Round(width))))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// StringPercentiles returns percentiles as bars on a logarithmic time axis.
+func (hist *Histogram) StringPercentiles() string {
+	var buffer strings.Builder
+	_, _ = hist.WritePercentilesTo(&buffer)
+	return buffer.String()
+}
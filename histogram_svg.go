@@ -0,0 +1,54 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteSVG writes histogram as a horizontal bar chart in SVG format to w.
+//
+// Bar lengths follow the same scaling as WriteTo, including LogScale.
+func (hist *Histogram) WriteSVG(w io.Writer) (int64, error) {
+	const (
+		rowHeight  = 20
+		labelWidth = 140
+		barWidth   = 400
+	)
+
+	height := rowHeight * len(hist.Bins)
+	width := labelWidth + barWidth + 10
+
+	var written int64
+	n, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", width, height)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	widths := hist.barWidths()
+	for i, bin := range hist.Bins {
+		label := formatBin(bin.Start).String()
+		if bin.andAbove {
+			label += "+"
+		}
+
+		y := i * rowHeight
+		n, err = fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="end">%s [%d]</text>`+"\n",
+			labelWidth-5, y+rowHeight-6, label, bin.Count)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		n, err = fmt.Fprintf(w, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="#4c78a8"><title>%s: %d laps</title></rect>`+"\n",
+			labelWidth, y+2, widths[i]*barWidth, rowHeight-4, label, bin.Count)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	n, err = io.WriteString(w, "</svg>\n")
+	written += int64(n)
+	return written, err
+}
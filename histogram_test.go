@@ -0,0 +1,203 @@
+package hrtime_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestHistogramLogScale(t *testing.T) {
+	laps := make([]time.Duration, 0, 10001)
+	for i := 0; i < 10000; i++ {
+		laps = append(laps, time.Microsecond)
+	}
+	laps = append(laps, 10*time.Microsecond)
+
+	opts := hrtime.HistogramOptions{BinCount: 10, NiceRange: true}
+	hist := hrtime.NewDurationHistogram(laps, &opts)
+
+	linear := hist.String()
+	hist.LogScale = true
+	logscale := hist.String()
+
+	if strings.Count(logscale, "█") <= strings.Count(linear, "█") {
+		t.Errorf("expected log scale to show tail bins:\n%s\n%s", linear, logscale)
+	}
+	t.Log("\n" + logscale)
+
+	var svg strings.Builder
+	if _, err := hist.WriteSVG(&svg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(svg.String(), "<svg") {
+		t.Errorf("invalid svg: %s", svg.String())
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	laps := make([]time.Duration, 0, 1000)
+	for i := 1; i <= 1000; i++ {
+		laps = append(laps, time.Duration(i*i)*time.Nanosecond)
+	}
+
+	opts := hrtime.HistogramOptions{BinCount: 10, NiceRange: true}
+	hist := hrtime.NewDurationHistogram(laps, &opts)
+
+	out := hist.StringPercentiles()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got:\n%s", out)
+	}
+	if !strings.HasSuffix(lines[4], strings.Repeat("█", hist.Width)) {
+		t.Errorf("maximum should use full width:\n%s", out)
+	}
+	t.Log("\n" + out)
+}
+
+func TestHistogramInvalid(t *testing.T) {
+	values := []float64{math.NaN(), -5, 10, 20, math.Inf(1)}
+
+	skip := hrtime.NewHistogram(values, &hrtime.HistogramOptions{BinCount: 4})
+	if skip.Invalid != 3 || skip.Minimum != 10 || skip.Maximum != 20 {
+		t.Errorf("unexpected skip histogram %+v", skip)
+	}
+
+	clamp := hrtime.NewHistogram(values, &hrtime.HistogramOptions{BinCount: 4, Invalid: hrtime.InvalidClamp})
+	if clamp.Invalid != 3 || clamp.Minimum != 0 || clamp.Maximum != 20 {
+		t.Errorf("unexpected clamp histogram %+v", clamp)
+	}
+
+	_, err := hrtime.NewHistogramChecked(values, &hrtime.HistogramOptions{BinCount: 4, Invalid: hrtime.InvalidError})
+	if !errors.Is(err, hrtime.ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+
+	if _, err := hrtime.NewHistogramChecked(values, &hrtime.HistogramOptions{}); !errors.Is(err, hrtime.ErrInvalidBinCount) {
+		t.Errorf("expected ErrInvalidBinCount, got %v", err)
+	}
+}
+
+func FuzzNewHistogram(f *testing.F) {
+	f.Add(1.0, 2.0, 3.0, 4, true)
+	f.Add(math.NaN(), math.Inf(1), -1.0, 1, false)
+	f.Add(5.0, 5.0, 5.0, 3, true)
+	f.Fuzz(func(t *testing.T, a, b, c float64, binCount int, nice bool) {
+		if binCount <= 0 || binCount > 1000 {
+			return
+		}
+		for _, policy := range []hrtime.InvalidPolicy{hrtime.InvalidSkip, hrtime.InvalidClamp} {
+			hist := hrtime.NewHistogram([]float64{a, b, c}, &hrtime.HistogramOptions{
+				BinCount:        binCount,
+				NiceRange:       nice,
+				ClampPercentile: 0.999,
+				Invalid:         policy,
+			})
+			total := 0
+			for _, bin := range hist.Bins {
+				if math.IsNaN(bin.Start) || math.IsNaN(bin.Width) {
+					t.Fatalf("NaN bin %+v", bin)
+				}
+				total += bin.Count
+			}
+			if expected := 3 - hist.Invalid; policy == hrtime.InvalidSkip && total != expected {
+				t.Fatalf("expected %d values in bins, got %d", expected, total)
+			}
+			_ = hist.String()
+		}
+	})
+}
+
+func TestHistogramClampPercentile(t *testing.T) {
+	bench := hrtime.NewBenchmark(100)
+	for i := 0; bench.Next(); i++ {
+		if i == 50 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	hist := bench.HistogramClampPercentile(10, 0, 0.9)
+	last := hist.Bins[len(hist.Bins)-1]
+	if last.Start >= float64(5*time.Millisecond) {
+		t.Errorf("outlier determined the range:\n%v", hist)
+	}
+	if hist.Maximum < float64(5*time.Millisecond) {
+		t.Errorf("outlier missing from maximum: %v", hist.Maximum)
+	}
+}
+
+func TestDualHistogram(t *testing.T) {
+	laps := make([]time.Duration, 0, 1000)
+	for i := 0; i < 990; i++ {
+		laps = append(laps, time.Duration(1000+i)*time.Nanosecond)
+	}
+	for i := 0; i < 10; i++ {
+		laps = append(laps, time.Duration(i+1)*time.Millisecond)
+	}
+
+	dual := hrtime.NewDurationDualHistogram(laps, 10, 0.95)
+	body, tail := 0, 0
+	for _, bin := range dual.Body.Bins {
+		body += bin.Count
+	}
+	for _, bin := range dual.Tail.Bins {
+		tail += bin.Count
+	}
+	if body+tail != len(laps) || tail < 10 {
+		t.Errorf("invalid split %d + %d:\n%v", body, tail, dual)
+	}
+	if dual.Body.Maximum > float64(2*time.Microsecond) {
+		t.Errorf("tail leaked into body:\n%v", dual)
+	}
+
+	// log bins grow geometrically
+	bins := dual.Tail.Bins
+	if bins[2].Start-bins[1].Start >= bins[9].Start-bins[8].Start {
+		t.Errorf("expected logarithmic tail bins:\n%v", dual)
+	}
+	t.Log("\n" + dual.String())
+
+	empty := hrtime.NewDualHistogram(nil, 10, 0.95)
+	if empty.String() == "" {
+		t.Errorf("expected output for empty histogram")
+	}
+}
+
+func TestHistogramChart(t *testing.T) {
+	var old, new []time.Duration
+	for i := 0; i < 1000; i++ {
+		old = append(old, time.Duration(1000+i%100)*time.Nanosecond)
+		new = append(new, time.Duration(1050+i%100)*time.Nanosecond)
+	}
+
+	hist := hrtime.NewDurationHistogram(old, &hrtime.HistogramOptions{BinCount: 5, LogBins: true})
+	hist.ASCII = true
+	hist.FitWidth(60)
+	output := hist.String()
+	if strings.Contains(output, "█") || !strings.Contains(output, "#") {
+		t.Errorf("expected ASCII bars:\n%s", output)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if len(line) > 60 {
+			t.Errorf("line %q longer than 60 columns", line)
+		}
+	}
+
+	a, b := hrtime.NewHistogramPair(old, new, &hrtime.HistogramOptions{BinCount: 6, NiceRange: true})
+	var overlay strings.Builder
+	if _, err := hrtime.WriteOverlay(&overlay, a, b); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + overlay.String())
+	if !strings.Contains(overlay.String(), " old:") || strings.Count(overlay.String(), "│") != 6 {
+		t.Errorf("invalid overlay:\n%s", overlay.String())
+	}
+
+	other := hrtime.NewDurationHistogram(new, &hrtime.HistogramOptions{BinCount: 3})
+	if _, err := hrtime.WriteOverlay(&overlay, a, other); !errors.Is(err, hrtime.ErrIncompatibleHistogram) {
+		t.Errorf("expected ErrIncompatibleHistogram, got %v", err)
+	}
+}
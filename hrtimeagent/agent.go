@@ -0,0 +1,129 @@
+// Package hrtimeagent implements a minimal continuous benchmarking setup.
+//
+// Agent runs a suite and posts results to a collector, Collector is an
+// http.Handler that stores and merges results from multiple agents.
+package hrtimeagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimepb"
+)
+
+// Content types accepted by Collector.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Agent runs benchmarks and posts results to URL.
+type Agent struct {
+	// URL is the collector endpoint.
+	URL string
+	// Client is used for posting, http.DefaultClient is used when nil.
+	Client *http.Client
+	// Protobuf selects protobuf encoding instead of JSON.
+	Protobuf bool
+	// Metadata is attached to every result.
+	Metadata map[string]string
+}
+
+// Run runs suite and posts results to the collector.
+//
+// When the suite is canceled, results of completed benchmarks are still posted.
+func (agent *Agent) Run(ctx context.Context, suite *hrtime.Suite, opts ...hrtime.SuiteOption) error {
+	suiteResults, runErr := suite.Run(ctx, opts...)
+	if len(suiteResults) == 0 {
+		return runErr
+	}
+
+	env := hrtime.CaptureEnv()
+	results := make([]*hrtime.JSONResult, 0, len(suiteResults))
+	for _, suiteResult := range suiteResults {
+		result := hrtime.NewJSONResult(suiteResult.Name, suiteResult.Benchmark.Laps())
+		result.Environment = env
+		result.Metadata = mergeMetadata(agent.Metadata, suiteResult.Metadata)
+		results = append(results, result)
+	}
+
+	// use a fresh context for posting, when the suite was canceled
+	postCtx := ctx
+	if runErr != nil {
+		postCtx = context.WithoutCancel(ctx)
+	}
+	if err := agent.Post(postCtx, results); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// Post posts results to the collector.
+func (agent *Agent) Post(ctx context.Context, results []*hrtime.JSONResult) error {
+	// stamp copies to leave results of the caller unchanged
+	stamped := make([]*hrtime.JSONResult, len(results))
+	for i, result := range results {
+		copied := *result
+		copied.SchemaVersion = hrtime.SchemaVersion
+		stamped[i] = &copied
+	}
+
+	var body []byte
+	contentType := ContentTypeJSON
+	if agent.Protobuf {
+		contentType = ContentTypeProtobuf
+		var pb hrtimepb.Results
+		for _, result := range stamped {
+			pb.Results = append(pb.Results, hrtimepb.FromJSONResult(result))
+		}
+		body = pb.Marshal()
+	} else {
+		var err error
+		body, err = json.Marshal(stamped)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := agent.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// mergeMetadata combines agent metadata with metadata of a suite result.
+func mergeMetadata(agent, suite map[string]string) map[string]string {
+	if len(suite) == 0 {
+		return agent
+	}
+	merged := make(map[string]string, len(agent)+len(suite))
+	for key, value := range suite {
+		merged[key] = value
+	}
+	for key, value := range agent {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,71 @@
+package hrtimeagent_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimeagent"
+)
+
+func TestAgentCollector(t *testing.T) {
+	collector := hrtimeagent.NewCollector()
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	suite := hrtime.NewSuite()
+	suite.Add("empty", 8, func() {})
+
+	for _, protobuf := range []bool{false, true} {
+		agent := &hrtimeagent.Agent{
+			URL:      server.URL,
+			Protobuf: protobuf,
+			Metadata: map[string]string{"host": "test"},
+		}
+		if err := agent.Run(context.Background(), suite); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results := collector.Results()
+	if len(results) != 1 {
+		t.Fatalf("expected one merged result, got %d", len(results))
+	}
+	if results[0].Count != 16 || results[0].Metadata["host"] != "test" {
+		t.Errorf("unexpected merged result %+v", results[0])
+	}
+
+	for _, protobuf := range []bool{false, true} {
+		unversioned := &hrtime.JSONResult{Name: "unversioned", Count: 1}
+		agent := &hrtimeagent.Agent{URL: server.URL, Protobuf: protobuf}
+		if err := agent.Post(context.Background(), []*hrtime.JSONResult{unversioned}); err != nil {
+			t.Fatal(err)
+		}
+		if unversioned.SchemaVersion != 0 {
+			t.Errorf("Post modified the result of the caller: %+v", unversioned)
+		}
+	}
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %v", resp.Status)
+	}
+}
+
+func TestCollectorRejectsInvalid(t *testing.T) {
+	collector := hrtimeagent.NewCollector()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[{"schemaVersion": 99}]`))
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request, got %v", rec.Code)
+	}
+}
@@ -0,0 +1,143 @@
+package hrtimeagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimepb"
+)
+
+// MaxBodySize is the maximum accepted request size by Collector.
+const MaxBodySize = 64 << 20
+
+// Collector stores results posted by agents.
+//
+// Results with the same name are merged by combining their laps.
+// POST accepts JSON or protobuf results, GET responds with merged results as JSON.
+type Collector struct {
+	mu      sync.Mutex
+	results map[string]*hrtime.JSONResult
+}
+
+// NewCollector creates an empty collector.
+func NewCollector() *Collector {
+	return &Collector{results: map[string]*hrtime.JSONResult{}}
+}
+
+// Add merges results into the collector.
+func (collector *Collector) Add(results ...*hrtime.JSONResult) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	for _, result := range results {
+		existing, ok := collector.results[result.Name]
+		if !ok {
+			merged := hrtime.NewJSONResult(result.Name, result.Durations())
+			merged.Environment = result.Environment
+			merged.Metadata = copyMetadata(nil, result.Metadata)
+			collector.results[result.Name] = merged
+			continue
+		}
+
+		laps := append(existing.Durations(), result.Durations()...)
+		merged := hrtime.NewJSONResult(result.Name, laps)
+		merged.Environment = result.Environment
+		merged.Metadata = copyMetadata(existing.Metadata, result.Metadata)
+		collector.results[result.Name] = merged
+	}
+}
+
+// Results returns merged results sorted by name.
+func (collector *Collector) Results() []*hrtime.JSONResult {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	results := make([]*hrtime.JSONResult, 0, len(collector.results))
+	for _, result := range collector.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, k int) bool {
+		return results[i].Name < results[k].Name
+	})
+	return results
+}
+
+// ServeHTTP implements http.Handler.
+func (collector *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(collector.Results())
+	case http.MethodPost:
+		results, err := decodeResults(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		collector.Add(results...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeResults decodes results from request body based on content type.
+func decodeResults(r *http.Request) ([]*hrtime.JSONResult, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > MaxBodySize {
+		return nil, fmt.Errorf("request body exceeds %d bytes", MaxBodySize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var results []*hrtime.JSONResult
+	switch mediaType {
+	case ContentTypeProtobuf:
+		var pb hrtimepb.Results
+		if err := pb.Unmarshal(body); err != nil {
+			return nil, err
+		}
+		for _, result := range pb.Results {
+			results = append(results, result.JSONResult())
+		}
+	case ContentTypeJSON, "":
+		if err := json.Unmarshal(body, &results); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	for _, result := range results {
+		if result.SchemaVersion <= 0 || result.SchemaVersion > hrtime.SchemaVersion {
+			return nil, fmt.Errorf("%w: %d", hrtime.ErrUnsupportedSchema, result.SchemaVersion)
+		}
+	}
+	return results, nil
+}
+
+// copyMetadata returns a new map containing values from base and extra.
+func copyMetadata(base, extra map[string]string) map[string]string {
+	if len(base) == 0 && len(extra) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(extra))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,119 @@
+package hrtimeagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/loov/hrtime"
+)
+
+// WebhookFormat specifies the payload format of Notifier.
+type WebhookFormat int
+
+const (
+	// WebhookGeneric posts comparisons as structured JSON.
+	WebhookGeneric WebhookFormat = iota
+	// WebhookSlack posts a Slack incoming webhook message.
+	WebhookSlack
+	// WebhookTeams posts a Microsoft Teams incoming webhook message.
+	WebhookTeams
+)
+
+// Notifier posts a summary to a webhook when comparisons exceed Threshold.
+type Notifier struct {
+	// URL is the webhook endpoint.
+	URL string
+	// Format specifies the payload format.
+	Format WebhookFormat
+	// Threshold is the relative slowdown considered a regression, e.g. 0.05.
+	Threshold float64
+	// Client is used for posting, http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+// genericRegression is the payload entry for WebhookGeneric.
+type genericRegression struct {
+	Name   string  `json:"name"`
+	Metric string  `json:"metric"`
+	Old    int64   `json:"old"`
+	New    int64   `json:"new"`
+	Change float64 `json:"change"`
+}
+
+// Notify posts a summary of regressions in comparisons.
+//
+// It returns whether any regressions were found. When there are none,
+// nothing is posted.
+func (notifier *Notifier) Notify(ctx context.Context, comparisons ...*hrtime.Comparison) (bool, error) {
+	var summary strings.Builder
+	var generic []genericRegression
+	for _, comparison := range comparisons {
+		regressions := comparison.Regressions(notifier.Threshold)
+		if len(regressions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&summary, "%s regressed:\n", comparison.Name)
+		for _, delta := range regressions {
+			fmt.Fprintf(&summary, "  %s %v -> %v (%+.1f%%)\n", delta.Metric, delta.Old, delta.New, delta.Change*100)
+			generic = append(generic, genericRegression{
+				Name:   comparison.Name,
+				Metric: delta.Metric,
+				Old:    delta.Old.Nanoseconds(),
+				New:    delta.New.Nanoseconds(),
+				Change: delta.Change,
+			})
+		}
+	}
+	if len(generic) == 0 {
+		return false, nil
+	}
+
+	var payload interface{}
+	switch notifier.Format {
+	case WebhookSlack:
+		payload = map[string]string{"text": "```\n" + summary.String() + "```"}
+	case WebhookTeams:
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "Benchmark regression",
+			"text":     "<pre>" + summary.String() + "</pre>",
+		}
+	default:
+		payload = map[string]interface{}{
+			"summary":     summary.String(),
+			"regressions": generic,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return true, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifier.URL, bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	client := notifier.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return true, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return true, nil
+}
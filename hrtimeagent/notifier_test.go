@@ -0,0 +1,40 @@
+package hrtimeagent_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimeagent"
+)
+
+func TestNotifierSlack(t *testing.T) {
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		text = payload["text"]
+	}))
+	defer server.Close()
+
+	notifier := &hrtimeagent.Notifier{URL: server.URL, Format: hrtimeagent.WebhookSlack, Threshold: 0.1}
+
+	same := hrtime.CompareLaps("same", []time.Duration{100, 100}, []time.Duration{100, 100})
+	if notified, err := notifier.Notify(context.Background(), same); notified || err != nil {
+		t.Fatalf("unexpected notification %v %v", notified, err)
+	}
+
+	slower := hrtime.CompareLaps("slower", []time.Duration{100, 100}, []time.Duration{200, 200})
+	notified, err := notifier.Notify(context.Background(), same, slower)
+	if !notified || err != nil {
+		t.Fatalf("expected notification %v %v", notified, err)
+	}
+	if !strings.Contains(text, "slower regressed") || strings.Contains(text, "same") {
+		t.Errorf("unexpected text %q", text)
+	}
+}
@@ -0,0 +1,128 @@
+// Package hrtimebenchfmt converts hrtime results to and from
+// golang.org/x/perf/benchfmt results.
+//
+// This allows using benchstat, benchseries and other upstream tooling
+// with hrtime measurements. It is a separate module to keep hrtime
+// free of dependencies.
+package hrtimebenchfmt
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/loov/hrtime"
+	"golang.org/x/perf/benchfmt"
+)
+
+// Unit is the benchfmt unit used for lap durations.
+const Unit = "sec/op"
+
+// ToResults converts result into benchfmt results.
+//
+// Every lap becomes a single benchfmt result with one iteration, which
+// lets benchstat see the whole distribution instead of a single mean.
+// Environment and metadata are included as file configuration.
+func ToResults(result *hrtime.JSONResult) []*benchfmt.Result {
+	var config []benchfmt.Config
+	addConfig := func(key, value string) {
+		if value != "" {
+			config = append(config, benchfmt.Config{Key: key, Value: []byte(value), File: true})
+		}
+	}
+	if env := result.Environment; env != nil {
+		addConfig("goos", env.GOOS)
+		addConfig("goarch", env.GOARCH)
+		addConfig("hostname", env.Hostname)
+	}
+
+	keys := make([]string, 0, len(result.Metadata))
+	for key := range result.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		addConfig(key, result.Metadata[key])
+	}
+
+	results := make([]*benchfmt.Result, 0, len(result.Laps))
+	for _, lap := range result.Laps {
+		results = append(results, &benchfmt.Result{
+			Config: append([]benchfmt.Config(nil), config...),
+			Name:   benchfmt.Name(result.Name),
+			Iters:  1,
+			Values: []benchfmt.Value{{
+				Value: time.Duration(lap).Seconds(),
+				Unit:  Unit,
+			}},
+		})
+	}
+	return results
+}
+
+// FromResults converts benchfmt results into hrtime results.
+//
+// Results with the same name are combined, each result becomes a single lap.
+// Results without a sec/op value are ignored.
+func FromResults(results []*benchfmt.Result) []*hrtime.JSONResult {
+	var names []string
+	laps := map[string][]time.Duration{}
+	metadata := map[string]map[string]string{}
+
+	for _, result := range results {
+		seconds, ok := result.Value(Unit)
+		if !ok {
+			continue
+		}
+		name := result.Name.String()
+		if _, exists := laps[name]; !exists {
+			names = append(names, name)
+			metadata[name] = map[string]string{}
+		}
+		laps[name] = append(laps[name], time.Duration(seconds*float64(time.Second)))
+		for _, config := range result.Config {
+			metadata[name][config.Key] = string(config.Value)
+		}
+	}
+
+	converted := make([]*hrtime.JSONResult, 0, len(names))
+	for _, name := range names {
+		result := hrtime.NewJSONResult(name, laps[name])
+		if len(metadata[name]) > 0 {
+			result.Metadata = metadata[name]
+		}
+		converted = append(converted, result)
+	}
+	return converted
+}
+
+// Write writes results in Go benchmark format to w.
+func Write(w io.Writer, results ...*hrtime.JSONResult) error {
+	writer := benchfmt.NewWriter(w)
+	for _, result := range results {
+		for _, converted := range ToResults(result) {
+			if err := writer.Write(converted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Read reads results in Go benchmark format from r.
+//
+// Syntax errors are skipped, similarly to benchstat.
+func Read(r io.Reader, fileName string) ([]*hrtime.JSONResult, error) {
+	reader := benchfmt.NewReader(r, fileName)
+
+	var results []*benchfmt.Result
+	for reader.Scan() {
+		if result, ok := reader.Result().(*benchfmt.Result); ok {
+			results = append(results, result.Clone())
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return FromResults(results), nil
+}
@@ -0,0 +1,35 @@
+package hrtimebenchfmt_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimebenchfmt"
+)
+
+func TestRoundtrip(t *testing.T) {
+	result := hrtime.NewJSONResult("Sleep", []time.Duration{1000, 2000, 3000})
+	result.Environment = &hrtime.Environment{GOOS: "linux", GOARCH: "amd64"}
+	result.Metadata = map[string]string{"commit": "abc"}
+
+	var out strings.Builder
+	if err := hrtimebenchfmt.Write(&out, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "BenchmarkSleep") || !strings.Contains(out.String(), "commit: abc") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	results, err := hrtimebenchfmt.Read(strings.NewReader(out.String()), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Count != 3 || results[0].Stats.P50 != 2000 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Metadata["goos"] != "linux" || results[0].Metadata["commit"] != "abc" {
+		t.Errorf("unexpected metadata %v", results[0].Metadata)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/loov/hrtime/hrtimebenchfmt
+
+go 1.26.0
+
+require (
+	github.com/loov/hrtime v0.0.0-20261014192712-089936a860fb
+	golang.org/x/perf v0.0.0-20260908200009-22c9c6c9d4da
+)
+
+require github.com/aclements/go-moremath v0.0.0-20210112150236-f10218a38794 // indirect
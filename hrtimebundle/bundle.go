@@ -0,0 +1,272 @@
+// Package hrtimebundle implements a single file format for complete
+// measurement sessions.
+//
+// A bundle is a zip archive containing:
+//
+//	manifest.json         format version, creation time and metadata
+//	environment.json      environment capture, when present
+//	results.json          results as an array of hrtime.JSONResult
+//	annotations.json      annotations, when present
+//	laps/NNN-name.csv     raw laps of every result in nanoseconds
+//
+// The CSV files are for inspection with other tools, Read only uses
+// results.json.
+package hrtimebundle
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// Version is the version of the bundle format.
+const Version = 1
+
+// ErrUnsupportedVersion is returned when reading a bundle with an unknown version.
+var ErrUnsupportedVersion = errors.New("unsupported bundle version")
+
+// Names of files in the bundle.
+const (
+	manifestFile    = "manifest.json"
+	environmentFile = "environment.json"
+	resultsFile     = "results.json"
+	annotationsFile = "annotations.json"
+	lapsDir         = "laps/"
+)
+
+// Bundle is a complete measurement session.
+type Bundle struct {
+	// Created is the creation time of the bundle.
+	Created time.Time
+	// Environment describes where the session was measured.
+	Environment *hrtime.Environment
+	// Results contains measured results.
+	Results []*hrtime.JSONResult
+	// Annotations contains events during the session.
+	Annotations []hrtime.Annotation
+	// Metadata contains user specified key-value pairs.
+	Metadata map[string]string
+}
+
+// manifest is the content of manifest.json.
+type manifest struct {
+	Version  int               `json:"version"`
+	Created  time.Time         `json:"created"`
+	Results  int               `json:"results"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// New creates an empty bundle with the current environment.
+func New() *Bundle {
+	return &Bundle{
+		Created:     time.Now().UTC(),
+		Environment: hrtime.CaptureEnv(),
+	}
+}
+
+// Add adds named laps as a result.
+func (bundle *Bundle) Add(name string, laps []time.Duration) *hrtime.JSONResult {
+	result := hrtime.NewJSONResult(name, laps)
+	bundle.Results = append(bundle.Results, result)
+	return result
+}
+
+// AddBenchmark adds a completed benchmark as a result,
+// including its sources and metadata, see Benchmark.JSONResult.
+func (bundle *Bundle) AddBenchmark(name string, bench *hrtime.Benchmark) *hrtime.JSONResult {
+	result := bench.JSONResult(name)
+	bundle.Results = append(bundle.Results, result)
+	return result
+}
+
+// Annotate records an event at the current time.
+func (bundle *Bundle) Annotate(text string) {
+	bundle.Annotations = append(bundle.Annotations, hrtime.Annotation{Time: time.Now(), Text: text})
+}
+
+// Write writes bundle to w as a zip archive.
+func Write(w io.Writer, bundle *Bundle) error {
+	archive := zip.NewWriter(w)
+
+	err := writeJSON(archive, manifestFile, manifest{
+		Version:  Version,
+		Created:  bundle.Created,
+		Results:  len(bundle.Results),
+		Metadata: bundle.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+	if bundle.Environment != nil {
+		if err := writeJSON(archive, environmentFile, bundle.Environment); err != nil {
+			return err
+		}
+	}
+
+	// stamp copies to leave results of the bundle unchanged
+	results := make([]*hrtime.JSONResult, len(bundle.Results))
+	for i, result := range bundle.Results {
+		copied := *result
+		copied.SchemaVersion = hrtime.SchemaVersion
+		results[i] = &copied
+	}
+	if err := writeJSON(archive, resultsFile, results); err != nil {
+		return err
+	}
+	if len(bundle.Annotations) > 0 {
+		if err := writeJSON(archive, annotationsFile, bundle.Annotations); err != nil {
+			return err
+		}
+	}
+
+	for i, result := range results {
+		if err := writeLaps(archive, lapsPath(i, result.Name), result.Laps); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// WriteFile writes bundle to a file at path.
+func WriteFile(path string, bundle *Bundle) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, bundle); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read reads a bundle from a zip archive of size bytes.
+//
+// It returns ErrUnsupportedVersion when the bundle uses a newer format version.
+func Read(r io.ReaderAt, size int64) (*Bundle, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var info manifest
+	if err := readJSON(archive, manifestFile, &info); err != nil {
+		return nil, err
+	}
+	if info.Version <= 0 || info.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, info.Version)
+	}
+
+	bundle := &Bundle{
+		Created:  info.Created,
+		Metadata: info.Metadata,
+	}
+
+	var env hrtime.Environment
+	switch err := readJSON(archive, environmentFile, &env); {
+	case err == nil:
+		bundle.Environment = &env
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	if err := readJSON(archive, resultsFile, &bundle.Results); err != nil {
+		return nil, err
+	}
+	for _, result := range bundle.Results {
+		if result.SchemaVersion <= 0 || result.SchemaVersion > hrtime.SchemaVersion {
+			return nil, fmt.Errorf("%s: %w: %d", result.Name, hrtime.ErrUnsupportedSchema, result.SchemaVersion)
+		}
+	}
+
+	err = readJSON(archive, annotationsFile, &bundle.Annotations)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// ReadFile reads a bundle from a file at path.
+func ReadFile(path string) (*Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(file, stat.Size())
+}
+
+// writeJSON writes value as indented JSON to the named file in archive.
+func writeJSON(archive *zip.Writer, name string, value any) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "\t")
+	return enc.Encode(value)
+}
+
+// readJSON decodes the named file in archive into value.
+func readJSON(archive *zip.Reader, name string, value any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := json.NewDecoder(file).Decode(value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// writeLaps writes laps in nanoseconds to the named file in archive.
+func writeLaps(archive *zip.Writer, name string, laps []int64) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	out := csv.NewWriter(file)
+	if err := out.Write([]string{"lap_ns"}); err != nil {
+		return err
+	}
+	for _, lap := range laps {
+		if err := out.Write([]string{strconv.FormatInt(lap, 10)}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// lapsPath returns the path of raw laps of the i-th result.
+func lapsPath(i int, name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == "" {
+		return fmt.Sprintf("%s%03d.csv", lapsDir, i)
+	}
+	return fmt.Sprintf("%s%03d-%s.csv", lapsDir, i, safe)
+}
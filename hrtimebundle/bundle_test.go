@@ -0,0 +1,73 @@
+package hrtimebundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimebundle"
+)
+
+func TestBundleRoundtrip(t *testing.T) {
+	bundle := &hrtimebundle.Bundle{
+		Created:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Environment: &hrtime.Environment{Hostname: "bench-host", NumCPU: 8},
+		Metadata:    map[string]string{"commit": "abc123"},
+		Annotations: []hrtime.Annotation{{Time: time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC), Text: "deploy"}},
+	}
+	bundle.Add("encode/json", []time.Duration{100, 200, 300})
+	bundle.Add("decode", []time.Duration{50})
+
+	failing := hrtime.NewBenchmark(2)
+	for failing.Next() {
+		failing.Fail()
+	}
+	bundle.AddBenchmark("failing", failing)
+	unversioned := &hrtime.JSONResult{Name: "unversioned"}
+	bundle.Results = append(bundle.Results, unversioned)
+
+	var buf bytes.Buffer
+	if err := hrtimebundle.Write(&buf, bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	if _, err := archive.Open("laps/000-encode_json.csv"); err != nil {
+		t.Errorf("expected raw laps in bundle, got %v", names)
+	}
+
+	got, err := hrtimebundle.Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Created.Equal(bundle.Created) || got.Metadata["commit"] != "abc123" {
+		t.Errorf("unexpected manifest %v %v", got.Created, got.Metadata)
+	}
+	if got.Environment == nil || got.Environment.Hostname != "bench-host" {
+		t.Errorf("unexpected environment %+v", got.Environment)
+	}
+	if len(got.Annotations) != 1 || got.Annotations[0].Text != "deploy" {
+		t.Errorf("unexpected annotations %+v", got.Annotations)
+	}
+	if unversioned.SchemaVersion != 0 {
+		t.Errorf("Write modified results of the bundle: %+v", unversioned)
+	}
+	if len(got.Results) != 4 || got.Results[0].Name != "encode/json" {
+		t.Fatalf("unexpected results %+v", got.Results)
+	}
+	if laps := got.Results[0].Durations(); len(laps) != 3 || laps[2] != 300 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+	if failed := got.Results[2].Metadata["failed"]; failed != "2" {
+		t.Errorf("expected failures in metadata, got %v", got.Results[2].Metadata)
+	}
+}
@@ -0,0 +1,99 @@
+package hrtimeimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// criterionSample is the content of Criterion sample.json.
+type criterionSample struct {
+	Iters []float64 `json:"iters"`
+	Times []float64 `json:"times"`
+}
+
+// CriterionSample imports a Criterion (Rust) sample.json file.
+//
+// Criterion stores them as target/criterion/<name>/new/sample.json.
+// Each sample becomes a single lap containing the time per iteration.
+func CriterionSample(name string, r io.Reader) (*hrtime.JSONResult, error) {
+	var sample criterionSample
+	if err := json.NewDecoder(r).Decode(&sample); err != nil {
+		return nil, err
+	}
+	laps, err := perIteration(sample.Times, sample.Iters, time.Nanosecond)
+	if err != nil {
+		return nil, fmt.Errorf("benchmark %q: %w", name, err)
+	}
+
+	result := hrtime.NewJSONResult(name, laps)
+	result.Metadata = map[string]string{"source": "criterion"}
+	return result, nil
+}
+
+// criterionMessage is a single message of cargo-criterion --message-format=json.
+type criterionMessage struct {
+	Reason         string    `json:"reason"`
+	ID             string    `json:"id"`
+	Unit           string    `json:"unit"`
+	MeasuredValues []float64 `json:"measured_values"`
+	IterationCount []float64 `json:"iteration_count"`
+}
+
+// CriterionMessages imports newline delimited JSON messages produced by
+// cargo-criterion --message-format=json.
+//
+// Messages other than "benchmark-complete" are ignored.
+func CriterionMessages(r io.Reader) ([]*hrtime.JSONResult, error) {
+	var results []*hrtime.JSONResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var message criterionMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			return nil, err
+		}
+		if message.Reason != "benchmark-complete" {
+			continue
+		}
+
+		unit, err := timeUnit(message.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("benchmark %q: %w", message.ID, err)
+		}
+		laps, err := perIteration(message.MeasuredValues, message.IterationCount, unit)
+		if err != nil {
+			return nil, fmt.Errorf("benchmark %q: %w", message.ID, err)
+		}
+
+		result := hrtime.NewJSONResult(message.ID, laps)
+		result.Metadata = map[string]string{"source": "criterion"}
+		results = append(results, result)
+	}
+	return results, scanner.Err()
+}
+
+// perIteration divides total times by iteration counts.
+func perIteration(times, iters []float64, unit time.Duration) ([]time.Duration, error) {
+	if len(times) != len(iters) {
+		return nil, fmt.Errorf("mismatched sample lengths %d and %d", len(times), len(iters))
+	}
+	laps := make([]time.Duration, 0, len(times))
+	for i, total := range times {
+		if iters[i] <= 0 {
+			continue
+		}
+		laps = append(laps, time.Duration(total/iters[i]*float64(unit)))
+	}
+	return laps, nil
+}
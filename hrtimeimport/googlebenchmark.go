@@ -0,0 +1,91 @@
+// Package hrtimeimport converts results of other benchmarking tools into hrtime results.
+//
+// Imported results can be compared and reported using the same tooling as
+// results measured with hrtime.
+package hrtimeimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// googleBenchmarkFile is the JSON output of Google Benchmark (--benchmark_format=json).
+type googleBenchmarkFile struct {
+	Context struct {
+		HostName string `json:"host_name"`
+		NumCPUs  int    `json:"num_cpus"`
+	} `json:"context"`
+	Benchmarks []struct {
+		Name     string  `json:"name"`
+		RunName  string  `json:"run_name"`
+		RunType  string  `json:"run_type"`
+		RealTime float64 `json:"real_time"`
+		TimeUnit string  `json:"time_unit"`
+	} `json:"benchmarks"`
+}
+
+// GoogleBenchmark imports Google Benchmark JSON output.
+//
+// Each repetition of a benchmark becomes a single lap containing the time
+// per iteration. Aggregates (mean, median, stddev) are ignored, because
+// they can be recomputed from the repetitions.
+func GoogleBenchmark(r io.Reader) ([]*hrtime.JSONResult, error) {
+	var file googleBenchmarkFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	laps := map[string][]time.Duration{}
+	for _, bench := range file.Benchmarks {
+		if bench.RunType == "aggregate" {
+			continue
+		}
+		name := bench.RunName
+		if name == "" {
+			name = bench.Name
+		}
+
+		unit, err := timeUnit(bench.TimeUnit)
+		if err != nil {
+			return nil, fmt.Errorf("benchmark %q: %w", name, err)
+		}
+		if _, ok := laps[name]; !ok {
+			names = append(names, name)
+		}
+		laps[name] = append(laps[name], time.Duration(bench.RealTime*float64(unit)))
+	}
+
+	results := make([]*hrtime.JSONResult, 0, len(names))
+	for _, name := range names {
+		result := hrtime.NewJSONResult(name, laps[name])
+		result.Metadata = map[string]string{"source": "google-benchmark"}
+		if file.Context.HostName != "" {
+			result.Environment = &hrtime.Environment{
+				Hostname: file.Context.HostName,
+				NumCPU:   file.Context.NumCPUs,
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// timeUnit converts a time unit name to a duration.
+func timeUnit(unit string) (time.Duration, error) {
+	switch unit {
+	case "ns", "":
+		return time.Nanosecond, nil
+	case "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("unknown time unit %q", unit)
+}
@@ -0,0 +1,147 @@
+package hrtimeimport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// OpsPerLapKey is the metadata key of the number of operations in a lap
+// imported by GoTestBench, when ns/op values were scaled.
+const OpsPerLapKey = "ops_per_lap"
+
+// minScaledLap is the smallest lap in nanoseconds after scaling ns/op values.
+const minScaledLap = 1000
+
+// GoTestBench imports the textual output of go test -bench.
+//
+// Each run of a benchmark, e.g. when using -count, becomes a single lap
+// containing the mean ns/op of the run, hence the laps are per-run means
+// rather than timings of individual operations. Benchmarks faster than
+// a microsecond are scaled to keep sub-nanosecond precision: every lap is
+// the time of OpsPerLapKey operations, recorded in metadata. Other metrics, such as
+// B/op, allocs/op or p99_ns reported by hrtimetest.WrapB, are averaged
+// across runs and added to metadata together with the package and cpu.
+// Lines other than benchmark results and configuration are ignored.
+func GoTestBench(r io.Reader) ([]*hrtime.JSONResult, error) {
+	type benchmark struct {
+		name    string
+		pkg     string
+		nsPerOp []float64
+		metrics map[string]float64
+		units   []string
+	}
+
+	var order []*benchmark
+	byName := map[string]*benchmark{}
+	config := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if key, value, ok := strings.Cut(text, ": "); ok && !strings.ContainsAny(key, " \t") {
+			switch key {
+			case "goos", "goarch", "pkg", "cpu":
+				config[key] = strings.TrimSpace(value)
+			}
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+			continue
+		}
+
+		key := config["pkg"] + "\x00" + fields[0]
+		bench, ok := byName[key]
+		if !ok {
+			bench = &benchmark{name: fields[0], pkg: config["pkg"], metrics: map[string]float64{}}
+			byName[key] = bench
+			order = append(order, bench)
+		}
+
+		hasTime := false
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q: %w", line, fields[i], err)
+			}
+			unit := fields[i+1]
+			if unit == "ns/op" {
+				bench.nsPerOp = append(bench.nsPerOp, value)
+				hasTime = true
+				continue
+			}
+			if _, ok := bench.metrics[unit]; !ok {
+				bench.units = append(bench.units, unit)
+			}
+			bench.metrics[unit] += value
+		}
+		if !hasTime {
+			return nil, fmt.Errorf("line %d: missing ns/op", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]*hrtime.JSONResult, 0, len(order))
+	for _, bench := range order {
+		opsPerLap := lapScale(bench.nsPerOp)
+		laps := make([]time.Duration, len(bench.nsPerOp))
+		for i, value := range bench.nsPerOp {
+			laps[i] = time.Duration(math.Round(value * opsPerLap))
+		}
+
+		result := hrtime.NewJSONResult(bench.name, laps)
+		result.Metadata = map[string]string{"source": "go-test"}
+		if opsPerLap > 1 {
+			result.Metadata[OpsPerLapKey] = strconv.FormatFloat(opsPerLap, 'f', -1, 64)
+		}
+		if bench.pkg != "" {
+			result.Metadata["pkg"] = bench.pkg
+		}
+		if cpu := config["cpu"]; cpu != "" {
+			result.Metadata["cpu"] = cpu
+		}
+		for _, unit := range bench.units {
+			mean := bench.metrics[unit] / float64(len(bench.nsPerOp))
+			result.Metadata[unit] = strconv.FormatFloat(mean, 'g', 6, 64)
+		}
+		if config["goos"] != "" {
+			result.Environment = &hrtime.Environment{
+				GOOS:     config["goos"],
+				GOARCH:   config["goarch"],
+				CPUModel: config["cpu"],
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// lapScale returns the power of 10 by which to multiply ns/op values,
+// such that the smallest positive value becomes at least minScaledLap.
+func lapScale(nsPerOp []float64) float64 {
+	smallest := math.Inf(1)
+	for _, value := range nsPerOp {
+		if value > 0 && value < smallest {
+			smallest = value
+		}
+	}
+
+	scale := 1.0
+	for smallest*scale < minScaledLap {
+		scale *= 10
+	}
+	return scale
+}
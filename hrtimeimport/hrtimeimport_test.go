@@ -0,0 +1,100 @@
+package hrtimeimport_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime/hrtimeimport"
+)
+
+func TestGoogleBenchmark(t *testing.T) {
+	const input = `{
+		"context": {"host_name": "bench", "num_cpus": 4},
+		"benchmarks": [
+			{"name": "BM_Sort/8", "run_name": "BM_Sort/8", "run_type": "iteration", "real_time": 1.5, "time_unit": "us"},
+			{"name": "BM_Sort/8", "run_name": "BM_Sort/8", "run_type": "iteration", "real_time": 2.5, "time_unit": "us"},
+			{"name": "BM_Sort/8_mean", "run_name": "BM_Sort/8", "run_type": "aggregate", "real_time": 2, "time_unit": "us"}
+		]
+	}`
+
+	results, err := hrtimeimport.GoogleBenchmark(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Count != 2 || results[0].Stats.Mean != 2000 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Environment.Hostname != "bench" {
+		t.Errorf("environment not imported")
+	}
+}
+
+func TestCriterion(t *testing.T) {
+	sample, err := hrtimeimport.CriterionSample("fib", strings.NewReader(`{"iters": [1, 2], "times": [100, 400]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Stats.Minimum != 100 || sample.Stats.Maximum != 200 {
+		t.Errorf("unexpected sample stats %+v", sample.Stats)
+	}
+
+	const messages = `{"reason": "group-complete", "group_name": "fib"}
+{"reason": "benchmark-complete", "id": "fib/20", "unit": "ns", "measured_values": [300, 600], "iteration_count": [3, 3]}
+`
+	results, err := hrtimeimport.CriterionMessages(strings.NewReader(messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "fib/20" || results[0].Stats.Mean != 150 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestGoTestBench(t *testing.T) {
+	const input = `goos: linux
+goarch: amd64
+pkg: example.com/codec
+cpu: Example CPU @ 3.00GHz
+BenchmarkEncode-8   	 1000000	      1000 ns/op	      64 B/op	       2 allocs/op
+BenchmarkEncode-8   	 1000000	      1200 ns/op	      64 B/op	       2 allocs/op
+BenchmarkDecode-8   	  500000	      2500.4 ns/op	 2400 p99_ns
+PASS
+ok  	example.com/codec	3.000s
+`
+
+	results, err := hrtimeimport.GoTestBench(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Name != "BenchmarkEncode-8" || results[0].Count != 2 || results[0].Stats.Mean != 1100 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Metadata["B/op"] != "64" || results[0].Metadata["pkg"] != "example.com/codec" {
+		t.Errorf("unexpected metadata %v", results[0].Metadata)
+	}
+	if results[1].Laps[0] != 2500 || results[1].Metadata["p99_ns"] != "2400" {
+		t.Errorf("unexpected result %+v", results[1])
+	}
+	if results[1].Environment == nil || results[1].Environment.CPUModel != "Example CPU @ 3.00GHz" {
+		t.Errorf("environment not imported")
+	}
+}
+
+func TestGoTestBenchScaled(t *testing.T) {
+	const input = `pkg: example.com/fast
+BenchmarkAdd-8   	1000000000	         0.2512 ns/op
+BenchmarkAdd-8   	1000000000	         0.2498 ns/op
+BenchmarkAdd-8   	1000000000	         0.2531 ns/op
+`
+
+	results, err := hrtimeimport.GoTestBench(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Metadata[hrtimeimport.OpsPerLapKey] != "10000" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if laps := results[0].Laps; laps[0] != 2512 || laps[1] != 2498 || laps[2] != 2531 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+}
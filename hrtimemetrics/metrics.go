@@ -0,0 +1,204 @@
+// Package hrtimemetrics writes latencies of benchmarks in the Prometheus
+// text exposition format and publishes them as expvar variables.
+//
+// TextWriter writes the text exposition format directly, which keeps
+// hrtime dependency free. It serves its own endpoint that can be scraped
+// by Prometheus or any compatible agent. To register the same metrics with
+// a prometheus.Registry use the Collector of the hrtimeprom module.
+// Sources are read on every scrape, hence RollingBenchmark can be used for
+// live monitoring of a service.
+package hrtimemetrics
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Source provides laps for the writer.
+//
+// It's implemented by hrtime.RollingBenchmark, hrtime.Recorder and
+// completed hrtime.Benchmark.
+type Source interface {
+	Laps() []time.Duration
+}
+
+// DefaultQuantiles are the quantiles written by NewTextWriter.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99, 0.999}
+
+// DefaultBuckets are the histogram bucket upper bounds written by NewTextWriter.
+var DefaultBuckets = []time.Duration{
+	100 * time.Nanosecond, time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second,
+}
+
+// TextWriter writes percentiles and histogram buckets of registered sources
+// in the Prometheus text exposition format.
+//
+// TextWriter is an http.Handler serving the metrics for scraping.
+type TextWriter struct {
+	// Namespace is the prefix of metric names.
+	Namespace string
+	// Quantiles are exported as a summary, in range [0, 1].
+	Quantiles []float64
+	// Buckets are upper bounds of the exported histogram.
+	Buckets []time.Duration
+
+	mu      sync.Mutex
+	sources map[string]Source
+}
+
+// NewTextWriter creates a writer with "hrtime" namespace and default quantiles and buckets.
+func NewTextWriter() *TextWriter {
+	return &TextWriter{
+		Namespace: "hrtime",
+		Quantiles: DefaultQuantiles,
+		Buckets:   DefaultBuckets,
+		sources:   map[string]Source{},
+	}
+}
+
+// Register adds source with the specified name, replacing a previous source with the same name.
+func (writer *TextWriter) Register(name string, source Source) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	writer.sources[name] = source
+}
+
+// Unregister removes source with the specified name.
+func (writer *TextWriter) Unregister(name string) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	delete(writer.sources, name)
+}
+
+// snapshot returns sorted names and laps of every source.
+func (writer *TextWriter) snapshot() ([]string, map[string][]time.Duration) {
+	writer.mu.Lock()
+	sources := make(map[string]Source, len(writer.sources))
+	for name, source := range writer.sources {
+		sources[name] = source
+	}
+	writer.mu.Unlock()
+
+	names := make([]string, 0, len(sources))
+	laps := make(map[string][]time.Duration, len(sources))
+	for name, source := range sources {
+		names = append(names, name)
+		laps[name] = source.Laps()
+	}
+	slices.Sort(names)
+	return names, laps
+}
+
+// WriteTo writes metrics of all sources in Prometheus text exposition format.
+func (writer *TextWriter) WriteTo(w io.Writer) (int64, error) {
+	names, laps := writer.snapshot()
+
+	var b strings.Builder
+	summary := writer.Namespace + "_lap_seconds"
+	histogram := writer.Namespace + "_lap_histogram_seconds"
+
+	fmt.Fprintf(&b, "# HELP %s Lap durations measured by hrtime.\n", summary)
+	fmt.Fprintf(&b, "# TYPE %s summary\n", summary)
+	for _, name := range names {
+		stats := hrtime.NewStats(laps[name])
+		label := `benchmark="` + escapeLabel(name) + `"`
+		for _, q := range writer.Quantiles {
+			fmt.Fprintf(&b, "%s{%s,quantile=\"%s\"} %s\n", summary, label, formatFloat(q), formatSeconds(stats.Percentile(q)))
+		}
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", summary, label, formatSeconds(sum(laps[name])))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", summary, label, stats.Count)
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Histogram of lap durations measured by hrtime.\n", histogram)
+	fmt.Fprintf(&b, "# TYPE %s histogram\n", histogram)
+	for _, name := range names {
+		sorted := slices.Clone(laps[name])
+		slices.Sort(sorted)
+		label := `benchmark="` + escapeLabel(name) + `"`
+		for _, bound := range writer.Buckets {
+			count, _ := slices.BinarySearch(sorted, bound+1)
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%s\"} %d\n", histogram, label, formatSeconds(bound), count)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", histogram, label, len(sorted))
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", histogram, label, formatSeconds(sum(sorted)))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", histogram, label, len(sorted))
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP responds with metrics in Prometheus text exposition format.
+func (writer *TextWriter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = writer.WriteTo(w)
+}
+
+// PublishExpvar publishes summaries of all sources as expvar variable name.
+//
+// The variable is a map from source name to count, mean and quantiles in nanoseconds.
+// Like expvar.Publish, it panics when the name is already in use.
+func (writer *TextWriter) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(writer.expvar))
+}
+
+// expvar returns summaries of all sources.
+func (writer *TextWriter) expvar() any {
+	names, laps := writer.snapshot()
+
+	values := make(map[string]map[string]any, len(names))
+	for _, name := range names {
+		stats := hrtime.NewStats(laps[name])
+		value := map[string]any{
+			"count":   stats.Count,
+			"mean_ns": stats.Mean.Nanoseconds(),
+		}
+		for _, q := range writer.Quantiles {
+			value[percentileKey(q)] = stats.Percentile(q).Nanoseconds()
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// percentileKey returns expvar key for quantile q, e.g. "p999_ns" for 0.999.
+func percentileKey(q float64) string {
+	percent := math.Round(q*1e6) / 1e4
+	return "p" + strings.ReplaceAll(formatFloat(percent), ".", "") + "_ns"
+}
+
+// sum returns the total of laps.
+func sum(laps []time.Duration) time.Duration {
+	var total time.Duration
+	for _, lap := range laps {
+		total += lap
+	}
+	return total
+}
+
+// formatSeconds formats duration as seconds.
+func formatSeconds(d time.Duration) string {
+	return formatFloat(d.Seconds())
+}
+
+// formatFloat formats value with the minimal number of digits.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes label value for the text exposition format.
+var escapeLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace
@@ -0,0 +1,58 @@
+package hrtimemetrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimemetrics"
+)
+
+// expvarAttempts makes expvar names unique when tests run repeatedly.
+var expvarAttempts atomic.Int32
+
+func TestTextWriter(t *testing.T) {
+	bench := hrtime.NewRollingBenchmark(100)
+	for i := 1; i <= 100; i++ {
+		bench.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	writer := hrtimemetrics.NewTextWriter()
+	writer.Register(`api "get"`, bench)
+
+	recorder := httptest.NewRecorder()
+	writer.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Header().Get("Content-Type") != hrtimemetrics.ContentType {
+		t.Errorf("invalid content type %q", recorder.Header().Get("Content-Type"))
+	}
+
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"# TYPE hrtime_lap_seconds summary\n",
+		`hrtime_lap_seconds{benchmark="api \"get\"",quantile="0.5"} 5`,
+		`hrtime_lap_seconds_count{benchmark="api \"get\""} 100` + "\n",
+		`hrtime_lap_histogram_seconds_bucket{benchmark="api \"get\"",le="1e-05"} 10` + "\n",
+		`hrtime_lap_histogram_seconds_bucket{benchmark="api \"get\"",le="+Inf"} 100` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("missing %q in:\n%s", expected, body)
+		}
+	}
+
+	// expvar names cannot be reused when tests run repeatedly
+	name := fmt.Sprintf("hrtime_test_%d", expvarAttempts.Add(1))
+	writer.PublishExpvar(name)
+	var values map[string]map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &values); err != nil {
+		t.Fatal(err)
+	}
+	if got := values[`api "get"`]; got["count"] != 100 || got["p999_ns"] == 0 {
+		t.Errorf("invalid expvar %v", got)
+	}
+}
@@ -0,0 +1,116 @@
+// Package hrtimeotel exports hrtime measurements to OpenTelemetry.
+//
+// Measurements are taken with the low-overhead hrtime clocks and converted
+// to wall-clock times only when emitted. The package doesn't depend on the
+// OpenTelemetry SDK, instead spans and durations are passed to SpanFunc and
+// RecordFunc, which are usually a few lines of glue:
+//
+//	span := func(ctx context.Context, name string, start, end time.Time) {
+//		_, s := tracer.Start(ctx, name, trace.WithTimestamp(start))
+//		s.End(trace.WithTimestamp(end))
+//	}
+//	record := func(ctx context.Context, name string, d time.Duration) {
+//		histogram.Record(ctx, d.Seconds(), metric.WithAttributes(attribute.String("name", name)))
+//	}
+//	exporter := hrtimeotel.New(span, record)
+package hrtimeotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// SpanFunc receives a finished span, e.g. to create an OpenTelemetry span.
+type SpanFunc func(ctx context.Context, name string, start, end time.Time)
+
+// RecordFunc receives a measured duration, e.g. to record it into an
+// OpenTelemetry histogram instrument.
+type RecordFunc func(ctx context.Context, name string, duration time.Duration)
+
+// Exporter converts hrtime measurements into spans and durations.
+type Exporter struct {
+	span   SpanFunc
+	record RecordFunc
+
+	// wall and mono are the same instant on wall-clock and hrtime.Now.
+	wall time.Time
+	mono time.Duration
+}
+
+// New creates an exporter, either span or record can be nil.
+func New(span SpanFunc, record RecordFunc) *Exporter {
+	return &Exporter{
+		span:   span,
+		record: record,
+		wall:   time.Now(),
+		mono:   hrtime.Now(),
+	}
+}
+
+// WallTime converts time returned by hrtime.Now to wall-clock time.
+func (exporter *Exporter) WallTime(at time.Duration) time.Time {
+	return exporter.wall.Add(at - exporter.mono)
+}
+
+// emit passes a single measurement to span and record.
+func (exporter *Exporter) emit(ctx context.Context, name string, start, finish time.Duration) {
+	if exporter.span != nil {
+		exporter.span(ctx, name, exporter.WallTime(start), exporter.WallTime(finish))
+	}
+	if exporter.record != nil {
+		exporter.record(ctx, name, finish-start)
+	}
+}
+
+// Section is a measured section started by Exporter.Start.
+type Section struct {
+	exporter *Exporter
+	ctx      context.Context
+	name     string
+	start    time.Duration
+}
+
+// Start starts measuring a section with the specified name.
+//
+// The section is emitted when End is called.
+func (exporter *Exporter) Start(ctx context.Context, name string) Section {
+	return Section{
+		exporter: exporter,
+		ctx:      ctx,
+		name:     name,
+		start:    hrtime.Now(),
+	}
+}
+
+// End finishes the section and emits it.
+func (section Section) End() {
+	section.exporter.emit(section.ctx, section.name, section.start, hrtime.Now())
+}
+
+// EmitBenchmark emits each lap of a completed benchmark.
+//
+// Laps recorded with NextWithLabel are emitted as name + "/" + label.
+// The benchmark must use the default clock, for accurate span times
+// it should be created WithTimestamps, see Benchmark.Spans.
+func (exporter *Exporter) EmitBenchmark(ctx context.Context, name string, bench *hrtime.Benchmark) {
+	labels := bench.LapLabels()
+	for i, span := range bench.Spans() {
+		lapName := name
+		if labels != nil && labels[i] != "" {
+			lapName = name + "/" + labels[i]
+		}
+		exporter.emit(ctx, lapName, span.Start, span.Finish)
+	}
+}
+
+// EmitLaps records durations without span times.
+func (exporter *Exporter) EmitLaps(ctx context.Context, name string, laps []time.Duration) {
+	if exporter.record == nil {
+		return
+	}
+	for _, lap := range laps {
+		exporter.record(ctx, name, lap)
+	}
+}
@@ -0,0 +1,51 @@
+package hrtimeotel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimeotel"
+)
+
+func TestExporter(t *testing.T) {
+	type span struct {
+		name       string
+		start, end time.Time
+	}
+	var spans []span
+	var durations []time.Duration
+
+	exporter := hrtimeotel.New(
+		func(ctx context.Context, name string, start, end time.Time) {
+			spans = append(spans, span{name, start, end})
+		},
+		func(ctx context.Context, name string, d time.Duration) {
+			durations = append(durations, d)
+		})
+
+	before := time.Now()
+	section := exporter.Start(context.Background(), "section")
+	time.Sleep(time.Millisecond)
+	section.End()
+
+	if len(spans) != 1 || spans[0].name != "section" || len(durations) != 1 {
+		t.Fatalf("expected single span, got %v", spans)
+	}
+	if spans[0].end.Sub(spans[0].start) != durations[0] || durations[0] < time.Millisecond {
+		t.Errorf("invalid span %v, duration %v", spans[0], durations[0])
+	}
+	if spans[0].start.Before(before.Add(-time.Millisecond)) || spans[0].end.After(time.Now().Add(time.Millisecond)) {
+		t.Errorf("span %v outside of measured interval", spans[0])
+	}
+
+	spans, durations = nil, nil
+	bench := hrtime.NewBenchmark(4, hrtime.WithTimestamps())
+	for i := 0; bench.NextWithLabel([]string{"a", "b"}[i%2]); i++ {
+	}
+	exporter.EmitBenchmark(context.Background(), "bench", bench)
+	if len(spans) != 4 || spans[0].name != "bench/a" || spans[1].name != "bench/b" {
+		t.Errorf("invalid benchmark spans %v", spans)
+	}
+}
@@ -0,0 +1,723 @@
+// Package hrtimepb implements protocol buffer encoding of hrtime results.
+//
+// The types mirror results.proto and are encoded without depending on
+// the protocol buffers runtime, which keeps hrtime dependency free.
+// The encoding is wire-compatible with code generated from results.proto,
+// including the deterministic order of fields and map entries, which is
+// checked against the encoding in testdata/result.golden.
+package hrtimepb
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// Results is a collection of results.
+type Results struct {
+	Results []*Result
+}
+
+// Result is a single benchmark result.
+type Result struct {
+	SchemaVersion     uint32
+	Name              string
+	TimestampUnixNano int64
+	Count             uint64
+	Stats             *Stats
+	Laps              []int64
+	Metadata          map[string]string
+	Environment       *Environment
+	Sources           []*Source
+}
+
+// Source contains statistics of a single source of a merged benchmark.
+type Source struct {
+	Name      string
+	Count     uint64
+	Stats     *Stats
+	Straggler bool
+}
+
+// Stats contains summary statistics in nanoseconds.
+type Stats struct {
+	Mean  float64
+	Min   int64
+	Max   int64
+	P50   int64
+	P90   int64
+	P99   int64
+	P999  int64
+	P9999 int64
+}
+
+// Environment describes where the results were measured.
+type Environment struct {
+	Hostname   string
+	Goos       string
+	Goarch     string
+	GoVersion  string
+	NumCpu     int32
+	Gomaxprocs int32
+	Warnings   []string
+
+	CpuModel        string
+	Overhead        int64
+	Precision       float64
+	TscSupported    bool
+	ClockSource     string
+	ClockSyscall    bool
+	SyscallOverhead int64
+	Hypervisor      string
+	Container       string
+	CpuQuota        float64
+	Governors       []string
+	Clocks          *Clocks
+}
+
+// Clocks describes the backends used by Now and TSC.
+type Clocks struct {
+	Now *ClockBackend
+	Tsc *ClockBackend
+}
+
+// ClockBackend describes a clock backend.
+type ClockBackend struct {
+	Name       string
+	Available  bool
+	Resolution float64
+	Overhead   int64
+	Frequency  float64
+	Fallback   string
+}
+
+// FromJSONResult converts result into its protocol buffer representation.
+func FromJSONResult(result *hrtime.JSONResult) *Result {
+	pb := &Result{
+		SchemaVersion:     uint32(result.SchemaVersion),
+		Name:              result.Name,
+		TimestampUnixNano: result.Timestamp.UnixNano(),
+		Count:             uint64(result.Count),
+		Stats:             fromJSONStats(result.Stats),
+		Laps:              append([]int64(nil), result.Laps...),
+		Metadata:          result.Metadata,
+	}
+	if env := result.Environment; env != nil {
+		pb.Environment = &Environment{
+			Hostname:   env.Hostname,
+			Goos:       env.GOOS,
+			Goarch:     env.GOARCH,
+			GoVersion:  env.GoVersion,
+			NumCpu:     int32(env.NumCPU),
+			Gomaxprocs: int32(env.GOMAXPROCS),
+			Warnings:   env.Warnings,
+
+			CpuModel:        env.CPUModel,
+			Overhead:        int64(env.Overhead),
+			Precision:       env.Precision,
+			TscSupported:    env.TSCSupported,
+			ClockSource:     env.ClockSource,
+			ClockSyscall:    env.ClockSyscall,
+			SyscallOverhead: int64(env.SyscallOverhead),
+			Hypervisor:      env.Hypervisor,
+			Container:       env.Container,
+			CpuQuota:        env.CPUQuota,
+			Governors:       env.Governors,
+			Clocks: &Clocks{
+				Now: fromBackendInfo(env.Clocks.Now),
+				Tsc: fromBackendInfo(env.Clocks.TSC),
+			},
+		}
+	}
+	for _, source := range result.Sources {
+		pb.Sources = append(pb.Sources, &Source{
+			Name:      source.Name,
+			Count:     uint64(source.Count),
+			Stats:     fromJSONStats(source.Stats),
+			Straggler: source.Straggler,
+		})
+	}
+	return pb
+}
+
+// fromJSONStats converts stats into their protocol buffer representation.
+func fromJSONStats(stats hrtime.JSONStats) *Stats {
+	return &Stats{
+		Mean:  stats.Mean,
+		Min:   stats.Minimum,
+		Max:   stats.Maximum,
+		P50:   stats.P50,
+		P90:   stats.P90,
+		P99:   stats.P99,
+		P999:  stats.P999,
+		P9999: stats.P9999,
+	}
+}
+
+// fromBackendInfo converts info into its protocol buffer representation.
+func fromBackendInfo(info hrtime.BackendInfo) *ClockBackend {
+	return &ClockBackend{
+		Name:       info.Name,
+		Available:  info.Available,
+		Resolution: info.Resolution,
+		Overhead:   int64(info.Overhead),
+		Frequency:  info.Frequency,
+		Fallback:   info.Fallback,
+	}
+}
+
+// JSONResult converts result into hrtime.JSONResult.
+func (result *Result) JSONResult() *hrtime.JSONResult {
+	converted := &hrtime.JSONResult{
+		SchemaVersion: int(result.SchemaVersion),
+		Name:          result.Name,
+		Timestamp:     time.Unix(0, result.TimestampUnixNano).UTC(),
+		Count:         int(result.Count),
+		Stats:         result.Stats.jsonStats(),
+		Laps:          append([]int64(nil), result.Laps...),
+		Metadata:      result.Metadata,
+	}
+	if env := result.Environment; env != nil {
+		converted.Environment = &hrtime.Environment{
+			Hostname:   env.Hostname,
+			GOOS:       env.Goos,
+			GOARCH:     env.Goarch,
+			GoVersion:  env.GoVersion,
+			NumCPU:     int(env.NumCpu),
+			GOMAXPROCS: int(env.Gomaxprocs),
+			Warnings:   env.Warnings,
+
+			CPUModel:        env.CpuModel,
+			Overhead:        time.Duration(env.Overhead),
+			Precision:       env.Precision,
+			TSCSupported:    env.TscSupported,
+			ClockSource:     env.ClockSource,
+			ClockSyscall:    env.ClockSyscall,
+			SyscallOverhead: time.Duration(env.SyscallOverhead),
+			Hypervisor:      env.Hypervisor,
+			Container:       env.Container,
+			CPUQuota:        env.CpuQuota,
+			Governors:       env.Governors,
+		}
+		if clocks := env.Clocks; clocks != nil {
+			converted.Environment.Clocks = hrtime.Clocks{
+				Now: clocks.Now.backendInfo(),
+				TSC: clocks.Tsc.backendInfo(),
+			}
+		}
+	}
+	for _, source := range result.Sources {
+		converted.Sources = append(converted.Sources, hrtime.JSONSource{
+			Name:      source.Name,
+			Count:     int(source.Count),
+			Stats:     source.Stats.jsonStats(),
+			Straggler: source.Straggler,
+		})
+	}
+	return converted
+}
+
+// jsonStats converts stats into hrtime.JSONStats, nil stats are zero.
+func (stats *Stats) jsonStats() hrtime.JSONStats {
+	if stats == nil {
+		return hrtime.JSONStats{}
+	}
+	return hrtime.JSONStats{
+		Mean:    stats.Mean,
+		Minimum: stats.Min,
+		Maximum: stats.Max,
+		P50:     stats.P50,
+		P90:     stats.P90,
+		P99:     stats.P99,
+		P999:    stats.P999,
+		P9999:   stats.P9999,
+	}
+}
+
+// backendInfo converts backend into hrtime.BackendInfo, nil backend is zero.
+func (backend *ClockBackend) backendInfo() hrtime.BackendInfo {
+	if backend == nil {
+		return hrtime.BackendInfo{}
+	}
+	return hrtime.BackendInfo{
+		Name:       backend.Name,
+		Available:  backend.Available,
+		Resolution: backend.Resolution,
+		Overhead:   time.Duration(backend.Overhead),
+		Frequency:  backend.Frequency,
+		Fallback:   backend.Fallback,
+	}
+}
+
+// Marshal encodes results.
+func (results *Results) Marshal() []byte {
+	var b []byte
+	for _, result := range results.Results {
+		b = appendBytesField(b, 1, result.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes results from data.
+func (results *Results) Unmarshal(data []byte) error {
+	*results = Results{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		if field == 1 && d.expect(wireType, wireBytes) {
+			result := &Result{}
+			if err := result.Unmarshal(d.bytes()); err != nil {
+				return err
+			}
+			results.Results = append(results.Results, result)
+			continue
+		}
+		d.skip(wireType)
+	}
+	return d.err
+}
+
+// Marshal encodes result.
+func (result *Result) Marshal() []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(result.SchemaVersion))
+	b = appendStringField(b, 2, result.Name)
+	b = appendVarintField(b, 3, uint64(result.TimestampUnixNano))
+	b = appendVarintField(b, 4, result.Count)
+	if result.Stats != nil {
+		b = appendBytesField(b, 5, result.Stats.Marshal())
+	}
+	if len(result.Laps) > 0 {
+		var packed []byte
+		for _, lap := range result.Laps {
+			packed = binary.AppendUvarint(packed, uint64(lap))
+		}
+		b = appendBytesField(b, 6, packed)
+	}
+
+	// sort keys for deterministic output
+	keys := make([]string, 0, len(result.Metadata))
+	for key := range result.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// map entries always contain both the key and the value
+		var entry []byte
+		entry = appendBytesField(entry, 1, []byte(key))
+		entry = appendBytesField(entry, 2, []byte(result.Metadata[key]))
+		b = appendBytesField(b, 7, entry)
+	}
+
+	if result.Environment != nil {
+		b = appendBytesField(b, 8, result.Environment.Marshal())
+	}
+	for _, source := range result.Sources {
+		b = appendBytesField(b, 9, source.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes result from data.
+func (result *Result) Unmarshal(data []byte) error {
+	*result = Result{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		switch field {
+		case 1:
+			if d.expect(wireType, wireVarint) {
+				result.SchemaVersion = uint32(d.varint())
+			}
+		case 2:
+			if d.expect(wireType, wireBytes) {
+				result.Name = string(d.bytes())
+			}
+		case 3:
+			if d.expect(wireType, wireVarint) {
+				result.TimestampUnixNano = int64(d.varint())
+			}
+		case 4:
+			if d.expect(wireType, wireVarint) {
+				result.Count = d.varint()
+			}
+		case 5:
+			if d.expect(wireType, wireBytes) {
+				result.Stats = &Stats{}
+				if err := result.Stats.Unmarshal(d.bytes()); err != nil {
+					return err
+				}
+			}
+		case 6:
+			switch wireType {
+			case wireVarint:
+				result.Laps = append(result.Laps, int64(d.varint()))
+			case wireBytes:
+				packed := &decoder{data: d.bytes()}
+				for len(packed.data) > 0 && packed.err == nil {
+					result.Laps = append(result.Laps, int64(packed.varint()))
+				}
+				if packed.err != nil {
+					return packed.err
+				}
+			default:
+				d.err = errWireType
+			}
+		case 7:
+			if d.expect(wireType, wireBytes) {
+				key, value, err := unmarshalMapEntry(d.bytes())
+				if err != nil {
+					return err
+				}
+				if result.Metadata == nil {
+					result.Metadata = map[string]string{}
+				}
+				result.Metadata[key] = value
+			}
+		case 8:
+			if d.expect(wireType, wireBytes) {
+				result.Environment = &Environment{}
+				if err := result.Environment.Unmarshal(d.bytes()); err != nil {
+					return err
+				}
+			}
+		case 9:
+			if d.expect(wireType, wireBytes) {
+				source := &Source{}
+				if err := source.Unmarshal(d.bytes()); err != nil {
+					return err
+				}
+				result.Sources = append(result.Sources, source)
+			}
+		default:
+			d.skip(wireType)
+		}
+	}
+	return d.err
+}
+
+// unmarshalMapEntry decodes a map<string, string> entry.
+func unmarshalMapEntry(data []byte) (key, value string, err error) {
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && d.expect(wireType, wireBytes):
+			key = string(d.bytes())
+		case field == 2 && d.expect(wireType, wireBytes):
+			value = string(d.bytes())
+		default:
+			d.skip(wireType)
+		}
+	}
+	return key, value, d.err
+}
+
+// Marshal encodes stats.
+func (stats *Stats) Marshal() []byte {
+	var b []byte
+	b = appendDoubleField(b, 1, stats.Mean)
+	b = appendVarintField(b, 2, uint64(stats.Min))
+	b = appendVarintField(b, 3, uint64(stats.Max))
+	b = appendVarintField(b, 4, uint64(stats.P50))
+	b = appendVarintField(b, 5, uint64(stats.P90))
+	b = appendVarintField(b, 6, uint64(stats.P99))
+	b = appendVarintField(b, 7, uint64(stats.P999))
+	b = appendVarintField(b, 8, uint64(stats.P9999))
+	return b
+}
+
+// Unmarshal decodes stats from data.
+func (stats *Stats) Unmarshal(data []byte) error {
+	*stats = Stats{}
+	targets := []*int64{2: &stats.Min, &stats.Max, &stats.P50, &stats.P90, &stats.P99, &stats.P999, &stats.P9999}
+
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1:
+			if d.expect(wireType, wireFixed64) {
+				stats.Mean = math.Float64frombits(d.fixed64())
+			}
+		case field >= 2 && field < len(targets):
+			if d.expect(wireType, wireVarint) {
+				*targets[field] = int64(d.varint())
+			}
+		default:
+			d.skip(wireType)
+		}
+	}
+	return d.err
+}
+
+// Marshal encodes source.
+func (source *Source) Marshal() []byte {
+	var b []byte
+	b = appendStringField(b, 1, source.Name)
+	b = appendVarintField(b, 2, source.Count)
+	if source.Stats != nil {
+		b = appendBytesField(b, 3, source.Stats.Marshal())
+	}
+	b = appendBoolField(b, 4, source.Straggler)
+	return b
+}
+
+// Unmarshal decodes source from data.
+func (source *Source) Unmarshal(data []byte) error {
+	*source = Source{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		switch field {
+		case 1:
+			if d.expect(wireType, wireBytes) {
+				source.Name = string(d.bytes())
+			}
+		case 2:
+			if d.expect(wireType, wireVarint) {
+				source.Count = d.varint()
+			}
+		case 3:
+			if d.expect(wireType, wireBytes) {
+				source.Stats = &Stats{}
+				if err := source.Stats.Unmarshal(d.bytes()); err != nil {
+					return err
+				}
+			}
+		case 4:
+			if d.expect(wireType, wireVarint) {
+				source.Straggler = d.varint() != 0
+			}
+		default:
+			d.skip(wireType)
+		}
+	}
+	return d.err
+}
+
+// Marshal encodes environment.
+func (env *Environment) Marshal() []byte {
+	var b []byte
+	b = appendStringField(b, 1, env.Hostname)
+	b = appendStringField(b, 2, env.Goos)
+	b = appendStringField(b, 3, env.Goarch)
+	b = appendStringField(b, 4, env.GoVersion)
+	b = appendVarintField(b, 5, uint64(env.NumCpu))
+	b = appendVarintField(b, 6, uint64(env.Gomaxprocs))
+	for _, warning := range env.Warnings {
+		b = appendBytesField(b, 7, []byte(warning))
+	}
+	b = appendStringField(b, 8, env.CpuModel)
+	b = appendVarintField(b, 9, uint64(env.Overhead))
+	b = appendDoubleField(b, 10, env.Precision)
+	b = appendBoolField(b, 11, env.TscSupported)
+	b = appendStringField(b, 12, env.ClockSource)
+	b = appendBoolField(b, 13, env.ClockSyscall)
+	b = appendVarintField(b, 14, uint64(env.SyscallOverhead))
+	b = appendStringField(b, 15, env.Hypervisor)
+	b = appendStringField(b, 16, env.Container)
+	b = appendDoubleField(b, 17, env.CpuQuota)
+	for _, governor := range env.Governors {
+		b = appendBytesField(b, 18, []byte(governor))
+	}
+	if env.Clocks != nil {
+		b = appendBytesField(b, 19, env.Clocks.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes environment from data.
+func (env *Environment) Unmarshal(data []byte) error {
+	*env = Environment{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+
+		var target *string
+		switch field {
+		case 1:
+			target = &env.Hostname
+		case 2:
+			target = &env.Goos
+		case 3:
+			target = &env.Goarch
+		case 4:
+			target = &env.GoVersion
+		case 8:
+			target = &env.CpuModel
+		case 12:
+			target = &env.ClockSource
+		case 15:
+			target = &env.Hypervisor
+		case 16:
+			target = &env.Container
+		case 5, 6, 9, 11, 13, 14:
+			if d.expect(wireType, wireVarint) {
+				v := d.varint()
+				switch field {
+				case 5:
+					env.NumCpu = int32(v)
+				case 6:
+					env.Gomaxprocs = int32(v)
+				case 9:
+					env.Overhead = int64(v)
+				case 11:
+					env.TscSupported = v != 0
+				case 13:
+					env.ClockSyscall = v != 0
+				case 14:
+					env.SyscallOverhead = int64(v)
+				}
+			}
+			continue
+		case 10, 17:
+			if d.expect(wireType, wireFixed64) {
+				v := math.Float64frombits(d.fixed64())
+				if field == 10 {
+					env.Precision = v
+				} else {
+					env.CpuQuota = v
+				}
+			}
+			continue
+		case 7:
+			if d.expect(wireType, wireBytes) {
+				env.Warnings = append(env.Warnings, string(d.bytes()))
+			}
+			continue
+		case 18:
+			if d.expect(wireType, wireBytes) {
+				env.Governors = append(env.Governors, string(d.bytes()))
+			}
+			continue
+		case 19:
+			if d.expect(wireType, wireBytes) {
+				env.Clocks = &Clocks{}
+				if err := env.Clocks.Unmarshal(d.bytes()); err != nil {
+					return err
+				}
+			}
+			continue
+		default:
+			d.skip(wireType)
+			continue
+		}
+		if d.expect(wireType, wireBytes) {
+			*target = string(d.bytes())
+		}
+	}
+	return d.err
+}
+
+// Marshal encodes clocks.
+func (clocks *Clocks) Marshal() []byte {
+	var b []byte
+	if clocks.Now != nil {
+		b = appendBytesField(b, 1, clocks.Now.Marshal())
+	}
+	if clocks.Tsc != nil {
+		b = appendBytesField(b, 2, clocks.Tsc.Marshal())
+	}
+	return b
+}
+
+// Unmarshal decodes clocks from data.
+func (clocks *Clocks) Unmarshal(data []byte) error {
+	*clocks = Clocks{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		if (field == 1 || field == 2) && d.expect(wireType, wireBytes) {
+			backend := &ClockBackend{}
+			if err := backend.Unmarshal(d.bytes()); err != nil {
+				return err
+			}
+			if field == 1 {
+				clocks.Now = backend
+			} else {
+				clocks.Tsc = backend
+			}
+			continue
+		}
+		d.skip(wireType)
+	}
+	return d.err
+}
+
+// Marshal encodes backend.
+func (backend *ClockBackend) Marshal() []byte {
+	var b []byte
+	b = appendStringField(b, 1, backend.Name)
+	b = appendBoolField(b, 2, backend.Available)
+	b = appendDoubleField(b, 3, backend.Resolution)
+	b = appendVarintField(b, 4, uint64(backend.Overhead))
+	b = appendDoubleField(b, 5, backend.Frequency)
+	b = appendStringField(b, 6, backend.Fallback)
+	return b
+}
+
+// Unmarshal decodes backend from data.
+func (backend *ClockBackend) Unmarshal(data []byte) error {
+	*backend = ClockBackend{}
+	d := &decoder{data: data}
+	for {
+		field, wireType, ok := d.next()
+		if !ok {
+			break
+		}
+		switch field {
+		case 1:
+			if d.expect(wireType, wireBytes) {
+				backend.Name = string(d.bytes())
+			}
+		case 2:
+			if d.expect(wireType, wireVarint) {
+				backend.Available = d.varint() != 0
+			}
+		case 3:
+			if d.expect(wireType, wireFixed64) {
+				backend.Resolution = math.Float64frombits(d.fixed64())
+			}
+		case 4:
+			if d.expect(wireType, wireVarint) {
+				backend.Overhead = int64(d.varint())
+			}
+		case 5:
+			if d.expect(wireType, wireFixed64) {
+				backend.Frequency = math.Float64frombits(d.fixed64())
+			}
+		case 6:
+			if d.expect(wireType, wireBytes) {
+				backend.Fallback = string(d.bytes())
+			}
+		default:
+			d.skip(wireType)
+		}
+	}
+	return d.err
+}
@@ -0,0 +1,263 @@
+package hrtimepb_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimepb"
+)
+
+func TestResultsRoundtrip(t *testing.T) {
+	result := hrtime.NewJSONResult("sleep", []time.Duration{3, 1, 2, -1})
+	result.Environment = hrtime.CaptureEnv()
+	result.Metadata = map[string]string{"commit": "abc", "branch": "main"}
+
+	results := &hrtimepb.Results{Results: []*hrtimepb.Result{hrtimepb.FromJSONResult(result)}}
+	data := results.Marshal()
+
+	var decoded hrtimepb.Results
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, &decoded) {
+		t.Errorf("roundtrip mismatch:\n%+v\n%+v", results.Results[0], decoded.Results[0])
+	}
+
+	converted := decoded.Results[0].JSONResult()
+	if converted.Name != "sleep" || converted.Stats.P50 != result.Stats.P50 || !converted.Timestamp.Equal(result.Timestamp) {
+		t.Errorf("conversion mismatch: %+v", converted)
+	}
+}
+
+func TestResultJSONRoundtrip(t *testing.T) {
+	backend := hrtime.BackendInfo{Name: "b", Available: true, Resolution: 1, Overhead: 2, Frequency: 3, Fallback: "f"}
+	result := &hrtime.JSONResult{
+		SchemaVersion: hrtime.SchemaVersion,
+		Name:          "merged",
+		Timestamp:     time.Unix(1700000000, 1).UTC(),
+		Count:         3,
+		Stats:         hrtime.JSONStats{Mean: 1, Minimum: 2, Maximum: 3, P50: 4, P90: 5, P99: 6, P999: 7, P9999: 8},
+		Laps:          []int64{1, 2, 3},
+		Environment: &hrtime.Environment{
+			Hostname: "h", GOOS: "o", GOARCH: "a", GoVersion: "v", NumCPU: 1, GOMAXPROCS: 2, CPUModel: "m",
+			Overhead: 3, Precision: 4, TSCSupported: true,
+			ClockSource: "s", ClockSyscall: true, SyscallOverhead: 5,
+			Hypervisor: "h", Container: "c", CPUQuota: 6, Governors: []string{"g"},
+			Clocks:   hrtime.Clocks{Now: backend, TSC: backend},
+			Warnings: []string{"w"},
+		},
+		Metadata: map[string]string{"k": "v"},
+		Sources:  []hrtime.JSONSource{{Name: "s", Count: 1, Stats: hrtime.JSONStats{Mean: 1}, Straggler: true}},
+	}
+	// every field must be set to check that it's carried
+	for _, value := range []reflect.Value{reflect.ValueOf(*result), reflect.ValueOf(*result.Environment)} {
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).IsZero() {
+				t.Fatalf("%s.%s is not set", value.Type().Name(), value.Type().Field(i).Name)
+			}
+		}
+	}
+
+	var decoded hrtimepb.Result
+	if err := decoded.Unmarshal(hrtimepb.FromJSONResult(result).Marshal()); err != nil {
+		t.Fatal(err)
+	}
+	if converted := decoded.JSONResult(); !reflect.DeepEqual(converted, result) {
+		t.Errorf("conversion mismatch:\n%+v\n%+v", converted, result)
+	}
+}
+
+func TestResultUnmarshalTruncated(t *testing.T) {
+	result := &hrtimepb.Result{Name: "truncated", Laps: []int64{1, 2, 3}}
+	data := result.Marshal()
+
+	var decoded hrtimepb.Result
+	if err := decoded.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+}
+
+// goldenResult is the result encoded in testdata/result.golden.
+var goldenResult = &hrtimepb.Result{
+	SchemaVersion:     1,
+	Name:              "sleep",
+	TimestampUnixNano: 1700000000000000000,
+	Count:             3,
+	Stats:             &hrtimepb.Stats{Mean: 2.5, Min: -1, Max: 3, P50: 2, P90: 3, P99: 3, P999: 3, P9999: 3},
+	Laps:              []int64{3, 1, -1},
+	Metadata:          map[string]string{"commit": "abc", "empty": ""},
+	Environment: &hrtimepb.Environment{
+		Goos:       "linux",
+		Goarch:     "amd64",
+		GoVersion:  "go1.23",
+		NumCpu:     8,
+		Gomaxprocs: -1,
+		Warnings:   []string{"", "w"},
+
+		CpuModel:        "cpu",
+		Overhead:        25,
+		Precision:       0.5,
+		TscSupported:    true,
+		ClockSource:     "tsc",
+		SyscallOverhead: 300,
+		Hypervisor:      "kvm",
+		Container:       "docker",
+		CpuQuota:        1.5,
+		Governors:       []string{"performance"},
+		Clocks: &hrtimepb.Clocks{
+			Now: &hrtimepb.ClockBackend{Name: "vdso", Available: true, Resolution: 1, Overhead: 20},
+			Tsc: &hrtimepb.ClockBackend{Fallback: "none"},
+		},
+	},
+	Sources: []*hrtimepb.Source{
+		{Name: "a", Count: 2, Stats: &hrtimepb.Stats{Mean: 1}, Straggler: true},
+		{},
+	},
+}
+
+// readGolden reads hex bytes from testdata/result.golden, ignoring comments.
+func readGolden(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "result.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var golden []byte
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for _, field := range strings.Fields(line) {
+			b, err := hex.DecodeString(field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden = append(golden, b...)
+		}
+	}
+	return golden
+}
+
+func TestResultGolden(t *testing.T) {
+	golden := readGolden(t)
+	if data := goldenResult.Marshal(); !bytes.Equal(data, golden) {
+		t.Errorf("encoding mismatch:\n got  % x\n want % x", data, golden)
+	}
+
+	var decoded hrtimepb.Result
+	if err := decoded.Unmarshal(golden); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, goldenResult) {
+		t.Errorf("decoding mismatch:\n%+v\n%+v", &decoded, goldenResult)
+	}
+}
+
+// protoField is a field declared in results.proto.
+type protoField struct {
+	typ      string
+	repeated bool
+}
+
+// readSchema parses messages and their fields from results.proto.
+func readSchema(t *testing.T) map[string]map[int]protoField {
+	t.Helper()
+	data, err := os.ReadFile("results.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := map[string]map[int]protoField{}
+	fieldPattern := regexp.MustCompile(`^(repeated\s+)?(map<string, string>|\w+)\s+\w+\s*=\s*(\d+);`)
+	var current map[int]protoField
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, "message "); ok {
+			current = map[int]protoField{}
+			messages[strings.TrimSuffix(name, " {")] = current
+			continue
+		}
+		if match := fieldPattern.FindStringSubmatch(line); match != nil && current != nil {
+			number, _ := strconv.Atoi(match[3])
+			current[number] = protoField{typ: match[2], repeated: match[1] != ""}
+		}
+	}
+	return messages
+}
+
+// checkSchema checks that every field in data is declared in message
+// with a wire type matching its declared type.
+func checkSchema(t *testing.T, schema map[string]map[int]protoField, message string, data []byte) {
+	t.Helper()
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			t.Fatalf("%s: invalid tag", message)
+		}
+		data = data[n:]
+		number, wireType := int(tag>>3), int(tag&7)
+
+		field, ok := schema[message][number]
+		if !ok {
+			t.Fatalf("%s: field %d is not declared in results.proto", message, number)
+		}
+
+		var value []byte
+		switch wireType {
+		case 0:
+			_, n = binary.Uvarint(data)
+			data = data[n:]
+		case 1:
+			data = data[8:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			value, data = data[n:n+int(size)], data[n+int(size):]
+		default:
+			t.Fatalf("%s: field %d has unexpected wire type %d", message, number, wireType)
+		}
+
+		var expected int
+		switch field.typ {
+		case "double":
+			expected = 1
+		case "bool", "int32", "int64", "uint32", "uint64":
+			expected = 0
+			if field.repeated {
+				// repeated scalars are packed
+				expected = 2
+			}
+		default:
+			expected = 2
+		}
+		if wireType != expected {
+			t.Errorf("%s: field %d of type %s has wire type %d, expected %d", message, number, field.typ, wireType, expected)
+		}
+
+		switch {
+		case field.typ == "map<string, string>":
+			checkSchema(t, map[string]map[int]protoField{"entry": {1: {typ: "string"}, 2: {typ: "string"}}}, "entry", value)
+		case schema[field.typ] != nil:
+			checkSchema(t, schema, field.typ, value)
+		}
+	}
+}
+
+func TestResultSchema(t *testing.T) {
+	schema := readSchema(t)
+	if len(schema["Result"]) != 9 || len(schema["Source"]) != 4 || len(schema["Stats"]) != 8 ||
+		len(schema["Environment"]) != 19 || len(schema["Clocks"]) != 2 || len(schema["ClockBackend"]) != 6 {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	checkSchema(t, schema, "Result", readGolden(t))
+	results := &hrtimepb.Results{Results: []*hrtimepb.Result{goldenResult}}
+	checkSchema(t, schema, "Results", results.Marshal())
+}
@@ -0,0 +1,149 @@
+package hrtimepb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// wire types as defined by protocol buffers encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var (
+	errTruncated  = errors.New("hrtimepb: truncated message")
+	errWireType   = errors.New("hrtimepb: unexpected wire type")
+	errOverflowed = errors.New("hrtimepb: varint overflow")
+)
+
+func appendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendBoolField(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return append(b, 1)
+}
+
+func appendDoubleField(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireFixed64)
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendStringField(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// decoder reads protocol buffer fields from a message.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+// next returns the next field number and wire type.
+func (d *decoder) next() (field int, wireType int, ok bool) {
+	if d.err != nil || len(d.data) == 0 {
+		return 0, 0, false
+	}
+	tag := d.varint()
+	if d.err != nil {
+		return 0, 0, false
+	}
+	return int(tag >> 3), int(tag & 7), true
+}
+
+func (d *decoder) varint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n == 0 {
+		d.err = errTruncated
+		return 0
+	}
+	if n < 0 {
+		d.err = errOverflowed
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) fixed64() uint64 {
+	if len(d.data) < 8 {
+		d.err = errTruncated
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.varint()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.data)) < n {
+		d.err = errTruncated
+		return nil
+	}
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+// skip skips a field value with the specified wire type.
+func (d *decoder) skip(wireType int) {
+	switch wireType {
+	case wireVarint:
+		d.varint()
+	case wireFixed64:
+		d.fixed64()
+	case wireBytes:
+		d.bytes()
+	case wireFixed32:
+		if len(d.data) < 4 {
+			d.err = errTruncated
+			return
+		}
+		d.data = d.data[4:]
+	default:
+		d.err = errWireType
+	}
+}
+
+// expect checks wire type and records an error on mismatch.
+func (d *decoder) expect(actual, expected int) bool {
+	if actual != expected {
+		d.err = errWireType
+		return false
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// Package hrtimeprom publishes latencies of benchmarks as Prometheus metrics.
+//
+// Collector implements prometheus.Collector, hence it can be registered with
+// an existing prometheus.Registry next to other metrics of a service. It is
+// a separate module to keep hrtime free of dependencies, hrtimemetrics
+// writes the same metrics in the text exposition format without depending
+// on the Prometheus client. Sources are read on every scrape, hence
+// RollingBenchmark can be used for live monitoring of a service.
+package hrtimeprom
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source provides laps for the collector.
+//
+// It's implemented by hrtime.RollingBenchmark, hrtime.Recorder and
+// completed hrtime.Benchmark.
+type Source interface {
+	Laps() []time.Duration
+}
+
+// DefaultQuantiles are the quantiles collected by NewCollector.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99, 0.999}
+
+// DefaultBuckets are the histogram bucket upper bounds collected by NewCollector.
+var DefaultBuckets = []time.Duration{
+	100 * time.Nanosecond, time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second,
+}
+
+// Collector collects percentiles and histogram buckets of registered sources.
+//
+// Every source is collected as a summary and a histogram with
+// a "benchmark" label containing the name of the source.
+type Collector struct {
+	// Quantiles are collected as a summary, in range [0, 1].
+	Quantiles []float64
+	// Buckets are upper bounds of the collected histogram.
+	Buckets []time.Duration
+
+	summary   *prometheus.Desc
+	histogram *prometheus.Desc
+
+	mu      sync.Mutex
+	sources map[string]Source
+}
+
+// NewCollector creates a collector of metrics prefixed with namespace,
+// using default quantiles and buckets.
+func NewCollector(namespace string) *Collector {
+	labels := []string{"benchmark"}
+	return &Collector{
+		Quantiles: DefaultQuantiles,
+		Buckets:   DefaultBuckets,
+
+		summary: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "lap_seconds"),
+			"Lap durations measured by hrtime.", labels, nil),
+		histogram: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "lap_histogram_seconds"),
+			"Histogram of lap durations measured by hrtime.", labels, nil),
+
+		sources: map[string]Source{},
+	}
+}
+
+// Register adds source with the specified name, replacing a previous source with the same name.
+func (collector *Collector) Register(name string, source Source) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.sources[name] = source
+}
+
+// Unregister removes source with the specified name.
+func (collector *Collector) Unregister(name string) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	delete(collector.sources, name)
+}
+
+// Describe implements prometheus.Collector.
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.summary
+	ch <- collector.histogram
+}
+
+// Collect implements prometheus.Collector.
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	collector.mu.Lock()
+	sources := make(map[string]Source, len(collector.sources))
+	for name, source := range collector.sources {
+		sources[name] = source
+	}
+	collector.mu.Unlock()
+
+	for name, source := range sources {
+		laps := source.Laps()
+		stats := hrtime.NewStats(laps)
+
+		var total time.Duration
+		for _, lap := range laps {
+			total += lap
+		}
+
+		quantiles := make(map[float64]float64, len(collector.Quantiles))
+		for _, q := range collector.Quantiles {
+			quantiles[q] = stats.Percentile(q).Seconds()
+		}
+		ch <- prometheus.MustNewConstSummary(collector.summary,
+			uint64(stats.Count), total.Seconds(), quantiles, name)
+
+		sorted := slices.Sorted(slices.Values(laps))
+		buckets := make(map[float64]uint64, len(collector.Buckets))
+		for _, bound := range collector.Buckets {
+			count, _ := slices.BinarySearch(sorted, bound+1)
+			buckets[bound.Seconds()] = uint64(count)
+		}
+		ch <- prometheus.MustNewConstHistogram(collector.histogram,
+			uint64(len(sorted)), total.Seconds(), buckets, name)
+	}
+}
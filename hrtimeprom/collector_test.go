@@ -0,0 +1,52 @@
+package hrtimeprom_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimeprom"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollector(t *testing.T) {
+	bench := hrtime.NewRollingBenchmark(100)
+	for i := 1; i <= 100; i++ {
+		bench.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	collector := hrtimeprom.NewCollector("hrtime")
+	collector.Register("api", bench)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 2 {
+		t.Fatalf("expected summary and histogram, got %v", families)
+	}
+
+	for _, family := range families {
+		metric := family.GetMetric()[0]
+		if label := metric.GetLabel()[0]; label.GetName() != "benchmark" || label.GetValue() != "api" {
+			t.Errorf("unexpected label %v", label)
+		}
+		switch family.GetName() {
+		case "hrtime_lap_seconds":
+			summary := metric.GetSummary()
+			if summary.GetSampleCount() != 100 || summary.GetQuantile()[0].GetValue() < 50e-6 {
+				t.Errorf("unexpected summary %v", summary)
+			}
+		case "hrtime_lap_histogram_seconds":
+			histogram := metric.GetHistogram()
+			// buckets are sorted by upper bound, 10µs is the third
+			if histogram.GetSampleCount() != 100 || histogram.GetBucket()[2].GetCumulativeCount() != 10 {
+				t.Errorf("unexpected histogram %v", histogram)
+			}
+		default:
+			t.Errorf("unexpected metric %v", family.GetName())
+		}
+	}
+}
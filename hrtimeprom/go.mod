@@ -0,0 +1,8 @@
+module github.com/loov/hrtime/hrtimeprom
+
+go 1.23
+
+require (
+	github.com/loov/hrtime v0.0.0-20261014192712-089936a860fb
+	github.com/prometheus/client_golang v1.20.5
+)
@@ -0,0 +1,277 @@
+package hrtimereport
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// WriteText writes report as plain text to w.
+func (report *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s\n%s\n\n", report.Title, strings.Repeat("=", len(report.Title)))
+
+	for _, section := range report.Sections {
+		fmt.Fprintf(w, "%s\n%s\n", section.Name, strings.Repeat("-", len(section.Name)))
+		for _, note := range section.Notes {
+			fmt.Fprintf(w, "%s\n", note)
+		}
+		for _, annotation := range section.Annotations {
+			fmt.Fprintf(w, "%s %s\n", annotation.Time.Format(time.RFC3339), annotation.Text)
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "name\tcount\tmean\tp50\tp90\tp99\tmax\t")
+		for _, r := range rows(section.Results) {
+			fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%v\t%v\t\n", r.Name, r.Count, r.Mean, r.P50, r.P90, r.P99, r.Max)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		writeTotalsText(w, section.Totals())
+		if err := writeSourcesText(w, section.Results); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, cross := range report.Comparisons {
+		fmt.Fprintf(w, "%s vs %s\n", cross.Old, cross.New)
+		for _, comparison := range cross.Comparisons {
+			if _, err := comparison.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprint(w, "Total: ")
+	writeTotalsText(w, report.Totals())
+	return err
+}
+
+// writeSourcesText writes per-source statistics of merged results,
+// stragglers are marked with an asterisk.
+func writeSourcesText(w io.Writer, results []*hrtime.JSONResult) error {
+	for _, result := range results {
+		if len(result.Sources) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s sources\n", result.Name)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "source\tcount\tmean\tp50\tp90\tp99\tmax\t\t")
+		for _, r := range sourceRows(result) {
+			fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%v\t%v\t%s\t\n", r.Name, r.Count, r.Mean, r.P50, r.P90, r.P99, r.Max, stragglerMark(r.Straggler))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stragglerMark returns the marker of straggler sources.
+func stragglerMark(straggler bool) string {
+	if straggler {
+		return "*"
+	}
+	return ""
+}
+
+// writeTotalsText writes totals as a single line.
+func writeTotalsText(w io.Writer, t Totals) {
+	fmt.Fprintf(w, "%d results, %d laps, %v measured\n", t.Results, t.Laps, hrtime.RoundDuration(t.Measured))
+}
+
+// WriteMarkdown writes report as markdown to w.
+func (report *Report) WriteMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# %s\n\n", report.Title)
+
+	for _, section := range report.Sections {
+		fmt.Fprintf(w, "## %s\n\n", section.Name)
+		for _, note := range section.Notes {
+			fmt.Fprintf(w, "%s\n\n", note)
+		}
+		for _, annotation := range section.Annotations {
+			fmt.Fprintf(w, "- %s %s\n", annotation.Time.Format(time.RFC3339), annotation.Text)
+		}
+		if len(section.Annotations) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "| name | count | mean | p50 | p90 | p99 | max |")
+		fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|")
+		for _, r := range rows(section.Results) {
+			fmt.Fprintf(w, "| %s | %d | %v | %v | %v | %v | %v |\n",
+				escapeMarkdown(r.Name), r.Count, r.Mean, r.P50, r.P90, r.P99, r.Max)
+		}
+		fmt.Fprintf(w, "\n")
+		writeTotalsText(w, section.Totals())
+		fmt.Fprintln(w)
+
+		for _, result := range section.Results {
+			if len(result.Sources) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "### %s sources\n\n", escapeMarkdown(result.Name))
+			fmt.Fprintln(w, "| source | count | mean | p50 | p90 | p99 | max | |")
+			fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|---:|---|")
+			for _, r := range sourceRows(result) {
+				fmt.Fprintf(w, "| %s | %d | %v | %v | %v | %v | %v | %s |\n",
+					escapeMarkdown(r.Name), r.Count, r.Mean, r.P50, r.P90, r.P99, r.Max, stragglerMark(r.Straggler))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, cross := range report.Comparisons {
+		fmt.Fprintf(w, "## %s vs %s\n\n", cross.Old, cross.New)
+		fmt.Fprintln(w, "| name | metric | old | new | change |")
+		fmt.Fprintln(w, "|---|---|---:|---:|---:|")
+		for _, comparison := range cross.Comparisons {
+			for _, delta := range comparison.Deltas {
+				fmt.Fprintf(w, "| %s | %s | %v | %v | %+.2f%% |\n",
+					escapeMarkdown(comparison.Name), delta.Metric,
+					hrtime.RoundDuration(delta.Old), hrtime.RoundDuration(delta.New), delta.Change*100)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprint(w, "**Total:** ")
+	writeTotalsText(w, report.Totals())
+	return err
+}
+
+// escapeMarkdown escapes characters breaking markdown tables.
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// htmlTemplate renders Report as a self-contained HTML document.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rows":    rows,
+	"sources": sourceRows,
+	"round":   hrtime.RoundDuration,
+	"percent": func(change float64) string { return fmt.Sprintf("%+.2f%%", change*100) },
+	"chart":   chart,
+	"series":  seriesChart,
+	"heatmap": heatmapChart,
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.slower { color: #b00; }
+.faster { color: #070; }
+.environment { color: #555; }
+.straggler { background: #fdd; }
+details { margin-bottom: 1em; }
+svg rect:hover { fill: #f58518; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}
+<h2>{{.Name}}</h2>
+{{range .Notes}}<p>{{.}}</p>{{end}}
+{{with .Annotations}}<ul>{{range .}}<li>{{rfc3339 .Time}} {{.Text}}</li>{{end}}</ul>{{end}}
+{{with .Environment}}<p class="environment">{{.GOOS}}/{{.GOARCH}}{{with .CPUModel}}, {{.}}{{end}}, {{.NumCPU}} CPUs, {{.GoVersion}}{{with .Hostname}}, {{.}}{{end}}</p>
+{{range .Warnings}}<p class="environment">warning: {{.}}</p>{{end}}{{end}}
+<table>
+<tr><th>name</th><th>count</th><th>mean</th><th>p50</th><th>p90</th><th>p99</th><th>max</th></tr>
+{{range rows .Results}}<tr><td>{{.Name}}</td><td>{{.Count}}</td><td>{{.Mean}}</td><td>{{.P50}}</td><td>{{.P90}}</td><td>{{.P99}}</td><td>{{.Max}}</td></tr>
+{{end}}</table>
+{{with .Totals}}<p>{{.Results}} results, {{.Laps}} laps, {{round .Measured}} measured</p>{{end}}
+{{range rows .Results}}<details open>
+<summary>{{.Name}}</summary>
+<table>
+<tr><th>min</th><th>p50</th><th>p90</th><th>p99</th><th>p99.9</th><th>p99.99</th><th>max</th></tr>
+<tr><td>{{.Min}}</td><td>{{.P50}}</td><td>{{.P90}}</td><td>{{.P99}}</td><td>{{.P999}}</td><td>{{.P9999}}</td><td>{{.Max}}</td></tr>
+</table>
+{{with .Result.Metadata}}<table>
+{{range $key, $value := .}}<tr><td>{{$key}}</td><td>{{$value}}</td></tr>
+{{end}}</table>{{end}}
+{{with sources .Result}}<table>
+<tr><th>source</th><th>count</th><th>mean</th><th>p50</th><th>p90</th><th>p99</th><th>max</th></tr>
+{{range .}}<tr{{if .Straggler}} class="straggler"{{end}}><td>{{.Name}}</td><td>{{.Count}}</td><td>{{.Mean}}</td><td>{{.P50}}</td><td>{{.P90}}</td><td>{{.P99}}</td><td>{{.Max}}</td></tr>
+{{end}}</table>{{end}}
+{{chart .Result}}
+{{series .Result}}
+{{heatmap .Result}}
+</details>
+{{end}}{{end}}
+{{range .Comparisons}}
+<h2>{{.Old}} vs {{.New}}</h2>
+<table>
+<tr><th>name</th><th>metric</th><th>old</th><th>new</th><th>change</th></tr>
+{{range $comparison := .Comparisons}}{{range .Deltas}}<tr><td>{{$comparison.Name}}</td><td>{{.Metric}}</td><td>{{round .Old}}</td><td>{{round .New}}</td><td class="{{if gt .Change 0.0}}slower{{else if lt .Change 0.0}}faster{{end}}">{{percent .Change}}</td></tr>
+{{end}}{{end}}</table>
+{{end}}
+{{with .Totals}}<p><b>Total:</b> {{.Results}} results, {{.Laps}} laps, {{round .Measured}} measured</p>{{end}}
+</body>
+</html>
+`))
+
+// chartBins is the number of bins in latency distribution charts.
+const chartBins = 20
+
+// chart renders the latency distribution of result as inline SVG.
+//
+// It returns an empty chart when the result doesn't include laps.
+func chart(result *hrtime.JSONResult) template.HTML {
+	if len(result.Laps) == 0 {
+		return ""
+	}
+	opts := hrtime.HistogramOptions{BinCount: chartBins, NiceRange: true, ClampPercentile: 0.999}
+	hist := hrtime.NewDurationHistogram(result.Durations(), &opts)
+
+	var svg strings.Builder
+	_, _ = hist.WriteSVG(&svg)
+	// the SVG is generated from numbers and formatted durations only
+	return template.HTML(svg.String())
+}
+
+// seriesChart renders laps of result over time as inline SVG.
+//
+// Laps in results are in the order they were measured, hence the chart
+// shows whether latency degraded during the run.
+func seriesChart(result *hrtime.JSONResult) template.HTML {
+	if len(result.Laps) < 2 {
+		return ""
+	}
+	var svg strings.Builder
+	_, _ = hrtime.NewTimeSeries(result.Durations()).WriteSVG(&svg)
+	// the SVG is generated from numbers and formatted durations only
+	return template.HTML(svg.String())
+}
+
+// heatmapMinLaps is the number of laps needed for a latency heatmap.
+const heatmapMinLaps = 100
+
+// heatmapChart renders the latency distribution of result over time as inline SVG.
+//
+// Short results don't have enough laps per window for a useful heatmap.
+func heatmapChart(result *hrtime.JSONResult) template.HTML {
+	if len(result.Laps) < heatmapMinLaps {
+		return ""
+	}
+	var svg strings.Builder
+	_, _ = hrtime.NewTimeSeries(result.Durations()).Heatmap(60, chartBins).WriteSVG(&svg)
+	// the SVG is generated from numbers and formatted durations only
+	return template.HTML(svg.String())
+}
+
+// WriteHTML writes report as a self-contained HTML document to w.
+func (report *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, report)
+}
@@ -0,0 +1,200 @@
+// Package hrtimereport assembles results from multiple suites or runs
+// into a single document.
+//
+// Report is built from sections, each containing results of a single suite
+// or run, together with totals and comparisons between sections.
+// It can be rendered as plain text, markdown or HTML. The HTML document is
+// self-contained and includes latency distribution, latency over time and
+// latency heatmap charts as inline SVG, percentile tables and the measurement environment.
+package hrtimereport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// Report is a composite report across suites.
+type Report struct {
+	Title    string
+	Sections []*Section
+	// Comparisons contains cross-section comparisons.
+	Comparisons []*CrossComparison
+}
+
+// Section contains results of a single suite or run.
+type Section struct {
+	Name    string
+	Notes   []string
+	Results []*hrtime.JSONResult
+	// Annotations contains operational events that happened during the run.
+	Annotations []hrtime.Annotation
+}
+
+// CrossComparison compares results with the same name in two sections.
+type CrossComparison struct {
+	Old, New    string
+	Comparisons []*hrtime.Comparison
+}
+
+// Totals summarizes a set of results.
+type Totals struct {
+	Results int
+	Laps    int
+	// Measured is the sum of all lap durations.
+	Measured time.Duration
+}
+
+// New creates an empty report.
+func New(title string) *Report {
+	return &Report{Title: title}
+}
+
+// Add adds a section with results.
+func (report *Report) Add(name string, results ...*hrtime.JSONResult) *Section {
+	section := &Section{Name: name, Results: results}
+	report.Sections = append(report.Sections, section)
+	return section
+}
+
+// captureEnv captures the environment once for all benchmarks added to reports.
+var captureEnv = sync.OnceValue(hrtime.CaptureEnv)
+
+// AddBenchmark adds laps of a completed benchmark to the section.
+//
+// The result includes the current environment and sources of merged benchmarks.
+func (section *Section) AddBenchmark(name string, bench *hrtime.Benchmark) *Section {
+	result := bench.JSONResult(name)
+	result.Environment = captureEnv()
+	section.Results = append(section.Results, result)
+	return section
+}
+
+// Environment returns the environment of the first result that has one.
+func (section *Section) Environment() *hrtime.Environment {
+	for _, result := range section.Results {
+		if result.Environment != nil {
+			return result.Environment
+		}
+	}
+	return nil
+}
+
+// Note adds a free-form note to the section.
+func (section *Section) Note(text string) *Section {
+	section.Notes = append(section.Notes, text)
+	return section
+}
+
+// Annotate adds events, e.g. from Recorder.Annotations, to the section.
+func (section *Section) Annotate(annotations ...hrtime.Annotation) *Section {
+	section.Annotations = append(section.Annotations, annotations...)
+	return section
+}
+
+// Totals returns totals of the section.
+func (section *Section) Totals() Totals {
+	return totals(section.Results)
+}
+
+// Compare adds comparison of results in section old against section new.
+//
+// It panics when either of the sections doesn't exist.
+func (report *Report) Compare(old, new string) *CrossComparison {
+	oldSection, newSection := report.mustSection(old), report.mustSection(new)
+	comparison := &CrossComparison{
+		Old:         old,
+		New:         new,
+		Comparisons: hrtime.CompareResults(oldSection.Results, newSection.Results),
+	}
+	report.Comparisons = append(report.Comparisons, comparison)
+	return comparison
+}
+
+// Totals returns totals of all sections.
+func (report *Report) Totals() Totals {
+	var all Totals
+	for _, section := range report.Sections {
+		t := section.Totals()
+		all.Results += t.Results
+		all.Laps += t.Laps
+		all.Measured += t.Measured
+	}
+	return all
+}
+
+// mustSection finds a section by name.
+func (report *Report) mustSection(name string) *Section {
+	for _, section := range report.Sections {
+		if section.Name == name {
+			return section
+		}
+	}
+	panic("section " + name + " does not exist")
+}
+
+// totals calculates totals of results.
+func totals(results []*hrtime.JSONResult) Totals {
+	t := Totals{Results: len(results)}
+	for _, result := range results {
+		t.Laps += result.Count
+		t.Measured += time.Duration(result.Stats.Mean * float64(result.Count))
+	}
+	return t
+}
+
+// row contains formatted statistics of a single result.
+type row struct {
+	Name                     string
+	Count                    int
+	Mean, P50, P90, P99, Max time.Duration
+	Min, P999, P9999         time.Duration
+	Result                   *hrtime.JSONResult
+	// Straggler is set for sources that are considered stragglers.
+	Straggler bool
+}
+
+// rows formats results for rendering.
+func rows(results []*hrtime.JSONResult) []row {
+	rows := make([]row, len(results))
+	for i, result := range results {
+		rows[i] = row{
+			Name:  result.Name,
+			Count: result.Count,
+			Mean:  hrtime.RoundDuration(time.Duration(result.Stats.Mean)),
+			P50:   hrtime.RoundDuration(time.Duration(result.Stats.P50)),
+			P90:   hrtime.RoundDuration(time.Duration(result.Stats.P90)),
+			P99:   hrtime.RoundDuration(time.Duration(result.Stats.P99)),
+			Max:   hrtime.RoundDuration(time.Duration(result.Stats.Maximum)),
+
+			Min:    hrtime.RoundDuration(time.Duration(result.Stats.Minimum)),
+			P999:   hrtime.RoundDuration(time.Duration(result.Stats.P999)),
+			P9999:  hrtime.RoundDuration(time.Duration(result.Stats.P9999)),
+			Result: result,
+		}
+	}
+	return rows
+}
+
+// sourceRows formats sources of a merged result for rendering.
+func sourceRows(result *hrtime.JSONResult) []row {
+	rows := make([]row, len(result.Sources))
+	for i, source := range result.Sources {
+		rows[i] = row{
+			Name:  source.Name,
+			Count: source.Count,
+			Mean:  hrtime.RoundDuration(time.Duration(source.Stats.Mean)),
+			P50:   hrtime.RoundDuration(time.Duration(source.Stats.P50)),
+			P90:   hrtime.RoundDuration(time.Duration(source.Stats.P90)),
+			P99:   hrtime.RoundDuration(time.Duration(source.Stats.P99)),
+			Max:   hrtime.RoundDuration(time.Duration(source.Stats.Maximum)),
+
+			Min:       hrtime.RoundDuration(time.Duration(source.Stats.Minimum)),
+			P999:      hrtime.RoundDuration(time.Duration(source.Stats.P999)),
+			P9999:     hrtime.RoundDuration(time.Duration(source.Stats.P9999)),
+			Straggler: source.Straggler,
+		}
+	}
+	return rows
+}
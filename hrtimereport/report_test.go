@@ -0,0 +1,102 @@
+package hrtimereport_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimereport"
+)
+
+func TestReport(t *testing.T) {
+	report := hrtimereport.New("Weekly")
+	report.Add("last week", hrtime.NewJSONResult("parse", []time.Duration{100, 100}))
+	report.Add("this week", hrtime.NewJSONResult("parse", []time.Duration{150, 150})).Note("after refactoring").
+		Annotate(hrtime.Annotation{Time: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), Text: "deploy v2"})
+	report.Compare("last week", "this week")
+
+	if totals := report.Totals(); totals.Results != 2 || totals.Laps != 4 || totals.Measured != 500 {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+
+	var text, markdown, html strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+
+	for format, output := range map[string]string{"text": text.String(), "markdown": markdown.String(), "html": html.String()} {
+		for _, expected := range []string{"Weekly", "this week", "after refactoring", "50.00%", "4 laps", "2020-01-01T12:00:00Z deploy v2"} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("%s output missing %q:\n%s", format, expected, output)
+			}
+		}
+	}
+}
+
+func TestReportHTML(t *testing.T) {
+	bench := hrtime.NewBenchmark(100)
+	for bench.Next() {
+	}
+
+	report := hrtimereport.New("Latency")
+	report.Add("run").AddBenchmark("empty loop", bench)
+	section := report.Sections[0]
+	section.Results[0].Metadata = map[string]string{"commit": "abc123"}
+	if section.Environment() == nil {
+		t.Fatal("expected environment of the benchmark")
+	}
+
+	var html strings.Builder
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<svg", "<polyline", "fill-opacity", "<title>", "p99.99", "empty loop", "abc123", section.Environment().GOARCH} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("html output missing %q", expected)
+		}
+	}
+}
+
+func TestReportSources(t *testing.T) {
+	record := func(lap time.Duration) *hrtime.Benchmark {
+		clock := hrtime.NewManualClock(time.Second)
+		bench := hrtime.NewBenchmark(10, hrtime.WithClock(clock))
+		for bench.Next() {
+			clock.Advance(lap)
+		}
+		return bench
+	}
+	merged := hrtime.MergeBenchmarksNamed([]string{"fast-0", "fast-1", "slow"}, record(100), record(100), record(900))
+
+	report := hrtimereport.New("Workers")
+	report.Add("run").AddBenchmark("pool", merged)
+
+	var text, markdown, html strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.WriteMarkdown(&markdown); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+
+	for format, output := range map[string]string{"text": text.String(), "markdown": markdown.String()} {
+		for _, expected := range []string{"pool sources", "fast-1", "slow"} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("%s output missing %q:\n%s", format, expected, output)
+			}
+		}
+	}
+	if !strings.Contains(html.String(), `<tr class="straggler"><td>slow</td>`) {
+		t.Errorf("html output missing straggler:\n%s", html.String())
+	}
+}
@@ -0,0 +1,214 @@
+// Package hrtimestore implements a directory based store of benchmark runs.
+//
+// Every run is a set of results saved together. Runs can be tagged with
+// names such as "baseline-v1.2", and later runs compared against a tag
+// instead of only the previous run.
+package hrtimestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// Special references resolved by Store.Resolve.
+const (
+	Latest   = "latest"
+	Previous = "previous"
+)
+
+// ErrNotFound is returned when a run or tag doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// runIDFormat is used for run identifiers, which sort chronologically.
+const runIDFormat = "20060102T150405.000000000Z"
+
+// Store keeps runs in a directory.
+//
+// Runs are saved as runs/<id>.json and tags as tags/<name>,
+// containing the tagged run id.
+type Store struct {
+	dir string
+}
+
+// Open opens or creates a store in dir.
+func Open(dir string) (*Store, error) {
+	for _, sub := range []string{"runs", "tags"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save saves results as a new run and returns its id.
+func (store *Store) Save(results []*hrtime.JSONResult) (string, error) {
+	// stamp copies to leave results of the caller unchanged
+	stamped := make([]*hrtime.JSONResult, len(results))
+	for i, result := range results {
+		copied := *result
+		copied.SchemaVersion = hrtime.SchemaVersion
+		stamped[i] = &copied
+	}
+	data, err := json.MarshalIndent(stamped, "", "\t")
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	for {
+		id := now.Format(runIDFormat)
+		file, err := os.OpenFile(store.runPath(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			now = now.Add(time.Nanosecond)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := file.Write(data); err != nil {
+			_ = file.Close()
+			return "", err
+		}
+		return id, file.Close()
+	}
+}
+
+// Runs returns run ids in chronological order.
+func (store *Store) Runs() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(store.dir, "runs"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Load loads results of a run, ref is resolved using Resolve.
+func (store *Store) Load(ref string) ([]*hrtime.JSONResult, error) {
+	id, err := store.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(store.runPath(id))
+	if err != nil {
+		return nil, err
+	}
+	var results []*hrtime.JSONResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("run %s: %w", id, err)
+	}
+	return results, nil
+}
+
+// Tag tags a run with name, ref is resolved using Resolve.
+//
+// Tagging with an existing name moves the tag.
+func (store *Store) Tag(name, ref string) error {
+	if !validTag(name) {
+		return fmt.Errorf("invalid tag name %q", name)
+	}
+	id, err := store.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(store.dir, "tags", name), []byte(id+"\n"), 0o644)
+}
+
+// Tags returns tagged run ids by tag name.
+func (store *Store) Tags() (map[string]string, error) {
+	entries, err := os.ReadDir(filepath.Join(store.dir, "tags"))
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(store.dir, "tags", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		tags[entry.Name()] = strings.TrimSpace(string(data))
+	}
+	return tags, nil
+}
+
+// Resolve converts ref into a run id.
+//
+// ref can be Latest, Previous, a tag name or a run id.
+func (store *Store) Resolve(ref string) (string, error) {
+	runs, err := store.Runs()
+	if err != nil {
+		return "", err
+	}
+
+	switch ref {
+	case Latest:
+		if len(runs) < 1 {
+			return "", fmt.Errorf("%s run: %w", ref, ErrNotFound)
+		}
+		return runs[len(runs)-1], nil
+	case Previous:
+		if len(runs) < 2 {
+			return "", fmt.Errorf("%s run: %w", ref, ErrNotFound)
+		}
+		return runs[len(runs)-2], nil
+	}
+
+	if validTag(ref) {
+		data, err := os.ReadFile(filepath.Join(store.dir, "tags", ref))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	index := sort.SearchStrings(runs, ref)
+	if index < len(runs) && runs[index] == ref {
+		return ref, nil
+	}
+	return "", fmt.Errorf("run or tag %q: %w", ref, ErrNotFound)
+}
+
+// Compare compares run ref against baseline, both are resolved using Resolve.
+func (store *Store) Compare(baseline, ref string) ([]*hrtime.Comparison, error) {
+	old, err := store.Load(baseline)
+	if err != nil {
+		return nil, err
+	}
+	new, err := store.Load(ref)
+	if err != nil {
+		return nil, err
+	}
+	return hrtime.CompareResults(old, new), nil
+}
+
+// runPath returns path of the run file.
+func (store *Store) runPath(id string) string {
+	return filepath.Join(store.dir, "runs", id+".json")
+}
+
+// validTag checks whether name can be used as a tag file name.
+func validTag(name string) bool {
+	if name == "" || name == Latest || name == Previous || strings.HasPrefix(name, ".") {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\:`)
+}
@@ -0,0 +1,67 @@
+package hrtimestore_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+	"github.com/loov/hrtime/hrtimestore"
+)
+
+func TestStoreTags(t *testing.T) {
+	store, err := hrtimestore.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	save := func(lap time.Duration) string {
+		id, err := store.Save([]*hrtime.JSONResult{hrtime.NewJSONResult("bench", []time.Duration{lap, lap})})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	baseline := save(100)
+	if err := store.Tag("baseline-v1.2", hrtimestore.Latest); err != nil {
+		t.Fatal(err)
+	}
+	save(110)
+	latest := save(200)
+
+	if id, _ := store.Resolve(hrtimestore.Latest); id != latest {
+		t.Errorf("expected latest %v, got %v", latest, id)
+	}
+	if id, _ := store.Resolve("baseline-v1.2"); id != baseline {
+		t.Errorf("expected baseline %v, got %v", baseline, id)
+	}
+
+	comparisons, err := store.Compare("baseline-v1.2", hrtimestore.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparisons) != 1 || comparisons[0].Deltas[0].Change != 1 {
+		t.Errorf("unexpected comparison against tag %v", comparisons)
+	}
+
+	comparisons, err = store.Compare(hrtimestore.Previous, hrtimestore.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change := comparisons[0].Deltas[0].Change; change < 0.8 || change > 0.82 {
+		t.Errorf("unexpected comparison against previous %v", change)
+	}
+
+	if _, err := store.Resolve("missing"); !errors.Is(err, hrtimestore.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	unversioned := &hrtime.JSONResult{Name: "unversioned"}
+	if _, err := store.Save([]*hrtime.JSONResult{unversioned}); err != nil {
+		t.Fatal(err)
+	}
+	if unversioned.SchemaVersion != 0 {
+		t.Errorf("Save modified the result of the caller: %+v", unversioned)
+	}
+}
@@ -0,0 +1,56 @@
+package hrtimetest
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+// B drives a testing.B benchmark, measuring every iteration with hrtime.
+//
+// Usage:
+//
+//	func BenchmarkParse(tb *testing.B) {
+//		b := hrtimetest.WrapB(tb)
+//		for b.Next() {
+//			parse()
+//		}
+//	}
+//
+// After the loop, p50_ns, p90_ns and p99_ns are reported with ReportMetric
+// in addition to the standard ns/op.
+type B struct {
+	*testing.B
+
+	opts  []hrtime.Option
+	bench *hrtime.Benchmark
+}
+
+// WrapB wraps b, the benchmark is configured by opts.
+func WrapB(b *testing.B, opts ...hrtime.Option) *B {
+	return &B{B: b, opts: opts}
+}
+
+// Next starts measuring the next iteration.
+// It returns false after b.N iterations.
+func (b *B) Next() bool {
+	if b.bench == nil {
+		b.bench = hrtime.NewBenchmark(b.N, b.opts...)
+		b.ResetTimer()
+	}
+	if b.bench.Next() {
+		return true
+	}
+
+	b.StopTimer()
+	stats := b.bench.Stats()
+	b.ReportMetric(float64(stats.P50.Nanoseconds()), "p50_ns")
+	b.ReportMetric(float64(stats.P90.Nanoseconds()), "p90_ns")
+	b.ReportMetric(float64(stats.P99.Nanoseconds()), "p99_ns")
+	return false
+}
+
+// Benchmark returns the benchmark measuring the current b.N iterations.
+//
+// It's nil before the first call to Next.
+func (b *B) Benchmark() *hrtime.Benchmark { return b.bench }
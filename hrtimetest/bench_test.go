@@ -0,0 +1,26 @@
+package hrtimetest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime/hrtimetest"
+)
+
+func TestWrapB(t *testing.T) {
+	result := testing.Benchmark(func(tb *testing.B) {
+		b := hrtimetest.WrapB(tb)
+		for b.Next() {
+			time.Sleep(time.Microsecond)
+		}
+		if got := len(b.Benchmark().Laps()); got != tb.N {
+			t.Errorf("expected %d laps, got %d", tb.N, got)
+		}
+	})
+
+	for _, metric := range []string{"p50_ns", "p90_ns", "p99_ns"} {
+		if value, ok := result.Extra[metric]; !ok || value <= 0 {
+			t.Errorf("missing metric %s in %v", metric, result.Extra)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package hrtimetest implements test helpers, such as golden-file testing of
+// hrtime based reports and measuring testing.B benchmarks with hrtime.
+package hrtimetest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+// UpdateEnv is the environment variable, which when set to "1"
+// makes Golden overwrite golden files instead of comparing them.
+const UpdateEnv = "HRTIME_UPDATE_GOLDEN"
+
+// Normalizer replaces volatile parts of reports with placeholders.
+type Normalizer struct {
+	replacements []replacement
+}
+
+// replacement is a single normalization rule.
+type replacement struct {
+	pattern *regexp.Regexp
+	with    string
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	goVersionPattern = regexp.MustCompile(`go1\.\d+(\.\d+)?([a-z]+\d*)?`)
+	durationPattern  = regexp.MustCompile(`-?\d+(\.\d+)?(ns|µs|us|ms|s|m|h)([\d.]+(ns|µs|us|ms|s|m))*`)
+)
+
+// NewNormalizer creates a normalizer replacing hostname, Go version and timestamps.
+func NewNormalizer() *Normalizer {
+	normalizer := &Normalizer{}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		normalizer.Literal(hostname, "<hostname>")
+	}
+	normalizer.Literal(runtime.Version(), "<goversion>")
+	normalizer.Replace(goVersionPattern, "<goversion>")
+	normalizer.Replace(timestampPattern, "<timestamp>")
+	return normalizer
+}
+
+// Replace adds a rule replacing matches of pattern.
+func (normalizer *Normalizer) Replace(pattern *regexp.Regexp, with string) *Normalizer {
+	normalizer.replacements = append(normalizer.replacements, replacement{pattern: pattern, with: with})
+	return normalizer
+}
+
+// Literal adds a rule replacing all occurrences of s.
+func (normalizer *Normalizer) Literal(s, with string) *Normalizer {
+	return normalizer.Replace(regexp.MustCompile(regexp.QuoteMeta(s)), with)
+}
+
+// Durations adds a rule replacing all formatted durations, such as "1.23ms".
+//
+// It's useful when the report contains live measurements.
+func (normalizer *Normalizer) Durations() *Normalizer {
+	return normalizer.Replace(durationPattern, "<duration>")
+}
+
+// Normalize applies all rules to report.
+func (normalizer *Normalizer) Normalize(report string) string {
+	for _, r := range normalizer.replacements {
+		report = r.pattern.ReplaceAllLiteralString(report, r.with)
+	}
+	return report
+}
+
+// Golden compares got with the contents of golden file at path.
+//
+// When UpdateEnv is set to "1", the golden file is overwritten with got.
+func Golden(t testing.TB, path string, got string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (set %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if !bytes.Equal(expected, []byte(got)) {
+		t.Errorf("output differs from %s (set %s=1 to update):\n--- got\n%s\n--- expected\n%s", path, UpdateEnv, got, expected)
+	}
+}
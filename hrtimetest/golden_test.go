@@ -0,0 +1,32 @@
+package hrtimetest_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/loov/hrtime/hrtimetest"
+)
+
+func TestNormalizer(t *testing.T) {
+	hostname, _ := os.Hostname()
+	report := "host " + hostname + " " + runtime.Version() + " at 2024-01-02T03:04:05Z took 1.23ms"
+
+	got := hrtimetest.NewNormalizer().Durations().Normalize(report)
+	expected := "host <hostname> <goversion> at <timestamp> took <duration>"
+	if hostname == "" {
+		expected = "host  <goversion> at <timestamp> took <duration>"
+	}
+	if got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.golden")
+	if err := os.WriteFile(path, []byte("report"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hrtimetest.Golden(t, path, "report")
+}
@@ -7,6 +7,7 @@
 // Package also supports using hardware time stamp counters (TSC).
 // They offer better accuracy and on some platforms correspond to the processor cycles.
 // However, they are not supported on all platforms.
+// Use ClockInfo to find out which clocks are in use and why.
 //
 // The basic usage of this package looks like:
 //
@@ -35,9 +36,6 @@ func init() {
 	calculateNanosOverhead()
 
 	initCPU()
-	{
-		_, _, _, edx := cpuid(0x80000007, 0x0)
-		rdtscpInvariant = edx&(1<<8) != 0
-	}
+	rdtscpInvariant = counterInvariant()
 	calculateTSCOverhead()
 }
@@ -0,0 +1,96 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Jitter analyzes the schedule of a periodic task, e.g. a ticker loop.
+type Jitter struct {
+	// Period is the ideal interval between consecutive timestamps.
+	Period time.Duration
+	// Intervals between consecutive timestamps.
+	Intervals []time.Duration
+	// Deviations of intervals from the period, negative values mean early ticks.
+	Deviations []time.Duration
+	// Drift is the offset of the last timestamp from the ideal schedule.
+	Drift time.Duration
+	// MaxDrift is the largest absolute offset of a timestamp from the ideal schedule.
+	MaxDrift time.Duration
+}
+
+// NewJitter analyzes absolute timestamps, e.g. from Benchmark.Timestamps,
+// of a task that should run every period.
+//
+// The ideal schedule starts at the first timestamp. When period is zero,
+// the mean interval is used as the period.
+func NewJitter(timestamps []time.Duration, period time.Duration) *Jitter {
+	jitter := &Jitter{Period: period}
+	if len(timestamps) < 2 {
+		return jitter
+	}
+
+	jitter.Intervals = make([]time.Duration, len(timestamps)-1)
+	for i := range jitter.Intervals {
+		jitter.Intervals[i] = timestamps[i+1] - timestamps[i]
+	}
+	if jitter.Period <= 0 {
+		jitter.Period = (timestamps[len(timestamps)-1] - timestamps[0]) / time.Duration(len(jitter.Intervals))
+	}
+
+	jitter.Deviations = make([]time.Duration, len(jitter.Intervals))
+	for i, interval := range jitter.Intervals {
+		jitter.Deviations[i] = interval - jitter.Period
+	}
+
+	for i, at := range timestamps {
+		drift := at - (timestamps[0] + time.Duration(i)*jitter.Period)
+		jitter.Drift = drift
+		if drift < 0 {
+			drift = -drift
+		}
+		if drift > jitter.MaxDrift {
+			jitter.MaxDrift = drift
+		}
+	}
+	return jitter
+}
+
+// Stats calculates summary statistics of absolute deviations from the period.
+func (jitter *Jitter) Stats() *Stats {
+	absolute := make([]time.Duration, len(jitter.Deviations))
+	for i, deviation := range jitter.Deviations {
+		if deviation < 0 {
+			deviation = -deviation
+		}
+		absolute[i] = deviation
+	}
+	return NewStats(absolute)
+}
+
+// Histogram creates an histogram of intervals.
+func (jitter *Jitter) Histogram(binCount int) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	return NewDurationHistogram(jitter.Intervals, &opts)
+}
+
+// WriteTo writes jitter statistics to w.
+func (jitter *Jitter) WriteTo(w io.Writer) (int64, error) {
+	stats := jitter.Stats()
+	n, err := fmt.Fprintf(w, "  period %v;  intervals %d;  jitter p50 %v;  p99 %v;  max %v;\n  drift %v;  max drift %v;\n",
+		jitter.Period, len(jitter.Intervals),
+		formatStat(float64(stats.P50)), formatStat(float64(stats.P99)), formatStat(float64(stats.Maximum)),
+		formatStat(float64(jitter.Drift)), formatStat(float64(jitter.MaxDrift)))
+	return int64(n), err
+}
+
+// String returns jitter statistics as a string.
+func (jitter *Jitter) String() string {
+	var buffer strings.Builder
+	_, _ = jitter.WriteTo(&buffer)
+	return buffer.String()
+}
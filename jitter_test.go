@@ -0,0 +1,33 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestJitter(t *testing.T) {
+	ms := time.Millisecond
+	timestamps := []time.Duration{0, 10 * ms, 21 * ms, 30 * ms, 42 * ms}
+
+	jitter := hrtime.NewJitter(timestamps, 10*ms)
+	expected := []time.Duration{0, ms, -ms, 2 * ms}
+	for i, deviation := range jitter.Deviations {
+		if deviation != expected[i] {
+			t.Fatalf("expected deviations %v, got %v", expected, jitter.Deviations)
+		}
+	}
+	if jitter.Drift != 2*ms || jitter.MaxDrift != 2*ms {
+		t.Errorf("unexpected drift %v, max %v", jitter.Drift, jitter.MaxDrift)
+	}
+	if stats := jitter.Stats(); stats.Maximum != 2*ms || stats.Minimum != 0 {
+		t.Errorf("unexpected stats %v", stats)
+	}
+
+	estimated := hrtime.NewJitter(timestamps, 0)
+	if estimated.Period != 10500*time.Microsecond {
+		t.Errorf("unexpected estimated period %v", estimated.Period)
+	}
+	t.Log("\n" + jitter.String())
+}
@@ -0,0 +1,215 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// lapLabels tags laps with labels, it's shared by Benchmark and BenchmarkTSC.
+type lapLabels struct {
+	// labels contains an index into labelNames for each lap, when using NextWithLabel.
+	labels     []uint32
+	labelNames []string
+	lastLabel  uint32
+}
+
+// NextWithLabel starts measuring the next lap tagged with label.
+//
+// Labels allow a single benchmark to capture several phases, e.g. "parse",
+// "execute" and "serialize", and report them separately using ForLabel.
+// Laps started with Next have an empty label. It will return false,
+// when all measurements have been made.
+func (bench *Benchmark) NextWithLabel(label string) bool {
+	// label is assigned before reading time to keep it out of the lap
+	if !bench.unsupportedBySampling("labels") {
+		bench.setLabel(label, bench.step, len(bench.laps), bench.unbounded)
+	}
+	return bench.Next()
+}
+
+// NextWithLabel starts measuring the next lap tagged with label,
+// see Benchmark.NextWithLabel.
+func (bench *BenchmarkTSC) NextWithLabel(label string) bool {
+	if !bench.unsupportedBySampling("labels") {
+		bench.setLabel(label, bench.step, len(bench.laps), bench.unbounded)
+	}
+	return bench.Next()
+}
+
+// setLabel assigns label to the lap started at step, when there are count laps.
+func (labels *lapLabels) setLabel(label string, step, count int, unbounded bool) {
+	if labels.labelNames == nil {
+		labels.labelNames = []string{""}
+		if !unbounded {
+			labels.labels = make([]uint32, count)
+		} else {
+			labels.labels = []uint32{}
+		}
+	}
+
+	index := labels.lastLabel
+	if labels.labelNames[index] != label {
+		index = uint32(len(labels.labelNames))
+		for i, name := range labels.labelNames {
+			if name == label {
+				index = uint32(i)
+				break
+			}
+		}
+		if int(index) == len(labels.labelNames) {
+			labels.labelNames = append(labels.labelNames, label)
+		}
+		labels.lastLabel = index
+	}
+
+	if step >= count && !unbounded {
+		return
+	}
+	for len(labels.labels) < step {
+		labels.labels = append(labels.labels, 0)
+	}
+	if len(labels.labels) == step {
+		labels.labels = append(labels.labels, index)
+	} else {
+		labels.labels[step] = index
+	}
+}
+
+// alignLabels ensures there's exactly one label for each of count laps.
+func (labels *lapLabels) alignLabels(count int) {
+	if len(labels.labels) > count {
+		labels.labels = labels.labels[:count]
+	}
+	for len(labels.labels) < count {
+		labels.labels = append(labels.labels, 0)
+	}
+}
+
+// lapLabelNames returns the label of each lap.
+func (labels *lapLabels) lapLabelNames() []string {
+	if labels.labels == nil {
+		return nil
+	}
+	names := make([]string, len(labels.labels))
+	for i, index := range labels.labels {
+		names[i] = labels.labelNames[index]
+	}
+	return names
+}
+
+// distinctLabels returns distinct labels of laps in the order of first use.
+func (labels *lapLabels) distinctLabels() []string {
+	used := make([]bool, len(labels.labelNames))
+	for _, index := range labels.labels {
+		used[index] = true
+	}
+
+	var names []string
+	for i, name := range labels.labelNames {
+		if used[i] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lapsWithLabel returns laps tagged with label.
+func (labels *lapLabels) lapsWithLabel(laps []time.Duration, label string) []time.Duration {
+	var tagged []time.Duration
+	for i, index := range labels.labels {
+		if labels.labelNames[index] == label {
+			tagged = append(tagged, laps[i])
+		}
+	}
+	return tagged
+}
+
+// LapLabels returns the label of each lap.
+//
+// It returns nil, unless NextWithLabel was used.
+func (bench *Benchmark) LapLabels() []string {
+	bench = bench.mustBeCompleted()
+	return bench.lapLabelNames()
+}
+
+// LapLabels returns the label of each lap.
+//
+// It returns nil, unless NextWithLabel was used.
+func (bench *BenchmarkTSC) LapLabels() []string {
+	bench = bench.mustBeCompleted()
+	return bench.lapLabelNames()
+}
+
+// Labels returns distinct labels of laps in the order of first use.
+func (bench *Benchmark) Labels() []string {
+	bench = bench.mustBeCompleted()
+	return bench.distinctLabels()
+}
+
+// Labels returns distinct labels of laps in the order of first use.
+func (bench *BenchmarkTSC) Labels() []string {
+	bench = bench.mustBeCompleted()
+	return bench.distinctLabels()
+}
+
+// ForLabel returns the result of laps tagged with label.
+func (bench *Benchmark) ForLabel(label string) *Result {
+	bench = bench.mustBeCompleted()
+	result := bench.analysis()
+	result.laps = bench.lapsWithLabel(bench.laps, label)
+	return result
+}
+
+// ForLabel returns the result of laps tagged with label.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) ForLabel(label string) *Result {
+	bench = bench.mustBeCompleted()
+	result := *bench.Result()
+	result.laps = bench.lapsWithLabel(result.laps, label)
+	return &result
+}
+
+// WriteLabelStatsTo writes summary statistics of each label to w.
+func (bench *Benchmark) WriteLabelStatsTo(w io.Writer) (int64, error) {
+	return writeLabelStats(w, bench.Labels(), bench.ForLabel)
+}
+
+// WriteLabelStatsTo writes summary statistics of each label to w.
+func (bench *BenchmarkTSC) WriteLabelStatsTo(w io.Writer) (int64, error) {
+	return writeLabelStats(w, bench.Labels(), bench.ForLabel)
+}
+
+// writeLabelStats writes summary statistics of the result of each label to w.
+func writeLabelStats(w io.Writer, labels []string, forLabel func(string) *Result) (int64, error) {
+	var written int64
+	for _, label := range labels {
+		n, err := fmt.Fprintf(w, "%s:\n", label)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		m, err := forLabel(label).Stats().WriteTo(w)
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// StringLabelStats returns summary statistics of each label.
+func (bench *Benchmark) StringLabelStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteLabelStatsTo(&buffer)
+	return buffer.String()
+}
+
+// StringLabelStats returns summary statistics of each label.
+func (bench *BenchmarkTSC) StringLabelStats() string {
+	var buffer strings.Builder
+	_, _ = bench.WriteLabelStatsTo(&buffer)
+	return buffer.String()
+}
@@ -0,0 +1,71 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkLabels(t *testing.T) {
+	phases := []string{"parse", "execute", "serialize"}
+
+	bench := hrtime.NewBenchmark(9, hrtime.WithWarmup(1))
+	for i := 0; bench.NextWithLabel(phases[i%3]); i++ {
+		if phases[i%3] == "execute" {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	labels := bench.LapLabels()
+	if len(labels) != 9 {
+		t.Fatalf("expected 9 labels, got %v", labels)
+	}
+	// the first parse lap was used as warmup
+	if labels[0] != "execute" {
+		t.Errorf("labels not aligned after warmup: %v", labels)
+	}
+	if got := bench.Labels(); len(got) != 3 {
+		t.Errorf("expected 3 distinct labels, got %v", got)
+	}
+
+	execute := bench.ForLabel("execute")
+	parse := bench.ForLabel("parse")
+	if execute.Count() != 3 || parse.Count() != 3 {
+		t.Errorf("unexpected counts %d %d", execute.Count(), parse.Count())
+	}
+	if execute.Stats().Minimum < time.Millisecond || parse.Stats().Maximum >= time.Millisecond {
+		t.Errorf("laps assigned to wrong labels:\n%s", bench.StringLabelStats())
+	}
+	t.Log("\n" + bench.StringLabelStats())
+
+	streaming := hrtime.NewStreamingBenchmark()
+	for i := 0; i < 4 && streaming.NextWithLabel("a"); i++ {
+	}
+	streaming.Next()
+	streaming.Stop()
+	if labels := streaming.LapLabels(); len(labels) != 5 || labels[3] != "a" || labels[4] != "" {
+		t.Errorf("unexpected streaming labels %q", labels)
+	}
+}
+
+func TestStreamingBenchmarkLabels(t *testing.T) {
+	bench := hrtime.NewStreamingBenchmark()
+	benchTSC := hrtime.NewStreamingBenchmarkTSC()
+	for i := 0; i < 6; i++ {
+		label := []string{"a", "b"}[i%2]
+		bench.NextWithLabel(label)
+		benchTSC.NextWithLabel(label)
+	}
+	bench.Stop()
+	benchTSC.Stop()
+
+	for _, labels := range [][]string{bench.LapLabels(), benchTSC.LapLabels()} {
+		if len(labels) != 6 || labels[0] != "a" || labels[5] != "b" {
+			t.Errorf("unexpected labels %q", labels)
+		}
+	}
+	if got := benchTSC.Labels(); len(got) != 2 {
+		t.Errorf("expected 2 distinct labels, got %v", got)
+	}
+}
@@ -0,0 +1,300 @@
+package hrtime
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lapCore contains lap storage and completion shared by Benchmark and
+// BenchmarkTSC, T is the unit of clock readings, i.e. time.Duration or Count.
+type lapCore[T ~int64] struct {
+	step int
+	// laps contains start readings of laps during measurement,
+	// which finalizeLaps converts to lap durations.
+	laps  []T
+	start T
+	stop  T
+
+	opts         options
+	nonMonotonic int
+	err          error
+	// cause is reported by Err, when the benchmark was stopped by a context.
+	cause error
+	// misused is reported by Err, when the benchmark was misused during measurement.
+	misused error
+	// unbounded benchmarks grow laps until Stop.
+	unbounded bool
+	// budget stops unbounded benchmark after elapsed time, when positive.
+	budget   T
+	deadline T
+	// adaptive stops unbounded benchmark once laps are precise enough, when not nil.
+	adaptive *adaptiveCount
+	// recording is set when laps are added using Record,
+	// recordAt is the end of the last recorded lap.
+	recording bool
+	recordAt  T
+	// sampling measures only some laps, when enabled by WithSampling or WithReservoir.
+	sampling *lapSampler[T]
+	// observed is the number of laps observed by a sampled benchmark
+	// decoded with UnmarshalBinary.
+	observed int
+	// bytes is the number of bytes processed by a single lap, see SetBytes.
+	bytes int64
+	// sources contains lap ranges of merged benchmarks.
+	sources []benchmarkSource
+
+	// timestamps contains lap start readings, when enabled by WithTimestamps.
+	timestamps []T
+	// wallStop is the wall-clock time corresponding to stop.
+	wallStop time.Time
+
+	lapLabels
+	lapFailures
+	lapPhases[T]
+	lapMetrics
+	lapPauses[T]
+
+	// memStart and memStats track allocations, when enabled by WithMemStats.
+	memStart memSnapshot
+	memStats *MemStats
+	// gcPauses contains garbage collector pauses, when enabled by WithGCPauses.
+	gcPauses []GCPause
+
+	// finalizing guards finalization and completed channel.
+	finalizing sync.Mutex
+	// finishing is set once completion starts, see awaitCompleted.
+	finishing atomic.Bool
+	done      atomic.Bool
+	completed chan struct{}
+}
+
+// resetLaps clears measurements, the embedding benchmark resizes laps
+// and then resets samples.
+func (core *lapCore[T]) resetLaps() {
+	core.step = 0
+	core.recording, core.recordAt = false, 0
+	if core.adaptive != nil {
+		core.adaptive.reset()
+	}
+	core.start, core.stop = 0, 0
+	core.nonMonotonic = 0
+	core.err, core.cause, core.misused = nil, nil, nil
+	core.bytes = 0
+	core.sources = nil
+	core.lapLabels = lapLabels{}
+	core.lapFailures = lapFailures{}
+	core.lapPhases = lapPhases[T]{}
+	core.metrics, core.metricNames = nil, nil
+	core.lapPauses = lapPauses[T]{}
+	core.memStats = nil
+	core.gcPauses = nil
+	if core.opts.memStats {
+		core.memStart = readMemSnapshot()
+	}
+	core.finishing.Store(false)
+	core.done.Store(false)
+	core.completed = nil
+}
+
+// misuse reports misuse of the benchmark in progress, it's reported by Err
+// once the benchmark completes.
+func (core *lapCore[T]) misuse(message string) {
+	err := core.opts.misuse(message)
+	if !core.done.Load() {
+		core.misused = errors.Join(core.misused, err)
+	}
+}
+
+// proceed returns whether to start another lap after the lap ending at now,
+// otherwise the benchmark must complete at now.
+func (core *lapCore[T]) proceed(now T) bool {
+	if !core.unbounded {
+		return core.step < len(core.laps)
+	}
+	if core.budget > 0 {
+		if core.step == 0 {
+			core.deadline = now + core.budget
+		} else if now >= core.deadline {
+			return false
+		}
+	}
+	if core.adaptive != nil && core.step > core.opts.warmup {
+		// the relative error doesn't depend on the unit of laps
+		if core.adaptive.add(time.Duration(now - core.laps[core.step-1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// startLap starts the next lap at start.
+func (core *lapCore[T]) startLap(start T) {
+	if core.unbounded {
+		core.laps = append(core.laps, start)
+	} else {
+		core.laps[core.step] = start
+	}
+	core.step++
+}
+
+// record adds an externally measured lap, placing it after the previously
+// recorded lap or at read for the first one. It returns whether all laps
+// have been recorded, in which case the benchmark must complete at recordAt.
+func (core *lapCore[T]) record(lap T, read func() T) bool {
+	if !core.recording {
+		if core.step > 0 {
+			core.misuse("cannot mix Next and Record")
+			return false
+		}
+		core.recording = true
+		core.recordAt = read()
+	}
+	core.startLap(core.recordAt)
+	core.recordAt += lap
+	return !core.unbounded && core.step >= len(core.laps)
+}
+
+// doneChannel returns a channel that is closed on completion, see Benchmark.Done.
+func (core *lapCore[T]) doneChannel() <-chan struct{} {
+	core.finalizing.Lock()
+	defer core.finalizing.Unlock()
+	if core.completed == nil {
+		core.completed = make(chan struct{})
+		if core.done.Load() {
+			close(core.completed)
+		}
+	}
+	return core.completed
+}
+
+// awaitCompleted returns whether the core has completed.
+//
+// When another goroutine has started completing the core, e.g. in the last
+// call to Next, it waits for finalization to finish instead of reporting
+// the benchmark as incomplete.
+func (core *lapCore[T]) awaitCompleted() bool {
+	if !core.done.Load() && core.finishing.Load() {
+		<-core.doneChannel()
+	}
+	return core.done.Load()
+}
+
+// finalizeLaps converts lap start readings to durations, where the last lap
+// ends at last, and drops warmup laps together with their phases and metrics.
+//
+// It returns the number of dropped warmup laps, or false when no measured
+// lap started, in which case the core is completed without laps.
+// It must be called with finalizing locked.
+func (core *lapCore[T]) finalizeLaps(last T) (warmup int, measured bool) {
+	if core.opts.memStats {
+		core.memStats = core.memStart.since(len(core.laps))
+	}
+
+	if core.timestamps != nil {
+		core.wallStop = time.Now()
+		core.timestamps = append(core.timestamps[:0], core.laps...)
+	}
+
+	if core.labels != nil {
+		core.alignLabels(len(core.laps))
+	}
+	if core.failed != nil {
+		core.alignFailed(len(core.laps))
+	}
+
+	warmup = min(core.opts.warmup, len(core.laps))
+	if warmup == len(core.laps) {
+		// stopped before any measured lap started
+		core.laps = core.laps[:0]
+		if core.timestamps != nil {
+			core.timestamps = core.timestamps[:0]
+		}
+		if core.labels != nil {
+			core.labels = core.labels[:0]
+		}
+		if core.failed != nil {
+			core.failed = core.failed[:0]
+		}
+		core.dropWarmupExtras(warmup)
+		core.start, core.stop = last, last
+		core.err = errors.Join(core.cause, core.opts.misused, core.misused)
+		core.completeLaps()
+		return warmup, false
+	}
+
+	core.start = core.laps[warmup]
+	for i := range core.laps[:len(core.laps)-1] {
+		core.laps[i] = core.laps[i+1] - core.laps[i]
+	}
+	core.laps[len(core.laps)-1] = last - core.laps[len(core.laps)-1]
+	core.stop = last
+	if core.pauses != nil {
+		core.excludePauses(core.laps)
+	}
+
+	core.laps = dropWarmup(core.laps, warmup)
+	if core.timestamps != nil {
+		core.timestamps = dropWarmup(core.timestamps, warmup)
+	}
+	if core.labels != nil {
+		core.labels = dropWarmup(core.labels, warmup)
+	}
+	if core.failed != nil {
+		core.failed = dropWarmup(core.failed, warmup)
+	}
+	core.dropWarmupExtras(warmup)
+
+	if core.opts.nonMonotonic == NonMonotonicDrop {
+		core.keepMonotonic()
+	}
+	return warmup, true
+}
+
+// dropWarmupExtras removes phases and metrics of the first warmup laps.
+func (core *lapCore[T]) dropWarmupExtras(warmup int) {
+	if core.phases != nil {
+		core.dropWarmupPhases(warmup)
+	}
+	if core.metrics != nil {
+		core.dropWarmupMetrics(warmup)
+	}
+}
+
+// keepMonotonic removes per-lap data of non-monotonic laps,
+// the laps themselves are removed by fixLaps.
+func (core *lapCore[T]) keepMonotonic() {
+	if core.timestamps != nil {
+		core.timestamps = keepMonotonicTimestamps(core.timestamps, core.laps)
+	}
+	if core.labels != nil {
+		core.labels = keepMonotonicTimestamps(core.labels, core.laps)
+	}
+	if core.failed != nil {
+		core.failed = keepMonotonicTimestamps(core.failed, core.laps)
+	}
+	if core.metrics != nil {
+		core.keepMonotonicMetrics()
+	}
+}
+
+// fixLaps applies the non-monotonic policy to lap durations and subtracts
+// the overhead of a lap, when measuring WithOverheadSubtraction.
+func (core *lapCore[T]) fixLaps(overhead func(*TimerOverhead) T) {
+	core.laps, core.nonMonotonic, core.err = fixNonMonotonic(core.laps, core.opts.nonMonotonic)
+	if core.cause != nil || core.opts.misused != nil || core.misused != nil {
+		core.err = errors.Join(core.cause, core.opts.misused, core.misused, core.err)
+	}
+	if core.opts.overhead != nil {
+		subtractOverhead(core.laps, overhead(core.opts.overhead))
+	}
+}
+
+// completeLaps marks the core as completed and closes the completed channel.
+func (core *lapCore[T]) completeLaps() {
+	core.done.Store(true)
+	if core.completed != nil {
+		close(core.completed)
+	}
+}
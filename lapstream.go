@@ -0,0 +1,149 @@
+package hrtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LapWriter writes laps in a compact binary stream.
+//
+// Every lap is encoded as the difference to the previous lap using a
+// zig-zag varint, which is the delta-of-delta of lap end times. Laps of
+// a stable benchmark differ by nanoseconds and take a byte or two,
+// roughly an order of magnitude less than JSON or CSV.
+//
+// The stream starts with a header, hence streams can't be concatenated.
+// Laps are buffered, Flush must be called after the last lap.
+type LapWriter struct {
+	w        *bufio.Writer
+	previous time.Duration
+	count    int
+	buf      [binary.MaxVarintLen64]byte
+}
+
+// NewLapWriter creates a lap stream writing to w.
+func NewLapWriter(w io.Writer) *LapWriter {
+	lw := &LapWriter{w: bufio.NewWriter(w)}
+	_, _ = lw.w.WriteString(lapsMagic)
+	_, _ = lw.w.Write(binary.AppendUvarint(lw.buf[:0], SnapshotVersion))
+	return lw
+}
+
+// Write appends a single lap to the stream.
+func (lw *LapWriter) Write(lap time.Duration) error {
+	n := binary.PutVarint(lw.buf[:], int64(lap-lw.previous))
+	lw.previous = lap
+	lw.count++
+	_, err := lw.w.Write(lw.buf[:n])
+	return err
+}
+
+// WriteLaps appends laps to the stream.
+func (lw *LapWriter) WriteLaps(laps []time.Duration) error {
+	for _, lap := range laps {
+		if err := lw.Write(lap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns the number of laps written.
+func (lw *LapWriter) Count() int { return lw.count }
+
+// Flush writes buffered laps to the underlying writer.
+func (lw *LapWriter) Flush() error { return lw.w.Flush() }
+
+// LapReader reads laps written by LapWriter.
+type LapReader struct {
+	r        *bufio.Reader
+	previous time.Duration
+	started  bool
+	err      error
+}
+
+// NewLapReader creates a lap stream reader reading from r.
+func NewLapReader(r io.Reader) *LapReader {
+	return &LapReader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next lap, or io.EOF at the end of the stream.
+//
+// Corrupted streams return an error matching ErrInvalidSnapshot.
+func (lr *LapReader) Read() (time.Duration, error) {
+	if lr.err != nil {
+		return 0, lr.err
+	}
+	if !lr.started {
+		lr.started = true
+		if err := lr.readHeader(); err != nil {
+			lr.err = err
+			return 0, err
+		}
+	}
+
+	delta, err := binary.ReadVarint(lr.r)
+	switch {
+	case errors.Is(err, io.EOF):
+		lr.err = io.EOF
+		return 0, io.EOF
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		lr.err = fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+		return 0, lr.err
+	case err != nil:
+		lr.err = fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		return 0, lr.err
+	}
+	lr.previous += time.Duration(delta)
+	return lr.previous, nil
+}
+
+// ReadAll reads the remaining laps.
+func (lr *LapReader) ReadAll() ([]time.Duration, error) {
+	var laps []time.Duration
+	for {
+		lap, err := lr.Read()
+		if errors.Is(err, io.EOF) {
+			return laps, nil
+		}
+		if err != nil {
+			return laps, err
+		}
+		laps = append(laps, lap)
+	}
+}
+
+// readHeader verifies the magic and the version of the stream.
+func (lr *LapReader) readHeader() error {
+	var magic [len(lapsMagic)]byte
+	if _, err := io.ReadFull(lr.r, magic[:]); err != nil || string(magic[:]) != lapsMagic {
+		return fmt.Errorf("%w: unknown format", ErrInvalidSnapshot)
+	}
+	version, err := binary.ReadUvarint(lr.r)
+	if err != nil {
+		return fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+	}
+	if version == 0 || version > SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	return nil
+}
+
+// CompressLaps encodes laps as a lap stream, see LapWriter.
+func CompressLaps(laps []time.Duration) []byte {
+	var buf bytes.Buffer
+	lw := NewLapWriter(&buf)
+	_ = lw.WriteLaps(laps)
+	_ = lw.Flush()
+	return buf.Bytes()
+}
+
+// DecompressLaps decodes laps encoded by CompressLaps or LapWriter.
+func DecompressLaps(data []byte) ([]time.Duration, error) {
+	return NewLapReader(bytes.NewReader(data)).ReadAll()
+}
@@ -0,0 +1,64 @@
+package hrtime_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestLapStream(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	laps := make([]time.Duration, 10000)
+	for i := range laps {
+		laps[i] = time.Microsecond + time.Duration(rng.Intn(100)-50)
+	}
+	laps[10] = -5 * time.Millisecond
+
+	var buf bytes.Buffer
+	lw := hrtime.NewLapWriter(&buf)
+	for _, lap := range laps {
+		if err := lw.Write(lap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lr := hrtime.NewLapReader(bytes.NewReader(buf.Bytes()))
+	if first, err := lr.Read(); err != nil || first != laps[0] {
+		t.Fatalf("unexpected first lap %v %v", first, err)
+	}
+	rest, err := lr.ReadAll()
+	if err != nil || !slices.Equal(rest, laps[1:]) {
+		t.Fatalf("unexpected laps %d %v", len(rest), err)
+	}
+	if _, err := lr.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got %v", err)
+	}
+
+	encoded, _ := json.MarshalIndent(hrtime.NewJSONResult("bench", laps), "", "\t")
+	if ratio := float64(len(encoded)) / float64(buf.Len()); ratio < 5 {
+		t.Errorf("expected compression against JSON, got %d and %d bytes", len(encoded), buf.Len())
+	}
+}
+
+func TestLapStreamInvalid(t *testing.T) {
+	data := hrtime.CompressLaps([]time.Duration{100, 200, 1 << 40})
+	if laps, err := hrtime.DecompressLaps(data); err != nil || len(laps) != 3 || laps[2] != 1<<40 {
+		t.Fatalf("unexpected laps %v %v", laps, err)
+	}
+
+	for _, corrupted := range [][]byte{nil, []byte("HRTX\x01"), data[:len(data)-1]} {
+		if _, err := hrtime.DecompressLaps(corrupted); !errors.Is(err, hrtime.ErrInvalidSnapshot) {
+			t.Errorf("expected ErrInvalidSnapshot for %q, got %v", corrupted, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package hrtime
+
+import (
+	"slices"
+	"time"
+)
+
+// LapTimer measures phases within a single lap of a benchmark.
+//
+//	for bench.Next() {
+//		lap := bench.StartLap()
+//		encode()
+//		lap.Mark("encode")
+//		send()
+//		lap.Mark("send")
+//		lap.End()
+//	}
+//
+// Phases are aggregated across laps, see Benchmark.ForPhase and
+// Benchmark.PhaseBreakdown. Marking a phase only reads the clock,
+// hence it's cheap, however it's still included in the lap.
+type LapTimer struct {
+	bench *Benchmark
+	lap   int
+	last  time.Duration
+	// next is the expected index of the next phase name.
+	next  int
+	ended bool
+}
+
+// lapPhase is the duration of a phase within a lap.
+type lapPhase[T ~int64] struct {
+	lap      int
+	phase    int
+	duration T
+}
+
+// lapPhases contains phases of laps, when using StartLap.
+type lapPhases[T ~int64] struct {
+	phases     []lapPhase[T]
+	phaseNames []string
+}
+
+// StartLap starts timing phases of the lap in progress.
+func (bench *Benchmark) StartLap() LapTimer {
+	if bench.step == 0 {
+		bench.misuse("start lap called before Next")
+		return LapTimer{bench: bench, lap: -1, ended: true}
+	}
+	if bench.unsupportedBySampling("phases") {
+		return LapTimer{bench: bench, lap: -1, ended: true}
+	}
+	return LapTimer{bench: bench, lap: bench.step - 1, last: bench.opts.now()}
+}
+
+// Mark ends the phase named name, which started at the previous Mark
+// or at StartLap.
+func (lap *LapTimer) Mark(name string) {
+	now := lap.bench.opts.now()
+	if lap.ended {
+		// misuse of StartLap has already been reported
+		if lap.lap >= 0 {
+			lap.bench.misuse("mark called after End")
+		}
+		return
+	}
+	lap.next = lap.bench.mark(lap.lap, lap.next, name, now-lap.last)
+	lap.last = now
+}
+
+// End ends timing phases of the lap.
+//
+// Time after the last Mark is not attributed to any phase.
+func (lap *LapTimer) End() {
+	lap.ended = true
+}
+
+// LapTimerTSC measures phases within a single lap of a BenchmarkTSC,
+// see LapTimer.
+type LapTimerTSC struct {
+	bench *BenchmarkTSC
+	lap   int
+	last  Count
+	// next is the expected index of the next phase name.
+	next  int
+	ended bool
+}
+
+// StartLap starts timing phases of the lap in progress.
+func (bench *BenchmarkTSC) StartLap() LapTimerTSC {
+	if bench.step == 0 {
+		bench.misuse("start lap called before Next")
+		return LapTimerTSC{bench: bench, lap: -1, ended: true}
+	}
+	if bench.unsupportedBySampling("phases") {
+		return LapTimerTSC{bench: bench, lap: -1, ended: true}
+	}
+	return LapTimerTSC{bench: bench, lap: bench.step - 1, last: bench.opts.tsc()}
+}
+
+// Mark ends the phase named name, which started at the previous Mark
+// or at StartLap.
+func (lap *LapTimerTSC) Mark(name string) {
+	now := lap.bench.opts.tsc()
+	if lap.ended {
+		// misuse of StartLap has already been reported
+		if lap.lap >= 0 {
+			lap.bench.misuse("mark called after End")
+		}
+		return
+	}
+	lap.next = lap.bench.mark(lap.lap, lap.next, name, now-lap.last)
+	lap.last = now
+}
+
+// End ends timing phases of the lap.
+//
+// Time after the last Mark is not attributed to any phase.
+func (lap *LapTimerTSC) End() {
+	lap.ended = true
+}
+
+// mark adds phase name of lap and returns the expected index of the next
+// phase, where next is the expected index of this phase.
+func (phases *lapPhases[T]) mark(lap, next int, name string, duration T) int {
+	// phases usually come in the same order in every lap
+	index := next
+	if index >= len(phases.phaseNames) || phases.phaseNames[index] != name {
+		index = slices.Index(phases.phaseNames, name)
+		if index < 0 {
+			index = len(phases.phaseNames)
+			phases.phaseNames = append(phases.phaseNames, name)
+		}
+	}
+	phases.phases = append(phases.phases, lapPhase[T]{lap: lap, phase: index, duration: duration})
+	return index + 1
+}
+
+// dropWarmupPhases removes phases of warmup laps.
+func (phases *lapPhases[T]) dropWarmupPhases(warmup int) {
+	phases.phases = slices.DeleteFunc(phases.phases, func(phase lapPhase[T]) bool {
+		return phase.lap < warmup
+	})
+}
+
+// markedPhases returns names of marked phases in order of first appearance.
+func (phases *lapPhases[T]) markedPhases() []string {
+	var names []string
+	for index, name := range phases.phaseNames {
+		if slices.ContainsFunc(phases.phases, func(phase lapPhase[T]) bool { return phase.phase == index }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// phaseLaps returns durations of phase name across laps.
+func (phases *lapPhases[T]) phaseLaps(name string, duration func(T) time.Duration) []time.Duration {
+	var laps []time.Duration
+	for _, phase := range phases.phases {
+		if phases.phaseNames[phase.phase] == name {
+			laps = append(laps, duration(phase.duration))
+		}
+	}
+	return laps
+}
+
+// phaseBreakdown returns how much each phase contributes to the time of laps.
+func (phases *lapPhases[T]) phaseBreakdown(binCount int, duration func(T) time.Duration) *PhaseBreakdown {
+	labels := make([]string, len(phases.phases))
+	laps := make([]time.Duration, len(phases.phases))
+	for i, phase := range phases.phases {
+		labels[i] = phases.phaseNames[phase.phase]
+		laps[i] = duration(phase.duration)
+	}
+	return NewPhaseBreakdown(labels, laps, binCount)
+}
+
+// Phases returns names of phases marked with LapTimer in order of first appearance.
+func (bench *Benchmark) Phases() []string {
+	bench = bench.mustBeCompleted()
+	return bench.markedPhases()
+}
+
+// ForPhase returns the result of the durations of phase name across laps.
+func (bench *Benchmark) ForPhase(name string) *Result {
+	bench = bench.mustBeCompleted()
+	result := bench.analysis()
+	result.laps = bench.phaseLaps(name, func(d time.Duration) time.Duration { return d })
+	return result
+}
+
+// PhaseBreakdown returns how much each phase marked with LapTimer
+// contributes to the time of laps.
+func (bench *Benchmark) PhaseBreakdown(binCount int) *PhaseBreakdown {
+	bench = bench.mustBeCompleted()
+	return bench.phaseBreakdown(binCount, func(d time.Duration) time.Duration { return d })
+}
+
+// Phases returns names of phases marked with LapTimerTSC in order of first appearance.
+func (bench *BenchmarkTSC) Phases() []string {
+	bench = bench.mustBeCompleted()
+	return bench.markedPhases()
+}
+
+// ForPhase returns the result of the durations of phase name across laps.
+//
+// Counts are converted using Count.ApproxDuration.
+func (bench *BenchmarkTSC) ForPhase(name string) *Result {
+	bench = bench.mustBeCompleted()
+	result := bench.analysis()
+	result.laps = bench.phaseLaps(name, Count.ApproxDuration)
+	return result
+}
+
+// PhaseBreakdown returns how much each phase marked with LapTimerTSC
+// contributes to the time of laps.
+func (bench *BenchmarkTSC) PhaseBreakdown(binCount int) *PhaseBreakdown {
+	bench = bench.mustBeCompleted()
+	return bench.phaseBreakdown(binCount, Count.ApproxDuration)
+}
@@ -0,0 +1,72 @@
+package hrtime_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestLapTimer(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(4, hrtime.WithWarmup(1), hrtime.WithClock(clock))
+	for i := 0; bench.Next(); i++ {
+		lap := bench.StartLap()
+		clock.Advance(10)
+		lap.Mark("encode")
+		clock.Advance(30)
+		lap.Mark("send")
+		if i == 0 {
+			// only in the warmup lap
+			lap.Mark("connect")
+		}
+		clock.Advance(5)
+		lap.End()
+	}
+
+	if phases := bench.Phases(); !slices.Equal(phases, []string{"encode", "send"}) {
+		t.Errorf("unexpected phases %v", phases)
+	}
+	encode, send := bench.ForPhase("encode"), bench.ForPhase("send")
+	if encode.Count() != 4 || send.Count() != 4 || encode.Stats().Mean != 10 || send.Stats().Mean != 30 {
+		t.Errorf("unexpected phase laps %v %v", encode.Laps(), send.Laps())
+	}
+	if laps := bench.Laps(); laps[0] != 45 {
+		t.Errorf("unexpected laps %v", laps)
+	}
+
+	breakdown := bench.PhaseBreakdown(4)
+	if len(breakdown.Phases) != 2 || breakdown.Phases[1].Label != "send" || breakdown.Phases[1].Share != 0.75 {
+		t.Errorf("unexpected breakdown %+v", breakdown.Phases)
+	}
+	if text := breakdown.String(); !strings.Contains(text, "encode") {
+		t.Errorf("unexpected breakdown:\n%s", text)
+	}
+	t.Log("\n" + breakdown.String())
+}
+
+func TestLapTimerTSC(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmarkTSC(4, hrtime.WithWarmup(1), hrtime.WithClock(clock))
+	for bench.Next() {
+		lap := bench.StartLap()
+		clock.Advance(10 * time.Microsecond)
+		lap.Mark("encode")
+		clock.Advance(30 * time.Microsecond)
+		lap.Mark("send")
+		lap.End()
+	}
+
+	if phases := bench.Phases(); !slices.Equal(phases, []string{"encode", "send"}) {
+		t.Errorf("unexpected phases %v", phases)
+	}
+	encode, send := bench.ForPhase("encode"), bench.ForPhase("send")
+	if encode.Count() != 4 || send.Count() != 4 || send.Stats().Mean <= encode.Stats().Mean {
+		t.Errorf("unexpected phase laps %v %v", encode.Laps(), send.Laps())
+	}
+	if breakdown := bench.PhaseBreakdown(4); len(breakdown.Phases) != 2 || breakdown.Phases[1].Share < 0.7 {
+		t.Errorf("unexpected breakdown %+v", breakdown.Phases)
+	}
+}
@@ -0,0 +1,105 @@
+package hrtime
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadOptions configures a background load generator.
+type LoadOptions struct {
+	// Goroutines is the number of busy goroutines.
+	Goroutines int
+	// Duty is the fraction of time each goroutine is busy, in range (0, 1].
+	Duty float64
+	// Memory is the size of a buffer in bytes each goroutine writes
+	// through while busy, which adds memory bandwidth contention.
+	// When zero, the load is CPU only.
+	Memory int
+	// Period is the length of a busy and idle cycle, 10ms when zero.
+	Period time.Duration
+}
+
+// Load is a running background load generator.
+type Load struct {
+	opts    LoadOptions
+	stopped atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// StartLoad starts generating background load for interference testing.
+//
+// The load runs until Stop is called.
+func StartLoad(opts LoadOptions) *Load {
+	if opts.Goroutines <= 0 {
+		panic("must have at least one goroutine")
+	}
+	if !(opts.Duty > 0 && opts.Duty <= 1) {
+		panic("duty must be in range (0, 1]")
+	}
+	if opts.Period <= 0 {
+		opts.Period = 10 * time.Millisecond
+	}
+
+	load := &Load{opts: opts}
+	for i := 0; i < opts.Goroutines; i++ {
+		load.wg.Add(1)
+		go load.run()
+	}
+	return load
+}
+
+// run alternates between busy and idle phases until stopped.
+func (load *Load) run() {
+	defer load.wg.Done()
+
+	var buffer []byte
+	if load.opts.Memory > 0 {
+		buffer = make([]byte, load.opts.Memory)
+	}
+
+	busy := time.Duration(float64(load.opts.Period) * load.opts.Duty)
+	idle := load.opts.Period - busy
+	offset := 0
+	for !load.stopped.Load() {
+		deadline := Now() + busy
+		for Now() < deadline {
+			if buffer != nil {
+				// touch a cache line at a time
+				for i := 0; i < 64; i++ {
+					buffer[offset]++
+					offset = (offset + 64) % len(buffer)
+				}
+			}
+		}
+		if idle > 0 {
+			time.Sleep(idle)
+		}
+	}
+}
+
+// Stop stops the load and waits for the goroutines to exit.
+func (load *Load) Stop() {
+	load.stopped.Store(true)
+	load.wg.Wait()
+}
+
+// Metadata describes the load level as result metadata.
+func (load *Load) Metadata() map[string]string { return load.opts.metadata() }
+
+// metadata describes the load level as result metadata.
+func (opts LoadOptions) metadata() map[string]string {
+	return map[string]string{
+		"load.goroutines": strconv.Itoa(opts.Goroutines),
+		"load.duty":       strconv.FormatFloat(opts.Duty, 'g', -1, 64),
+		"load.memory":     strconv.Itoa(opts.Memory),
+	}
+}
+
+// WithLoad runs a background load generator while the suite is running.
+//
+// The load level is recorded in SuiteResult.Metadata.
+func WithLoad(opts LoadOptions) SuiteOption {
+	return func(config *suiteConfig) { config.load = &opts }
+}
@@ -0,0 +1,29 @@
+package hrtime
+
+import "time"
+
+// Measure returns the time it takes to call fn once.
+func Measure(fn func()) time.Duration {
+	start := Now()
+	fn()
+	return Since(start)
+}
+
+// MeasureN calls fn n times, measuring each call as a lap,
+// and returns the completed benchmark.
+//
+// fn receives the index of the lap. Options are the same as for NewBenchmark.
+func MeasureN(n int, fn func(i int), opts ...Option) *Benchmark {
+	bench := NewBenchmark(n, opts...)
+	for i := 0; bench.Next(); i++ {
+		fn(i)
+	}
+	return bench
+}
+
+// Time returns the result of fn and the time it took to call it.
+func Time[T any](fn func() T) (T, time.Duration) {
+	start := Now()
+	value := fn()
+	return value, Since(start)
+}
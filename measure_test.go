@@ -0,0 +1,30 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestMeasure(t *testing.T) {
+	if d := hrtime.Measure(func() { time.Sleep(time.Millisecond) }); d < time.Millisecond {
+		t.Errorf("expected at least 1ms, got %v", d)
+	}
+
+	value, d := hrtime.Time(func() int { return 42 })
+	if value != 42 || d < 0 {
+		t.Errorf("unexpected %v, %v", value, d)
+	}
+
+	calls := 0
+	bench := hrtime.MeasureN(10, func(i int) {
+		if i != calls {
+			t.Errorf("expected index %d, got %d", calls, i)
+		}
+		calls++
+	}, hrtime.WithWarmup(2))
+	if !bench.Completed() || calls != 12 || len(bench.Laps()) != 10 {
+		t.Errorf("expected completed benchmark with 10 laps, got %d calls and %d laps", calls, len(bench.Laps()))
+	}
+}
@@ -0,0 +1,141 @@
+package hrtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Measurement records named checkpoints of a single operation, e.g. handling a request.
+//
+// A Measurement is usually propagated with a context, so that libraries
+// deep in a call stack can add checkpoints without passing it through
+// every signature:
+//
+//	ctx = hrtime.WithMeasurement(ctx, "handler")
+//	...
+//	hrtime.FromContext(ctx).Split("db")
+//
+// Methods of a nil Measurement do nothing, hence libraries can call them
+// regardless whether the caller is measuring. Measurement is safe for concurrent use.
+type Measurement struct {
+	Name string
+
+	opts   options
+	start  time.Duration
+	mu     sync.Mutex
+	last   time.Duration
+	splits []Split
+}
+
+// Split is the time between two checkpoints of a Measurement.
+type Split struct {
+	Name string
+	// Start is the time of the previous checkpoint since the start of the measurement.
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// measurementKey is the context key of Measurement.
+type measurementKey struct{}
+
+// NewMeasurement starts a measurement.
+//
+// Only WithClock option is used.
+func NewMeasurement(name string, opts ...Option) *Measurement {
+	measurement := &Measurement{Name: name, opts: newOptions(opts)}
+	measurement.start = measurement.opts.now()
+	measurement.last = measurement.start
+	return measurement
+}
+
+// WithMeasurement starts a measurement and returns a context carrying it.
+func WithMeasurement(ctx context.Context, name string, opts ...Option) context.Context {
+	return ContextWithMeasurement(ctx, NewMeasurement(name, opts...))
+}
+
+// ContextWithMeasurement returns a context carrying measurement.
+func ContextWithMeasurement(ctx context.Context, measurement *Measurement) context.Context {
+	return context.WithValue(ctx, measurementKey{}, measurement)
+}
+
+// FromContext returns the measurement carried by ctx, or nil.
+func FromContext(ctx context.Context) *Measurement {
+	measurement, _ := ctx.Value(measurementKey{}).(*Measurement)
+	return measurement
+}
+
+// Split records the time since the previous checkpoint as name and returns it.
+func (measurement *Measurement) Split(name string) time.Duration {
+	if measurement == nil {
+		return 0
+	}
+	now := measurement.opts.now()
+
+	measurement.mu.Lock()
+	defer measurement.mu.Unlock()
+	split := Split{
+		Name:     name,
+		Start:    measurement.last - measurement.start,
+		Duration: now - measurement.last,
+	}
+	measurement.splits = append(measurement.splits, split)
+	measurement.last = now
+	return split.Duration
+}
+
+// Elapsed returns the time since the start of the measurement.
+func (measurement *Measurement) Elapsed() time.Duration {
+	if measurement == nil {
+		return 0
+	}
+	return measurement.opts.now() - measurement.start
+}
+
+// Splits returns a copy of the recorded splits.
+func (measurement *Measurement) Splits() []Split {
+	if measurement == nil {
+		return nil
+	}
+	measurement.mu.Lock()
+	defer measurement.mu.Unlock()
+	return append(measurement.splits[:0:0], measurement.splits...)
+}
+
+// WriteTo writes the splits with their share of the measured time to w.
+func (measurement *Measurement) WriteTo(w io.Writer) (int64, error) {
+	splits := measurement.Splits()
+	var total time.Duration
+	for _, split := range splits {
+		total += split.Duration
+	}
+
+	var written int64
+	n, err := fmt.Fprintf(w, "%s: %v\n", measurement.Name, formatStat(float64(total)))
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, split := range splits {
+		share := 0.0
+		if total > 0 {
+			share = float64(split.Duration) / float64(total) * 100
+		}
+		n, err = fmt.Fprintf(w, "  %-20s %10v %6.2f%%\n", split.Name, formatStat(float64(split.Duration)), share)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns the splits as a string.
+func (measurement *Measurement) String() string {
+	var buffer strings.Builder
+	_, _ = measurement.WriteTo(&buffer)
+	return buffer.String()
+}
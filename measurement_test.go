@@ -0,0 +1,41 @@
+package hrtime_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestMeasurementContext(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	ctx := hrtime.WithMeasurement(context.Background(), "handler", hrtime.WithClock(clock))
+
+	query := func(ctx context.Context) {
+		clock.Advance(300)
+		hrtime.FromContext(ctx).Split("db")
+	}
+	clock.Advance(100)
+	hrtime.FromContext(ctx).Split("parse")
+	query(ctx)
+
+	measurement := hrtime.FromContext(ctx)
+	splits := measurement.Splits()
+	if len(splits) != 2 || splits[1].Name != "db" || splits[1].Start != 100 || splits[1].Duration != 300 {
+		t.Errorf("unexpected splits %+v", splits)
+	}
+	if measurement.Elapsed() != 400 {
+		t.Errorf("unexpected elapsed %v", measurement.Elapsed())
+	}
+	if out := measurement.String(); !strings.Contains(out, "handler: 400ns") || !strings.Contains(out, "75.00%") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestMeasurementMissing(t *testing.T) {
+	measurement := hrtime.FromContext(context.Background())
+	if measurement != nil || measurement.Split("db") != 0 || measurement.Splits() != nil {
+		t.Errorf("expected no-op measurement, got %v", measurement)
+	}
+}
@@ -0,0 +1,102 @@
+package hrtime
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// MemStats contains memory allocated during a benchmark,
+// similarly to go test -benchmem.
+type MemStats struct {
+	// Laps is the number of laps the allocations are divided by.
+	Laps int
+	// Allocs is the total number of heap allocations.
+	Allocs uint64
+	// Bytes is the total number of allocated heap bytes.
+	Bytes uint64
+}
+
+// AllocsPerOp returns the average number of allocations per lap.
+func (stats MemStats) AllocsPerOp() float64 {
+	if stats.Laps == 0 {
+		return 0
+	}
+	return float64(stats.Allocs) / float64(stats.Laps)
+}
+
+// BytesPerOp returns the average number of allocated bytes per lap.
+func (stats MemStats) BytesPerOp() float64 {
+	if stats.Laps == 0 {
+		return 0
+	}
+	return float64(stats.Bytes) / float64(stats.Laps)
+}
+
+// String returns allocations in go test -benchmem format.
+func (stats MemStats) String() string {
+	return fmt.Sprintf("%.0f B/op\t%.0f allocs/op", stats.BytesPerOp(), stats.AllocsPerOp())
+}
+
+// WithMemStats captures heap allocations during the whole benchmark.
+//
+// Memory statistics are read when the benchmark is created and when it
+// completes, hence allocations between NewBenchmark and the loop are included.
+// Reading memory statistics stops the world briefly, so it's not done per lap.
+func WithMemStats() Option {
+	return func(opts *options) { opts.memStats = true }
+}
+
+// memSnapshot is the allocation counters at a point in time.
+type memSnapshot struct {
+	allocs uint64
+	bytes  uint64
+}
+
+// readMemSnapshot reads the current allocation counters.
+func readMemSnapshot() memSnapshot {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return memSnapshot{allocs: stats.Mallocs, bytes: stats.TotalAlloc}
+}
+
+// since returns allocations since the snapshot divided by laps.
+func (start memSnapshot) since(laps int) *MemStats {
+	end := readMemSnapshot()
+	return &MemStats{
+		Laps:   laps,
+		Allocs: end.allocs - start.allocs,
+		Bytes:  end.bytes - start.bytes,
+	}
+}
+
+// MemStats returns heap allocations during the benchmark.
+//
+// It returns false, unless the benchmark was created WithMemStats.
+func (bench *Benchmark) MemStats() (MemStats, bool) {
+	bench = bench.mustBeCompleted()
+	if bench.memStats == nil {
+		return MemStats{}, false
+	}
+	return *bench.memStats, true
+}
+
+// MemStats returns heap allocations during the benchmark.
+//
+// It returns false, unless the benchmark was created WithMemStats.
+func (bench *BenchmarkTSC) MemStats() (MemStats, bool) {
+	bench = bench.mustBeCompleted()
+	if bench.memStats == nil {
+		return MemStats{}, false
+	}
+	return *bench.memStats, true
+}
+
+// MemStats returns heap allocations during the benchmark.
+//
+// It returns false, unless the benchmark was created WithMemStats.
+func (result *Result) MemStats() (MemStats, bool) {
+	if result.memStats == nil {
+		return MemStats{}, false
+	}
+	return *result.memStats, true
+}
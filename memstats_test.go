@@ -0,0 +1,40 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+var sink []byte
+
+func TestBenchmarkMemStats(t *testing.T) {
+	bench := hrtime.NewBenchmark(100, hrtime.WithMemStats())
+	for bench.Next() {
+		sink = make([]byte, 1024)
+	}
+
+	stats, ok := bench.MemStats()
+	if !ok {
+		t.Fatal("memory statistics missing")
+	}
+	if stats.AllocsPerOp() < 1 || stats.BytesPerOp() < 1024 {
+		t.Errorf("unexpected allocations %v", stats)
+	}
+
+	var line strings.Builder
+	if err := bench.WriteGoBench(&line, "Alloc"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line.String(), "allocs/op") {
+		t.Errorf("missing allocations in %q", line.String())
+	}
+
+	plain := hrtime.NewBenchmark(1)
+	for plain.Next() {
+	}
+	if _, ok := plain.MemStats(); ok {
+		t.Errorf("unexpected memory statistics")
+	}
+}
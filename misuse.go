@@ -0,0 +1,110 @@
+package hrtime
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// MisusePolicy defines how misuse of benchmarks is handled, i.e. creating
+// a benchmark or a Stopwatch with an invalid count or a negative WithWarmup,
+// reading results of an incomplete benchmark or Stopwatch, calling Record,
+// Fail, Repeat, Pause, Resume, StartLap and LapTimer.Mark at the wrong time,
+// using timestamps, labels, failures or phases in a sampled benchmark,
+// calling Stopwatch.Stop too many times, or recording into a completed
+// ConcurrentBenchmark.
+//
+// Other invalid arguments, such as a negative number of histogram bins or
+// a non-positive CalibrateTSC window, and misuse of other types, such as
+// BenchmarkPool, Suite, RollingBenchmark and PairedComparison, are
+// programming errors and always panic.
+type MisusePolicy byte
+
+const (
+	// MisusePanic panics on misuse, which is the default and fails fast in tests.
+	MisusePanic MisusePolicy = iota + 1
+	// MisuseError reports misuse as an error matching ErrMisuse instead of panicking.
+	//
+	// Benchmarks created with invalid arguments are completed without laps,
+	// a negative warmup is ignored and reported by Err, and reading results
+	// of an incomplete benchmark returns results of an empty benchmark.
+	// Misused calls during measurement are ignored and reported by Err once
+	// the benchmark completes. In all cases the error is passed to the
+	// handler registered with OnMisuse.
+	MisuseError
+)
+
+// ErrMisuse is reported under MisuseError policy.
+var ErrMisuse = errors.New("misuse")
+
+var (
+	misusePolicy  atomic.Uint32
+	misuseHandler atomic.Pointer[func(error)]
+)
+
+// SetMisusePolicy sets the package-wide misuse policy and returns the previous one.
+//
+// Benchmarks created WithMisusePolicy use their own policy.
+func SetMisusePolicy(policy MisusePolicy) MisusePolicy {
+	if policy != MisusePanic && policy != MisuseError {
+		panic("invalid misuse policy")
+	}
+	previous := MisusePolicy(misusePolicy.Swap(uint32(policy)))
+	if previous == 0 {
+		previous = MisusePanic
+	}
+	return previous
+}
+
+// OnMisuse registers fn to be called with every misuse reported under
+// MisuseError policy, e.g. to log it. fn must be safe for concurrent use,
+// nil removes the handler.
+func OnMisuse(fn func(error)) {
+	if fn == nil {
+		misuseHandler.Store(nil)
+		return
+	}
+	misuseHandler.Store(&fn)
+}
+
+// WithMisusePolicy sets the misuse policy of a benchmark, overriding SetMisusePolicy.
+func WithMisusePolicy(policy MisusePolicy) Option {
+	if policy != MisusePanic && policy != MisuseError {
+		panic("invalid misuse policy")
+	}
+	return func(opts *options) { opts.misusePolicy = policy }
+}
+
+// misuse panics with message under MisusePanic policy, otherwise
+// it returns the message as an error and reports it to the OnMisuse handler.
+func (opts *options) misuse(message string) error {
+	policy := opts.misusePolicy
+	if policy == 0 {
+		policy = MisusePolicy(misusePolicy.Load())
+	}
+	if policy != MisuseError {
+		panic(message)
+	}
+
+	err := fmt.Errorf("%w: %s", ErrMisuse, message)
+	tracef("misuse", "%s", message)
+	if handler := misuseHandler.Load(); handler != nil {
+		(*handler)(err)
+	}
+	return err
+}
+
+// newMisusedBenchmark creates a completed benchmark without laps reporting err.
+func newMisusedBenchmark(opts options, err error) *Benchmark {
+	bench := &Benchmark{lapCore: lapCore[time.Duration]{opts: opts, err: err}}
+	bench.done.Store(true)
+	return bench
+}
+
+// newMisusedBenchmarkTSC creates a completed benchmark without laps reporting err.
+func newMisusedBenchmarkTSC(opts options, err error) *BenchmarkTSC {
+	bench := &BenchmarkTSC{lapCore: lapCore[Count]{opts: opts, err: err}}
+	bench.done.Store(true)
+	return bench
+}
@@ -0,0 +1,181 @@
+package hrtime_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestMisuseError(t *testing.T) {
+	var reported []error
+	hrtime.OnMisuse(func(err error) { reported = append(reported, err) })
+	defer hrtime.OnMisuse(nil)
+
+	bench := hrtime.NewBenchmark(0, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	if bench.Next() || !errors.Is(bench.Err(), hrtime.ErrMisuse) || len(bench.Laps()) != 0 {
+		t.Errorf("expected completed benchmark reporting misuse, got %v", bench.Err())
+	}
+
+	incomplete := hrtime.NewBenchmark(10, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	incomplete.Next()
+	if err := incomplete.Err(); !errors.Is(err, hrtime.ErrMisuse) || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected incomplete misuse, got %v", err)
+	}
+	_ = incomplete.Stats().String()
+	_ = incomplete.Histogram(10).String()
+	if err := incomplete.WriteJSON(io.Discard); err != nil {
+		t.Error(err)
+	}
+
+	tsc := hrtime.NewBenchmarkTSC(-1, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	if tsc.Next() || !errors.Is(tsc.Err(), hrtime.ErrMisuse) {
+		t.Errorf("expected completed TSC benchmark reporting misuse, got %v", tsc.Err())
+	}
+
+	if len(reported) != 6 {
+		t.Errorf("expected every misuse to be reported, got %v", reported)
+	}
+}
+
+func TestMisuseDuringMeasurement(t *testing.T) {
+	var reported []error
+	hrtime.OnMisuse(func(err error) { reported = append(reported, err) })
+	defer hrtime.OnMisuse(nil)
+
+	bench := hrtime.NewBenchmark(2, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	bench.Fail()
+	lap := bench.StartLap()
+	lap.Mark("ignored")
+	for bench.Next() {
+		bench.Record(time.Millisecond)
+	}
+	if err := bench.Err(); !errors.Is(err, hrtime.ErrMisuse) || !strings.Contains(err.Error(), "cannot mix Next and Record") {
+		t.Errorf("expected misuse reported by Err, got %v", err)
+	}
+	if bench.Phases() != nil || bench.Failures() != 0 || len(bench.Laps()) != 2 {
+		t.Errorf("expected misused calls to be ignored, got %v, %v, %v", bench.Phases(), bench.Failures(), bench.Laps())
+	}
+	bench.Fail()
+	if reps := bench.Repeat(0, func(*hrtime.Benchmark) {}); len(reps.Runs) != 0 {
+		t.Errorf("expected no runs, got %v", reps.Runs)
+	}
+
+	concurrent := hrtime.NewConcurrentBenchmark(hrtime.WithMisusePolicy(hrtime.MisuseError))
+	concurrent.Run(0, 10, func() {})
+	if err := concurrent.Finalize().Err(); !errors.Is(err, hrtime.ErrMisuse) {
+		t.Errorf("expected misuse of Run, got %v", err)
+	}
+	concurrent.Start().Finish()
+
+	// Fail, StartLap, 2x Record, Fail, Repeat, Run, Finish
+	if len(reported) != 8 {
+		t.Errorf("expected every misuse to be reported, got %v", reported)
+	}
+}
+
+func TestMisusePolicy(t *testing.T) {
+	previous := hrtime.SetMisusePolicy(hrtime.MisuseError)
+	if previous != hrtime.MisusePanic {
+		t.Errorf("expected panic policy by default, got %v", previous)
+	}
+	bench := hrtime.NewBenchmarkFor(0)
+	hrtime.SetMisusePolicy(previous)
+	if !errors.Is(bench.Err(), hrtime.ErrMisuse) {
+		t.Errorf("expected misuse, got %v", bench.Err())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic with panic policy")
+		}
+	}()
+	hrtime.NewBenchmark(0)
+}
+
+func TestMisuseNegativeWarmup(t *testing.T) {
+	bench := hrtime.NewBenchmark(4, hrtime.WithWarmup(-1), hrtime.WithMisusePolicy(hrtime.MisuseError))
+	for bench.Next() {
+	}
+	if err := bench.Err(); !errors.Is(err, hrtime.ErrMisuse) || !strings.Contains(err.Error(), "warmup") {
+		t.Errorf("expected warmup misuse, got %v", err)
+	}
+	if len(bench.Laps()) != 4 {
+		t.Errorf("expected 4 laps, got %v", bench.Laps())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic under MisusePanic")
+		}
+	}()
+	hrtime.NewBenchmarkTSC(4, hrtime.WithWarmup(-1))
+}
+
+func TestMisuseStopwatch(t *testing.T) {
+	var reported []error
+	hrtime.OnMisuse(func(err error) { reported = append(reported, err) })
+	defer hrtime.OnMisuse(nil)
+
+	empty := hrtime.NewStopwatch(0, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	empty.Stop(empty.Start())
+	empty.Wait()
+	if !errors.Is(empty.Err(), hrtime.ErrMisuse) || len(empty.Spans()) != 0 {
+		t.Errorf("expected completed stopwatch reporting misuse, got %v", empty.Err())
+	}
+
+	incomplete := hrtime.NewStopwatchTSC(2, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	if spans := incomplete.Spans(); len(spans) != 0 {
+		t.Errorf("expected no spans, got %v", spans)
+	}
+	_ = incomplete.Histogram(10).String()
+
+	bench := hrtime.NewStopwatch(1, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	lap := bench.Start()
+	bench.Stop(lap)
+	bench.Stop(lap)
+	if err := bench.Err(); !errors.Is(err, hrtime.ErrMisuse) || !strings.Contains(err.Error(), "too many times") {
+		t.Errorf("expected misuse reported by Err, got %v", err)
+	}
+	if len(bench.Durations()) != 1 {
+		t.Errorf("expected 1 duration, got %v", bench.Durations())
+	}
+
+	// count, Spans, Histogram, Stop
+	if len(reported) != 4 {
+		t.Errorf("expected every misuse to be reported, got %v", reported)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic under MisusePanic")
+		}
+	}()
+	hrtime.NewStopwatchTSC(0)
+}
+
+func TestMisuseStopwatchOptions(t *testing.T) {
+	bench := hrtime.NewStopwatch(1, hrtime.WithWarmup(-1), hrtime.WithTimestamps(), hrtime.WithMisusePolicy(hrtime.MisuseError))
+	bench.Stop(bench.Start())
+	err := bench.Err()
+	if !errors.Is(err, hrtime.ErrMisuse) || !strings.Contains(err.Error(), "warmup must not be negative") || !strings.Contains(err.Error(), "does not support") {
+		t.Errorf("expected unsupported options reported by Err, got %v", err)
+	}
+	if len(bench.Spans()) != 1 {
+		t.Errorf("expected 1 span, got %v", bench.Spans())
+	}
+
+	if err := hrtime.NewStopwatchTSC(1, hrtime.WithClockMitigation(), hrtime.WithMisusePolicy(hrtime.MisuseError)).Err(); err != nil {
+		t.Errorf("expected supported options, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic under MisusePanic")
+		}
+	}()
+	hrtime.NewStopwatchTSC(1, hrtime.WithSampling(2))
+}
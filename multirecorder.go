@@ -0,0 +1,185 @@
+package hrtime
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MultiRecorder records laps with multiple numeric dimensions.
+//
+// For example a single instrumentation point can record latency together
+// with payload size, and both can be analyzed separately or jointly.
+type MultiRecorder struct {
+	mu     sync.Mutex
+	names  []string
+	values [][]float64
+}
+
+// DimensionStats contains summary statistics of a single dimension.
+type DimensionStats struct {
+	Name    string
+	Count   int
+	Mean    float64
+	Minimum float64
+	Maximum float64
+	P50     float64
+	P90     float64
+	P99     float64
+}
+
+// NewMultiRecorder creates a recorder with the specified dimension names.
+func NewMultiRecorder(names ...string) *MultiRecorder {
+	if len(names) == 0 {
+		panic("must have at least one dimension")
+	}
+	return &MultiRecorder{
+		names:  append([]string(nil), names...),
+		values: make([][]float64, len(names)),
+	}
+}
+
+// Names returns dimension names.
+func (rec *MultiRecorder) Names() []string {
+	return append([]string(nil), rec.names...)
+}
+
+// Record adds a single lap, values must be in the same order as dimension names.
+func (rec *MultiRecorder) Record(values ...float64) {
+	if len(values) != len(rec.names) {
+		panic("value count must match dimension count")
+	}
+	rec.mu.Lock()
+	for i, value := range values {
+		rec.values[i] = append(rec.values[i], value)
+	}
+	rec.mu.Unlock()
+}
+
+// RecordLap adds a lap where the first dimension is a duration in nanoseconds.
+func (rec *MultiRecorder) RecordLap(lap time.Duration, values ...float64) {
+	rec.Record(append([]float64{float64(lap)}, values...)...)
+}
+
+// Count returns the number of recorded laps.
+func (rec *MultiRecorder) Count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.values[0])
+}
+
+// Dimension returns a copy of values of the named dimension.
+//
+// It returns nil when the dimension doesn't exist.
+func (rec *MultiRecorder) Dimension(name string) []float64 {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for i, dimension := range rec.names {
+		if dimension == name {
+			return append([]float64(nil), rec.values[i]...)
+		}
+	}
+	return nil
+}
+
+// Stats returns summary statistics for every dimension.
+func (rec *MultiRecorder) Stats() []DimensionStats {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	stats := make([]DimensionStats, len(rec.names))
+	for i, name := range rec.names {
+		stats[i] = newDimensionStats(name, rec.values[i])
+	}
+	return stats
+}
+
+// newDimensionStats calculates statistics of values.
+func newDimensionStats(name string, values []float64) DimensionStats {
+	stats := DimensionStats{Name: name, Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+	stats.Mean = total / float64(len(sorted))
+	stats.Minimum = sorted[0]
+	stats.Maximum = sorted[len(sorted)-1]
+	stats.P50 = quantileFloat(sorted, 0.5)
+	stats.P90 = quantileFloat(sorted, 0.9)
+	stats.P99 = quantileFloat(sorted, 0.99)
+	return stats
+}
+
+// quantileFloat returns q-th quantile of sorted values using linear interpolation.
+func quantileFloat(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return sorted[0]
+	}
+	if q >= 1 {
+		return sorted[len(sorted)-1]
+	}
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[i]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// WriteCSV writes all laps to w with a header row of dimension names.
+func (rec *MultiRecorder) WriteCSV(w io.Writer) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	out := csv.NewWriter(w)
+	if err := out.Write(rec.names); err != nil {
+		return err
+	}
+	row := make([]string, len(rec.names))
+	for lap := range rec.values[0] {
+		for i := range rec.names {
+			row[i] = strconv.FormatFloat(rec.values[i][lap], 'g', -1, 64)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteTo writes per-dimension statistics to w.
+func (rec *MultiRecorder) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, stats := range rec.Stats() {
+		n, err := fmt.Fprintf(w, "%-12s count %d  avg %.4g  min %.4g  p50 %.4g  p90 %.4g  p99 %.4g  max %.4g\n",
+			stats.Name, stats.Count, stats.Mean, stats.Minimum, stats.P50, stats.P90, stats.P99, stats.Maximum)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns per-dimension statistics.
+func (rec *MultiRecorder) String() string {
+	var buffer strings.Builder
+	_, _ = rec.WriteTo(&buffer)
+	return buffer.String()
+}
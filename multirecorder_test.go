@@ -0,0 +1,30 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestMultiRecorder(t *testing.T) {
+	rec := hrtime.NewMultiRecorder("latency", "size")
+	rec.RecordLap(100, 10)
+	rec.RecordLap(300, 30)
+
+	stats := rec.Stats()
+	if len(stats) != 2 || stats[0].Mean != 200 || stats[1].Maximum != 30 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if size := rec.Dimension("size"); len(size) != 2 || size[0] != 10 {
+		t.Errorf("unexpected size dimension %v", size)
+	}
+
+	var csv strings.Builder
+	if err := rec.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "latency,size\n100,10\n300,30\n"; csv.String() != expected {
+		t.Errorf("unexpected csv %q", csv.String())
+	}
+}
@@ -1,9 +1,13 @@
-// +build !windows
+//go:build !windows || purego
+// +build !windows purego
 
 package hrtime
 
 import "time"
 
+// nowBackend is the name of the clock used by Now.
+const nowBackend = "time.Now"
+
 // Now returns current time.Duration with best possible precision.
 //
 // Now returns time offset from a specific time.
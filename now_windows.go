@@ -1,3 +1,6 @@
+//go:build !purego
+// +build !purego
+
 package hrtime
 
 import (
@@ -15,6 +18,9 @@ var (
 	qpcFrequency = getFrequency()
 )
 
+// nowBackend is the name of the clock used by Now.
+const nowBackend = "QPC"
+
 // getFrequency returns frequency in ticks per second.
 func getFrequency() int64 {
 	var freq int64
@@ -29,6 +35,10 @@ func getFrequency() int64 {
 //
 // Now returns time offset from a specific time.
 // The values aren't comparable between computer restarts or between computers.
+//
+// Under certain hypervisors QPC may go backwards or misreport its frequency,
+// WithClockMitigation compensates backward jumps and calibrates the
+// frequency against the wall-clock, see MitigatedClock.
 func Now() time.Duration {
 	var now int64
 	syscall.Syscall(procCounter.Addr(), 1, uintptr(unsafe.Pointer(&now)), 0, 0)
@@ -0,0 +1,55 @@
+package hrtime
+
+import "time"
+
+// CorrectCoordinatedOmission returns laps with synthetic laps back-filled
+// for operations that were delayed by slow laps.
+//
+// In a closed-loop benchmark a slow lap delays all operations that should
+// have started during it, hence the tail latency is under-reported.
+// Assuming the operations should start every expectedInterval, each lap
+// longer than expectedInterval is followed by laps lap-expectedInterval,
+// lap-2*expectedInterval and so on, while they are longer than
+// expectedInterval. This is the same correction as used by HdrHistogram.
+func CorrectCoordinatedOmission(laps []time.Duration, expectedInterval time.Duration) []time.Duration {
+	if expectedInterval <= 0 {
+		panic("expected interval must be positive")
+	}
+
+	corrected := make([]time.Duration, 0, len(laps))
+	for _, lap := range laps {
+		corrected = append(corrected, lap)
+		for missing := lap - expectedInterval; missing >= expectedInterval; missing -= expectedInterval {
+			corrected = append(corrected, missing)
+		}
+	}
+	return corrected
+}
+
+// CorrectCoordinatedOmission returns a result with synthetic laps
+// back-filled for operations delayed by slow laps.
+//
+// See CorrectCoordinatedOmission function for details.
+func (result *Result) CorrectCoordinatedOmission(expectedInterval time.Duration) *Result {
+	corrected := *result
+	corrected.laps = CorrectCoordinatedOmission(result.laps, expectedInterval)
+	return &corrected
+}
+
+// CorrectCoordinatedOmission returns a result with synthetic laps
+// back-filled for operations delayed by slow laps.
+//
+// The benchmark itself is not modified.
+// See CorrectCoordinatedOmission function for details.
+func (bench *Benchmark) CorrectCoordinatedOmission(expectedInterval time.Duration) *Result {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().CorrectCoordinatedOmission(expectedInterval)
+}
+
+// CorrectCoordinatedOmission returns a result with synthetic laps
+// back-filled for operations delayed by slow laps.
+//
+// See CorrectCoordinatedOmission function for details.
+func (bench *BenchmarkTSC) CorrectCoordinatedOmission(expectedInterval time.Duration) *Result {
+	return bench.Result().CorrectCoordinatedOmission(expectedInterval)
+}
@@ -0,0 +1,34 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestCorrectCoordinatedOmission(t *testing.T) {
+	laps := []time.Duration{10, 10, 45, 10}
+	corrected := hrtime.CorrectCoordinatedOmission(laps, 10)
+
+	expected := []time.Duration{10, 10, 45, 35, 25, 15, 10}
+	if len(corrected) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, corrected)
+	}
+	for i := range expected {
+		if corrected[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, corrected)
+		}
+	}
+
+	bench := hrtime.NewBenchmark(4)
+	for i := 0; bench.Next(); i++ {
+		if i == 2 {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	result := bench.CorrectCoordinatedOmission(time.Millisecond)
+	if result.Count() < 8 || len(bench.Laps()) != 4 {
+		t.Errorf("expected back-filled laps, got %d", result.Count())
+	}
+}
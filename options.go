@@ -0,0 +1,161 @@
+package hrtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Option configures Benchmark and BenchmarkTSC.
+type Option func(*options)
+
+// options contains configuration shared by benchmarks.
+type options struct {
+	nonMonotonic NonMonotonicPolicy
+	timestamps   bool
+	warmup       int
+	clock        Clock
+	subtract     bool
+	overhead     *TimerOverhead
+	memStats     bool
+	mitigate     bool
+	gcPauses     bool
+	// stableQuantile is the quantile targeted by NewStableBenchmark, zero targets the mean.
+	stableQuantile float64
+	// misusePolicy overrides the package-wide misuse policy, when set.
+	misusePolicy MisusePolicy
+	// misused reports invalid options under MisuseError policy.
+	misused error
+	// untraced disables trace events of the benchmark.
+	untraced bool
+	// sampleEvery and reservoir configure sampling, see WithSampling and WithReservoir.
+	sampleEvery int
+	reservoir   int
+	// collectors measure custom metrics around laps, see WithCollector.
+	collectors []Collector
+}
+
+// newOptions applies all opts to the default configuration.
+func newOptions(opts []Option) options {
+	var config options
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.warmup < 0 {
+		config.misused = config.misuse("warmup must not be negative")
+		config.warmup = 0
+	}
+	if config.timestamps && config.sampled() {
+		config.misused = errors.Join(config.misused, config.misuse("sampled benchmark does not support timestamps"))
+		config.timestamps = false
+	}
+	if config.mitigate {
+		if _, ok := config.clock.(*MitigatedClock); !ok {
+			mitigated := NewMitigatedClock(config.clock)
+			mitigated.Calibrate(MitigationCalibration)
+			config.clock = mitigated
+		}
+	}
+	if config.subtract {
+		overhead := config.timerOverhead()
+		config.overhead = &overhead
+	}
+	return config
+}
+
+// NonMonotonicPolicy defines how laps with negative duration are handled.
+//
+// Negative laps happen when the clock goes backwards, e.g. under buggy
+// hypervisors or when TSC is not synchronized between cores.
+type NonMonotonicPolicy byte
+
+const (
+	// NonMonotonicClamp replaces negative laps with zero.
+	NonMonotonicClamp NonMonotonicPolicy = iota
+	// NonMonotonicDrop removes negative laps.
+	NonMonotonicDrop
+	// NonMonotonicError replaces negative laps with zero and reports ErrNonMonotonic from Err.
+	NonMonotonicError
+)
+
+// ErrNonMonotonic is reported when the clock went backwards during measurement.
+var ErrNonMonotonic = errors.New("non-monotonic clock reads")
+
+// WithNonMonotonic sets how laps with negative duration are handled.
+//
+// The default is NonMonotonicClamp.
+func WithNonMonotonic(policy NonMonotonicPolicy) Option {
+	return func(opts *options) { opts.nonMonotonic = policy }
+}
+
+// WithTimestamps keeps the start timestamp of each lap after finalization.
+//
+// It enables analyzing latency against wall-clock time, e.g. to correlate
+// slow laps with external events. It doubles the memory used by laps.
+func WithTimestamps() Option {
+	return func(opts *options) { opts.timestamps = true }
+}
+
+// WithWarmup discards the first n laps from the results.
+//
+// Warmup laps are still driven by Next, however they are excluded from
+// laps, histograms and statistics. For benchmarks with a fixed count the
+// warmup laps are measured in addition to count. It helps to avoid effects
+// such as cold caches and lazy initialization affecting the results.
+// A negative n is misuse, under MisuseError policy no laps are discarded.
+func WithWarmup(n int) Option {
+	return func(opts *options) { opts.warmup = n }
+}
+
+// dropWarmup removes the first warmup laps while keeping the lap storage.
+func dropWarmup[T any](laps []T, warmup int) []T {
+	if warmup <= 0 {
+		return laps
+	}
+	return append(laps[:0], laps[warmup:]...)
+}
+
+// keepMonotonicTimestamps removes timestamps, or other per-lap values, of laps
+// that will be dropped by NonMonotonicDrop, keeping them aligned with laps.
+func keepMonotonicTimestamps[V any, T ~int64](timestamps []V, laps []T) []V {
+	kept := timestamps[:0]
+	for i, lap := range laps {
+		if lap >= 0 {
+			kept = append(kept, timestamps[i])
+		}
+	}
+	return kept
+}
+
+// fixNonMonotonic applies policy to laps and returns the fixed laps,
+// number of non-monotonic laps and an error when policy requires one.
+func fixNonMonotonic[T ~int64](laps []T, policy NonMonotonicPolicy) ([]T, int, error) {
+	count := 0
+	for _, lap := range laps {
+		if lap < 0 {
+			count++
+		}
+	}
+	if count == 0 {
+		return laps, 0, nil
+	}
+
+	if policy == NonMonotonicDrop {
+		kept := laps[:0]
+		for _, lap := range laps {
+			if lap >= 0 {
+				kept = append(kept, lap)
+			}
+		}
+		return kept, count, nil
+	}
+
+	for i, lap := range laps {
+		if lap < 0 {
+			laps[i] = 0
+		}
+	}
+	if policy == NonMonotonicError {
+		return laps, count, fmt.Errorf("%w: %d laps", ErrNonMonotonic, count)
+	}
+	return laps, count, nil
+}
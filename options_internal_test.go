@@ -0,0 +1,26 @@
+package hrtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFixNonMonotonic(t *testing.T) {
+	laps := func() []time.Duration { return []time.Duration{1, -2, 0, 3} }
+
+	clamped, count, err := fixNonMonotonic(laps(), NonMonotonicClamp)
+	if count != 1 || err != nil || len(clamped) != 4 || clamped[1] != 0 {
+		t.Errorf("clamp: %v %v %v", clamped, count, err)
+	}
+
+	dropped, count, err := fixNonMonotonic(laps(), NonMonotonicDrop)
+	if count != 1 || err != nil || len(dropped) != 3 || dropped[1] != 0 {
+		t.Errorf("drop: %v %v %v", dropped, count, err)
+	}
+
+	_, count, err = fixNonMonotonic(laps(), NonMonotonicError)
+	if count != 1 || !errors.Is(err, ErrNonMonotonic) {
+		t.Errorf("error: %v %v", count, err)
+	}
+}
@@ -0,0 +1,152 @@
+package hrtime
+
+import (
+	"math"
+	"time"
+)
+
+// OutlierMethod specifies how outlier fences are computed.
+type OutlierMethod int
+
+const (
+	// OutlierIQR uses Tukey's fences, Threshold interquartile ranges
+	// below p25 or above p75. The default threshold is 1.5.
+	OutlierIQR OutlierMethod = iota
+	// OutlierMAD uses modified z-score based on the median absolute deviation,
+	// which is robust against the outliers themselves. The default threshold is 3.5.
+	OutlierMAD
+	// OutlierZScore uses Threshold standard deviations from the mean.
+	// The default threshold is 3.
+	OutlierZScore
+)
+
+// OutlierCriterion configures outlier detection.
+type OutlierCriterion struct {
+	Method OutlierMethod
+	// Threshold is the distance of fences, the method default is used when zero.
+	Threshold float64
+	// HighOnly detects only slow outliers, which is common for latencies,
+	// since GC pauses and preemption only make laps slower.
+	HighOnly bool
+}
+
+// threshold returns the configured or the default threshold.
+func (criterion OutlierCriterion) threshold() float64 {
+	if criterion.Threshold > 0 {
+		return criterion.Threshold
+	}
+	switch criterion.Method {
+	case OutlierMAD:
+		return 3.5
+	case OutlierZScore:
+		return 3
+	default:
+		return 1.5
+	}
+}
+
+// Outliers contains laps outside of fences.
+type Outliers struct {
+	Criterion OutlierCriterion
+	// Low and High are the fences, laps outside of [Low, High] are outliers.
+	Low, High time.Duration
+	// Indices contains the index of each outlier in the laps.
+	Indices []int
+	// Laps contains outlier laps.
+	Laps []time.Duration
+}
+
+// FindOutliers finds laps outside of fences specified by criterion.
+func FindOutliers(laps []time.Duration, criterion OutlierCriterion) *Outliers {
+	outliers := &Outliers{Criterion: criterion, Low: math.MinInt64, High: math.MaxInt64}
+	if len(laps) == 0 {
+		return outliers
+	}
+
+	sorted := sortedDurations(laps)
+	threshold := criterion.threshold()
+	var low, high float64
+	switch criterion.Method {
+	case OutlierMAD:
+		median := float64(quantile(sorted, 0.5))
+		deviations := make([]time.Duration, len(sorted))
+		for i, lap := range sorted {
+			deviations[i] = time.Duration(math.Abs(float64(lap) - median))
+		}
+		// 0.6745 makes MAD consistent with standard deviation of normal distribution
+		mad := float64(quantile(sortedDurations(deviations), 0.5)) / 0.6745
+		low, high = median-threshold*mad, median+threshold*mad
+	case OutlierZScore:
+		stats := NewStats(sorted)
+		low = float64(stats.Mean) - threshold*float64(stats.StdDev)
+		high = float64(stats.Mean) + threshold*float64(stats.StdDev)
+	default:
+		q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+		iqr := float64(q3 - q1)
+		low, high = float64(q1)-threshold*iqr, float64(q3)+threshold*iqr
+	}
+
+	outliers.High = time.Duration(high)
+	if !criterion.HighOnly {
+		outliers.Low = time.Duration(low)
+	}
+	for i, lap := range laps {
+		if lap < outliers.Low || lap > outliers.High {
+			outliers.Indices = append(outliers.Indices, i)
+			outliers.Laps = append(outliers.Laps, lap)
+		}
+	}
+	return outliers
+}
+
+// Count returns the number of outliers.
+func (outliers *Outliers) Count() int { return len(outliers.Laps) }
+
+// Outliers finds laps outside of fences specified by criterion.
+func (result *Result) Outliers(criterion OutlierCriterion) *Outliers {
+	return FindOutliers(result.laps, criterion)
+}
+
+// Trimmed returns a result without outliers.
+//
+// Statistics of the trimmed result report the number of removed laps,
+// see Stats.Trimmed.
+func (result *Result) Trimmed(criterion OutlierCriterion) *Result {
+	outliers := result.Outliers(criterion)
+	trimmed := *result
+	trimmed.laps = make([]time.Duration, 0, len(result.laps)-outliers.Count())
+	for _, lap := range result.laps {
+		if lap >= outliers.Low && lap <= outliers.High {
+			trimmed.laps = append(trimmed.laps, lap)
+		}
+	}
+	trimmed.trimmed += outliers.Count()
+	return &trimmed
+}
+
+// TrimmedCount returns the number of laps removed by Trimmed.
+func (result *Result) TrimmedCount() int { return result.trimmed }
+
+// Outliers finds laps outside of fences specified by criterion.
+func (bench *Benchmark) Outliers(criterion OutlierCriterion) *Outliers {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Outliers(criterion)
+}
+
+// Trimmed returns a result without outliers, see Result.Trimmed.
+func (bench *Benchmark) Trimmed(criterion OutlierCriterion) *Result {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Trimmed(criterion)
+}
+
+// Outliers finds laps outside of fences specified by criterion.
+func (bench *BenchmarkTSC) Outliers(criterion OutlierCriterion) *Outliers {
+	bench = bench.mustBeCompleted()
+	return bench.Result().Outliers(criterion)
+}
+
+// Trimmed returns a result without outliers, see Result.Trimmed.
+func (bench *BenchmarkTSC) Trimmed(criterion OutlierCriterion) *Result {
+	bench = bench.mustBeCompleted()
+	return bench.Result().Trimmed(criterion)
+}
@@ -0,0 +1,45 @@
+package hrtime_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestOutliers(t *testing.T) {
+	var laps []time.Duration
+	for i := 0; i < 100; i++ {
+		laps = append(laps, time.Duration(1000+i%10))
+	}
+	laps[10], laps[50] = 10*time.Microsecond, 0
+
+	for _, method := range []hrtime.OutlierMethod{hrtime.OutlierIQR, hrtime.OutlierMAD} {
+		outliers := hrtime.FindOutliers(laps, hrtime.OutlierCriterion{Method: method})
+		if !slices.Equal(outliers.Indices, []int{10, 50}) {
+			t.Errorf("method %v: expected outliers at 10 and 50, got %v", method, outliers.Indices)
+		}
+	}
+
+	// the slow outlier inflates standard deviation and masks the fast one
+	zscore := hrtime.FindOutliers(laps, hrtime.OutlierCriterion{Method: hrtime.OutlierZScore})
+	if !slices.Equal(zscore.Indices, []int{10}) {
+		t.Errorf("expected z-score outlier at 10, got %v", zscore.Indices)
+	}
+
+	high := hrtime.FindOutliers(laps, hrtime.OutlierCriterion{HighOnly: true})
+	if !slices.Equal(high.Laps, []time.Duration{10 * time.Microsecond}) {
+		t.Errorf("expected only the slow outlier, got %v", high.Laps)
+	}
+
+	trimmed := hrtime.NewResult(laps).Trimmed(hrtime.OutlierCriterion{})
+	stats := trimmed.Stats()
+	if trimmed.Count() != 98 || stats.Trimmed != 2 || stats.Maximum != 1009 {
+		t.Errorf("unexpected trimmed result:\n%v", stats)
+	}
+	if !strings.Contains(stats.String(), "2 trimmed") {
+		t.Errorf("expected trimmed count in output:\n%v", stats)
+	}
+}
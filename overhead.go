@@ -0,0 +1,119 @@
+package hrtime
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TimerOverhead contains the measured cost of reading the clocks.
+type TimerOverhead struct {
+	// Now is the median cost of a Now call.
+	Now time.Duration
+	// TSC is the median cost of a TSC call, zero when TSC is not supported.
+	TSC Count
+	// Lap is the median duration of an empty Benchmark lap.
+	Lap time.Duration
+	// LapTSC is the median count of an empty BenchmarkTSC lap.
+	LapTSC Count
+}
+
+var (
+	calibratedOverhead atomic.Pointer[TimerOverhead]
+	calibrateOnce      sync.Once
+)
+
+// CalibrateOverhead measures the cost of Now and TSC using tight loops.
+//
+// The result is used by benchmarks created WithOverheadSubtraction.
+// Calling it again recalibrates, e.g. after changing CPU frequency settings.
+// It takes a few milliseconds to run.
+func CalibrateOverhead() TimerOverhead {
+	const calls = calibrationCalls
+
+	var overhead TimerOverhead
+	overhead.Now = medianRound(func() time.Duration {
+		start := Now()
+		for i := 0; i < calls; i++ {
+			Now()
+		}
+		return (Now() - start) / (calls + 1)
+	})
+	overhead.Lap = medianRound(func() time.Duration {
+		bench := NewBenchmark(calls, withoutTrace())
+		for bench.Next() {
+		}
+		return quantile(sortedDurations(bench.laps), 0.5)
+	})
+
+	if TSCSupported() {
+		overhead.TSC = Count(medianRound(func() time.Duration {
+			start := TSC()
+			for i := 0; i < calls; i++ {
+				TSC()
+			}
+			return time.Duration((TSC() - start) / (calls + 1))
+		}))
+		overhead.LapTSC = Count(medianRound(func() time.Duration {
+			bench := NewBenchmarkTSC(calls, withoutTrace())
+			for bench.Next() {
+			}
+			return time.Duration(quantile(sortedCounts(bench.laps), 0.5))
+		}))
+	}
+
+	calibratedOverhead.Store(&overhead)
+	tracef("calibrate", "overhead now %v, lap %v, tsc %v, lap tsc %v", overhead.Now, overhead.Lap, overhead.TSC, overhead.LapTSC)
+	return overhead
+}
+
+// medianRound returns the median result of selfTestRounds calls to fn.
+func medianRound(fn func() time.Duration) time.Duration {
+	results := make([]time.Duration, selfTestRounds)
+	for round := range results {
+		results[round] = fn()
+	}
+	return quantile(sortedDurations(results), 0.5)
+}
+
+// currentOverhead returns calibrated overhead, calibrating when necessary.
+func currentOverhead() *TimerOverhead {
+	calibrateOnce.Do(func() {
+		if calibratedOverhead.Load() == nil {
+			CalibrateOverhead()
+		}
+	})
+	return calibratedOverhead.Load()
+}
+
+// WithOverheadSubtraction subtracts the median empty lap duration from each lap.
+//
+// It's useful for sub-microsecond operations, where the timer overhead
+// dominates the measurement. Laps are clamped to zero after subtraction.
+// The overhead is calibrated with CalibrateOverhead on first use,
+// unless the overhead is provided by a clock configured using WithClock.
+func WithOverheadSubtraction() Option {
+	return func(opts *options) { opts.subtract = true }
+}
+
+// timerOverhead returns the overhead of the configured clock.
+func (opts *options) timerOverhead() TimerOverhead {
+	if opts.clock != nil {
+		return opts.clock.Overhead()
+	}
+	return *currentOverhead()
+}
+
+// subtractOverhead subtracts overhead from each lap, clamping to zero.
+func subtractOverhead[T ~int64](laps []T, overhead T) {
+	if overhead <= 0 {
+		return
+	}
+	for i, lap := range laps {
+		if lap > overhead {
+			laps[i] = lap - overhead
+		} else if lap >= 0 {
+			laps[i] = 0
+		}
+	}
+}
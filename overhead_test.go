@@ -0,0 +1,27 @@
+package hrtime_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestOverheadSubtraction(t *testing.T) {
+	overhead := hrtime.CalibrateOverhead()
+	if overhead.Now <= 0 || overhead.Lap <= 0 {
+		t.Fatalf("invalid overhead %+v", overhead)
+	}
+
+	bench := hrtime.NewBenchmark(1024, hrtime.WithOverheadSubtraction())
+	for bench.Next() {
+	}
+	// empty laps should be close to zero after subtraction
+	if p50 := bench.Stats().P50; p50 > overhead.Lap {
+		t.Errorf("expected median empty lap below %v, got %v", overhead.Lap, p50)
+	}
+	for _, lap := range bench.Laps() {
+		if lap < 0 {
+			t.Fatalf("negative lap %v", lap)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package hrtime
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PairedComparison contains differences of paired laps of A and B.
+//
+// Pairs are measured close together in time, hence drift affects
+// both sides of a pair equally and cancels out in the difference.
+// This gives far tighter comparisons than two benchmarks run back to back.
+type PairedComparison struct {
+	Name string
+	// A and B contain laps of each function, in pair order.
+	A []time.Duration
+	B []time.Duration
+	// Diffs contains B - A of every pair.
+	Diffs []time.Duration
+
+	// MedianDiff and MeanDiff summarize Diffs, positive values mean that B is slower.
+	MedianDiff time.Duration
+	MeanDiff   time.Duration
+	// Low and High are bounds of the 95% confidence interval of MeanDiff.
+	Low  time.Duration
+	High time.Duration
+	// Change is MeanDiff relative to the mean of A, where 0.1 means B is 10% slower.
+	Change float64
+	// PValue is the two-sided p-value of Wilcoxon signed-rank test on Diffs.
+	PValue float64
+	// Faster is the fraction of pairs where B was faster than A.
+	Faster float64
+}
+
+// RunAB measures count pairs of calls to a and b, interleaving them
+// within the same run, and compares them pair by pair.
+//
+// The order within pairs alternates, i.e. AB, BA, AB, ..., to cancel
+// effects of running first or second. Options WithClock, WithWarmup and
+// WithOverheadSubtraction are used, warmup discards pairs.
+func RunAB(count int, a, b func(), opts ...Option) *PairedComparison {
+	if count <= 0 {
+		panic("must have count at least 1")
+	}
+
+	config := newOptions(opts)
+	var overhead time.Duration
+	if config.overhead != nil {
+		overhead = config.overhead.Lap
+	}
+
+	lapsA := make([]time.Duration, 0, count)
+	lapsB := make([]time.Duration, 0, count)
+	for i := range count + config.warmup {
+		first, second := a, b
+		if i%2 == 1 {
+			first, second = b, a
+		}
+		start := config.now()
+		first()
+		middle := config.now()
+		second()
+		stop := config.now()
+		if i < config.warmup {
+			continue
+		}
+
+		lapFirst, lapSecond := max(middle-start-overhead, 0), max(stop-middle-overhead, 0)
+		if i%2 == 1 {
+			lapFirst, lapSecond = lapSecond, lapFirst
+		}
+		lapsA = append(lapsA, lapFirst)
+		lapsB = append(lapsB, lapSecond)
+	}
+	return ComparePaired("", lapsA, lapsB)
+}
+
+// ComparePaired compares paired laps a and b, where a[i] and b[i] were measured together.
+func ComparePaired(name string, a, b []time.Duration) *PairedComparison {
+	if len(a) != len(b) {
+		panic("must have the same number of laps")
+	}
+
+	comparison := &PairedComparison{Name: name, A: a, B: b, Diffs: make([]time.Duration, len(a)), PValue: 1}
+	if len(a) == 0 {
+		return comparison
+	}
+
+	var faster int
+	var stats runningStats
+	for i := range a {
+		diff := b[i] - a[i]
+		comparison.Diffs[i] = diff
+		stats.add(diff)
+		if diff < 0 {
+			faster++
+		}
+	}
+
+	comparison.MedianDiff = quantile(sortedDurations(comparison.Diffs), 0.5)
+	comparison.MeanDiff = time.Duration(stats.mean)
+	if stats.count > 1 {
+		halfWidth := stableZ * math.Sqrt(stats.m2/float64(stats.count-1)/float64(stats.count))
+		comparison.Low = time.Duration(stats.mean - halfWidth)
+		comparison.High = time.Duration(stats.mean + halfWidth)
+	} else {
+		comparison.Low, comparison.High = comparison.MeanDiff, comparison.MeanDiff
+	}
+	if meanA := meanDuration(a); meanA != 0 {
+		comparison.Change = stats.mean / float64(meanA)
+	}
+	comparison.PValue = wilcoxonSignedRank(comparison.Diffs)
+	comparison.Faster = float64(faster) / float64(len(a))
+	return comparison
+}
+
+// wilcoxonSignedRank returns two-sided p-value of Wilcoxon signed-rank test
+// using normal approximation with tie correction. Zero differences are dropped.
+func wilcoxonSignedRank(diffs []time.Duration) float64 {
+	var nonzero []time.Duration
+	for _, diff := range diffs {
+		if diff != 0 {
+			nonzero = append(nonzero, diff)
+		}
+	}
+	slices.SortFunc(nonzero, func(a, b time.Duration) int { return cmp.Compare(abs(a), abs(b)) })
+
+	n := float64(len(nonzero))
+	var positive, ties float64
+	for i := 0; i < len(nonzero); {
+		k := i
+		for k < len(nonzero) && abs(nonzero[k]) == abs(nonzero[i]) {
+			k++
+		}
+		rank := float64(i+k+1) / 2
+		for _, diff := range nonzero[i:k] {
+			if diff > 0 {
+				positive += rank
+			}
+		}
+		tied := float64(k - i)
+		ties += tied*tied*tied - tied
+		i = k
+	}
+
+	mu := n * (n + 1) / 4
+	sigma := math.Sqrt(n*(n+1)*(2*n+1)/24 - ties/48)
+	if !(sigma > 0) {
+		return 1
+	}
+	z := math.Max(math.Abs(positive-mu)-0.5, 0) / sigma
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// abs returns the absolute value of d.
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Significant returns whether A and B differ at significance level alpha, e.g. 0.05.
+func (comparison *PairedComparison) Significant(alpha float64) bool {
+	return comparison.PValue < alpha
+}
+
+// WriteTo writes textual comparison to w.
+func (comparison *PairedComparison) WriteTo(w io.Writer) (int64, error) {
+	name := comparison.Name
+	if name == "" {
+		name = "A vs B"
+	}
+	n, err := fmt.Fprintf(w, "%s: %d pairs, B-A median %v, mean %v [%v, %v] %+.2f%%, p=%.3g, B faster in %.1f%%\n",
+		name, len(comparison.Diffs),
+		formatStat(float64(comparison.MedianDiff)), formatStat(float64(comparison.MeanDiff)),
+		formatStat(float64(comparison.Low)), formatStat(float64(comparison.High)),
+		comparison.Change*100, comparison.PValue, comparison.Faster*100)
+	return int64(n), err
+}
+
+// String returns textual comparison.
+func (comparison *PairedComparison) String() string {
+	var buffer strings.Builder
+	_, _ = comparison.WriteTo(&buffer)
+	return buffer.String()
+}
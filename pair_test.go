@@ -0,0 +1,45 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestRunAB(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	drift := time.Duration(0)
+	// the machine gets gradually slower, which affects both sides of a pair
+	a := func() { clock.Advance(1000 + drift); drift += 5 }
+	b := func() { clock.Advance(1010 + drift); drift += 5 }
+
+	comparison := hrtime.RunAB(100, a, b, hrtime.WithClock(clock), hrtime.WithWarmup(2))
+	if len(comparison.A) != 100 || len(comparison.B) != 100 {
+		t.Fatalf("unexpected pairs %d %d", len(comparison.A), len(comparison.B))
+	}
+	if comparison.MedianDiff <= 0 || !comparison.Significant(0.01) || comparison.Faster > 0.5 {
+		t.Errorf("expected B to be slower: %v", comparison)
+	}
+	if !strings.Contains(comparison.String(), "100 pairs") {
+		t.Errorf("unexpected output %v", comparison)
+	}
+}
+
+func TestComparePaired(t *testing.T) {
+	a := []time.Duration{100, 200, 300, 400, 500, 600}
+	b := []time.Duration{90, 190, 290, 390, 490, 590}
+
+	comparison := hrtime.ComparePaired("drifting", a, b)
+	if comparison.MedianDiff != -10 || comparison.MeanDiff != -10 || comparison.Faster != 1 {
+		t.Errorf("unexpected comparison %v", comparison)
+	}
+	if comparison.Low != -10 || comparison.High != -10 || comparison.PValue > 0.05 {
+		t.Errorf("expected exact interval and significance, got %v", comparison)
+	}
+
+	if same := hrtime.ComparePaired("", a, a); same.PValue != 1 || same.MeanDiff != 0 {
+		t.Errorf("expected no difference, got %v", same)
+	}
+}
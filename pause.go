@@ -0,0 +1,95 @@
+package hrtime
+
+// lapPause is the time excluded from a lap using Pause.
+type lapPause[T ~int64] struct {
+	lap      int
+	duration T
+}
+
+// lapPauses tracks pauses of laps, it's shared by Benchmark and BenchmarkTSC.
+type lapPauses[T ~int64] struct {
+	// paused is set between Pause and Resume, pausedAt is the reading at Pause.
+	paused   bool
+	pausedAt T
+	// pauses contains time excluded from laps in progress.
+	pauses []lapPause[T]
+}
+
+// Pause stops measuring the lap in progress until Resume, which excludes
+// e.g. preparing input of the lap, similarly to testing.B.StopTimer.
+//
+// Next and Stop resume a paused lap. Pause and Resume read the clock,
+// hence their overhead is still included in the lap.
+func (bench *Benchmark) Pause() {
+	bench.pause(bench.opts.now())
+}
+
+// Resume continues measuring the lap stopped by Pause.
+func (bench *Benchmark) Resume() {
+	bench.resume(bench.opts.now())
+}
+
+// Pause stops measuring the lap in progress until Resume, see Benchmark.Pause.
+func (bench *BenchmarkTSC) Pause() {
+	bench.pause(bench.opts.tsc())
+}
+
+// Resume continues measuring the lap stopped by Pause.
+func (bench *BenchmarkTSC) Resume() {
+	bench.resume(bench.opts.tsc())
+}
+
+// pause pauses the lap in progress at now.
+func (core *lapCore[T]) pause(now T) {
+	switch {
+	case core.done.Load():
+		core.misuse("benchmark already completed")
+	case core.step == 0:
+		core.misuse("pause called before Next")
+	case core.recording:
+		core.misuse("cannot pause recorded laps")
+	case core.paused:
+		core.misuse("lap already paused")
+	default:
+		core.paused, core.pausedAt = true, now
+	}
+}
+
+// resume resumes the paused lap, excluding the time until now from it.
+func (core *lapCore[T]) resume(now T) {
+	if !core.paused {
+		core.misuse("resume called without Pause")
+		return
+	}
+	core.endPause(now)
+}
+
+// endPause resumes the lap at now, when it's paused.
+func (core *lapCore[T]) endPause(now T) {
+	if !core.paused {
+		return
+	}
+	core.paused = false
+	excluded := now - core.pausedAt
+	if core.sampling != nil {
+		if core.sampling.measuring {
+			core.sampling.lapStart += excluded
+		}
+		return
+	}
+
+	lap := core.step - 1
+	if n := len(core.pauses); n > 0 && core.pauses[n-1].lap == lap {
+		core.pauses[n-1].duration += excluded
+	} else {
+		core.pauses = append(core.pauses, lapPause[T]{lap: lap, duration: excluded})
+	}
+}
+
+// excludePauses subtracts pauses from laps converted to durations.
+func (pauses *lapPauses[T]) excludePauses(laps []T) {
+	for _, pause := range pauses.pauses {
+		laps[pause.lap] -= pause.duration
+	}
+	pauses.pauses = pauses.pauses[:0]
+}
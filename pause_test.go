@@ -0,0 +1,85 @@
+package hrtime_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkPause(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(3, hrtime.WithWarmup(1), hrtime.WithClock(clock), hrtime.WithTimestamps())
+	for i := 0; bench.Next(); i++ {
+		clock.Advance(10)
+		bench.Pause()
+		clock.Advance(100)
+		bench.Resume()
+		clock.Advance(5)
+		if i == 2 {
+			// paused twice and until Next
+			bench.Pause()
+			clock.Advance(50)
+			bench.Resume()
+			bench.Pause()
+			clock.Advance(50)
+		}
+	}
+
+	if laps := bench.Laps(); !slices.Equal(laps, []time.Duration{15, 15, 15}) {
+		t.Errorf("unexpected laps %v", laps)
+	}
+	if timestamps := bench.Timestamps(); timestamps[1]-timestamps[0] != 115 {
+		t.Errorf("expected paused time on the timeline, got %v", timestamps)
+	}
+}
+
+func TestBenchmarkTSCPause(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmarkTSC(4, hrtime.WithClock(clock))
+	for bench.Next() {
+		clock.Advance(time.Microsecond)
+		bench.Pause()
+		clock.Advance(time.Millisecond)
+		bench.Resume()
+	}
+
+	if max := bench.Stats().Maximum; max >= 100*time.Microsecond {
+		t.Errorf("expected paused time to be excluded, got %v", max)
+	}
+}
+
+func TestBenchmarkPauseSampling(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(100, hrtime.WithSampling(2), hrtime.WithClock(clock))
+	for bench.Next() {
+		clock.Advance(10)
+		bench.Pause()
+		clock.Advance(100)
+	}
+
+	for _, lap := range bench.Laps() {
+		if lap != 10 {
+			t.Fatalf("expected paused time to be excluded, got %v", bench.Laps())
+		}
+	}
+}
+
+func TestBenchmarkPauseMisuse(t *testing.T) {
+	var reported []error
+	hrtime.OnMisuse(func(err error) { reported = append(reported, err) })
+	defer hrtime.OnMisuse(nil)
+
+	bench := hrtime.NewBenchmark(1, hrtime.WithMisusePolicy(hrtime.MisuseError))
+	bench.Pause()
+	for bench.Next() {
+		bench.Resume()
+		bench.Pause()
+		bench.Pause()
+	}
+	if len(reported) != 3 || !errors.Is(bench.Err(), hrtime.ErrMisuse) {
+		t.Errorf("expected every misuse to be reported, got %v", reported)
+	}
+}
@@ -0,0 +1,123 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// PhaseSummary contains statistics of laps with the same label.
+type PhaseSummary struct {
+	Label string
+	Count int
+	Total time.Duration
+	Mean  time.Duration
+	// Share is the fraction of total time spent in this phase.
+	Share float64
+
+	Histogram *Histogram
+}
+
+// PhaseBreakdown reports how much each labeled phase contributes to total time.
+type PhaseBreakdown struct {
+	Phases []PhaseSummary
+	Total  time.Duration
+
+	// for pretty printing
+	Width int
+}
+
+// NewPhaseBreakdown groups laps by labels, where labels[i] is the label of laps[i].
+//
+// Phases are ordered by first appearance of the label.
+func NewPhaseBreakdown(labels []string, laps []time.Duration, binCount int) *PhaseBreakdown {
+	if len(labels) != len(laps) {
+		panic("labels and laps must have the same length")
+	}
+
+	breakdown := &PhaseBreakdown{Width: 60}
+
+	var order []string
+	grouped := map[string][]time.Duration{}
+	for i, label := range labels {
+		if _, exists := grouped[label]; !exists {
+			order = append(order, label)
+		}
+		grouped[label] = append(grouped[label], laps[i])
+		breakdown.Total += laps[i]
+	}
+
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	for _, label := range order {
+		phase := PhaseSummary{
+			Label:     label,
+			Count:     len(grouped[label]),
+			Histogram: NewDurationHistogram(grouped[label], &opts),
+		}
+		for _, lap := range grouped[label] {
+			phase.Total += lap
+		}
+		phase.Mean = phase.Total / time.Duration(phase.Count)
+		if breakdown.Total > 0 {
+			phase.Share = float64(phase.Total) / float64(breakdown.Total)
+		}
+		breakdown.Phases = append(breakdown.Phases, phase)
+	}
+
+	return breakdown
+}
+
+// phaseSymbols are used for distinguishing phases in the stacked bar.
+var phaseSymbols = []rune("█▓▒░#=+*")
+
+// WriteTo writes stacked bar of time shares and the per-phase table to w.
+func (breakdown *PhaseBreakdown) WriteTo(w io.Writer) (int64, error) {
+	var bar strings.Builder
+	for i, phase := range breakdown.Phases {
+		symbol := string(phaseSymbols[i%len(phaseSymbols)])
+		bar.WriteString(strings.Repeat(symbol, int(math.Round(phase.Share*float64(breakdown.Width)))))
+	}
+
+	labelLength := len("phase")
+	for _, phase := range breakdown.Phases {
+		if len(phase.Label) > labelLength {
+			labelLength = len(phase.Label)
+		}
+	}
+
+	var written int64
+	n, err := fmt.Fprintf(w, " |%s|\n %-[2]*[3]s   %8s %10s %7s %10s %10s %10s %10s\n",
+		bar.String(), labelLength, "phase", "count", "mean", "share", "p50", "p90", "p99", "max")
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for i, phase := range breakdown.Phases {
+		hist := phase.Histogram
+		n, err = fmt.Fprintf(w, " %-[1]*[2]s %[3]c %8[4]d %10[5]v %6.1[6]f%% %10[7]v %10[8]v %10[9]v %10[10]v\n",
+			labelLength, phase.Label, phaseSymbols[i%len(phaseSymbols)],
+			phase.Count, formatStat(float64(phase.Mean)), phase.Share*100,
+			formatStat(hist.P50),
+			formatStat(hist.P90),
+			formatStat(hist.P99),
+			formatStat(hist.Maximum),
+		)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns the phase breakdown as text.
+func (breakdown *PhaseBreakdown) String() string {
+	var buffer strings.Builder
+	_, _ = breakdown.WriteTo(&buffer)
+	return buffer.String()
+}
@@ -0,0 +1,22 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestPhaseBreakdown(t *testing.T) {
+	labels := []string{"parse", "execute", "parse", "execute"}
+	laps := []time.Duration{10, 30, 10, 50}
+
+	breakdown := hrtime.NewPhaseBreakdown(labels, laps, 4)
+	if len(breakdown.Phases) != 2 || breakdown.Phases[0].Label != "parse" {
+		t.Fatalf("unexpected phases %+v", breakdown.Phases)
+	}
+	if breakdown.Phases[1].Mean != 40 || breakdown.Phases[1].Share != 0.8 {
+		t.Errorf("unexpected execute phase %+v", breakdown.Phases[1])
+	}
+	t.Log("\n" + breakdown.String())
+}
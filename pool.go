@@ -0,0 +1,47 @@
+package hrtime
+
+import "sync"
+
+// BenchmarkPool reuses Benchmark-s and their lap storage across
+// many short measurement sessions, avoiding allocator pressure.
+//
+// BenchmarkPool is safe for concurrent use.
+type BenchmarkPool struct {
+	count int
+	opts  []Option
+	pool  sync.Pool
+}
+
+// NewBenchmarkPool creates a pool of benchmarks measuring count samples.
+func NewBenchmarkPool(count int, opts ...Option) *BenchmarkPool {
+	if count <= 0 {
+		panic("must have count at least 1")
+	}
+
+	pool := &BenchmarkPool{
+		count: count,
+		opts:  opts,
+	}
+	pool.pool.New = func() interface{} {
+		bench := NewBenchmark(pool.count, pool.opts...)
+		bench.pool = pool
+		return bench
+	}
+	return pool
+}
+
+// Get returns a benchmark ready for measurement.
+func (pool *BenchmarkPool) Get() *Benchmark {
+	return pool.pool.Get().(*Benchmark)
+}
+
+// Put returns bench to the pool.
+//
+// bench and any slices returned by LapsUnsafe must not be used after calling Put.
+func (pool *BenchmarkPool) Put(bench *Benchmark) {
+	if bench.pool != pool {
+		panic("benchmark does not belong to the pool")
+	}
+	bench.reset()
+	pool.pool.Put(bench)
+}
@@ -0,0 +1,56 @@
+package hrtime_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkPool(t *testing.T) {
+	pool := hrtime.NewBenchmarkPool(8, hrtime.WithWarmup(2))
+	for i := 0; i < 4; i++ {
+		bench := pool.Get()
+		for bench.Next() {
+		}
+		if len(bench.Laps()) != 8 {
+			t.Errorf("expected 8 laps, got %d", len(bench.Laps()))
+		}
+		pool.Put(bench)
+	}
+}
+
+func TestBenchmarkPoolSampling(t *testing.T) {
+	for _, option := range []hrtime.Option{hrtime.WithSampling(2), hrtime.WithReservoir(4)} {
+		pool := hrtime.NewBenchmarkPool(16, option)
+		for i := 0; i < 4; i++ {
+			bench := pool.Get()
+			for bench.Next() {
+			}
+			if bench.Observed() != 16 || len(bench.Laps()) > 16 {
+				t.Errorf("expected 16 observed laps, got %d with %d samples", bench.Observed(), len(bench.Laps()))
+			}
+			pool.Put(bench)
+		}
+	}
+}
+
+func TestBenchmarkPoolForeign(t *testing.T) {
+	pool := hrtime.NewBenchmarkPool(8)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a benchmark from another pool")
+		}
+	}()
+	pool.Put(hrtime.NewBenchmarkPool(8).Get())
+}
+
+func BenchmarkBenchmarkPool(b *testing.B) {
+	pool := hrtime.NewBenchmarkPool(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bench := pool.Get()
+		for bench.Next() {
+		}
+		pool.Put(bench)
+	}
+}
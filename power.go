@@ -0,0 +1,68 @@
+package hrtime
+
+import "math"
+
+// SignificanceLevel is the two-sided significance level used by RequiredSamples.
+const SignificanceLevel = 0.05
+
+// RequiredSamples returns the number of laps per benchmark needed to detect
+// a difference of effectSize between two benchmarks with the given power,
+// e.g. 0.8, at SignificanceLevel.
+//
+// effectSize is in nanoseconds and variance in nanoseconds squared.
+// It uses the normal approximation of a two-sample t-test.
+func RequiredSamples(effectSize, variance, power float64) int {
+	if !(power > 0 && power < 1) {
+		panic("power must be in range (0, 1)")
+	}
+	if effectSize == 0 {
+		panic("effect size must be non-zero")
+	}
+
+	za := normalQuantile(1 - SignificanceLevel/2)
+	zb := normalQuantile(power)
+	n := 2 * (za + zb) * (za + zb) * variance / (effectSize * effectSize)
+	return max(int(math.Ceil(n)), 2)
+}
+
+// normalQuantile returns the p-th quantile of the standard normal distribution.
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// RequiredSamples estimates the number of laps needed to detect a relative
+// change of the mean, e.g. 0.05 for 5%, with the given power, e.g. 0.8.
+//
+// The result is treated as a pilot run: its mean and variance are used
+// as estimates for the benchmark.
+func (result *Result) RequiredSamples(change, power float64) int {
+	if len(result.laps) < 2 {
+		panic("pilot run must have at least 2 laps")
+	}
+	mean := float64(meanDuration(result.laps))
+	var variance float64
+	for _, lap := range result.laps {
+		diff := float64(lap) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(result.laps) - 1)
+	return RequiredSamples(change*mean, variance, power)
+}
+
+// RequiredSamples estimates the number of laps needed to detect a relative
+// change of the mean, using the benchmark as a pilot run.
+//
+// See Result.RequiredSamples for details.
+func (bench *Benchmark) RequiredSamples(change, power float64) int {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().RequiredSamples(change, power)
+}
+
+// RequiredSamples estimates the number of laps needed to detect a relative
+// change of the mean, using the benchmark as a pilot run.
+//
+// See Result.RequiredSamples for details.
+func (bench *BenchmarkTSC) RequiredSamples(change, power float64) int {
+	bench = bench.mustBeCompleted()
+	return bench.Result().RequiredSamples(change, power)
+}
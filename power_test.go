@@ -0,0 +1,24 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestRequiredSamples(t *testing.T) {
+	// textbook: d = 0.5 with power 0.8 needs about 63 samples per group
+	if n := hrtime.RequiredSamples(0.5, 1, 0.8); n < 62 || n > 64 {
+		t.Errorf("expected about 63 samples, got %d", n)
+	}
+	if small, large := hrtime.RequiredSamples(1, 1, 0.8), hrtime.RequiredSamples(1, 4, 0.8); large <= small {
+		t.Errorf("larger variance should need more samples: %d <= %d", large, small)
+	}
+
+	pilot := hrtime.NewResult([]time.Duration{90, 110, 95, 105, 100, 100})
+	coarse, fine := pilot.RequiredSamples(0.10, 0.8), pilot.RequiredSamples(0.01, 0.8)
+	if fine <= coarse {
+		t.Errorf("detecting smaller change should need more samples: %d <= %d", fine, coarse)
+	}
+}
@@ -0,0 +1,143 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MaxCheckpoints is the maximum number of checkpoints in a Profiler.
+const MaxCheckpoints = 16
+
+// Profiler aggregates named checkpoints of many requests into Recorders.
+//
+// Each checkpoint records the time since the previous reached checkpoint,
+// or since the start of the request for the first one.
+type Profiler struct {
+	names     []string
+	recorders []*Recorder
+	total     *Recorder
+}
+
+// NewProfiler creates a profiler with the specified checkpoints.
+//
+// Checkpoints are referred to by their index in Trace.Checkpoint.
+func NewProfiler(checkpoints ...string) *Profiler {
+	if len(checkpoints) > MaxCheckpoints {
+		panic("too many checkpoints")
+	}
+
+	profiler := &Profiler{
+		names:     append(checkpoints[:0:0], checkpoints...),
+		recorders: make([]*Recorder, len(checkpoints)),
+		total:     NewRecorder(),
+	}
+	for i := range profiler.recorders {
+		profiler.recorders[i] = NewRecorder()
+	}
+	return profiler
+}
+
+// Start starts tracing a single request.
+//
+// When recording is disabled globally, the returned trace does nothing.
+func (profiler *Profiler) Start() Trace {
+	if disabled.Load() {
+		return Trace{}
+	}
+	return Trace{
+		profiler: profiler,
+		start:    Now(),
+	}
+}
+
+// Recorder returns the recorder for the named checkpoint, or nil when it doesn't exist.
+func (profiler *Profiler) Recorder(name string) *Recorder {
+	for i, checkpoint := range profiler.names {
+		if checkpoint == name {
+			return profiler.recorders[i]
+		}
+	}
+	return nil
+}
+
+// Total returns the recorder for complete request durations.
+func (profiler *Profiler) Total() *Recorder { return profiler.total }
+
+// WriteTo writes per-checkpoint statistics to w.
+func (profiler *Profiler) WriteTo(w io.Writer) (int64, error) {
+	nameLength := len("total")
+	for _, name := range profiler.names {
+		if len(name) > nameLength {
+			nameLength = len(name)
+		}
+	}
+
+	var written int64
+	write := func(name string, rec *Recorder) error {
+		hist := rec.Histogram(1)
+		n, err := fmt.Fprintf(w, " %-[1]*[2]s %8[3]d  avg %[4]v;  p50 %[5]v;  p99 %[6]v;  max %[7]v;\n",
+			nameLength, name, rec.Count(),
+			formatStat(hist.Average),
+			formatStat(hist.P50),
+			formatStat(hist.P99),
+			formatStat(hist.Maximum),
+		)
+		written += int64(n)
+		return err
+	}
+
+	for i, name := range profiler.names {
+		if err := write(name, profiler.recorders[i]); err != nil {
+			return written, err
+		}
+	}
+	err := write("total", profiler.total)
+	return written, err
+}
+
+// String returns per-checkpoint statistics.
+func (profiler *Profiler) String() string {
+	var buffer strings.Builder
+	_, _ = profiler.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// Trace measures checkpoints of a single request.
+//
+// Trace is a value type with fixed-size storage, hence
+// tracing itself doesn't allocate.
+type Trace struct {
+	profiler *Profiler
+	start    time.Duration
+	reached  uint32
+	marks    [MaxCheckpoints]time.Duration
+}
+
+// Checkpoint marks reaching the checkpoint with the specified index.
+func (trace *Trace) Checkpoint(index int) {
+	if trace.profiler == nil {
+		return
+	}
+	trace.marks[index] = Now()
+	trace.reached |= 1 << uint(index)
+}
+
+// Finish records the request into profiler recorders.
+func (trace *Trace) Finish() {
+	if trace.profiler == nil {
+		return
+	}
+	stop := Now()
+
+	previous := trace.start
+	for i, rec := range trace.profiler.recorders {
+		if trace.reached&(1<<uint(i)) == 0 {
+			continue
+		}
+		rec.Record(trace.marks[i] - previous)
+		previous = trace.marks[i]
+	}
+	trace.profiler.total.Record(stop - trace.start)
+}
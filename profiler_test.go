@@ -0,0 +1,39 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestProfiler(t *testing.T) {
+	const (
+		decode = iota
+		handle
+		encode
+	)
+	profiler := hrtime.NewProfiler("decode", "handle", "encode")
+
+	for i := 0; i < 8; i++ {
+		trace := profiler.Start()
+		time.Sleep(1000 * time.Nanosecond)
+		trace.Checkpoint(decode)
+		if i%2 == 0 {
+			trace.Checkpoint(handle)
+		}
+		trace.Checkpoint(encode)
+		trace.Finish()
+	}
+
+	if n := profiler.Recorder("decode").Count(); n != 8 {
+		t.Errorf("decode: expected 8, got %d", n)
+	}
+	if n := profiler.Recorder("handle").Count(); n != 4 {
+		t.Errorf("handle: expected 4, got %d", n)
+	}
+	if n := profiler.Total().Count(); n != 8 {
+		t.Errorf("total: expected 8, got %d", n)
+	}
+	t.Log("\n" + profiler.String())
+}
@@ -0,0 +1,43 @@
+package hrtime
+
+import (
+	"sort"
+	"time"
+)
+
+// sortedDurations returns a sorted copy of durations.
+func sortedDurations(durations []time.Duration) []time.Duration {
+	sorted := append(durations[:0:0], durations...)
+	sort.Slice(sorted, func(i, k int) bool { return sorted[i] < sorted[k] })
+	return sorted
+}
+
+// sortedCounts returns a sorted copy of counts.
+func sortedCounts(counts []Count) []Count {
+	sorted := append(counts[:0:0], counts...)
+	sort.Slice(sorted, func(i, k int) bool { return sorted[i] < sorted[k] })
+	return sorted
+}
+
+// quantile returns q-th quantile of sorted values using linear interpolation.
+//
+// q is clamped to range [0, 1].
+func quantile[T ~int64 | ~float64](sorted []T, q float64) T {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return sorted[0]
+	}
+	if q >= 1 {
+		return sorted[len(sorted)-1]
+	}
+
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	frac := pos - float64(i)
+	if i+1 >= len(sorted) {
+		return sorted[i]
+	}
+	return sorted[i] + T(frac*float64(sorted[i+1]-sorted[i]))
+}
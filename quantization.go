@@ -0,0 +1,103 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// ValueCount is the number of laps with a specific value.
+type ValueCount struct {
+	Value time.Duration
+	Count int
+}
+
+// Quantization describes how much laps are affected by clock granularity.
+type Quantization struct {
+	// Resolution is the effective resolution, the greatest common divisor
+	// of differences between distinct lap values.
+	Resolution time.Duration
+	// Distinct is the number of distinct lap values.
+	Distinct int
+	// Total is the number of laps.
+	Total int
+	// Heavy reports whether laps are dominated by quantization,
+	// i.e. on average each distinct value appears at least 10 times.
+	Heavy bool
+	// Values contains counts at each distinct value, ordered by value.
+	Values []ValueCount
+
+	// for pretty printing
+	Width int
+}
+
+// DetectQuantization analyzes laps for timer quantization artifacts.
+func DetectQuantization(laps []time.Duration) *Quantization {
+	quant := &Quantization{Total: len(laps), Width: 40}
+	if len(laps) == 0 {
+		return quant
+	}
+
+	sorted := sortedDurations(laps)
+	for i, lap := range sorted {
+		if i == 0 || lap != sorted[i-1] {
+			quant.Values = append(quant.Values, ValueCount{Value: lap})
+		}
+		quant.Values[len(quant.Values)-1].Count++
+	}
+	quant.Distinct = len(quant.Values)
+
+	for i := 1; i < len(quant.Values); i++ {
+		quant.Resolution = gcdDuration(quant.Resolution, quant.Values[i].Value-quant.Values[i-1].Value)
+	}
+	quant.Heavy = quant.Distinct*10 <= quant.Total
+
+	return quant
+}
+
+// gcdDuration returns the greatest common divisor of a and b.
+func gcdDuration(a, b time.Duration) time.Duration {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// WriteTo writes effective resolution and counts at each value to w.
+//
+// It's a better alternative to histograms for heavily quantized laps.
+func (quant *Quantization) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "  resolution %v;  distinct %d of %d;  heavy %v;\n", quant.Resolution, quant.Distinct, quant.Total, quant.Heavy)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	maxCount := 0
+	for _, value := range quant.Values {
+		if value.Count > maxCount {
+			maxCount = value.Count
+		}
+	}
+	countLength := int(math.Ceil(math.Log10(float64(maxCount + 1))))
+
+	for _, value := range quant.Values {
+		width := int(math.Round(float64(quant.Width) * float64(value.Count) / float64(maxCount)))
+		n, err = fmt.Fprintf(w, " %10v [%[2]*[3]d] %[4]s\n", value.Value, countLength, value.Count, strings.Repeat("█", width))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns effective resolution and counts at each value.
+func (quant *Quantization) String() string {
+	var buffer strings.Builder
+	_, _ = quant.WriteTo(&buffer)
+	return buffer.String()
+}
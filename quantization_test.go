@@ -0,0 +1,24 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestDetectQuantization(t *testing.T) {
+	var laps []time.Duration
+	for i := 0; i < 100; i++ {
+		laps = append(laps, time.Duration(100*(1+i%3))*time.Nanosecond)
+	}
+
+	quant := hrtime.DetectQuantization(laps)
+	if quant.Resolution != 100*time.Nanosecond {
+		t.Errorf("resolution: got %v", quant.Resolution)
+	}
+	if quant.Distinct != 3 || !quant.Heavy {
+		t.Errorf("distinct %v, heavy %v", quant.Distinct, quant.Heavy)
+	}
+	t.Log("\n" + quant.String())
+}
@@ -0,0 +1,156 @@
+package hrtime
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Recorder collects durations from concurrent goroutines.
+//
+// Unlike Benchmark it doesn't need to know the number of samples up front,
+// which makes it useful for always-on instrumentation.
+type Recorder struct {
+	mu          sync.Mutex
+	laps        []time.Duration
+	annotations []Annotation
+
+	disabled atomic.Bool
+	// calls counts Record calls to keep every n-th duration.
+	calls atomic.Int64
+	// observed is incremented under mu for recorded durations,
+	// which keeps it consistent with laps across resets.
+	observed atomic.Int64
+	// every keeps only every n-th duration, when larger than 1.
+	every atomic.Int64
+	// dropProbability contains float64 bits of probability for skipping a duration.
+	dropProbability atomic.Uint64
+}
+
+// NewRecorder creates a new empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// SetEnabled enables or disables this recorder.
+//
+// Recording can be also disabled globally using SetEnabled.
+func (rec *Recorder) SetEnabled(enabled bool) { rec.disabled.Store(!enabled) }
+
+// Enabled returns whether this recorder is enabled.
+func (rec *Recorder) Enabled() bool { return !rec.disabled.Load() }
+
+// SampleEvery configures recorder to keep only 1 in n durations.
+//
+// n <= 1 records every duration. It disables SampleProbability.
+func (rec *Recorder) SampleEvery(n int) {
+	rec.dropProbability.Store(0)
+	rec.every.Store(int64(n))
+}
+
+// SampleProbability configures recorder to keep each duration with probability p.
+//
+// p >= 1 records every duration. It disables SampleEvery.
+func (rec *Recorder) SampleProbability(p float64) {
+	if p > 1 {
+		p = 1
+	}
+	if p < 0 {
+		p = 0
+	}
+	rec.every.Store(0)
+	rec.dropProbability.Store(math.Float64bits(1 - p))
+}
+
+// Observed returns the number of Record calls, including durations skipped by sampling.
+//
+// Durations skipped concurrently with Reset or SnapshotAndReset may be
+// counted either before or after it, recorded durations are counted
+// together with laps.
+func (rec *Recorder) Observed() int64 {
+	return rec.observed.Load()
+}
+
+// Record adds a single duration.
+//
+// When sampling is configured, the duration may be skipped.
+// When recording is disabled, the duration is ignored.
+func (rec *Recorder) Record(duration time.Duration) {
+	if disabled.Load() || rec.disabled.Load() {
+		return
+	}
+
+	if every := rec.every.Load(); every > 1 && rec.calls.Add(1)%every != 0 {
+		rec.observed.Add(1)
+		return
+	}
+	if drop := rec.dropProbability.Load(); drop != 0 && rand.Float64() < math.Float64frombits(drop) {
+		rec.observed.Add(1)
+		return
+	}
+
+	rec.mu.Lock()
+	rec.observed.Add(1)
+	rec.laps = append(rec.laps, duration)
+	rec.mu.Unlock()
+}
+
+// Count returns the number of recorded durations.
+func (rec *Recorder) Count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.laps)
+}
+
+// Laps returns a copy of recorded durations.
+func (rec *Recorder) Laps() []time.Duration {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append(rec.laps[:0:0], rec.laps...)
+}
+
+// Reset removes all recorded durations.
+//
+// Sampling configuration and annotations are kept.
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	rec.laps = rec.laps[:0]
+	rec.calls.Store(0)
+	rec.observed.Store(0)
+	rec.mu.Unlock()
+}
+
+// SnapshotAndReset returns recorded durations and removes them from the recorder.
+//
+// Lap storage is swapped while holding the lock, hence concurrent Record calls
+// are not blocked for the duration of the copy and every duration ends up
+// in exactly one snapshot. It's intended for periodic exporters.
+// Sampling configuration is kept.
+func (rec *Recorder) SnapshotAndReset() *Result {
+	rec.mu.Lock()
+	laps := rec.laps
+	rec.laps = make([]time.Duration, 0, cap(laps))
+	rec.calls.Store(0)
+	rec.observed.Store(0)
+	rec.mu.Unlock()
+
+	result := &Result{laps: laps}
+	for _, lap := range laps {
+		result.stop += lap
+	}
+	return result
+}
+
+// Histogram creates an histogram of all recorded durations.
+//
+// It creates binCount bins to distribute the data and uses the
+// 99.9 percentile as the last bucket range. However, for a nicer output
+// it might choose a larger value.
+func (rec *Recorder) Histogram(binCount int) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	return NewDurationHistogram(rec.Laps(), &opts)
+}
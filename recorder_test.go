@@ -0,0 +1,147 @@
+package hrtime_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := hrtime.NewRecorder()
+	for i := 0; i < 8; i++ {
+		go rec.Record(time.Duration(i))
+	}
+	for rec.Count() < 8 {
+		time.Sleep(time.Millisecond)
+	}
+	t.Log(rec.Histogram(4))
+
+	rec.Reset()
+	if rec.Count() != 0 {
+		t.Errorf("expected empty recorder after reset")
+	}
+}
+
+func TestRecorderSampleEvery(t *testing.T) {
+	rec := hrtime.NewRecorder()
+	rec.SampleEvery(4)
+	for i := 0; i < 100; i++ {
+		rec.Record(time.Duration(i))
+	}
+	if rec.Count() != 25 || rec.Observed() != 100 {
+		t.Errorf("expected 25 of 100, got %d of %d", rec.Count(), rec.Observed())
+	}
+}
+
+func TestRecorderSampleProbability(t *testing.T) {
+	rec := hrtime.NewRecorder()
+	rec.SampleProbability(0)
+	for i := 0; i < 100; i++ {
+		rec.Record(time.Duration(i))
+	}
+	if rec.Count() != 0 {
+		t.Errorf("expected no recorded durations, got %d", rec.Count())
+	}
+
+	rec.SampleProbability(1)
+	for i := 0; i < 100; i++ {
+		rec.Record(time.Duration(i))
+	}
+	if rec.Count() != 100 {
+		t.Errorf("expected all recorded durations, got %d", rec.Count())
+	}
+}
+
+func TestRecorderEnabled(t *testing.T) {
+	rec := hrtime.NewRecorder()
+
+	rec.SetEnabled(false)
+	rec.Record(1)
+	rec.SetEnabled(true)
+
+	hrtime.SetEnabled(false)
+	rec.Record(2)
+	hrtime.SetEnabled(true)
+
+	rec.Record(3)
+	if rec.Count() != 1 {
+		t.Errorf("expected only one recorded duration, got %d", rec.Count())
+	}
+}
+
+func BenchmarkRecorderDisabled(b *testing.B) {
+	rec := hrtime.NewRecorder()
+	rec.SetEnabled(false)
+	for i := 0; i < b.N; i++ {
+		rec.Record(time.Duration(i))
+	}
+}
+
+func TestRecorderSnapshotAndReset(t *testing.T) {
+	rec := hrtime.NewRecorder()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				rec.Record(time.Duration(i))
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	total := 0
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		case <-time.After(time.Microsecond):
+		}
+		total += rec.SnapshotAndReset().Count()
+	}
+
+	if total != 4000 {
+		t.Errorf("expected 4000 durations across snapshots, got %d", total)
+	}
+}
+
+func TestRecorderObservedAcrossReset(t *testing.T) {
+	rec := hrtime.NewRecorder()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				rec.Record(time.Duration(i))
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+			rec.Reset()
+		}
+	}
+
+	if rec.Observed() != int64(rec.Count()) {
+		t.Errorf("expected observed to match %d recorded durations, got %d", rec.Count(), rec.Observed())
+	}
+}
@@ -0,0 +1,122 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Repetitions contains statistics of repeated runs of a benchmark.
+type Repetitions struct {
+	// Runs contains statistics of each run, in order.
+	Runs []*Stats
+}
+
+// Repeat runs fn count times, resetting bench before every run.
+//
+// fn should measure using bench, for example with a `for bench.Next()` loop.
+// A run that doesn't complete the benchmark is stopped after fn returns.
+// Reusing bench avoids allocating lap storage between runs, which would
+// otherwise perturb the garbage collector behavior of the measured code.
+func (bench *Benchmark) Repeat(count int, fn func(bench *Benchmark)) *Repetitions {
+	if count <= 0 {
+		bench.opts.misuse("must have count at least 1")
+		return &Repetitions{}
+	}
+
+	reps := &Repetitions{Runs: make([]*Stats, 0, count)}
+	for range count {
+		bench.Reset()
+		fn(bench)
+		if !bench.Completed() {
+			bench.Stop()
+		}
+		reps.Runs = append(reps.Runs, NewStats(bench.laps))
+	}
+	return reps
+}
+
+// Repeat runs fn count times, resetting bench before every run, see Benchmark.Repeat.
+func (bench *BenchmarkTSC) Repeat(count int, fn func(bench *BenchmarkTSC)) *Repetitions {
+	if count <= 0 {
+		bench.opts.misuse("must have count at least 1")
+		return &Repetitions{}
+	}
+
+	reps := &Repetitions{Runs: make([]*Stats, 0, count)}
+	for range count {
+		bench.Reset()
+		fn(bench)
+		if !bench.Completed() {
+			bench.Stop()
+		}
+		reps.Runs = append(reps.Runs, bench.Stats())
+	}
+	return reps
+}
+
+// Means returns the mean of each run.
+func (reps *Repetitions) Means() []time.Duration {
+	means := make([]time.Duration, len(reps.Runs))
+	for i, run := range reps.Runs {
+		means[i] = run.Mean
+	}
+	return means
+}
+
+// MinMean returns the smallest mean across runs.
+//
+// It's the least affected by interference from other processes.
+func (reps *Repetitions) MinMean() time.Duration {
+	if len(reps.Runs) == 0 {
+		return 0
+	}
+	return sortedDurations(reps.Means())[0]
+}
+
+// MeanOfMeans returns the average of run means.
+func (reps *Repetitions) MeanOfMeans() time.Duration {
+	return meanDuration(reps.Means())
+}
+
+// MeanStdDev returns the sample standard deviation of run means,
+// which describes the variance across runs.
+func (reps *Repetitions) MeanStdDev() time.Duration {
+	means := reps.Means()
+	if len(means) < 2 {
+		return 0
+	}
+	mean := float64(meanDuration(means))
+	var variance float64
+	for _, m := range means {
+		diff := float64(m) - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(means) - 1)
+	return time.Duration(math.Sqrt(variance))
+}
+
+// WriteTo writes statistics of every run and a summary across runs to w.
+func (reps *Repetitions) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for i, run := range reps.Runs {
+		n, err := fmt.Fprintf(w, "run %d: %d laps, mean %v, p50 %v, p99 %v\n", i+1, run.Count, run.Mean, run.P50, run.P99)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err := fmt.Fprintf(w, "%d runs: min-of-means %v, mean-of-means %v ±%v\n",
+		len(reps.Runs), reps.MinMean(), reps.MeanOfMeans(), reps.MeanStdDev())
+	total += int64(n)
+	return total, err
+}
+
+// String returns statistics of every run and a summary across runs.
+func (reps *Repetitions) String() string {
+	var s strings.Builder
+	_, _ = reps.WriteTo(&s)
+	return s.String()
+}
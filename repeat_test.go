@@ -0,0 +1,36 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkRepeat(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmark(4, hrtime.WithClock(clock))
+
+	run := 0
+	var storage []*time.Duration
+	reps := bench.Repeat(3, func(bench *hrtime.Benchmark) {
+		run++
+		for bench.Next() {
+			clock.Advance(time.Duration(run) * time.Microsecond)
+		}
+		storage = append(storage, &bench.LapsUnsafe()[0])
+	})
+
+	if storage[0] != storage[1] || storage[1] != storage[2] {
+		t.Errorf("expected lap storage to be reused")
+	}
+	if len(reps.Runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(reps.Runs))
+	}
+	if reps.MinMean() != time.Microsecond {
+		t.Errorf("expected min-of-means 1µs, got %v", reps.MinMean())
+	}
+	if reps.MeanOfMeans() != 2*time.Microsecond || reps.MeanStdDev() != time.Microsecond {
+		t.Errorf("expected 2µs ±1µs, got %v ±%v", reps.MeanOfMeans(), reps.MeanStdDev())
+	}
+}
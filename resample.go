@@ -0,0 +1,43 @@
+package hrtime
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+)
+
+// Resampler configures resampling-based statistics,
+// such as CompareLapsBayesian and NewShiftFunction.
+//
+// Resampling is deterministic: the same laps and Seed always produce
+// the same results, which keeps CI reruns on identical data reproducible.
+type Resampler struct {
+	// Seed initializes the random number generator.
+	// When zero, the seed is derived from a hash of the laps.
+	Seed uint64
+}
+
+// rand returns a random number generator for resampling laps.
+func (resampler Resampler) rand(laps ...[]time.Duration) *rand.Rand {
+	seed := resampler.Seed
+	if seed == 0 {
+		seed = hashLaps(laps...)
+	}
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// hashLaps returns FNV-1a hash of laps.
+func hashLaps(laps ...[]time.Duration) uint64 {
+	hash := fnv.New64a()
+	var buffer [8]byte
+	for _, set := range laps {
+		binary.LittleEndian.PutUint64(buffer[:], uint64(len(set)))
+		_, _ = hash.Write(buffer[:])
+		for _, lap := range set {
+			binary.LittleEndian.PutUint64(buffer[:], uint64(lap))
+			_, _ = hash.Write(buffer[:])
+		}
+	}
+	return hash.Sum64()
+}
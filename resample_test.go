@@ -0,0 +1,32 @@
+package hrtime_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestResamplerDeterministic(t *testing.T) {
+	var old, new []time.Duration
+	for i := 0; i < 100; i++ {
+		old = append(old, time.Duration(1000+i*7%31))
+		new = append(new, time.Duration(1010+i*11%37))
+	}
+
+	if a, b := hrtime.CompareLapsBayesian("", old, new), hrtime.CompareLapsBayesian("", old, new); !reflect.DeepEqual(a, b) {
+		t.Errorf("expected identical results for identical data:\n%v%v", a, b)
+	}
+	if a, b := hrtime.NewShiftFunction("", old, new, 0.95), hrtime.NewShiftFunction("", old, new, 0.95); !reflect.DeepEqual(a, b) {
+		t.Errorf("expected identical results for identical data:\n%v%v", a, b)
+	}
+
+	seeded := hrtime.Resampler{Seed: 1}.CompareLapsBayesian("", old, new)
+	if again := (hrtime.Resampler{Seed: 1}).CompareLapsBayesian("", old, new); !reflect.DeepEqual(seeded, again) {
+		t.Errorf("expected identical results for identical seed")
+	}
+	if other := (hrtime.Resampler{Seed: 2}).CompareLapsBayesian("", old, new); reflect.DeepEqual(seeded, other) {
+		t.Errorf("expected different results for different seeds")
+	}
+}
@@ -0,0 +1,197 @@
+package hrtime
+
+import (
+	"io"
+	"iter"
+	"time"
+)
+
+// Result is an immutable measurement result of a completed benchmark.
+//
+// It decouples analysis from measurement: the benchmark can be reused or
+// discarded, while its Result is safe to share between goroutines and
+// to serialize. All the analysis of Benchmark and BenchmarkTSC is
+// implemented by Result.
+type Result struct {
+	laps  []time.Duration
+	start time.Duration
+	stop  time.Duration
+
+	nonMonotonic int
+	err          error
+	memStats     *MemStats
+	// bytes is the number of bytes processed by a single lap.
+	bytes int64
+	// trimmed is the number of outliers removed by Trimmed.
+	trimmed int
+}
+
+// NewResult creates a result from externally measured laps.
+func NewResult(laps []time.Duration) *Result {
+	result := &Result{laps: append(laps[:0:0], laps...)}
+	for _, lap := range result.laps {
+		result.stop += lap
+	}
+	return result
+}
+
+// Result returns the immutable result of the benchmark.
+//
+// The result shares lap storage with the benchmark, hence a benchmark
+// returned to BenchmarkPool allocates new storage after Result is used.
+func (bench *Benchmark) Result() *Result {
+	bench = bench.mustBeCompleted()
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+	if bench.result == nil {
+		bench.result = bench.analysis()
+	}
+	return bench.result
+}
+
+// analysis returns a result for analysis without sharing it.
+func (bench *Benchmark) analysis() *Result {
+	return &Result{
+		laps:         bench.laps,
+		start:        bench.start,
+		stop:         bench.stop,
+		nonMonotonic: bench.nonMonotonic,
+		err:          bench.err,
+		memStats:     bench.memStats,
+		bytes:        bench.bytes,
+	}
+}
+
+// Result returns the immutable result of the benchmark.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) Result() *Result {
+	bench = bench.mustBeCompleted()
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+	if bench.result == nil {
+		bench.result = bench.analysis()
+	}
+	return bench.result
+}
+
+// analysis returns a result for analysis without sharing it.
+//
+// Laps are converted once, sharing the conversion ratio.
+func (bench *BenchmarkTSC) analysis() *Result {
+	ratio := loadTSCRatio()
+	laps := make([]time.Duration, len(bench.laps))
+	for i, count := range bench.laps {
+		laps[i] = ratio.duration(count)
+	}
+	return &Result{
+		laps:         laps,
+		start:        bench.start.ApproxDuration(),
+		stop:         bench.stop.ApproxDuration(),
+		nonMonotonic: bench.nonMonotonic,
+		err:          bench.err,
+		memStats:     bench.memStats,
+		bytes:        bench.bytes,
+	}
+}
+
+// Count returns the number of laps.
+func (result *Result) Count() int { return len(result.laps) }
+
+// Laps returns a copy of lap durations.
+func (result *Result) Laps() []time.Duration {
+	return append(result.laps[:0:0], result.laps...)
+}
+
+// All returns an iterator over lap indices and timings.
+func (result *Result) All() iter.Seq2[int, time.Duration] {
+	return func(yield func(int, time.Duration) bool) {
+		for i, lap := range result.laps {
+			if !yield(i, lap) {
+				return
+			}
+		}
+	}
+}
+
+// Timeline returns the time when the benchmark started and stopped.
+func (result *Result) Timeline() (start, stop time.Duration) {
+	return result.start, result.stop
+}
+
+// NonMonotonic returns the number of laps where the clock went backwards.
+func (result *Result) NonMonotonic() int { return result.nonMonotonic }
+
+// Err returns an error when measurement is not reliable.
+func (result *Result) Err() error { return result.err }
+
+// Stats calculates summary statistics of all the laps.
+func (result *Result) Stats() *Stats {
+	stats := NewStats(result.laps)
+	stats.Trimmed = result.trimmed
+	return stats
+}
+
+// Histogram creates an histogram of all the laps.
+//
+// It creates binCount bins to distribute the data and uses the
+// 99.9 percentile as the last bucket range. However, for a nicer output
+// it might choose a larger value.
+func (result *Result) Histogram(binCount int) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	return NewDurationHistogram(result.laps, &opts)
+}
+
+// HistogramClamp creates an historgram of all the laps clamping minimum and maximum time.
+//
+// It creates binCount bins to distribute the data and uses the
+// maximum as the last bucket.
+func (result *Result) HistogramClamp(binCount int, min, max time.Duration) *Histogram {
+	return newClampHistogram(clampNanos(durationNanos(result.laps), min), binCount, max)
+}
+
+// HistogramClampPercentile creates an histogram of all the laps clamping minimum time
+// and the maximum to the percentile of the data.
+//
+// Percentile must be in range (0, 1].
+func (result *Result) HistogramClampPercentile(binCount int, min time.Duration, percentile float64) *Histogram {
+	return newPercentileHistogram(clampNanos(durationNanos(result.laps), min), binCount, percentile)
+}
+
+// DualHistogram creates body and tail histograms of all the laps split at p95.
+//
+// It creates binCount bins for both the body and the tail.
+func (result *Result) DualHistogram(binCount int) *DualHistogram {
+	return NewDurationDualHistogram(result.laps, binCount, 0.95)
+}
+
+// JSONResult converts the result to the stable exported representation.
+func (result *Result) JSONResult(name string) *JSONResult {
+	return NewJSONResult(name, result.laps)
+}
+
+// WriteJSON writes laps and summary statistics to w as JSONResult.
+func (result *Result) WriteJSON(w io.Writer) error {
+	return result.JSONResult("").Encode(w)
+}
+
+// WriteCSV writes laps in nanoseconds to w with a header row.
+func (result *Result) WriteCSV(w io.Writer) error {
+	return writeLapsCSV(w, result.laps)
+}
+
+// WriteGoBench writes the mean lap in go test -bench format to w.
+//
+// Allocations are included, when measured WithMemStats, and throughput,
+// when the benchmark used SetBytes.
+// name is prefixed with "Benchmark" when necessary and spaces are replaced with underscores.
+func (result *Result) WriteGoBench(w io.Writer, name string) error {
+	return writeGoBench(w, name, result.laps, result.Throughput(), result.memStats)
+}
+
+// CompareTo compares the result against baseline.
+func (result *Result) CompareTo(baseline *Result) *Comparison {
+	return CompareLaps("", baseline.laps, result.laps)
+}
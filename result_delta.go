@@ -0,0 +1,64 @@
+package hrtime
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrNotCumulative is returned by Result.Sub when the previous snapshot
+// contains laps that are missing from the current one.
+var ErrNotCumulative = errors.New("previous snapshot is not contained in the result")
+
+// Sub returns laps recorded after previous, where result and previous are
+// cumulative snapshots of the same source, e.g. consecutive Recorder.Laps.
+//
+// When previous is a prefix of result the lap order is kept, otherwise laps
+// are subtracted as a multiset and the delta is sorted. The delta can be analyzed
+// like any other result, e.g. with Stats or Histogram for interval dashboards.
+// It returns ErrNotCumulative when previous contains laps not in result.
+func (result *Result) Sub(previous *Result) (*Result, error) {
+	if len(previous.laps) > len(result.laps) {
+		return nil, ErrNotCumulative
+	}
+
+	delta := &Result{}
+	if slices.Equal(previous.laps, result.laps[:len(previous.laps)]) {
+		delta.laps = slices.Clone(result.laps[len(previous.laps):])
+	} else {
+		current, removed := sortedDurations(result.laps), sortedDurations(previous.laps)
+		delta.laps = make([]time.Duration, 0, len(current)-len(removed))
+		k := 0
+		for _, lap := range current {
+			if k < len(removed) && removed[k] == lap {
+				k++
+				continue
+			}
+			if k < len(removed) && removed[k] < lap {
+				return nil, ErrNotCumulative
+			}
+			delta.laps = append(delta.laps, lap)
+		}
+		if k < len(removed) {
+			return nil, ErrNotCumulative
+		}
+	}
+
+	for _, lap := range delta.laps {
+		delta.stop += lap
+	}
+	delta.start = result.stop - delta.stop
+	delta.stop = result.stop
+	delta.nonMonotonic = max(result.nonMonotonic-previous.nonMonotonic, 0)
+	if delta.nonMonotonic > 0 {
+		delta.err = result.err
+	}
+	if result.memStats != nil && previous.memStats != nil {
+		delta.memStats = &MemStats{
+			Laps:   len(delta.laps),
+			Allocs: result.memStats.Allocs - min(previous.memStats.Allocs, result.memStats.Allocs),
+			Bytes:  result.memStats.Bytes - min(previous.memStats.Bytes, result.memStats.Bytes),
+		}
+	}
+	return delta, nil
+}
@@ -0,0 +1,91 @@
+package hrtime_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkResult(t *testing.T) {
+	pool := hrtime.NewBenchmarkPool(16)
+	bench := pool.Get()
+	for bench.Next() {
+	}
+	result := bench.Result()
+	laps := result.Laps()
+	pool.Put(bench)
+
+	// reusing the benchmark must not modify the result
+	reused := pool.Get()
+	for reused.Next() {
+		time.Sleep(time.Microsecond)
+	}
+	for i, lap := range result.Laps() {
+		if lap != laps[i] {
+			t.Fatalf("result modified by reused benchmark")
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result.Stats().Count != 16 || result.Histogram(4) == nil {
+				t.Errorf("invalid result")
+			}
+		}()
+	}
+	wg.Wait()
+
+	tsc := hrtime.NewBenchmarkTSC(8)
+	for tsc.Next() {
+	}
+	if tsc.Result().Count() != 8 || tsc.Result() != tsc.Result() {
+		t.Errorf("invalid tsc result")
+	}
+
+	external := hrtime.NewResult([]time.Duration{time.Millisecond, 2 * time.Millisecond})
+	if c := external.CompareTo(result); c.NewCount != 2 || c.OldCount != 16 {
+		t.Errorf("invalid comparison %v", c)
+	}
+}
+
+func TestResultSub(t *testing.T) {
+	rec := hrtime.NewRecorder()
+	for i := 1; i <= 3; i++ {
+		rec.Record(time.Duration(i))
+	}
+	previous := hrtime.NewResult(rec.Laps())
+	for i := 4; i <= 6; i++ {
+		rec.Record(time.Duration(i))
+	}
+	current := hrtime.NewResult(rec.Laps())
+
+	delta, err := current.Sub(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if laps := delta.Laps(); !slices.Equal(laps, []time.Duration{4, 5, 6}) {
+		t.Errorf("expected laps after previous snapshot, got %v", laps)
+	}
+	if start, stop := delta.Timeline(); start != 6 || stop != 21 {
+		t.Errorf("unexpected timeline %v %v", start, stop)
+	}
+
+	shuffled := hrtime.NewResult([]time.Duration{5, 1, 4, 2, 6, 3})
+	if delta, err := shuffled.Sub(previous); err != nil || !slices.Equal(delta.Laps(), []time.Duration{4, 5, 6}) {
+		t.Errorf("expected multiset difference, got %v, %v", delta, err)
+	}
+
+	if _, err := previous.Sub(current); !errors.Is(err, hrtime.ErrNotCumulative) {
+		t.Errorf("expected ErrNotCumulative, got %v", err)
+	}
+	if _, err := current.Sub(hrtime.NewResult([]time.Duration{7})); !errors.Is(err, hrtime.ErrNotCumulative) {
+		t.Errorf("expected ErrNotCumulative, got %v", err)
+	}
+}
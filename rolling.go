@@ -0,0 +1,195 @@
+package hrtime
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RollingBenchmark keeps a sliding window of the most recent laps.
+//
+// It's intended for continuous latency monitoring inside long-running
+// services: durations are recorded from concurrent goroutines and
+// Stats, Histogram and Result snapshots can be taken at any time
+// without stopping the measurement.
+//
+// The window is limited either by the number of laps, see NewRollingBenchmark,
+// or by the age of laps, see NewRollingBenchmarkWindow.
+type RollingBenchmark struct {
+	mu     sync.Mutex
+	opts   options
+	size   int
+	window time.Duration
+
+	// laps and at contain lap durations and their finish times,
+	// for size limited benchmarks they are used as a ring buffer
+	// starting at head.
+	laps []time.Duration
+	at   []time.Duration
+	head int
+
+	// observed counts laps including those not sampled.
+	observed atomic.Int64
+}
+
+// NewRollingBenchmark creates a benchmark that keeps the last size laps.
+//
+// Only WithClock and WithSampling options are used.
+func NewRollingBenchmark(size int, opts ...Option) *RollingBenchmark {
+	if size <= 0 {
+		panic("must have size at least 1")
+	}
+	return &RollingBenchmark{
+		opts: newOptions(opts),
+		size: size,
+		laps: make([]time.Duration, 0, size),
+	}
+}
+
+// NewRollingBenchmarkWindow creates a benchmark that keeps laps
+// that finished within the last window duration.
+//
+// Only WithClock and WithSampling options are used.
+func NewRollingBenchmarkWindow(window time.Duration, opts ...Option) *RollingBenchmark {
+	if window <= 0 {
+		panic("window must be positive")
+	}
+	return &RollingBenchmark{
+		opts:   newOptions(opts),
+		window: window,
+	}
+}
+
+// Now returns the current time of the benchmark clock,
+// it's intended to be used together with Since.
+func (bench *RollingBenchmark) Now() time.Duration { return bench.opts.now() }
+
+// Since records the duration since start, which was returned by Now.
+func (bench *RollingBenchmark) Since(start time.Duration) {
+	if !bench.sample() {
+		return
+	}
+	now := bench.opts.now()
+	bench.record(now, now-start)
+}
+
+// Record adds a single lap that finished now.
+func (bench *RollingBenchmark) Record(lap time.Duration) {
+	if !bench.sample() {
+		return
+	}
+	bench.record(bench.opts.now(), lap)
+}
+
+// sample counts a lap and returns whether to record it.
+//
+// Laps that are not sampled WithSampling don't read the clock nor take the lock.
+func (bench *RollingBenchmark) sample() bool {
+	bench.observed.Add(1)
+	return bench.opts.sampleEvery <= 1 || rand.IntN(bench.opts.sampleEvery) == 0
+}
+
+// Observed returns the number of laps since the start or Reset,
+// including laps that were not sampled or are no longer in the window.
+func (bench *RollingBenchmark) Observed() int64 { return bench.observed.Load() }
+
+// record adds lap that finished at now.
+func (bench *RollingBenchmark) record(now, lap time.Duration) {
+	if lap < 0 {
+		lap = 0
+	}
+
+	bench.mu.Lock()
+	defer bench.mu.Unlock()
+
+	if bench.size > 0 {
+		if len(bench.laps) < bench.size {
+			bench.laps = append(bench.laps, lap)
+			return
+		}
+		bench.laps[bench.head] = lap
+		bench.head = (bench.head + 1) % bench.size
+		return
+	}
+
+	bench.expire(now)
+	bench.laps = append(bench.laps, lap)
+	bench.at = append(bench.at, now)
+}
+
+// expire removes laps older than the window, must be called with mu held.
+func (bench *RollingBenchmark) expire(now time.Duration) {
+	if bench.size > 0 {
+		return
+	}
+
+	cutoff := now - bench.window
+	for bench.head < len(bench.at) && bench.at[bench.head] < cutoff {
+		bench.head++
+	}
+	// compact when more than half of the storage has expired
+	if bench.head > 0 && bench.head >= len(bench.at)/2 {
+		n := copy(bench.laps, bench.laps[bench.head:])
+		copy(bench.at, bench.at[bench.head:])
+		bench.laps, bench.at = bench.laps[:n], bench.at[:n]
+		bench.head = 0
+	}
+}
+
+// Count returns the number of laps in the window.
+func (bench *RollingBenchmark) Count() int {
+	bench.mu.Lock()
+	defer bench.mu.Unlock()
+	bench.expire(bench.opts.now())
+	if bench.size > 0 {
+		return len(bench.laps)
+	}
+	return len(bench.laps) - bench.head
+}
+
+// Laps returns a copy of laps in the window, ordered from the oldest.
+func (bench *RollingBenchmark) Laps() []time.Duration {
+	bench.mu.Lock()
+	defer bench.mu.Unlock()
+	bench.expire(bench.opts.now())
+
+	if bench.size > 0 {
+		laps := make([]time.Duration, 0, len(bench.laps))
+		laps = append(laps, bench.laps[bench.head:]...)
+		return append(laps, bench.laps[:bench.head]...)
+	}
+	return append([]time.Duration(nil), bench.laps[bench.head:]...)
+}
+
+// Reset removes all laps from the window.
+func (bench *RollingBenchmark) Reset() {
+	bench.mu.Lock()
+	bench.laps = bench.laps[:0]
+	bench.at = bench.at[:0]
+	bench.head = 0
+	bench.observed.Store(0)
+	bench.mu.Unlock()
+}
+
+// Result returns a snapshot of laps in the window.
+func (bench *RollingBenchmark) Result() *Result {
+	return NewResult(bench.Laps())
+}
+
+// Stats calculates summary statistics of laps in the window.
+func (bench *RollingBenchmark) Stats() *Stats {
+	return NewStats(bench.Laps())
+}
+
+// Histogram creates an histogram of laps in the window.
+//
+// It creates binCount bins to distribute the data and uses the
+// 99.9 percentile as the last bucket range. However, for a nicer output
+// it might choose a larger value.
+func (bench *RollingBenchmark) Histogram(binCount int) *Histogram {
+	opts := defaultOptions
+	opts.BinCount = binCount
+
+	return NewDurationHistogram(bench.Laps(), &opts)
+}
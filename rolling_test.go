@@ -0,0 +1,48 @@
+package hrtime_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestRollingBenchmark(t *testing.T) {
+	bench := hrtime.NewRollingBenchmark(4)
+	for i := 1; i <= 10; i++ {
+		bench.Record(time.Duration(i))
+	}
+	if laps := bench.Laps(); !slices.Equal(laps, []time.Duration{7, 8, 9, 10}) {
+		t.Errorf("expected last 4 laps, got %v", laps)
+	}
+	if stats := bench.Stats(); stats.Count != 4 || stats.Minimum != 7 || stats.Maximum != 10 {
+		t.Errorf("invalid stats %v", stats)
+	}
+
+	bench.Reset()
+	if bench.Count() != 0 {
+		t.Errorf("expected empty benchmark after reset")
+	}
+}
+
+func TestRollingBenchmarkWindow(t *testing.T) {
+	var now time.Duration
+	clock := hrtime.ClockFunc(func() time.Duration { return now })
+
+	bench := hrtime.NewRollingBenchmarkWindow(10*time.Second, hrtime.WithClock(clock))
+	for i := 1; i <= 30; i++ {
+		now = time.Duration(i) * time.Second
+		start := bench.Now()
+		now += time.Duration(i)
+		bench.Since(start)
+	}
+	if laps := bench.Laps(); len(laps) != 10 || laps[0] != 21 || laps[9] != 30 {
+		t.Errorf("expected laps of the last 10 seconds, got %v", laps)
+	}
+
+	now += time.Minute
+	if count := bench.Count(); count != 0 {
+		t.Errorf("expected all laps to expire, got %d", count)
+	}
+}
@@ -0,0 +1,118 @@
+package hrtime
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a single value recorded by Sampler.
+type Sample struct {
+	// Time is the moment of sampling as returned by Now.
+	Time  time.Duration
+	Value float64
+}
+
+// Sampler periodically records values returned by a callback.
+//
+// Samples are timestamped using Now, which makes them comparable
+// with Benchmark.Timeline for correlating laps with external state
+// such as queue length or heap size.
+type Sampler struct {
+	interval time.Duration
+	sample   func() float64
+
+	mu      sync.Mutex
+	samples []Sample
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSampler creates a sampler calling sample every interval.
+func NewSampler(interval time.Duration, sample func() float64) *Sampler {
+	if interval <= 0 {
+		panic("interval must be positive")
+	}
+	if sample == nil {
+		panic("sample func must not be nil")
+	}
+
+	return &Sampler{
+		interval: interval,
+		sample:   sample,
+	}
+}
+
+// Start starts sampling in a separate goroutine.
+//
+// The first sample is recorded immediately.
+func (sampler *Sampler) Start() {
+	if sampler.stop != nil {
+		panic("sampler already started")
+	}
+	sampler.stop = make(chan struct{})
+	sampler.done = make(chan struct{})
+
+	sampler.record()
+	go sampler.run()
+}
+
+// run records samples until Stop is called.
+func (sampler *Sampler) run() {
+	defer close(sampler.done)
+
+	ticker := time.NewTicker(sampler.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sampler.record()
+		case <-sampler.stop:
+			return
+		}
+	}
+}
+
+// record appends a single sample.
+func (sampler *Sampler) record() {
+	value := sampler.sample()
+	now := Now()
+
+	sampler.mu.Lock()
+	sampler.samples = append(sampler.samples, Sample{Time: now, Value: value})
+	sampler.mu.Unlock()
+}
+
+// Stop stops sampling and records a final sample.
+func (sampler *Sampler) Stop() {
+	if sampler.stop == nil {
+		panic("sampler not started")
+	}
+	close(sampler.stop)
+	<-sampler.done
+
+	sampler.record()
+}
+
+// Samples returns all recorded samples.
+func (sampler *Sampler) Samples() []Sample {
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+
+	return append(sampler.samples[:0:0], sampler.samples...)
+}
+
+// Between returns samples recorded in the [start, stop] range.
+func (sampler *Sampler) Between(start, stop time.Duration) []Sample {
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+
+	var samples []Sample
+	for _, sample := range sampler.samples {
+		if sample.Time >= start && sample.Time <= stop {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
@@ -0,0 +1,38 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSampler(t *testing.T) {
+	value := 0.0
+	sampler := hrtime.NewSampler(time.Millisecond, func() float64 {
+		value++
+		return value
+	})
+
+	sampler.Start()
+	bench := hrtime.NewBenchmark(8)
+	for bench.Next() {
+		time.Sleep(time.Millisecond)
+	}
+	sampler.Stop()
+
+	samples := sampler.Samples()
+	if len(samples) < 2 {
+		t.Fatalf("expected at least 2 samples, got %d", len(samples))
+	}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time < samples[i-1].Time {
+			t.Errorf("samples not ordered: %v < %v", samples[i].Time, samples[i-1].Time)
+		}
+	}
+
+	start, stop := bench.Timeline()
+	if len(sampler.Between(start, stop)) == 0 {
+		t.Errorf("expected samples during benchmark")
+	}
+}
@@ -0,0 +1,314 @@
+package hrtime
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// WithSampling measures on average one in every laps, chosen at random.
+//
+// The clock isn't read for laps that are not sampled, hence the
+// overhead of Next is only a few instructions for most laps and memory
+// usage is proportional to the number of samples. Since the choice is
+// independent of the measured code, the samples are an unbiased estimate
+// of the distribution of all laps. Observed reports the number of all
+// laps, while Laps, Stats and other results contain samples.
+//
+// Sampled benchmarks don't support timestamps, labels, failures and phases,
+// using them is reported as misuse. Values of every below 2 disable sampling.
+func WithSampling(every int) Option {
+	return func(opts *options) { opts.sampleEvery = every }
+}
+
+// WithReservoir keeps a uniform random sample of at most size laps,
+// using reservoir sampling.
+//
+// Memory usage is fixed regardless of the number of laps, which is useful
+// for streaming benchmarks of unknown length. Once the reservoir is full,
+// laps that would not enter the reservoir are not measured, hence the
+// overhead decreases as the benchmark progresses. Samples are not in the
+// order they were measured.
+//
+// Similarly to WithSampling, timestamps, labels, failures and phases are
+// not supported and using them is reported as misuse. Non-positive size
+// disables sampling.
+func WithReservoir(size int) Option {
+	return func(opts *options) { opts.reservoir = size }
+}
+
+// sampled returns whether the options enable sampling.
+func (opts *options) sampled() bool {
+	return opts.sampleEvery > 1 || opts.reservoir > 0
+}
+
+// lapSampler chooses and stores sampled laps of a benchmark.
+type lapSampler[T ~int64] struct {
+	// probability of sampling a lap, when not using a reservoir.
+	probability float64
+	// size of the reservoir, zero when sampling with probability.
+	size int
+	// count of laps including warmup, zero for unbounded benchmarks.
+	count int
+	rng   *rand.Rand
+
+	// skip is the number of laps to skip before the next sample.
+	skip int
+	// weight is the state of reservoir sampling, see Li's algorithm L.
+	weight float64
+
+	start     T
+	observed  int
+	measuring bool
+	lapStart  T
+	// unsupported lists per lap data already reported as misuse.
+	unsupported []string
+
+	laps []T
+}
+
+// newLapSampler creates a sampler for a benchmark of count laps.
+func newLapSampler[T ~int64](opts *options, count int) *lapSampler[T] {
+	sampling := &lapSampler[T]{
+		count: count,
+		rng:   rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	if opts.reservoir > 0 {
+		sampling.size = opts.reservoir
+		sampling.laps = make([]T, 0, sampling.size)
+	} else {
+		sampling.probability = 1 / float64(opts.sampleEvery)
+		sampling.laps = make([]T, 0, count/opts.sampleEvery+1)
+	}
+	sampling.reset(sampling.laps)
+	return sampling
+}
+
+// reset clears samples, reusing laps as storage.
+func (sampling *lapSampler[T]) reset(laps []T) {
+	sampling.laps = laps[:0]
+	sampling.observed = 0
+	sampling.measuring = false
+	sampling.unsupported = nil
+	sampling.start, sampling.lapStart = 0, 0
+	sampling.skip = 0
+	if sampling.size == 0 {
+		// start at a random lap, avoiding aliasing with periodic workloads
+		sampling.skip = sampling.gap(sampling.probability)
+	}
+}
+
+// gap returns the number of laps before the next one sampled with probability.
+func (sampling *lapSampler[T]) gap(probability float64) int {
+	if probability >= 1 {
+		return 0
+	}
+	gap := math.Log(1-sampling.rng.Float64()) / math.Log(1-probability)
+	return int(min(gap, math.MaxInt32))
+}
+
+// sample returns whether to measure the next lap.
+func (sampling *lapSampler[T]) sample() bool {
+	if sampling.skip > 0 {
+		sampling.skip--
+		return false
+	}
+	return true
+}
+
+// add stores a sampled lap and chooses the next one.
+func (sampling *lapSampler[T]) add(lap T) {
+	if sampling.size == 0 {
+		sampling.laps = append(sampling.laps, lap)
+		sampling.skip = sampling.gap(sampling.probability)
+		return
+	}
+
+	if len(sampling.laps) < sampling.size {
+		sampling.laps = append(sampling.laps, lap)
+		if len(sampling.laps) < sampling.size {
+			return
+		}
+		sampling.weight = 1
+	} else {
+		sampling.laps[sampling.rng.IntN(sampling.size)] = lap
+	}
+	sampling.weight *= math.Exp(math.Log(1-sampling.rng.Float64()) / float64(sampling.size))
+	sampling.skip = sampling.gap(sampling.weight)
+}
+
+// stop ends the lap in progress at now.
+func (sampling *lapSampler[T]) stop(now T) {
+	if sampling.measuring {
+		sampling.measuring = false
+		sampling.add(now - sampling.lapStart)
+	}
+}
+
+// unsupportedBySampling reports using per lap data, i.e. labels, failures
+// or phases, of a sampled benchmark as misuse, once for each to keep Err
+// short. It returns whether the benchmark is sampled.
+func (core *lapCore[T]) unsupportedBySampling(what string) bool {
+	sampling := core.sampling
+	if sampling == nil {
+		return false
+	}
+	if !slices.Contains(sampling.unsupported, what) {
+		sampling.unsupported = append(sampling.unsupported, what)
+		core.misuse("sampled benchmark does not support " + what)
+	}
+	return true
+}
+
+// resetSamples clears samples of a sampled benchmark, reusing laps
+// resized by the embedding benchmark as storage.
+func (core *lapCore[T]) resetSamples() {
+	core.observed = 0
+	if core.sampling != nil {
+		core.sampling.reset(core.laps[:0])
+		core.laps = nil
+	}
+}
+
+// nextSampled is Next of sampled benchmarks, which reads the clock
+// only around sampled laps.
+//
+// It returns false when the benchmark must complete at last.
+func (core *lapCore[T]) nextSampled(read func() T) (last T, proceed bool) {
+	sampling := core.sampling
+
+	var now T
+	measured := false
+	if sampling.measuring {
+		now, measured = read(), true
+		core.endPause(now)
+		sampling.measuring = false
+		sampling.add(now - sampling.lapStart)
+	}
+	// pauses of laps that are not sampled are ignored
+	core.paused = false
+	if !measured && (core.step == 0 || core.budget > 0 || core.step >= sampling.count && sampling.count > 0) {
+		now = read()
+	}
+
+	if core.step == 0 {
+		sampling.start = now
+		core.deadline = now + core.budget
+	} else if core.budget > 0 && now >= core.deadline || sampling.count > 0 && core.step >= sampling.count {
+		return now, false
+	}
+
+	core.step++
+	if core.step > core.opts.warmup {
+		sampling.observed++
+		if sampling.sample() {
+			sampling.measuring = true
+			sampling.lapStart = read()
+		}
+	}
+	return now, true
+}
+
+// nextSampled is Next of sampled benchmarks.
+func (bench *Benchmark) nextSampled() bool {
+	if bench.done.Load() {
+		return false
+	}
+	if last, proceed := bench.lapCore.nextSampled(bench.opts.now); !proceed {
+		bench.complete(last)
+		return false
+	}
+	return true
+}
+
+// nextSampled is Next of sampled benchmarks.
+func (bench *BenchmarkTSC) nextSampled() bool {
+	if bench.done.Load() {
+		return false
+	}
+	if last, proceed := bench.lapCore.nextSampled(bench.opts.tsc); !proceed {
+		bench.complete(last)
+		return false
+	}
+	return true
+}
+
+// finalizeSamples converts samples of a benchmark stopped at last to laps,
+// it returns the number of warmup laps.
+func (core *lapCore[T]) finalizeSamples(last T) (warmup int) {
+	sampling := core.sampling
+	if core.opts.memStats {
+		core.memStats = core.memStart.since(core.step)
+	}
+	core.laps = sampling.laps
+	core.start, core.stop = sampling.start, last
+	if core.step <= core.opts.warmup {
+		core.start = last
+	}
+	return min(core.opts.warmup, core.step)
+}
+
+// Observed returns the number of measured laps, including laps that
+// were not sampled when using WithSampling or WithReservoir.
+func (bench *Benchmark) Observed() int {
+	bench = bench.mustBeCompleted()
+	return bench.observedOrLaps()
+}
+
+// SamplingRate returns the fraction of observed laps that were sampled.
+func (bench *Benchmark) SamplingRate() float64 {
+	bench = bench.mustBeCompleted()
+	return bench.samplingRate()
+}
+
+// Observed returns the number of measured laps, including laps that
+// were not sampled when using WithSampling or WithReservoir.
+func (bench *BenchmarkTSC) Observed() int {
+	bench = bench.mustBeCompleted()
+	return bench.observedOrLaps()
+}
+
+// SamplingRate returns the fraction of observed laps that were sampled.
+func (bench *BenchmarkTSC) SamplingRate() float64 {
+	bench = bench.mustBeCompleted()
+	return bench.samplingRate()
+}
+
+// observedOrLaps returns the number of observed laps, which are all
+// the laps of benchmarks that are not sampled.
+func (core *lapCore[T]) observedOrLaps() int {
+	observed, sampled := core.observedLaps()
+	if !sampled {
+		return len(core.laps)
+	}
+	return observed
+}
+
+// samplingRate returns the fraction of observed laps that were sampled.
+func (core *lapCore[T]) samplingRate() float64 {
+	observed, sampled := core.observedLaps()
+	if !sampled || observed == 0 {
+		return 1
+	}
+	return float64(len(core.laps)) / float64(observed)
+}
+
+// observedLaps returns the number of observed laps and whether they were sampled.
+func (core *lapCore[T]) observedLaps() (observed int, sampled bool) {
+	if core.sampling != nil {
+		return core.sampling.observed, true
+	}
+	return core.observed, core.observed > 0
+}
+
+// samplingMetadata describes sampling for JSONResult metadata.
+func (core *lapCore[T]) samplingMetadata() map[string]string {
+	observed, sampled := core.observedLaps()
+	if !sampled {
+		return nil
+	}
+	return map[string]string{
+		"sampled": fmt.Sprintf("%d of %d laps", len(core.laps), observed),
+	}
+}
@@ -0,0 +1,149 @@
+package hrtime_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkSampling(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(10000, hrtime.WithSampling(100), hrtime.WithWarmup(10), hrtime.WithClock(clock))
+	for i := 0; bench.Next(); i++ {
+		// every other lap is slow
+		clock.Advance(time.Duration(1 + i%2*99))
+	}
+
+	if bench.Observed() != 10000 {
+		t.Errorf("expected 10000 observed laps, got %d", bench.Observed())
+	}
+	sampled := len(bench.Laps())
+	if sampled < 50 || sampled > 200 {
+		t.Fatalf("expected about 100 samples, got %d", sampled)
+	}
+	if rate := bench.SamplingRate(); rate != float64(sampled)/10000 {
+		t.Errorf("unexpected sampling rate %v", rate)
+	}
+	slow := 0
+	for _, lap := range bench.Laps() {
+		if lap != 1 && lap != 100 {
+			t.Fatalf("sampled laps must be whole laps, got %v", lap)
+		}
+		if lap == 100 {
+			slow++
+		}
+	}
+	if slow < sampled/4 || slow > sampled*3/4 {
+		t.Errorf("biased samples: %d of %d slow", slow, sampled)
+	}
+	if metadata := bench.JSONResult("").Metadata; metadata["sampled"] == "" {
+		t.Errorf("expected sampling metadata, got %v", metadata)
+	}
+}
+
+func TestBenchmarkReservoir(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewStreamingBenchmark(hrtime.WithReservoir(200), hrtime.WithClock(clock))
+	for i := 0; i < 20000 && bench.Next(); i++ {
+		// laps grow during the benchmark
+		clock.Advance(time.Duration(i))
+	}
+	bench.Stop()
+
+	if bench.Observed() != 20000 || len(bench.Laps()) != 200 {
+		t.Fatalf("unexpected counts %d %d", bench.Observed(), len(bench.Laps()))
+	}
+	// a uniform sample has the median of all laps
+	if p50 := bench.Stats().P50; p50 < 7000 || p50 > 13000 {
+		t.Errorf("unexpected median %v", p50)
+	}
+
+	bench.Reset()
+	for i := 0; i < 10 && bench.Next(); i++ {
+	}
+	bench.Stop()
+	if bench.Observed() != 10 || len(bench.Laps()) != 10 {
+		t.Errorf("unexpected counts after reset %d %d", bench.Observed(), len(bench.Laps()))
+	}
+}
+
+func TestRollingBenchmarkSampling(t *testing.T) {
+	bench := hrtime.NewRollingBenchmark(1000, hrtime.WithSampling(10))
+	for range 10000 {
+		bench.Record(time.Microsecond)
+	}
+	if bench.Observed() != 10000 {
+		t.Errorf("expected 10000 observed laps, got %d", bench.Observed())
+	}
+	if count := bench.Count(); count < 800 || count > 1000 {
+		t.Errorf("expected about 1000 samples, got %d", count)
+	}
+}
+
+func TestBenchmarkTSCSampling(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmarkTSC(1000, hrtime.WithSampling(10), hrtime.WithClock(clock))
+	for bench.Next() {
+		clock.Advance(time.Microsecond)
+	}
+
+	sampled := len(bench.Counts())
+	if bench.Observed() != 1000 || sampled < 50 || sampled > 200 {
+		t.Fatalf("expected about 100 of 1000 laps, got %d of %d", sampled, bench.Observed())
+	}
+	if rate := bench.SamplingRate(); rate != float64(sampled)/1000 {
+		t.Errorf("unexpected sampling rate %v", rate)
+	}
+	if metadata := bench.JSONResult("").Metadata; metadata["sampled"] == "" {
+		t.Errorf("expected sampling metadata, got %v", metadata)
+	}
+
+	data, err := bench.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded hrtime.BenchmarkTSC
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Observed() != 1000 || decoded.SamplingRate() != bench.SamplingRate() {
+		t.Errorf("expected sampling to be decoded, got %d observed", decoded.Observed())
+	}
+
+	bench.Reset()
+	for bench.Next() {
+		clock.Advance(time.Microsecond)
+	}
+	if bench.Observed() != 1000 {
+		t.Errorf("expected 1000 observed laps after Reset, got %d", bench.Observed())
+	}
+}
+
+func TestSamplingMisuse(t *testing.T) {
+	bench := hrtime.NewBenchmark(100, hrtime.WithSampling(2), hrtime.WithTimestamps(), hrtime.WithMisusePolicy(hrtime.MisuseError))
+	for bench.NextWithLabel("label") {
+		bench.Fail()
+		lap := bench.StartLap()
+		lap.Mark("phase")
+	}
+
+	err := bench.Err()
+	for _, unsupported := range []string{"timestamps", "labels", "failures", "phases"} {
+		if !errors.Is(err, hrtime.ErrMisuse) || strings.Count(err.Error(), "support "+unsupported) != 1 {
+			t.Errorf("expected %s reported once, got %v", unsupported, err)
+		}
+	}
+	if bench.Timestamps() != nil || bench.Failures() != 0 || bench.Phases() != nil || bench.LapLabels() != nil {
+		t.Errorf("expected no per lap data")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic under MisusePanic")
+		}
+	}()
+	hrtime.NewBenchmarkTSC(100, hrtime.WithReservoir(10), hrtime.WithTimestamps())
+}
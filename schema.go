@@ -0,0 +1,137 @@
+package hrtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SchemaVersion is the version of JSONResult schema.
+//
+// Compatibility guarantees:
+//
+//   - new fields may be added without changing the version;
+//   - existing fields are never renamed, removed or change their meaning
+//     without incrementing the version;
+//   - all durations are integer nanoseconds.
+//
+// Consumers should ignore unknown fields and check the version.
+const SchemaVersion = 1
+
+// ErrUnsupportedSchema is returned when decoding results with an unknown schema version.
+var ErrUnsupportedSchema = errors.New("unsupported schema version")
+
+// JSONResult is the stable, versioned representation of exported results.
+type JSONResult struct {
+	// SchemaVersion is always set to SchemaVersion when encoding.
+	SchemaVersion int `json:"schemaVersion"`
+	// Name identifies the benchmark.
+	Name string `json:"name,omitempty"`
+	// Timestamp is the time of export.
+	Timestamp time.Time `json:"timestamp"`
+	// Count is the number of laps.
+	Count int `json:"count"`
+	// Stats contains summary statistics in nanoseconds.
+	Stats JSONStats `json:"stats"`
+	// Laps contains lap durations in nanoseconds, when included.
+	Laps []int64 `json:"laps,omitempty"`
+	// Environment describes where the results were measured.
+	Environment *Environment `json:"environment,omitempty"`
+	// Metadata contains user specified key-value pairs.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Sources contains statistics of every source of a merged benchmark.
+	Sources []JSONSource `json:"sources,omitempty"`
+}
+
+// JSONSource contains statistics of a single source of a merged benchmark.
+type JSONSource struct {
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	Stats     JSONStats `json:"stats"`
+	Straggler bool      `json:"straggler,omitempty"`
+}
+
+// JSONStats contains summary statistics in nanoseconds.
+type JSONStats struct {
+	Mean    float64 `json:"mean"`
+	Minimum int64   `json:"min"`
+	Maximum int64   `json:"max"`
+	P50     int64   `json:"p50"`
+	P90     int64   `json:"p90"`
+	P99     int64   `json:"p99"`
+	P999    int64   `json:"p999"`
+	P9999   int64   `json:"p9999"`
+}
+
+// NewJSONResult creates a result from named laps, including all laps.
+func NewJSONResult(name string, laps []time.Duration) *JSONResult {
+	result := &JSONResult{
+		SchemaVersion: SchemaVersion,
+		Name:          name,
+		Timestamp:     time.Now().UTC(),
+		Count:         len(laps),
+		Laps:          make([]int64, len(laps)),
+	}
+	for i, lap := range laps {
+		result.Laps[i] = lap.Nanoseconds()
+	}
+
+	result.Stats = newJSONStats(laps)
+	return result
+}
+
+// newJSONStats calculates summary statistics of laps.
+func newJSONStats(laps []time.Duration) JSONStats {
+	if len(laps) == 0 {
+		return JSONStats{}
+	}
+
+	sorted := sortedDurations(laps)
+	var total float64
+	for _, lap := range sorted {
+		total += float64(lap)
+	}
+	return JSONStats{
+		Mean:    total / float64(len(sorted)),
+		Minimum: int64(sorted[0]),
+		Maximum: int64(sorted[len(sorted)-1]),
+		P50:     int64(quantile(sorted, 0.5)),
+		P90:     int64(quantile(sorted, 0.9)),
+		P99:     int64(quantile(sorted, 0.99)),
+		P999:    int64(quantile(sorted, 0.999)),
+		P9999:   int64(quantile(sorted, 0.9999)),
+	}
+}
+
+// Durations returns laps as durations.
+func (result *JSONResult) Durations() []time.Duration {
+	laps := make([]time.Duration, len(result.Laps))
+	for i, lap := range result.Laps {
+		laps[i] = time.Duration(lap)
+	}
+	return laps
+}
+
+// Encode writes result as indented JSON to w.
+func (result *JSONResult) Encode(w io.Writer) error {
+	result.SchemaVersion = SchemaVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(result)
+}
+
+// DecodeJSONResult reads a result from r.
+//
+// It returns ErrUnsupportedSchema when the result uses a newer schema version.
+func DecodeJSONResult(r io.Reader) (*JSONResult, error) {
+	var result JSONResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.SchemaVersion <= 0 || result.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchema, result.SchemaVersion)
+	}
+	return &result, nil
+}
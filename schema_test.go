@@ -0,0 +1,39 @@
+package hrtime_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestJSONResultRoundtrip(t *testing.T) {
+	result := hrtime.NewJSONResult("sleep", []time.Duration{3, 1, 2})
+	result.Environment = hrtime.CaptureEnv()
+
+	var buf bytes.Buffer
+	if err := result.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"schemaVersion": 1`) {
+		t.Errorf("missing schema version:\n%s", buf.String())
+	}
+
+	decoded, err := hrtime.DecodeJSONResult(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "sleep" || decoded.Stats.P50 != 2 || len(decoded.Durations()) != 3 {
+		t.Errorf("unexpected result %+v", decoded)
+	}
+}
+
+func TestDecodeJSONResultNewerSchema(t *testing.T) {
+	_, err := hrtime.DecodeJSONResult(strings.NewReader(`{"schemaVersion": 1000, "futureField": true}`))
+	if !errors.Is(err, hrtime.ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema, got %v", err)
+	}
+}
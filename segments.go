@@ -0,0 +1,120 @@
+package hrtime
+
+import (
+	"sync"
+	"time"
+)
+
+// Segment contains statistics of durations recorded in a fixed time segment.
+type Segment struct {
+	// Start is the beginning of the segment.
+	Start time.Time
+	// Count is the number of durations recorded in the segment.
+	Count int
+
+	Mean    time.Duration
+	Minimum time.Duration
+	Maximum time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	P999    time.Duration
+
+	// Laps contains raw durations, when the recorder keeps them.
+	Laps []time.Duration
+	// Annotations contains events recorded within the segment.
+	Annotations []Annotation
+}
+
+// SegmentedRecorder rolls durations up into fixed time segments.
+//
+// Only durations of the current segment are kept in memory, which keeps
+// memory usage flat for multi-hour recordings while preserving trends.
+type SegmentedRecorder struct {
+	mu       sync.Mutex
+	length   time.Duration
+	keepLaps bool
+
+	start    time.Time
+	current  []time.Duration
+	segments []Segment
+
+	annotations []Annotation
+}
+
+// NewSegmentedRecorder creates a recorder with segments of the specified length.
+//
+// When keepLaps is true, raw durations are retained in each segment.
+func NewSegmentedRecorder(length time.Duration, keepLaps bool) *SegmentedRecorder {
+	if length <= 0 {
+		panic("segment length must be positive")
+	}
+	return &SegmentedRecorder{
+		length:   length,
+		keepLaps: keepLaps,
+	}
+}
+
+// Record adds a duration to the segment containing current time.
+func (rec *SegmentedRecorder) Record(duration time.Duration) {
+	rec.RecordAt(time.Now(), duration)
+}
+
+// RecordAt adds a duration that finished at time at.
+//
+// Times are expected to be non-decreasing, durations recorded before the
+// current segment are added to the current segment.
+func (rec *SegmentedRecorder) RecordAt(at time.Time, duration time.Duration) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	segmentStart := at.Truncate(rec.length)
+	if rec.start.IsZero() {
+		rec.start = segmentStart
+	} else if segmentStart.After(rec.start) {
+		rec.rollup()
+		rec.start = segmentStart
+	}
+	rec.current = append(rec.current, duration)
+}
+
+// Flush completes the current segment.
+func (rec *SegmentedRecorder) Flush() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.rollup()
+	rec.start = time.Time{}
+}
+
+// Segments returns completed segments.
+func (rec *SegmentedRecorder) Segments() []Segment {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append(rec.segments[:0:0], rec.segments...)
+}
+
+// rollup converts current durations into a segment.
+func (rec *SegmentedRecorder) rollup() {
+	if len(rec.current) == 0 {
+		return
+	}
+
+	sorted := sortedDurations(rec.current)
+	segment := Segment{
+		Start:   rec.start,
+		Count:   len(sorted),
+		Mean:    meanDuration(sorted),
+		Minimum: sorted[0],
+		Maximum: sorted[len(sorted)-1],
+		P50:     quantile(sorted, 0.5),
+		P90:     quantile(sorted, 0.9),
+		P99:     quantile(sorted, 0.99),
+		P999:    quantile(sorted, 0.999),
+	}
+	segment.Annotations = rec.segmentAnnotations(rec.start)
+	if rec.keepLaps {
+		segment.Laps = append([]time.Duration(nil), rec.current...)
+	}
+	rec.segments = append(rec.segments, segment)
+	rec.current = rec.current[:0]
+}
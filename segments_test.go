@@ -0,0 +1,55 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSegmentedRecorder(t *testing.T) {
+	rec := hrtime.NewSegmentedRecorder(time.Minute, false)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rec.RecordAt(start, 100)
+	rec.RecordAt(start.Add(30*time.Second), 300)
+	rec.RecordAt(start.Add(90*time.Second), 1000)
+	rec.RecordAt(start.Add(5*time.Minute), 50)
+	rec.Flush()
+
+	segments := rec.Segments()
+	if len(segments) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(segments))
+	}
+	if segments[0].Count != 2 || segments[0].Mean != 200 || segments[0].Laps != nil {
+		t.Errorf("unexpected first segment %+v", segments[0])
+	}
+	if !segments[2].Start.Equal(start.Add(5*time.Minute)) || segments[2].Maximum != 50 {
+		t.Errorf("unexpected last segment %+v", segments[2])
+	}
+}
+
+func TestSegmentedRecorderAnnotations(t *testing.T) {
+	rec := hrtime.NewSegmentedRecorder(time.Minute, false)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rec.RecordAt(start, 100)
+	rec.AnnotateAt(start.Add(70*time.Second), "deploy v2")
+	rec.RecordAt(start.Add(90*time.Second), 1000)
+	rec.Flush()
+
+	segments := rec.Segments()
+	if len(segments[0].Annotations) != 0 || len(segments[1].Annotations) != 1 || segments[1].Annotations[0].Text != "deploy v2" {
+		t.Errorf("annotation not attached to the second segment: %+v", segments)
+	}
+
+	var timeline strings.Builder
+	if err := rec.WriteCSV(&timeline); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(timeline.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], ",deploy v2") {
+		t.Errorf("unexpected timeline:\n%s", timeline.String())
+	}
+}
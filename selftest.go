@@ -0,0 +1,117 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SelfTestResult contains measured overhead of the package itself.
+type SelfTestResult struct {
+	// Now is the cost of a Now call.
+	Now time.Duration
+	// TSC is the cost of a TSC call, zero when TSC is not supported.
+	TSC time.Duration
+	// BenchmarkNext is the cost of a single Benchmark.Next call.
+	BenchmarkNext time.Duration
+	// BenchmarkTSCNext is the cost of a single BenchmarkTSC.Next call.
+	BenchmarkTSCNext time.Duration
+	// StopwatchLap is the cost of a Stopwatch.Start and Stop pair.
+	StopwatchLap time.Duration
+	// ApproxDuration is the cost of converting Count to time.Duration.
+	ApproxDuration time.Duration
+	// RecorderRecord is the cost of a single Recorder.Record call.
+	RecorderRecord time.Duration
+}
+
+// selfTestRounds is the number of repetitions for each measurement.
+const selfTestRounds = 16
+
+// measureOverhead returns median of per-call cost of fn measured over calls calls.
+func measureOverhead(calls int, fn func(n int)) time.Duration {
+	results := make([]time.Duration, selfTestRounds)
+	for round := range results {
+		start := Now()
+		fn(calls)
+		results[round] = (Now() - start - Overhead()) / time.Duration(calls)
+	}
+	sort.Slice(results, func(i, k int) bool { return results[i] < results[k] })
+	return results[len(results)/2]
+}
+
+// SelfTest measures the overhead of the package on the current machine.
+//
+// It takes several milliseconds to run.
+func SelfTest() SelfTestResult {
+	const calls = calibrationCalls
+
+	var result SelfTestResult
+
+	result.Now = measureOverhead(calls, func(n int) {
+		for i := 0; i < n; i++ {
+			Now()
+		}
+	})
+
+	result.BenchmarkNext = measureOverhead(calls, func(n int) {
+		bench := NewBenchmark(n)
+		for bench.Next() {
+		}
+	})
+
+	result.StopwatchLap = measureOverhead(calls, func(n int) {
+		bench := NewStopwatch(n)
+		for i := 0; i < n; i++ {
+			bench.Stop(bench.Start())
+		}
+	})
+
+	result.RecorderRecord = measureOverhead(calls, func(n int) {
+		rec := &Recorder{laps: make([]time.Duration, 0, n)}
+		for i := 0; i < n; i++ {
+			rec.Record(time.Duration(i))
+		}
+	})
+
+	if TSCSupported() {
+		result.TSC = measureOverhead(calls, func(n int) {
+			for i := 0; i < n; i++ {
+				TSC()
+			}
+		})
+
+		result.BenchmarkTSCNext = measureOverhead(calls, func(n int) {
+			bench := NewBenchmarkTSC(n)
+			for bench.Next() {
+			}
+		})
+
+		// ensure calibration is not included in the measurement
+		Count(1).ApproxDuration()
+		result.ApproxDuration = measureOverhead(calls, func(n int) {
+			for i := 0; i < n; i++ {
+				Count(i).ApproxDuration()
+			}
+		})
+	}
+
+	return result
+}
+
+// WriteTo writes formatted overheads to w.
+func (result SelfTestResult) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "  Now %v;  TSC %v;  ApproxDuration %v;\n  Benchmark.Next %v;  BenchmarkTSC.Next %v;  Stopwatch lap %v;  Recorder.Record %v;\n",
+		result.Now, result.TSC, result.ApproxDuration,
+		result.BenchmarkNext, result.BenchmarkTSCNext, result.StopwatchLap, result.RecorderRecord,
+	)
+	return int64(n), err
+}
+
+// String returns formatted overheads.
+func (result SelfTestResult) String() string {
+	var buffer strings.Builder
+	_, _ = result.WriteTo(&buffer)
+	return buffer.String()
+}
@@ -0,0 +1,40 @@
+//go:build !race
+// +build !race
+
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSelfTest(t *testing.T) {
+	result := hrtime.SelfTest()
+	t.Log("\n" + result.String())
+
+	// generous bounds to catch regressions, rather than slow machines
+	const limit = 10 * time.Microsecond
+	if result.BenchmarkNext > limit {
+		t.Errorf("Benchmark.Next too slow: %v", result.BenchmarkNext)
+	}
+	if result.BenchmarkTSCNext > limit {
+		t.Errorf("BenchmarkTSC.Next too slow: %v", result.BenchmarkTSCNext)
+	}
+	if result.StopwatchLap > limit {
+		t.Errorf("Stopwatch lap too slow: %v", result.StopwatchLap)
+	}
+}
+
+func BenchmarkBenchmarkNext(b *testing.B) {
+	bench := hrtime.NewBenchmark(b.N)
+	for bench.Next() {
+	}
+}
+
+func BenchmarkBenchmarkTSCNext(b *testing.B) {
+	bench := hrtime.NewBenchmarkTSC(b.N)
+	for bench.Next() {
+	}
+}
@@ -0,0 +1,214 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ShiftBootstrapSamples is the number of bootstrap resamples used by NewShiftFunction.
+const ShiftBootstrapSamples = 1000
+
+// ShiftQuantiles are the quantiles compared by NewShiftFunction.
+var ShiftQuantiles = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+// ShiftQuantile is the difference of a single quantile between two lap sets.
+type ShiftQuantile struct {
+	// Q is the compared quantile in range [0, 1].
+	Q   float64
+	Old time.Duration
+	New time.Duration
+	// Diff is New - Old, positive values mean that new is slower.
+	Diff time.Duration
+	// Low and High are bounds of the simultaneous confidence band of Diff.
+	Low, High time.Duration
+}
+
+// Significant returns whether the confidence band excludes zero.
+func (q *ShiftQuantile) Significant() bool {
+	return q.Low > 0 || q.High < 0
+}
+
+// ShiftFunction compares two lap sets quantile by quantile.
+//
+// It shows where in the distribution a change happened, e.g. whether
+// only the tail became slower. The confidence bands hold simultaneously
+// for all quantiles, i.e. all of them contain the true difference with
+// the specified Confidence.
+type ShiftFunction struct {
+	// Name identifies the compared benchmark.
+	Name string
+	// Confidence is the simultaneous confidence level of the bands, e.g. 0.95.
+	Confidence float64
+	Quantiles  []ShiftQuantile
+}
+
+// NewShiftFunction compares ShiftQuantiles of old and new laps.
+//
+// The bands are computed with bootstrap using the maximum standardized
+// deviation across quantiles. The cost is proportional to
+// ShiftBootstrapSamples times the number of laps. It uses the default Resampler.
+func NewShiftFunction(name string, old, new []time.Duration, confidence float64) *ShiftFunction {
+	return Resampler{}.NewShiftFunction(name, old, new, confidence)
+}
+
+// NewShiftFunction compares ShiftQuantiles of old and new laps.
+func (resampler Resampler) NewShiftFunction(name string, old, new []time.Duration, confidence float64) *ShiftFunction {
+	if !(confidence > 0 && confidence < 1) {
+		panic("confidence must be in range (0, 1)")
+	}
+	shift := &ShiftFunction{Name: name, Confidence: confidence}
+	if len(old) == 0 || len(new) == 0 {
+		return shift
+	}
+
+	oldSorted, newSorted := sortedDurations(old), sortedDurations(new)
+	diffs := make([]float64, len(ShiftQuantiles))
+	for i, q := range ShiftQuantiles {
+		oldValue, newValue := quantile(oldSorted, q), quantile(newSorted, q)
+		diffs[i] = float64(newValue - oldValue)
+		shift.Quantiles = append(shift.Quantiles, ShiftQuantile{
+			Q: q, Old: oldValue, New: newValue, Diff: newValue - oldValue,
+		})
+	}
+
+	// bootstrap differences of every quantile
+	rng := resampler.rand(old, new)
+	samples := make([][]float64, ShiftBootstrapSamples)
+	oldBuffer, newBuffer := make([]time.Duration, len(old)), make([]time.Duration, len(new))
+	for b := range samples {
+		resample(rng, oldBuffer, oldSorted)
+		resample(rng, newBuffer, newSorted)
+		sample := make([]float64, len(ShiftQuantiles))
+		for i, q := range ShiftQuantiles {
+			sample[i] = float64(quantile(newBuffer, q) - quantile(oldBuffer, q))
+		}
+		samples[b] = sample
+	}
+
+	// standard error of every quantile difference
+	stderr := make([]float64, len(ShiftQuantiles))
+	for i := range ShiftQuantiles {
+		var mean, sum float64
+		for _, sample := range samples {
+			mean += sample[i]
+		}
+		mean /= float64(len(samples))
+		for _, sample := range samples {
+			sum += (sample[i] - mean) * (sample[i] - mean)
+		}
+		stderr[i] = math.Sqrt(sum / float64(len(samples)-1))
+	}
+
+	// critical value of the maximum standardized deviation
+	deviations := make([]float64, len(samples))
+	for b, sample := range samples {
+		for i := range ShiftQuantiles {
+			if stderr[i] > 0 {
+				deviations[b] = math.Max(deviations[b], math.Abs(sample[i]-diffs[i])/stderr[i])
+			}
+		}
+	}
+	slices.Sort(deviations)
+	critical := quantileFloat(deviations, confidence)
+
+	for i := range shift.Quantiles {
+		margin := time.Duration(critical * stderr[i])
+		shift.Quantiles[i].Low = shift.Quantiles[i].Diff - margin
+		shift.Quantiles[i].High = shift.Quantiles[i].Diff + margin
+	}
+	return shift
+}
+
+// resample fills target with a sorted random resample of sorted.
+func resample(rng *rand.Rand, target, sorted []time.Duration) {
+	for i := range target {
+		target[i] = sorted[rng.IntN(len(sorted))]
+	}
+	slices.Sort(target)
+}
+
+// ShiftFunction compares result quantile by quantile against baseline.
+func (result *Result) ShiftFunction(baseline *Result, confidence float64) *ShiftFunction {
+	return NewShiftFunction("", baseline.laps, result.laps, confidence)
+}
+
+// WriteTo writes the shift function as a table to w.
+func (shift *ShiftFunction) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	n, err := fmt.Fprintf(w, "%s: shift function, %.0f%% simultaneous bands\n", shift.Name, shift.Confidence*100)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	for _, q := range shift.Quantiles {
+		mark := ""
+		if q.Significant() {
+			mark = " *"
+		}
+		n, err = fmt.Fprintf(w, "  q%-3.0f %10v -> %10v  %10v [%v, %v]%s\n",
+			q.Q*100, formatStat(float64(q.Old)), formatStat(float64(q.New)),
+			formatStat(float64(q.Diff)), formatStat(float64(q.Low)), formatStat(float64(q.High)), mark)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns the shift function as a table.
+func (shift *ShiftFunction) String() string {
+	var buffer strings.Builder
+	_, _ = shift.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// WriteSVG writes the shift function as a plot in SVG format to w.
+//
+// Quantiles are on the horizontal axis and differences with
+// confidence bands on the vertical axis.
+func (shift *ShiftFunction) WriteSVG(w io.Writer) (int64, error) {
+	const (
+		width   = 540
+		height  = 240
+		padding = 40
+	)
+
+	var low, high float64
+	for _, q := range shift.Quantiles {
+		low, high = math.Min(low, float64(q.Low)), math.Max(high, float64(q.High))
+	}
+	if high == low {
+		high, low = 1, -1
+	}
+	x := func(q float64) float64 { return padding + q*(width-2*padding) }
+	y := func(d time.Duration) float64 {
+		return padding + (high-float64(d))/(high-low)*(height-2*padding)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#888"/>`+"\n", padding, y(0), width-padding, y(0))
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%v</text>`+"\n", padding-4, y(time.Duration(high))+4, formatStat(high))
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%v</text>`+"\n", padding-4, y(time.Duration(low))+4, formatStat(low))
+	for _, q := range shift.Quantiles {
+		color := "#4c78a8"
+		if q.Significant() {
+			color = "#e45756"
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="2"/>`+"\n",
+			x(q.Q), y(q.Low), x(q.Q), y(q.High), color)
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", x(q.Q), y(q.Diff), color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">q%.0f</text>`+"\n", x(q.Q), height-padding/2, q.Q*100)
+	}
+	b.WriteString("</svg>\n")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
@@ -0,0 +1,40 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestShiftFunction(t *testing.T) {
+	var old, new []time.Duration
+	for i := 0; i < 500; i++ {
+		old = append(old, time.Duration(1000+i%100))
+		// only the slowest 15% of laps are slower
+		lap := time.Duration(1000 + (i+50)%100)
+		if lap >= 1085 {
+			lap += 500
+		}
+		new = append(new, lap)
+	}
+
+	shift := hrtime.NewShiftFunction("tail", old, new, 0.95)
+	if len(shift.Quantiles) != len(hrtime.ShiftQuantiles) {
+		t.Fatalf("expected %d quantiles, got %d", len(hrtime.ShiftQuantiles), len(shift.Quantiles))
+	}
+	for _, q := range shift.Quantiles {
+		if q.Low > q.Diff || q.Diff > q.High {
+			t.Errorf("q%v: diff %v outside band [%v, %v]", q.Q, q.Diff, q.Low, q.High)
+		}
+		if tail := q.Q > 0.85; q.Significant() != tail {
+			t.Errorf("q%v: expected significant=%v\n%v", q.Q, tail, shift)
+		}
+	}
+
+	var svg strings.Builder
+	if _, err := shift.WriteSVG(&svg); err != nil || !strings.HasPrefix(svg.String(), "<svg") {
+		t.Errorf("invalid svg %v", err)
+	}
+}
@@ -0,0 +1,485 @@
+package hrtime
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the binary snapshot format.
+const SnapshotVersion = 1
+
+// ErrInvalidSnapshot is returned when decoding corrupted or unsupported snapshots
+// and lap streams.
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// Magic numbers at the start of encoded benchmarks, snapshots and lap streams.
+const (
+	benchmarkMagic    = "HRTB"
+	benchmarkTSCMagic = "HRTC"
+	snapshotMagic     = "HRTS"
+	lapsMagic         = "HRTL"
+)
+
+// MarshalBinary encodes raw measurements of a completed benchmark.
+//
+// The encoding contains laps, the timeline, timestamps, labels, memory
+// statistics, bytes per lap, sources of merged benchmarks, failed laps,
+// the number of observed laps of sampled benchmarks and the error.
+// Phases, metrics of collectors and GC pauses are not encoded.
+// Laps are delta encoded, hence laps of similar durations take a byte or two.
+// Options such as the clock are not encoded.
+func (bench *Benchmark) MarshalBinary() ([]byte, error) {
+	bench = bench.mustBeCompleted()
+
+	var enc snapshotEncoder
+	enc.magic(benchmarkMagic)
+	encodeLaps(&enc, &bench.lapCore)
+	return enc.data, nil
+}
+
+// UnmarshalBinary replaces measurements of bench with data encoded by MarshalBinary.
+//
+// The benchmark is completed afterwards and can be analyzed, compared or
+// added to reports like a benchmark measured on this machine.
+func (bench *Benchmark) UnmarshalBinary(data []byte) error {
+	dec := snapshotDecoder{data: data}
+	dec.magic(benchmarkMagic)
+	snapshot := decodeLaps[time.Duration](&dec)
+
+	if dec.err == nil && len(dec.data) > 0 {
+		dec.fail("trailing data")
+	}
+	if dec.err != nil {
+		return dec.err
+	}
+
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+
+	bench.restoreLaps(snapshot)
+	bench.result = nil
+	return nil
+}
+
+// MarshalBinary encodes raw measurements of a completed benchmark in counts,
+// see Benchmark.MarshalBinary.
+func (bench *BenchmarkTSC) MarshalBinary() ([]byte, error) {
+	bench = bench.mustBeCompleted()
+
+	var enc snapshotEncoder
+	enc.magic(benchmarkTSCMagic)
+	encodeLaps(&enc, &bench.lapCore)
+	return enc.data, nil
+}
+
+// UnmarshalBinary replaces measurements of bench with data encoded by
+// BenchmarkTSC.MarshalBinary, see Benchmark.UnmarshalBinary.
+func (bench *BenchmarkTSC) UnmarshalBinary(data []byte) error {
+	dec := snapshotDecoder{data: data}
+	dec.magic(benchmarkTSCMagic)
+	snapshot := decodeLaps[Count](&dec)
+
+	if dec.err == nil && len(dec.data) > 0 {
+		dec.fail("trailing data")
+	}
+	if dec.err != nil {
+		return dec.err
+	}
+
+	bench.finalizing.Lock()
+	defer bench.finalizing.Unlock()
+
+	bench.restoreLaps(snapshot)
+	bench.result = nil
+	return nil
+}
+
+// lapSnapshot contains decoded measurements of lapCore.
+type lapSnapshot[T ~int64] struct {
+	start, stop  T
+	nonMonotonic int
+	err          error
+	bytes        int64
+	laps         []T
+	timestamps   []T
+	wallStop     time.Time
+	labelNames   []string
+	labels       []uint32
+	memStats     *MemStats
+	sources      []benchmarkSource
+	failed       []bool
+	observed     int
+}
+
+// encodeLaps encodes measurements of a completed core.
+func encodeLaps[T ~int64](enc *snapshotEncoder, core *lapCore[T]) {
+	enc.int(int64(core.start))
+	enc.int(int64(core.stop))
+	enc.uint(uint64(core.nonMonotonic))
+	if core.err != nil {
+		enc.string(core.err.Error())
+	} else {
+		enc.string("")
+	}
+	enc.int(core.bytes)
+	encodeDeltas(enc, core.laps)
+
+	enc.bool(core.timestamps != nil)
+	if core.timestamps != nil {
+		encodeDeltas(enc, core.timestamps)
+		enc.int(core.wallStop.UnixNano())
+	}
+
+	enc.uint(uint64(len(core.labelNames)))
+	for _, name := range core.labelNames {
+		enc.string(name)
+	}
+	enc.uint(uint64(len(core.labels)))
+	for _, label := range core.labels {
+		enc.uint(uint64(label))
+	}
+
+	enc.bool(core.memStats != nil)
+	if core.memStats != nil {
+		enc.uint(uint64(core.memStats.Laps))
+		enc.uint(core.memStats.Allocs)
+		enc.uint(core.memStats.Bytes)
+	}
+
+	enc.uint(uint64(len(core.sources)))
+	for _, source := range core.sources {
+		enc.string(source.name)
+		enc.uint(uint64(source.end))
+	}
+
+	// failed laps are delta encoded indices
+	enc.uint(uint64(core.failures()))
+	previous := 0
+	for i, failed := range core.failed {
+		if failed {
+			enc.uint(uint64(i - previous))
+			previous = i
+		}
+	}
+
+	// zero when not sampled
+	observed, _ := core.observedLaps()
+	enc.uint(uint64(observed))
+}
+
+// decodeLaps decodes measurements encoded by encodeLaps.
+func decodeLaps[T ~int64](dec *snapshotDecoder) lapSnapshot[T] {
+	var snapshot lapSnapshot[T]
+	snapshot.start = T(dec.int())
+	snapshot.stop = T(dec.int())
+	snapshot.nonMonotonic = int(dec.uint())
+	errText := dec.string()
+	snapshot.bytes = dec.int()
+	snapshot.laps = decodeDeltas[T](dec)
+	laps := snapshot.laps
+
+	if dec.bool() {
+		snapshot.timestamps = decodeDeltas[T](dec)
+		snapshot.wallStop = time.Unix(0, dec.int())
+	}
+
+	if n := dec.count(); n > 0 {
+		snapshot.labelNames = make([]string, n)
+		for i := range snapshot.labelNames {
+			snapshot.labelNames[i] = dec.string()
+		}
+	}
+	if n := dec.count(); n > 0 {
+		snapshot.labels = make([]uint32, n)
+		for i := range snapshot.labels {
+			snapshot.labels[i] = uint32(dec.uint())
+			if int(snapshot.labels[i]) >= len(snapshot.labelNames) {
+				dec.fail("label out of range")
+			}
+		}
+	}
+
+	if dec.bool() {
+		snapshot.memStats = &MemStats{Laps: int(dec.uint()), Allocs: dec.uint(), Bytes: dec.uint()}
+	}
+
+	if n := dec.count(); n > 0 {
+		sources := make([]benchmarkSource, n)
+		for i := range sources {
+			sources[i] = benchmarkSource{name: dec.string(), end: int(dec.uint())}
+			if sources[i].end > len(laps) || (i > 0 && sources[i].end < sources[i-1].end) {
+				dec.fail("source out of range")
+			}
+		}
+		snapshot.sources = sources
+	}
+
+	if n := dec.count(); n > 0 {
+		snapshot.failed = make([]bool, len(laps))
+		index := 0
+		for range n {
+			index += int(dec.uint())
+			if index >= len(laps) {
+				dec.fail("failed lap out of range")
+				break
+			}
+			snapshot.failed[index] = true
+		}
+	}
+
+	observed := dec.uint()
+	if observed > math.MaxInt32 || observed > 0 && observed < uint64(len(laps)) {
+		dec.fail("observed laps out of range")
+	}
+	snapshot.observed = int(observed)
+
+	if dec.err == nil && (snapshot.timestamps != nil && len(snapshot.timestamps) != len(laps) ||
+		snapshot.labels != nil && len(snapshot.labels) != len(laps)) {
+		dec.fail("misaligned laps")
+	}
+	snapshot.err = decodeSnapshotError(errText)
+	return snapshot
+}
+
+// restoreLaps replaces measurements of the core with snapshot and completes it,
+// it must be called with finalizing locked.
+func (core *lapCore[T]) restoreLaps(snapshot lapSnapshot[T]) {
+	core.step = len(snapshot.laps)
+	core.laps = snapshot.laps
+	core.start, core.stop = snapshot.start, snapshot.stop
+	core.nonMonotonic = snapshot.nonMonotonic
+	core.err = snapshot.err
+	core.unbounded = false
+	core.recording, core.recordAt = false, 0
+	core.bytes = snapshot.bytes
+	core.timestamps, core.wallStop = snapshot.timestamps, snapshot.wallStop
+	core.labels, core.labelNames, core.lastLabel = snapshot.labels, snapshot.labelNames, 0
+	core.memStats = snapshot.memStats
+	core.sources = snapshot.sources
+	core.failed = snapshot.failed
+	core.gcPauses = nil
+	core.lapPhases = lapPhases[T]{}
+	core.metrics, core.metricNames = nil, nil
+	core.lapPauses = lapPauses[T]{}
+	// sampling state belongs to the replaced measurements
+	core.sampling, core.observed = nil, snapshot.observed
+	core.done.Store(true)
+}
+
+// decodeSnapshotError restores an encoded error, keeping ErrNonMonotonic matchable with errors.Is.
+func decodeSnapshotError(text string) error {
+	switch {
+	case text == "":
+		return nil
+	case strings.HasPrefix(text, ErrNonMonotonic.Error()):
+		return fmt.Errorf("%w%s", ErrNonMonotonic, strings.TrimPrefix(text, ErrNonMonotonic.Error()))
+	default:
+		return errors.New(text)
+	}
+}
+
+// Snapshot is a benchmark together with the information needed to
+// analyze it on another machine.
+type Snapshot struct {
+	Name      string
+	Benchmark *Benchmark
+	// Environment describes where the benchmark was measured.
+	Environment *Environment
+	// Metadata contains user specified key-value pairs.
+	Metadata map[string]string
+}
+
+// NewSnapshot creates a snapshot of a completed benchmark with the current environment.
+func NewSnapshot(name string, bench *Benchmark) *Snapshot {
+	bench = bench.mustBeCompleted()
+	return &Snapshot{Name: name, Benchmark: bench, Environment: CaptureEnv()}
+}
+
+// Encode writes snapshot to w in the binary snapshot format.
+func (snapshot *Snapshot) Encode(w io.Writer) error {
+	bench, err := snapshot.Benchmark.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var env []byte
+	if snapshot.Environment != nil {
+		env, err = json.Marshal(snapshot.Environment)
+		if err != nil {
+			return err
+		}
+	}
+
+	var enc snapshotEncoder
+	enc.magic(snapshotMagic)
+	enc.string(snapshot.Name)
+	enc.uint(uint64(len(snapshot.Metadata)))
+	for _, key := range slices.Sorted(maps.Keys(snapshot.Metadata)) {
+		enc.string(key)
+		enc.string(snapshot.Metadata[key])
+	}
+	enc.string(string(env))
+	enc.string(string(bench))
+
+	_, err = w.Write(enc.data)
+	return err
+}
+
+// DecodeSnapshot reads a snapshot written by Snapshot.Encode from r.
+func DecodeSnapshot(r io.Reader) (*Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := snapshotDecoder{data: data}
+	dec.magic(snapshotMagic)
+	snapshot := &Snapshot{Name: dec.string()}
+	if n := dec.count(); n > 0 {
+		snapshot.Metadata = make(map[string]string, n)
+		for range n {
+			key := dec.string()
+			snapshot.Metadata[key] = dec.string()
+		}
+	}
+	env := dec.string()
+	bench := dec.string()
+	if dec.err == nil && len(dec.data) > 0 {
+		dec.fail("trailing data")
+	}
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	if env != "" {
+		snapshot.Environment = &Environment{}
+		if err := json.Unmarshal([]byte(env), snapshot.Environment); err != nil {
+			return nil, fmt.Errorf("%w: environment: %w", ErrInvalidSnapshot, err)
+		}
+	}
+	snapshot.Benchmark = &Benchmark{}
+	if err := snapshot.Benchmark.UnmarshalBinary([]byte(bench)); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// snapshotEncoder appends values in the snapshot format.
+type snapshotEncoder struct {
+	data []byte
+}
+
+func (enc *snapshotEncoder) magic(magic string) {
+	enc.data = append(enc.data, magic...)
+	enc.uint(SnapshotVersion)
+}
+
+func (enc *snapshotEncoder) uint(v uint64) { enc.data = binary.AppendUvarint(enc.data, v) }
+func (enc *snapshotEncoder) int(v int64)   { enc.data = binary.AppendVarint(enc.data, v) }
+
+func (enc *snapshotEncoder) bool(v bool) {
+	if v {
+		enc.uint(1)
+	} else {
+		enc.uint(0)
+	}
+}
+
+func (enc *snapshotEncoder) string(v string) {
+	enc.uint(uint64(len(v)))
+	enc.data = append(enc.data, v...)
+}
+
+// encodeDeltas encodes values as differences to the previous one.
+func encodeDeltas[T ~int64](enc *snapshotEncoder, values []T) {
+	enc.uint(uint64(len(values)))
+	var previous T
+	for _, v := range values {
+		enc.int(int64(v - previous))
+		previous = v
+	}
+}
+
+// snapshotDecoder reads values in the snapshot format.
+//
+// After the first failure all reads return zero values and err is set.
+type snapshotDecoder struct {
+	data []byte
+	err  error
+}
+
+func (dec *snapshotDecoder) fail(reason string) {
+	if dec.err == nil {
+		dec.err = fmt.Errorf("%w: %s", ErrInvalidSnapshot, reason)
+	}
+	dec.data = nil
+}
+
+func (dec *snapshotDecoder) magic(magic string) {
+	if !strings.HasPrefix(string(dec.data), magic) {
+		dec.fail("unknown format")
+		return
+	}
+	dec.data = dec.data[len(magic):]
+	if version := dec.uint(); dec.err == nil && (version == 0 || version > SnapshotVersion) {
+		dec.fail(fmt.Sprintf("unsupported version %d", version))
+	}
+}
+
+func (dec *snapshotDecoder) uint() uint64 {
+	v, n := binary.Uvarint(dec.data)
+	if n <= 0 {
+		dec.fail("truncated")
+		return 0
+	}
+	dec.data = dec.data[n:]
+	return v
+}
+
+func (dec *snapshotDecoder) int() int64 {
+	v, n := binary.Varint(dec.data)
+	if n <= 0 {
+		dec.fail("truncated")
+		return 0
+	}
+	dec.data = dec.data[n:]
+	return v
+}
+
+func (dec *snapshotDecoder) bool() bool { return dec.uint() != 0 }
+
+// count reads a length, which must not exceed the remaining data.
+func (dec *snapshotDecoder) count() int {
+	n := dec.uint()
+	if n > uint64(len(dec.data)) || n > math.MaxInt32 {
+		dec.fail("truncated")
+		return 0
+	}
+	return int(n)
+}
+
+func (dec *snapshotDecoder) string() string {
+	n := dec.count()
+	v := string(dec.data[:n])
+	dec.data = dec.data[n:]
+	return v
+}
+
+// decodeDeltas decodes values encoded by encodeDeltas.
+func decodeDeltas[T ~int64](dec *snapshotDecoder) []T {
+	n := dec.count()
+	values := make([]T, n)
+	var previous T
+	for i := range values {
+		previous += T(dec.int())
+		values[i] = previous
+	}
+	return values
+}
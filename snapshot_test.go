@@ -0,0 +1,174 @@
+package hrtime_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkMarshalBinary(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmark(4, hrtime.WithClock(clock), hrtime.WithTimestamps(), hrtime.WithNonMonotonic(hrtime.NonMonotonicError))
+	bench.SetBytes(64)
+	jumps := []time.Duration{100, 120, -10, 90}
+	for i := 0; bench.NextWithLabel([]string{"a", "b"}[i%2]); i++ {
+		clock.Advance(jumps[i])
+	}
+
+	data, err := bench.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded hrtime.Benchmark
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(decoded.Laps(), bench.Laps()) || !slices.Equal(decoded.Timestamps(), bench.Timestamps()) {
+		t.Errorf("laps mismatch %v %v", decoded.Laps(), bench.Laps())
+	}
+	if !slices.Equal(decoded.Labels(), bench.Labels()) {
+		t.Errorf("labels mismatch %v %v", decoded.Labels(), bench.Labels())
+	}
+	if !errors.Is(decoded.Err(), hrtime.ErrNonMonotonic) || decoded.NonMonotonic() != 1 {
+		t.Errorf("expected non-monotonic error, got %v", decoded.Err())
+	}
+	if decoded.Throughput() != bench.Throughput() {
+		t.Errorf("unexpected throughput %+v", decoded.Throughput())
+	}
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, hrtime.ErrInvalidSnapshot) {
+		t.Errorf("expected invalid snapshot for truncated data, got %v", err)
+	}
+}
+
+func TestBenchmarkMarshalBinaryFailures(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmark(4, hrtime.WithClock(clock))
+	for i := 0; bench.Next(); i++ {
+		clock.Advance(time.Duration(i+1) * 100)
+		if i%2 == 1 {
+			bench.Fail()
+		}
+	}
+	data, err := bench.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// decoding replaces failures and phases of the previous measurements
+	decoded := hrtime.NewBenchmark(3, hrtime.WithClock(clock))
+	for decoded.Next() {
+		lap := decoded.StartLap()
+		clock.Advance(50)
+		lap.Mark("encode")
+		decoded.Fail()
+	}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if failed := decoded.Failed().Laps(); !slices.Equal(failed, bench.Failed().Laps()) || decoded.Failures() != 2 {
+		t.Errorf("failed laps mismatch %v %v", failed, bench.Failed().Laps())
+	}
+	if phases := decoded.Phases(); phases != nil {
+		t.Errorf("expected no phases, got %v", phases)
+	}
+}
+
+func TestBenchmarkMarshalBinarySampling(t *testing.T) {
+	bench := hrtime.NewBenchmark(100, hrtime.WithReservoir(10))
+	for bench.Next() {
+	}
+	data, err := bench.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := hrtime.NewBenchmark(50, hrtime.WithSampling(5))
+	for decoded.Next() {
+	}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Observed() != 100 || decoded.SamplingRate() != 0.1 {
+		t.Errorf("expected 10 of 100 laps sampled, got %v of %v", len(decoded.Laps()), decoded.Observed())
+	}
+
+	unsampled := hrtime.NewBenchmark(20)
+	for unsampled.Next() {
+	}
+	data, err = unsampled.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Observed() != 20 || decoded.SamplingRate() != 1 {
+		t.Errorf("expected unsampled laps, got %v of %v", len(decoded.Laps()), decoded.Observed())
+	}
+}
+
+func TestSnapshotEncode(t *testing.T) {
+	merged := hrtime.MergeBenchmarksNamed([]string{"a", "b"}, benchmarkOf(3, 100), benchmarkOf(2, 300))
+	snapshot := hrtime.NewSnapshot("merged", merged)
+	snapshot.Metadata = map[string]string{"commit": "abc123"}
+
+	var buf bytes.Buffer
+	if err := snapshot.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := hrtime.DecodeSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Name != "merged" || decoded.Metadata["commit"] != "abc123" {
+		t.Errorf("unexpected snapshot %+v", decoded)
+	}
+	if decoded.Environment == nil || decoded.Environment.GOOS != snapshot.Environment.GOOS {
+		t.Errorf("unexpected environment %+v", decoded.Environment)
+	}
+	if sources := decoded.Benchmark.Sources(); len(sources) != 2 || sources[1].Name != "b" || sources[1].Mean != 300 {
+		t.Errorf("unexpected sources %+v", sources)
+	}
+	if comparison := hrtime.Compare(merged, decoded.Benchmark); comparison.OldCount != 5 || comparison.NewCount != 5 {
+		t.Errorf("unexpected comparison %v", comparison)
+	}
+}
+
+func TestBenchmarkTSCMarshalBinary(t *testing.T) {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmarkTSC(4, hrtime.WithClock(clock), hrtime.WithTimestamps())
+	for i := 0; bench.NextWithLabel([]string{"a", "b"}[i%2]); i++ {
+		clock.Advance(time.Duration(i+1) * 100)
+		if i == 2 {
+			bench.Fail()
+		}
+	}
+
+	data, err := bench.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded hrtime.BenchmarkTSC
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(decoded.Laps(), bench.Laps()) || !slices.Equal(decoded.Timestamps(), bench.Timestamps()) {
+		t.Errorf("laps mismatch %v %v", decoded.Laps(), bench.Laps())
+	}
+	if !slices.Equal(decoded.Labels(), bench.Labels()) || decoded.Failures() != 1 {
+		t.Errorf("labels mismatch %v %v", decoded.Labels(), bench.Labels())
+	}
+
+	var other hrtime.Benchmark
+	if err := other.UnmarshalBinary(data); !errors.Is(err, hrtime.ErrInvalidSnapshot) {
+		t.Errorf("expected invalid snapshot for counts, got %v", err)
+	}
+}
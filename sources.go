@@ -0,0 +1,228 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// StragglerThreshold is the ratio to the median source, above which
+// a source of a merged benchmark is considered a straggler.
+const StragglerThreshold = 1.5
+
+// benchmarkSource identifies laps of a single source in a merged benchmark.
+type benchmarkSource struct {
+	name string
+	// end is the index after the last lap of the source.
+	end int
+}
+
+// SourceStats contains statistics of laps from a single source of a merged benchmark,
+// e.g. a goroutine.
+type SourceStats struct {
+	Name  string
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+	// Straggler is set when the mean or p99 of the source exceeds
+	// the median across sources by StragglerThreshold.
+	Straggler bool
+}
+
+// MergeBenchmarksNamed merges benchmarks similarly to MergeBenchmarks,
+// naming each source for Sources.
+//
+// It panics when the number of names and benchmarks differs.
+func MergeBenchmarksNamed(names []string, benchmarks ...*Benchmark) *Benchmark {
+	if len(names) != len(benchmarks) {
+		panic("must have a name for every benchmark")
+	}
+	merged := MergeBenchmarks(benchmarks...)
+	if merged != nil {
+		for i := range merged.sources {
+			merged.sources[i].name = names[i]
+		}
+	}
+	return merged
+}
+
+// MergeBenchmarkTSCsNamed merges benchmarks similarly to MergeBenchmarkTSCs,
+// naming each source for Sources.
+//
+// It panics when the number of names and benchmarks differs.
+func MergeBenchmarkTSCsNamed(names []string, benchmarks ...*BenchmarkTSC) *BenchmarkTSC {
+	if len(names) != len(benchmarks) {
+		panic("must have a name for every benchmark")
+	}
+	merged := MergeBenchmarkTSCs(benchmarks...)
+	if merged != nil {
+		for i := range merged.sources {
+			merged.sources[i].name = names[i]
+		}
+	}
+	return merged
+}
+
+// SourceLaps returns laps recorded from the i-th source of a merged benchmark.
+func (bench *Benchmark) SourceLaps(i int) []time.Duration {
+	bench = bench.mustBeCompleted()
+	return sourceLaps(bench.laps, bench.sources, i)
+}
+
+// Sources returns statistics of every source of a benchmark created by
+// MergeBenchmarks or ConcurrentBenchmark, nil for other benchmarks.
+//
+// It allows finding skew between workers, which is hidden in the combined distribution.
+func (bench *Benchmark) Sources() []SourceStats {
+	bench = bench.mustBeCompleted()
+	return sourceStats(bench.laps, bench.sources)
+}
+
+// SourceLaps returns laps recorded from the i-th source of a merged benchmark.
+//
+// Laps are converted to durations using the approximate TSC frequency.
+func (bench *BenchmarkTSC) SourceLaps(i int) []time.Duration {
+	bench = bench.mustBeCompleted()
+	return sourceLaps(bench.Result().laps, bench.sources, i)
+}
+
+// Sources returns statistics of every source of a benchmark created by
+// MergeBenchmarkTSCs, nil for other benchmarks, see Benchmark.Sources.
+func (bench *BenchmarkTSC) Sources() []SourceStats {
+	bench = bench.mustBeCompleted()
+	return sourceStats(bench.Result().laps, bench.sources)
+}
+
+// sourceLaps returns a copy of laps of the i-th source.
+func sourceLaps(laps []time.Duration, sources []benchmarkSource, i int) []time.Duration {
+	start := 0
+	if i > 0 {
+		start = sources[i-1].end
+	}
+	return append(laps[:0:0], laps[start:sources[i].end]...)
+}
+
+// sourceStats returns statistics of laps of every source, nil without sources.
+func sourceStats(laps []time.Duration, benchmarkSources []benchmarkSource) []SourceStats {
+	if benchmarkSources == nil {
+		return nil
+	}
+
+	sources := make([]SourceStats, len(benchmarkSources))
+	means := make([]time.Duration, 0, len(sources))
+	p99s := make([]time.Duration, 0, len(sources))
+	start := 0
+	for i, source := range benchmarkSources {
+		laps := laps[start:source.end]
+		start = source.end
+
+		sources[i] = SourceStats{Name: source.name, Count: len(laps)}
+		if len(laps) == 0 {
+			continue
+		}
+		sorted := sortedDurations(laps)
+		sources[i].Mean = meanDuration(sorted)
+		sources[i].P50 = quantile(sorted, 0.5)
+		sources[i].P99 = quantile(sorted, 0.99)
+		sources[i].Max = sorted[len(sorted)-1]
+		means = append(means, sources[i].Mean)
+		p99s = append(p99s, sources[i].P99)
+	}
+	if len(means) == 0 {
+		return sources
+	}
+
+	medianMean := float64(quantile(sortedDurations(means), 0.5))
+	medianP99 := float64(quantile(sortedDurations(p99s), 0.5))
+	for i := range sources {
+		source := &sources[i]
+		source.Straggler = source.Count > 0 &&
+			(float64(source.Mean) > StragglerThreshold*medianMean ||
+				float64(source.P99) > StragglerThreshold*medianP99)
+	}
+	return sources
+}
+
+// Breakdown contains statistics of every source alongside the combined distribution.
+type Breakdown struct {
+	Sources []SourceStats
+	Total   *Stats
+}
+
+// Breakdown returns per-source and combined statistics of a merged benchmark.
+func (bench *Benchmark) Breakdown() *Breakdown {
+	return &Breakdown{
+		Sources: bench.Sources(),
+		Total:   NewStats(bench.laps),
+	}
+}
+
+// Breakdown returns per-source and combined statistics of a merged benchmark.
+func (bench *BenchmarkTSC) Breakdown() *Breakdown {
+	return &Breakdown{
+		Sources: bench.Sources(),
+		Total:   bench.Stats(),
+	}
+}
+
+// Stragglers returns sources that are considered stragglers.
+func (breakdown *Breakdown) Stragglers() []SourceStats {
+	var stragglers []SourceStats
+	for _, source := range breakdown.Sources {
+		if source.Straggler {
+			stragglers = append(stragglers, source)
+		}
+	}
+	return stragglers
+}
+
+// WriteTo writes a table of sources and the combined statistics to w,
+// stragglers are marked with an asterisk.
+func (breakdown *Breakdown) WriteTo(w io.Writer) (int64, error) {
+	var s strings.Builder
+	tw := tabwriter.NewWriter(&s, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "source\tcount\tmean\tp50\tp99\tmax\t\t")
+	for _, source := range breakdown.Sources {
+		mark := ""
+		if source.Straggler {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%v\t%s\t\n", source.Name, source.Count,
+			RoundDuration(source.Mean), RoundDuration(source.P50), RoundDuration(source.P99), RoundDuration(source.Max), mark)
+	}
+	total := breakdown.Total
+	fmt.Fprintf(tw, "total\t%d\t%v\t%v\t%v\t%v\t\t\n", total.Count,
+		RoundDuration(total.Mean), RoundDuration(total.P50), RoundDuration(total.P99), RoundDuration(total.Maximum))
+	_ = tw.Flush()
+
+	n, err := io.WriteString(w, s.String())
+	return int64(n), err
+}
+
+// String returns a table of sources and the combined statistics.
+func (breakdown *Breakdown) String() string {
+	var s strings.Builder
+	_, _ = breakdown.WriteTo(&s)
+	return s.String()
+}
+
+// jsonSources returns statistics of sources for JSONResult.
+func jsonSources(laps []time.Duration, benchmarkSources []benchmarkSource) []JSONSource {
+	if benchmarkSources == nil {
+		return nil
+	}
+	sources := make([]JSONSource, len(benchmarkSources))
+	for i, source := range sourceStats(laps, benchmarkSources) {
+		sources[i] = JSONSource{
+			Name:      source.Name,
+			Count:     source.Count,
+			Stats:     newJSONStats(sourceLaps(laps, benchmarkSources, i)),
+			Straggler: source.Straggler,
+		}
+	}
+	return sources
+}
@@ -0,0 +1,68 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+// benchmarkOf returns a completed benchmark with count laps of lap.
+func benchmarkOf(count int, lap time.Duration) *hrtime.Benchmark {
+	clock := hrtime.NewManualClock(time.Second)
+	bench := hrtime.NewBenchmark(count, hrtime.WithClock(clock))
+	for bench.Next() {
+		clock.Advance(lap)
+	}
+	return bench
+}
+
+func TestMergeBenchmarksSources(t *testing.T) {
+	merged := hrtime.MergeBenchmarksNamed(
+		[]string{"worker-0", "worker-1", "worker-2"},
+		benchmarkOf(10, 100), benchmarkOf(5, 110), benchmarkOf(10, 400),
+	)
+
+	sources := merged.Sources()
+	if len(sources) != 3 || sources[1].Name != "worker-1" || sources[1].Count != 5 {
+		t.Fatalf("unexpected sources %+v", sources)
+	}
+	if sources[2].Mean != 400 || sources[0].P99 != 100 {
+		t.Errorf("unexpected source statistics %+v", sources)
+	}
+	if laps := merged.SourceLaps(1); len(laps) != 5 || laps[0] != 110 {
+		t.Errorf("unexpected source laps %v", laps)
+	}
+
+	breakdown := merged.Breakdown()
+	if stragglers := breakdown.Stragglers(); len(stragglers) != 1 || stragglers[0].Name != "worker-2" {
+		t.Errorf("expected worker-2 to be a straggler, got %+v", stragglers)
+	}
+	if breakdown.Total.Count != 25 || !strings.Contains(breakdown.String(), "worker-2") {
+		t.Errorf("unexpected breakdown:\n%v", breakdown)
+	}
+
+	result := merged.JSONResult("merged")
+	if len(result.Sources) != 3 || !result.Sources[2].Straggler || result.Sources[2].Stats.P50 != 400 {
+		t.Errorf("unexpected JSON sources %+v", result.Sources)
+	}
+
+	if sources := benchmarkOf(3, 100).Sources(); sources != nil {
+		t.Errorf("expected no sources for a single benchmark, got %+v", sources)
+	}
+}
+
+func TestConcurrentBenchmarkSources(t *testing.T) {
+	bench := hrtime.NewConcurrentBenchmark()
+	bench.Run(1, 10, func() {})
+
+	sources := bench.Finalize().Sources()
+	total := 0
+	for _, source := range sources {
+		total += source.Count
+	}
+	if len(sources) == 0 || sources[0].Count != 10 || total != 10 {
+		t.Errorf("expected laps of a single goroutine in the first shard, got %+v", sources)
+	}
+}
@@ -0,0 +1,170 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SpanTree records nested named sections forming a tree.
+//
+// Sections are started with Start and finished with Stop, Checkpoint
+// records the time since the previous checkpoint as a child section.
+// Sections with the same name under the same parent are aggregated,
+// hence a SpanTree can be used as a lightweight in-process profiler
+// for request pipelines.
+//
+// SpanTree is not safe for concurrent use, use a SpanTree per goroutine
+// and combine them with Merge.
+type SpanTree struct {
+	opts  options
+	root  *SpanNode
+	stack []activeSpan
+}
+
+// SpanNode is an aggregated named section in SpanTree.
+type SpanNode struct {
+	Name string
+	// Total is the total duration of all calls.
+	Total time.Duration
+	// Count is the number of calls.
+	Count    int
+	Children []*SpanNode
+}
+
+// activeSpan is a section that has been started, but not stopped.
+type activeSpan struct {
+	node       *SpanNode
+	start      time.Duration
+	checkpoint time.Duration
+}
+
+// NewSpanTree creates an empty span tree.
+//
+// Only WithClock option is used.
+func NewSpanTree(opts ...Option) *SpanTree {
+	return &SpanTree{
+		opts: newOptions(opts),
+		root: &SpanNode{},
+	}
+}
+
+// current returns the innermost started section, or root.
+func (tree *SpanTree) current() *SpanNode {
+	if len(tree.stack) == 0 {
+		return tree.root
+	}
+	return tree.stack[len(tree.stack)-1].node
+}
+
+// Start starts a section nested in the current section.
+func (tree *SpanTree) Start(name string) {
+	node := tree.current().child(name)
+	now := tree.opts.now()
+	tree.stack = append(tree.stack, activeSpan{node: node, start: now, checkpoint: now})
+}
+
+// Stop finishes the current section.
+func (tree *SpanTree) Stop() {
+	now := tree.opts.now()
+	if len(tree.stack) == 0 {
+		panic("stop called without start")
+	}
+	active := tree.stack[len(tree.stack)-1]
+	tree.stack = tree.stack[:len(tree.stack)-1]
+	active.node.Total += now - active.start
+	active.node.Count++
+	// the next checkpoint in the parent starts after the nested section
+	if len(tree.stack) > 0 {
+		tree.stack[len(tree.stack)-1].checkpoint = now
+	}
+}
+
+// Checkpoint records the time since the start of the current section,
+// the previous checkpoint or the end of a nested section in it as a child section name.
+//
+// It panics when no section has been started.
+func (tree *SpanTree) Checkpoint(name string) {
+	now := tree.opts.now()
+	if len(tree.stack) == 0 {
+		panic("checkpoint called without start")
+	}
+	active := &tree.stack[len(tree.stack)-1]
+	node := active.node.child(name)
+	node.Total += now - active.checkpoint
+	node.Count++
+	active.checkpoint = now
+}
+
+// Root returns the root of the tree, whose children are the top-level sections.
+func (tree *SpanTree) Root() *SpanNode { return tree.root }
+
+// Merge adds all sections of other to tree.
+func (tree *SpanTree) Merge(other *SpanTree) { tree.root.merge(other.root) }
+
+// child returns the child with name, creating it when necessary.
+func (node *SpanNode) child(name string) *SpanNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child := &SpanNode{Name: name}
+	node.Children = append(node.Children, child)
+	return child
+}
+
+// merge adds other and its children to node.
+func (node *SpanNode) merge(other *SpanNode) {
+	node.Total += other.Total
+	node.Count += other.Count
+	for _, child := range other.Children {
+		node.child(child.Name).merge(child)
+	}
+}
+
+// WriteTo writes the tree with duration, share of total and call count of each section.
+//
+// The share is relative to the total duration of top-level sections.
+func (tree *SpanTree) WriteTo(w io.Writer) (int64, error) {
+	var total time.Duration
+	for _, child := range tree.root.Children {
+		total += child.Total
+	}
+
+	var written int64
+	var write func(node *SpanNode, depth int) error
+	write = func(node *SpanNode, depth int) error {
+		share := 0.0
+		if total > 0 {
+			share = float64(node.Total) / float64(total) * 100
+		}
+		name := strings.Repeat("  ", depth) + node.Name
+		n, err := fmt.Fprintf(w, "%-24s %10v %6.2f%% %8d calls\n", name, formatStat(float64(node.Total)), share, node.Count)
+		written += int64(n)
+		if err != nil {
+			return err
+		}
+		for _, child := range node.Children {
+			if err := write(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, child := range tree.root.Children {
+		if err := write(child, 0); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// String returns the tree as a string.
+func (tree *SpanTree) String() string {
+	var buffer strings.Builder
+	_, _ = tree.WriteTo(&buffer)
+	return buffer.String()
+}
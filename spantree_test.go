@@ -0,0 +1,62 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSpanTree(t *testing.T) {
+	var now time.Duration
+	clock := hrtime.ClockFunc(func() time.Duration { return now })
+
+	tree := hrtime.NewSpanTree(hrtime.WithClock(clock))
+	for i := 0; i < 2; i++ {
+		tree.Start("request")
+		now += 1 * time.Millisecond
+		tree.Checkpoint("parse")
+
+		tree.Start("execute")
+		now += 3 * time.Millisecond
+		tree.Stop()
+
+		now += 1 * time.Millisecond
+		tree.Checkpoint("serialize")
+		tree.Stop()
+	}
+
+	request := tree.Root().Children[0]
+	if request.Name != "request" || request.Count != 2 || request.Total != 10*time.Millisecond {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	names := []string{}
+	for _, child := range request.Children {
+		names = append(names, child.Name)
+	}
+	if strings.Join(names, ",") != "parse,execute,serialize" {
+		t.Errorf("unexpected children %v", names)
+	}
+	if execute := request.Children[1]; execute.Total != 6*time.Millisecond || execute.Count != 2 {
+		t.Errorf("unexpected execute %+v", execute)
+	}
+	if serialize := request.Children[2]; serialize.Total != 2*time.Millisecond {
+		t.Errorf("checkpoint after nested section includes it %+v", serialize)
+	}
+
+	other := hrtime.NewSpanTree(hrtime.WithClock(clock))
+	other.Start("request")
+	now += time.Millisecond
+	other.Stop()
+	tree.Merge(other)
+	if request.Count != 3 || request.Total != 11*time.Millisecond {
+		t.Errorf("unexpected merge %+v", request)
+	}
+
+	out := tree.String()
+	if !strings.Contains(out, "100.00%") || !strings.Contains(out, "  execute") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+	t.Log("\n" + out)
+}
@@ -0,0 +1,279 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Stats contains summary statistics of durations.
+//
+// Percentiles are computed from the exact durations using linear
+// interpolation, unlike Histogram, which rounds to the nearest rank.
+type Stats struct {
+	Count   int
+	Mean    time.Duration
+	StdDev  time.Duration
+	Minimum time.Duration
+	Maximum time.Duration
+
+	P50, P90, P99, P999, P9999 time.Duration
+
+	// LowOutliers and HighOutliers are the number of durations outside
+	// of Tukey's fences, i.e. 1.5 interquartile ranges below p25 or above p75.
+	LowOutliers  int
+	HighOutliers int
+	// Trimmed is the number of outliers removed before computing
+	// the statistics, see Result.Trimmed.
+	Trimmed int
+
+	sorted []time.Duration
+	// approximate is used by Percentile, when created from a histogram
+	// or from counts.
+	approximate func(q float64) time.Duration
+}
+
+// NewStats calculates summary statistics of durations.
+//
+// It keeps a sorted copy of durations for Percentile.
+func NewStats(durations []time.Duration) *Stats {
+	sorted := sortedDurations(durations)
+	stats := summarize(sorted, func(d time.Duration) time.Duration { return d }, 1)
+	stats.sorted = sorted
+	return stats
+}
+
+// newCountStats calculates summary statistics of counts.
+//
+// Statistics are computed on the sorted counts and only the summary
+// values are converted using the approximate TSC frequency.
+func newCountStats(counts []Count) *Stats {
+	ratio := loadTSCRatio()
+	sorted := sortedCounts(counts)
+	stats := summarize(sorted, ratio.duration, float64(ratio.nano)/float64(ratio.count))
+	stats.approximate = func(q float64) time.Duration { return ratio.duration(quantile(sorted, q)) }
+	return stats
+}
+
+// summarize calculates statistics of sorted values, which are converted to
+// durations with toDuration, or by multiplying with nanosPerValue for
+// the mean and the standard deviation.
+func summarize[T ~int64](sorted []T, toDuration func(T) time.Duration, nanosPerValue float64) *Stats {
+	stats := &Stats{Count: len(sorted)}
+	if len(sorted) == 0 {
+		return stats
+	}
+
+	stats.Minimum = toDuration(sorted[0])
+	stats.Maximum = toDuration(sorted[len(sorted)-1])
+
+	var total float64
+	for _, v := range sorted {
+		total += float64(v)
+	}
+	mean := total / float64(len(sorted))
+	stats.Mean = time.Duration(mean * nanosPerValue)
+
+	if len(sorted) > 1 {
+		var variance float64
+		for _, v := range sorted {
+			diff := float64(v) - mean
+			variance += diff * diff
+		}
+		variance /= float64(len(sorted) - 1)
+		stats.StdDev = time.Duration(math.Sqrt(variance) * nanosPerValue)
+	}
+
+	stats.P50 = toDuration(quantile(sorted, 0.5))
+	stats.P90 = toDuration(quantile(sorted, 0.9))
+	stats.P99 = toDuration(quantile(sorted, 0.99))
+	stats.P999 = toDuration(quantile(sorted, 0.999))
+	stats.P9999 = toDuration(quantile(sorted, 0.9999))
+
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	iqr := float64(q3 - q1)
+	low, high := float64(q1)-1.5*iqr, float64(q3)+1.5*iqr
+	for _, v := range sorted {
+		switch {
+		case float64(v) < low:
+			stats.LowOutliers++
+		case float64(v) > high:
+			stats.HighOutliers++
+		}
+	}
+
+	return stats
+}
+
+// Percentile returns the q-th quantile using linear interpolation between
+// the closest ranks, e.g. Percentile(0.999) returns p99.9.
+//
+// q is clamped to range [0, 1].
+//
+// Statistics of histograms approximate the quantile from bins.
+func (stats *Stats) Percentile(q float64) time.Duration {
+	if stats.approximate != nil {
+		return stats.approximate(min(max(q, 0), 1))
+	}
+	return quantile(stats.sorted, q)
+}
+
+// WriteTo writes the statistics to w.
+func (stats *Stats) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w,
+		"  count %d  avg %v ± %v  min %v  max %v\n"+
+			"  p50 %v  p90 %v  p99 %v  p999 %v  p9999 %v\n"+
+			"  outliers %d low, %d high%s\n",
+		stats.Count, formatStat(float64(stats.Mean)), formatStat(float64(stats.StdDev)),
+		formatStat(float64(stats.Minimum)), formatStat(float64(stats.Maximum)),
+		formatStat(float64(stats.P50)), formatStat(float64(stats.P90)), formatStat(float64(stats.P99)),
+		formatStat(float64(stats.P999)), formatStat(float64(stats.P9999)),
+		stats.LowOutliers, stats.HighOutliers, stats.trimmedSuffix())
+	return int64(n), err
+}
+
+// trimmedSuffix describes the number of trimmed outliers, when any.
+func (stats *Stats) trimmedSuffix() string {
+	if stats.Trimmed == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d trimmed", stats.Trimmed)
+}
+
+// String returns the statistics as a string.
+func (stats *Stats) String() string {
+	var buffer strings.Builder
+	_, _ = stats.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// Stats calculates summary statistics of all the laps.
+func (bench *Benchmark) Stats() *Stats {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Stats()
+}
+
+// Stats calculates summary statistics of all the laps.
+//
+// Statistics are computed on raw counts and only the summary values are
+// converted using the approximate TSC frequency.
+func (bench *BenchmarkTSC) Stats() *Stats {
+	bench = bench.mustBeCompleted()
+	return newCountStats(bench.laps)
+}
+
+// Stats calculates summary statistics of all the durations.
+func (bench *Stopwatch) Stats() *Stats {
+	bench.mustBeCompleted()
+	return NewStats(bench.Durations())
+}
+
+// Stats calculates summary statistics of all the durations.
+//
+// Durations are converted using the approximate TSC frequency.
+func (bench *StopwatchTSC) Stats() *Stats {
+	bench.mustBeCompleted()
+	return NewStats(bench.ApproxDurations())
+}
+
+// Stats calculates summary statistics of all recorded durations.
+func (rec *Recorder) Stats() *Stats {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return NewStats(rec.laps)
+}
+
+// Stats returns summary statistics of the histogram.
+//
+// Minimum, maximum, mean and the fixed percentiles are the values
+// computed when the histogram was created or merged. The standard
+// deviation, outliers and Percentile are approximated from bins or from
+// the HDR histogram, when using HistogramHDR backend.
+func (hist *Histogram) Stats() *Stats {
+	if hist.HDR != nil {
+		stats := hist.HDR.Stats()
+		stats.Mean = time.Duration(hist.Average)
+		stats.Minimum, stats.Maximum = time.Duration(hist.Minimum), time.Duration(hist.Maximum)
+		stats.P50, stats.P90, stats.P99 = time.Duration(hist.P50), time.Duration(hist.P90), time.Duration(hist.P99)
+		stats.P999, stats.P9999 = time.Duration(hist.P999), time.Duration(hist.P9999)
+		return stats
+	}
+
+	stats := &Stats{
+		Count:   hist.count(),
+		Mean:    time.Duration(hist.Average),
+		Minimum: time.Duration(hist.Minimum),
+		Maximum: time.Duration(hist.Maximum),
+		P50:     time.Duration(hist.P50),
+		P90:     time.Duration(hist.P90),
+		P99:     time.Duration(hist.P99),
+		P999:    time.Duration(hist.P999),
+		P9999:   time.Duration(hist.P9999),
+
+		approximate: func(q float64) time.Duration { return time.Duration(hist.binQuantile(q)) },
+	}
+	stats.approximateSpread(func(add func(value float64, count int64)) {
+		for i, bin := range hist.Bins {
+			end := hist.Maximum
+			if i+1 < len(hist.Bins) && !bin.andAbove {
+				end = math.Min(hist.Bins[i+1].Start, hist.Maximum)
+			}
+			add(bin.Start+(end-bin.Start)/2, int64(bin.Count))
+		}
+	})
+	return stats
+}
+
+// Stats returns summary statistics of recorded values within the
+// configured precision, see HDRHistogram.ValueAtPercentile.
+func (hdr *HDRHistogram) Stats() *Stats {
+	at := hdr.DurationAtPercentile
+	stats := &Stats{
+		Count:   int(hdr.Count()),
+		Mean:    time.Duration(hdr.Mean()),
+		Minimum: time.Duration(hdr.Min()),
+		Maximum: time.Duration(hdr.Max()),
+		P50:     at(0.5),
+		P90:     at(0.9),
+		P99:     at(0.99),
+		P999:    at(0.999),
+		P9999:   at(0.9999),
+
+		approximate: at,
+	}
+	stats.approximateSpread(func(add func(value float64, count int64)) {
+		for i, count := range hdr.counts {
+			low, high := hdr.bucketRange(i)
+			add(float64(low)+float64(high-low)/2, count)
+		}
+	})
+	return stats
+}
+
+// approximateSpread calculates the standard deviation and outliers from
+// counts of values in buckets, which are passed to add by each.
+func (stats *Stats) approximateSpread(each func(add func(value float64, count int64))) {
+	if stats.Count > 1 {
+		mean := float64(stats.Mean)
+		var variance float64
+		each(func(value float64, count int64) {
+			diff := value - mean
+			variance += float64(count) * diff * diff
+		})
+		stats.StdDev = time.Duration(math.Sqrt(variance / float64(stats.Count-1)))
+	}
+
+	q1, q3 := stats.Percentile(0.25), stats.Percentile(0.75)
+	iqr := float64(q3 - q1)
+	low, high := float64(q1)-1.5*iqr, float64(q3)+1.5*iqr
+	each(func(value float64, count int64) {
+		switch {
+		case value < low:
+			stats.LowOutliers += int(count)
+		case value > high:
+			stats.HighOutliers += int(count)
+		}
+	})
+}
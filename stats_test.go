@@ -0,0 +1,68 @@
+package hrtime_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestStats(t *testing.T) {
+	laps := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		laps = append(laps, time.Duration(i)*time.Microsecond)
+	}
+	laps = append(laps, time.Second)
+
+	stats := hrtime.NewStats(laps)
+	if stats.Count != 101 || stats.Minimum != time.Microsecond || stats.Maximum != time.Second {
+		t.Errorf("invalid count or range:\n%v", stats)
+	}
+	if stats.P50 != 51*time.Microsecond {
+		t.Errorf("expected p50 51µs, got %v", stats.P50)
+	}
+	if got := stats.Percentile(0.005); got != 1500*time.Nanosecond {
+		t.Errorf("expected interpolated 1.5µs, got %v", got)
+	}
+	if stats.HighOutliers != 1 || stats.LowOutliers != 0 {
+		t.Errorf("expected a single high outlier, got %d low %d high", stats.LowOutliers, stats.HighOutliers)
+	}
+	if stats.StdDev <= 0 {
+		t.Errorf("expected positive stddev, got %v", stats.StdDev)
+	}
+	t.Log("\n" + stats.String())
+
+	empty := hrtime.NewStats(nil)
+	if empty.Count != 0 || empty.Percentile(0.5) != 0 {
+		t.Errorf("invalid empty stats: %v", empty)
+	}
+}
+
+func TestHistogramStats(t *testing.T) {
+	laps := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		laps = append(laps, time.Duration(i)*time.Microsecond)
+	}
+	exact := hrtime.NewStats(laps)
+
+	for _, backend := range []hrtime.HistogramBackend{hrtime.HistogramLinear, hrtime.HistogramHDR} {
+		hist := hrtime.NewDurationHistogram(laps, &hrtime.HistogramOptions{BinCount: 20, Backend: backend})
+		stats := hist.Stats()
+		if stats.Count != 100 || stats.Minimum != exact.Minimum || stats.Maximum != exact.Maximum {
+			t.Errorf("%v: invalid count or range:\n%v", backend, stats)
+		}
+		if relative := math.Abs(float64(stats.StdDev-exact.StdDev)) / float64(exact.StdDev); relative > 0.05 {
+			t.Errorf("%v: stddev %v too far from %v", backend, stats.StdDev, exact.StdDev)
+		}
+		if p := stats.Percentile(0.25); p < 20*time.Microsecond || p > 31*time.Microsecond {
+			t.Errorf("%v: p25 %v too far from %v", backend, p, exact.Percentile(0.25))
+		}
+	}
+	if empty := hrtime.NewHDRHistogram(2).Stats(); empty.Count != 0 || empty.Percentile(0.5) != 0 {
+		t.Errorf("invalid empty stats: %v", empty)
+	}
+	if empty := hrtime.NewDurationHistogram(nil, nil).Stats(); empty.Count != 0 || empty.StdDev != 0 {
+		t.Errorf("invalid empty stats: %v", empty)
+	}
+}
@@ -1,6 +1,7 @@
 package hrtime
 
 import (
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,28 +24,77 @@ type Stopwatch struct {
 	lapsMeasured int32
 	spans        []Span
 	wait         sync.Mutex
+	opts         options
+
+	// mu protects misused.
+	mu sync.Mutex
+	// misused is reported by Err, when the stopwatch was misused.
+	misused error
 }
 
 // NewStopwatch creates a new concurrent benchmark using Now
-func NewStopwatch(count int) *Stopwatch {
+//
+// Only WithClock, WithClockMitigation and WithMisusePolicy options apply.
+// WithWarmup, WithTimestamps, WithSampling, WithReservoir, WithCollector,
+// WithCallCounts, WithMemStats, WithGCPauses and WithOverheadSubtraction
+// are not supported and using them is reported as misuse, other options
+// are ignored.
+func NewStopwatch(count int, opts ...Option) *Stopwatch {
+	config := newOptions(opts)
 	if count <= 0 {
-		panic("must have count at least 1")
+		return newMisusedStopwatch(config, errors.Join(config.misused, config.misuse("must have count at least 1")))
 	}
 
 	bench := &Stopwatch{
 		nextLap: 0,
 		spans:   make([]Span, count),
+		opts:    config,
+		misused: config.stopwatchMisused(),
 	}
 	// lock mutex to ensure Wait() blocks until finalize is called
 	bench.wait.Lock()
 	return bench
 }
 
+// stopwatchMisused reports invalid options and options not supported by stopwatches.
+func (opts *options) stopwatchMisused() error {
+	if opts.warmup > 0 || opts.timestamps || opts.sampled() || opts.collectors != nil || opts.memStats || opts.gcPauses || opts.subtract {
+		return errors.Join(opts.misused, opts.misuse("stopwatch does not support warmup, timestamps, sampling, collectors, memory stats, GC pauses and overhead subtraction"))
+	}
+	return opts.misused
+}
+
+// newMisusedStopwatch creates a completed stopwatch without spans reporting err.
+func newMisusedStopwatch(opts options, err error) *Stopwatch {
+	return &Stopwatch{opts: opts, misused: err}
+}
+
 // mustBeCompleted checks whether measurement has been completed.
-func (bench *Stopwatch) mustBeCompleted() {
+//
+// Under MisuseError policy it returns an empty completed stopwatch
+// reporting the misuse instead of panicking, otherwise it returns bench.
+func (bench *Stopwatch) mustBeCompleted() *Stopwatch {
 	if int(atomic.LoadInt32(&bench.lapsMeasured)) < len(bench.spans) {
-		panic("benchmarking incomplete")
+		return newMisusedStopwatch(bench.opts, bench.opts.misuse("benchmarking incomplete"))
 	}
+	return bench
+}
+
+// misuse reports misuse of the stopwatch, it's reported by Err
+// under MisuseError policy.
+func (bench *Stopwatch) misuse(message string) {
+	err := bench.opts.misuse(message)
+	bench.mu.Lock()
+	bench.misused = errors.Join(bench.misused, err)
+	bench.mu.Unlock()
+}
+
+// Err returns the misuse of the stopwatch, including unsupported options,
+// reported under MisuseError policy.
+func (bench *Stopwatch) Err() error {
+	bench.mu.Lock()
+	defer bench.mu.Unlock()
+	return bench.misused
 }
 
 // Start starts measuring a new lap.
@@ -54,10 +104,10 @@ func (bench *Stopwatch) mustBeCompleted() {
 // Call to Stop with -1 is ignored.
 func (bench *Stopwatch) Start() int32 {
 	lap := atomic.AddInt32(&bench.nextLap, 1) - 1
-	if int(lap) > len(bench.spans) {
+	if int(lap) >= len(bench.spans) {
 		return -1
 	}
-	bench.spans[lap].Start = Now()
+	bench.spans[lap].Start = bench.opts.now()
 	return lap
 }
 
@@ -68,13 +118,13 @@ func (bench *Stopwatch) Stop(lap int32) {
 	if lap < 0 {
 		return
 	}
-	bench.spans[lap].Finish = Now()
+	bench.spans[lap].Finish = bench.opts.now()
 
 	lapsMeasured := atomic.AddInt32(&bench.lapsMeasured, 1)
 	if int(lapsMeasured) == len(bench.spans) {
 		bench.finalize()
 	} else if int(lapsMeasured) > len(bench.spans) {
-		panic("stop called too many times")
+		bench.misuse("stop called too many times")
 	}
 }
 
@@ -94,13 +144,13 @@ func (bench *Stopwatch) Wait() {
 
 // Spans returns measured time-spans.
 func (bench *Stopwatch) Spans() []Span {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 	return append(bench.spans[:0:0], bench.spans...)
 }
 
 // Durations returns measured durations.
 func (bench *Stopwatch) Durations() []time.Duration {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	durations := make([]time.Duration, len(bench.spans))
 	for i, span := range bench.spans {
@@ -116,7 +166,7 @@ func (bench *Stopwatch) Durations() []time.Duration {
 // 99.9 percentile as the last bucket range. However, for a nicer output
 // it might choose a larger value.
 func (bench *Stopwatch) Histogram(binCount int) *Histogram {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	opts := defaultOptions
 	opts.BinCount = binCount
@@ -129,7 +179,7 @@ func (bench *Stopwatch) Histogram(binCount int) *Histogram {
 // It creates binCount bins to distribute the data and uses the
 // maximum as the last bucket.
 func (bench *Stopwatch) HistogramClamp(binCount int, min, max time.Duration) *Histogram {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	durations := make([]time.Duration, 0, len(bench.spans))
 	for _, span := range bench.spans {
@@ -148,3 +198,14 @@ func (bench *Stopwatch) HistogramClamp(binCount int, min, max time.Duration) *Hi
 
 	return NewDurationHistogram(durations, &opts)
 }
+
+// HistogramClampPercentile creates an histogram of all the durations clamping minimum time
+// and the maximum to the percentile of the data.
+//
+// Unlike HistogramClamp, the maximum adapts to the measurements, e.g. using 0.995
+// keeps outliers in the last bucket without hardcoding a maximum per machine.
+// Percentile must be in range (0, 1].
+func (bench *Stopwatch) HistogramClampPercentile(binCount int, min time.Duration, percentile float64) *Histogram {
+	bench = bench.mustBeCompleted()
+	return newPercentileHistogram(clampNanos(durationNanos(bench.Durations()), min), binCount, percentile)
+}
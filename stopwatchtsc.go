@@ -1,6 +1,7 @@
 package hrtime
 
 import (
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,28 +25,69 @@ type StopwatchTSC struct {
 	lapsMeasured int32
 	spans        []SpanTSC
 	wait         sync.Mutex
+	opts         options
+
+	// mu protects misused.
+	mu sync.Mutex
+	// misused is reported by Err, when the stopwatch was misused.
+	misused error
 }
 
 // NewStopwatchTSC creates a new concurrent benchmark using TSC
-func NewStopwatchTSC(count int) *StopwatchTSC {
+//
+// Only WithClock, WithClockMitigation and WithMisusePolicy options apply.
+// WithWarmup, WithTimestamps, WithSampling, WithReservoir, WithCollector,
+// WithCallCounts, WithMemStats, WithGCPauses and WithOverheadSubtraction
+// are not supported and using them is reported as misuse, other options
+// are ignored.
+func NewStopwatchTSC(count int, opts ...Option) *StopwatchTSC {
+	config := newOptions(opts)
 	if count <= 0 {
-		panic("must have count at least 1")
+		return newMisusedStopwatchTSC(config, errors.Join(config.misused, config.misuse("must have count at least 1")))
 	}
 
 	bench := &StopwatchTSC{
 		nextLap: 0,
 		spans:   make([]SpanTSC, count),
+		opts:    config,
+		misused: config.stopwatchMisused(),
 	}
 	// lock mutex to ensure Wait() blocks until finalize is called
 	bench.wait.Lock()
 	return bench
 }
 
+// newMisusedStopwatchTSC creates a completed stopwatch without spans reporting err.
+func newMisusedStopwatchTSC(opts options, err error) *StopwatchTSC {
+	return &StopwatchTSC{opts: opts, misused: err}
+}
+
 // mustBeCompleted checks whether measurement has been completed.
-func (bench *StopwatchTSC) mustBeCompleted() {
+//
+// Under MisuseError policy it returns an empty completed stopwatch
+// reporting the misuse instead of panicking, otherwise it returns bench.
+func (bench *StopwatchTSC) mustBeCompleted() *StopwatchTSC {
 	if int(atomic.LoadInt32(&bench.lapsMeasured)) < len(bench.spans) {
-		panic("benchmarking incomplete")
+		return newMisusedStopwatchTSC(bench.opts, bench.opts.misuse("benchmarking incomplete"))
 	}
+	return bench
+}
+
+// misuse reports misuse of the stopwatch, it's reported by Err
+// under MisuseError policy.
+func (bench *StopwatchTSC) misuse(message string) {
+	err := bench.opts.misuse(message)
+	bench.mu.Lock()
+	bench.misused = errors.Join(bench.misused, err)
+	bench.mu.Unlock()
+}
+
+// Err returns the misuse of the stopwatch, including unsupported options,
+// reported under MisuseError policy.
+func (bench *StopwatchTSC) Err() error {
+	bench.mu.Lock()
+	defer bench.mu.Unlock()
+	return bench.misused
 }
 
 // Start starts measuring a new lap.
@@ -55,10 +97,10 @@ func (bench *StopwatchTSC) mustBeCompleted() {
 // Call to Stop with -1 is ignored.
 func (bench *StopwatchTSC) Start() int32 {
 	lap := atomic.AddInt32(&bench.nextLap, 1) - 1
-	if int(lap) > len(bench.spans) {
+	if int(lap) >= len(bench.spans) {
 		return -1
 	}
-	bench.spans[lap].Start = TSC()
+	bench.spans[lap].Start = bench.opts.tsc()
 	return lap
 }
 
@@ -69,13 +111,13 @@ func (bench *StopwatchTSC) Stop(lap int32) {
 	if lap < 0 {
 		return
 	}
-	bench.spans[lap].Finish = TSC()
+	bench.spans[lap].Finish = bench.opts.tsc()
 
 	lapsMeasured := atomic.AddInt32(&bench.lapsMeasured, 1)
 	if int(lapsMeasured) == len(bench.spans) {
 		bench.finalize()
 	} else if int(lapsMeasured) > len(bench.spans) {
-		panic("stop called too many times")
+		bench.misuse("stop called too many times")
 	}
 }
 
@@ -95,13 +137,13 @@ func (bench *StopwatchTSC) Wait() {
 
 // Spans returns measured time-spans.
 func (bench *StopwatchTSC) Spans() []SpanTSC {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 	return append(bench.spans[:0:0], bench.spans...)
 }
 
 // ApproxDurations returns measured durations.
 func (bench *StopwatchTSC) ApproxDurations() []time.Duration {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	durations := make([]time.Duration, len(bench.spans))
 	for i, span := range bench.spans {
@@ -117,7 +159,7 @@ func (bench *StopwatchTSC) ApproxDurations() []time.Duration {
 // 99.9 percentile as the last bucket range. However, for a nicer output
 // it might choose a larger value.
 func (bench *StopwatchTSC) Histogram(binCount int) *Histogram {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	opts := defaultOptions
 	opts.BinCount = binCount
@@ -130,7 +172,7 @@ func (bench *StopwatchTSC) Histogram(binCount int) *Histogram {
 // It creates binCount bins to distribute the data and uses the
 // maximum as the last bucket.
 func (bench *StopwatchTSC) HistogramClamp(binCount int, min, max time.Duration) *Histogram {
-	bench.mustBeCompleted()
+	bench = bench.mustBeCompleted()
 
 	durations := make([]time.Duration, 0, len(bench.spans))
 	for _, span := range bench.spans {
@@ -149,3 +191,14 @@ func (bench *StopwatchTSC) HistogramClamp(binCount int, min, max time.Duration)
 
 	return NewDurationHistogram(durations, &opts)
 }
+
+// HistogramClampPercentile creates an histogram of all the durations clamping minimum time
+// and the maximum to the percentile of the data.
+//
+// Unlike HistogramClamp, the maximum adapts to the measurements, e.g. using 0.995
+// keeps outliers in the last bucket without hardcoding a maximum per machine.
+// Percentile must be in range (0, 1].
+func (bench *StopwatchTSC) HistogramClampPercentile(binCount int, min time.Duration, percentile float64) *Histogram {
+	bench = bench.mustBeCompleted()
+	return newPercentileHistogram(clampNanos(durationNanos(bench.ApproxDurations()), min), binCount, percentile)
+}
@@ -0,0 +1,226 @@
+package hrtime
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+)
+
+// Suite runs a set of named benchmarks one after another.
+type Suite struct {
+	entries []suiteEntry
+}
+
+// suiteEntry is a single benchmark in a suite.
+type suiteEntry struct {
+	name  string
+	count int
+	fn    func()
+	// setup prepares fn and teardown before every run, when not nil.
+	setup func() (fn, teardown func())
+}
+
+// SuiteResult is the result of a single benchmark in a suite.
+type SuiteResult struct {
+	Name      string
+	Benchmark *Benchmark
+	// Cooldown is the time spent cooling down before this benchmark.
+	Cooldown time.Duration
+	// Metadata describes how the benchmark was run, e.g. with WithGCDisabled.
+	Metadata map[string]string
+}
+
+// SuiteOption configures Suite.Run.
+type SuiteOption func(*suiteConfig)
+
+// suiteConfig contains configuration for running a suite.
+type suiteConfig struct {
+	cooldown        time.Duration
+	thermalCooldown time.Duration
+	gcDisabled      bool
+	gcCollect       bool
+	gcPercent       int
+	gcPercentSet    bool
+	ballast         int
+	load            *LoadOptions
+	filter          nameFilter
+	exporters       []Exporter
+	// err is returned by Run, when an option is invalid.
+	err error
+}
+
+// WithCooldown sleeps for d between benchmarks.
+//
+// It prevents one hot benchmark from degrading the next one on thermally
+// limited machines.
+func WithCooldown(d time.Duration) SuiteOption {
+	return func(config *suiteConfig) { config.cooldown = d }
+}
+
+// WithThermalCooldown waits between benchmarks until CPU temperature
+// returns close to the temperature before the suite started.
+//
+// It waits at most maxWait. When CPU temperature is not readable,
+// the option has no effect.
+func WithThermalCooldown(maxWait time.Duration) SuiteOption {
+	return func(config *suiteConfig) { config.thermalCooldown = maxWait }
+}
+
+// NewSuite creates an empty suite.
+func NewSuite() *Suite {
+	return &Suite{}
+}
+
+// Add adds a benchmark calling fn count times.
+func (suite *Suite) Add(name string, count int, fn func()) {
+	if count <= 0 {
+		panic("must have count at least 1")
+	}
+	suite.entries = append(suite.entries, suiteEntry{name: name, count: count, fn: fn})
+}
+
+// AddSetup adds a benchmark calling fn returned by setup count times.
+//
+// setup is called before every run of the benchmark, outside of the
+// measurement, and teardown after the run, even when the run is canceled
+// or fn panics. Both are called from the goroutine calling fn, which
+// allows e.g. locking it to an OS thread for the duration of the run.
+func (suite *Suite) AddSetup(name string, count int, setup func() (fn, teardown func())) {
+	if count <= 0 {
+		panic("must have count at least 1")
+	}
+	suite.entries = append(suite.entries, suiteEntry{name: name, count: count, setup: setup})
+}
+
+// Run runs all benchmarks in the order they were added.
+// When using WithFilter, benchmarks that do not match are skipped.
+//
+// When using WithExporter, each result is exported as soon as it completes.
+//
+// When ctx is canceled, Run returns results of completed benchmarks with ctx.Err().
+// A benchmark interrupted by cancellation is included with the laps measured
+// so far, see Benchmark.Canceled.
+func (suite *Suite) Run(ctx context.Context, opts ...SuiteOption) ([]SuiteResult, error) {
+	var config suiteConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.err != nil {
+		return nil, config.err
+	}
+
+	baseline, thermal := cpuTemperature()
+
+	restoreGC := config.applyGC()
+	defer restoreGC()
+
+	var metadata map[string]string
+	if config.load != nil {
+		load := StartLoad(*config.load)
+		defer load.Stop()
+		metadata = load.Metadata()
+	}
+	for key, value := range config.gcMetadata() {
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[key] = value
+	}
+
+	var results []SuiteResult
+	var exportErr error
+	for _, entry := range suite.entries {
+		if !config.filter.Match(entry.name) {
+			continue
+		}
+
+		var cooldown time.Duration
+		if len(results) > 0 {
+			start := Now()
+			if err := suite.cooldown(ctx, &config, baseline, thermal); err != nil {
+				return results, errors.Join(err, exportErr)
+			}
+			cooldown = Since(start)
+		}
+		if err := ctx.Err(); err != nil {
+			return results, errors.Join(err, exportErr)
+		}
+
+		bench := entry.run(ctx, &config)
+
+		results = append(results, SuiteResult{
+			Name:      entry.name,
+			Benchmark: bench,
+			Cooldown:  cooldown,
+			Metadata:  maps.Clone(metadata),
+		})
+		exportErr = errors.Join(exportErr, config.export(&results[len(results)-1]))
+		if bench.Canceled() {
+			return results, errors.Join(ctx.Err(), exportErr)
+		}
+	}
+	return results, exportErr
+}
+
+// run measures the benchmark of entry.
+func (entry *suiteEntry) run(ctx context.Context, config *suiteConfig) *Benchmark {
+	fn := entry.fn
+	if entry.setup != nil {
+		var teardown func()
+		fn, teardown = entry.setup()
+		if teardown != nil {
+			defer teardown()
+		}
+	}
+
+	bench := NewBenchmark(entry.count)
+	if config.gcDisabled {
+		// deferred, otherwise a panicking fn leaves GC disabled
+		defer DisableGC(config.gcCollect)()
+	}
+	for bench.NextCtx(ctx) {
+		fn()
+	}
+	return bench
+}
+
+// cooldown waits between benchmarks according to config.
+func (suite *Suite) cooldown(ctx context.Context, config *suiteConfig, baseline float64, thermal bool) error {
+	if config.cooldown > 0 {
+		if err := sleepContext(ctx, config.cooldown); err != nil {
+			return err
+		}
+	}
+
+	if config.thermalCooldown > 0 && thermal {
+		// allow small deviation, since temperature readings fluctuate
+		const tolerance = 2.0
+		const poll = 100 * time.Millisecond
+
+		deadline := Now() + config.thermalCooldown
+		for Now() < deadline {
+			current, ok := cpuTemperature()
+			if !ok || current <= baseline+tolerance {
+				break
+			}
+			if err := sleepContext(ctx, poll); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sleepContext sleeps for d or until ctx is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+package hrtime
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WithFilter runs only benchmarks whose names match pattern, similarly to go test -run.
+//
+// Names are hierarchical, delimited by slashes, e.g. "codec/encode/json".
+// The pattern is split by slashes outside of brackets and parentheses,
+// and every element is an unanchored regular expression matching the
+// corresponding name element. Name elements beyond the pattern match
+// any value, hence "codec" runs everything below "codec/".
+//
+// Run returns an error when pattern is not a valid regular expression.
+func WithFilter(pattern string) SuiteOption {
+	filter, err := newNameFilter(pattern)
+	return func(config *suiteConfig) {
+		config.filter = filter
+		if err != nil {
+			config.err = fmt.Errorf("invalid filter %q: %w", pattern, err)
+		}
+	}
+}
+
+// Group returns a prefix for adding benchmarks named "prefix/name" to the suite.
+func (suite *Suite) Group(prefix string) *SuiteGroup {
+	return &SuiteGroup{suite: suite, prefix: prefix}
+}
+
+// Names returns names of benchmarks in the order they were added.
+func (suite *Suite) Names() []string {
+	names := make([]string, len(suite.entries))
+	for i, entry := range suite.entries {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// SuiteGroup adds benchmarks with a common name prefix to a Suite.
+type SuiteGroup struct {
+	suite  *Suite
+	prefix string
+}
+
+// Add adds a benchmark named "prefix/name" calling fn count times.
+func (group *SuiteGroup) Add(name string, count int, fn func()) {
+	group.suite.Add(group.prefix+"/"+name, count, fn)
+}
+
+// AddSetup adds a benchmark named "prefix/name", see Suite.AddSetup.
+func (group *SuiteGroup) AddSetup(name string, count int, setup func() (fn, teardown func())) {
+	group.suite.AddSetup(group.prefix+"/"+name, count, setup)
+}
+
+// Group returns a nested group with prefix "prefix/name".
+func (group *SuiteGroup) Group(name string) *SuiteGroup {
+	return &SuiteGroup{suite: group.suite, prefix: group.prefix + "/" + name}
+}
+
+// nameFilter matches hierarchical names element by element.
+type nameFilter []*regexp.Regexp
+
+// newNameFilter compiles every element of pattern.
+func newNameFilter(pattern string) (nameFilter, error) {
+	var filter nameFilter
+	for _, elem := range splitPattern(pattern) {
+		re, err := regexp.Compile(elem)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, re)
+	}
+	return filter, nil
+}
+
+// Match returns whether name matches the filter, an empty filter matches everything.
+func (filter nameFilter) Match(name string) bool {
+	elems := strings.Split(name, "/")
+	if len(elems) < len(filter) {
+		return false
+	}
+	for i, re := range filter {
+		if !re.MatchString(elems[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPattern splits pattern at slashes outside of brackets and parentheses.
+func splitPattern(pattern string) []string {
+	var elems []string
+	depth, brackets, start := 0, false, 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			brackets = true
+		case ']':
+			brackets = false
+		case '(':
+			if !brackets {
+				depth++
+			}
+		case ')':
+			if !brackets {
+				depth--
+			}
+		case '/':
+			if depth == 0 && !brackets {
+				elems = append(elems, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(elems, pattern[start:])
+}
@@ -0,0 +1,167 @@
+package hrtime_test
+
+import (
+	"context"
+	"runtime/debug"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestSuite(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("sleep", 8, func() { time.Sleep(1000 * time.Nanosecond) })
+	suite.Add("empty", 8, func() {})
+
+	results, err := suite.Run(context.Background(), hrtime.WithCooldown(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Name != "empty" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[0].Cooldown != 0 || results[1].Cooldown < time.Millisecond {
+		t.Errorf("unexpected cooldowns %v %v", results[0].Cooldown, results[1].Cooldown)
+	}
+}
+
+func TestSuiteCanceled(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("empty", 8, func() {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := suite.Run(ctx)
+	if err == nil || len(results) != 0 {
+		t.Errorf("expected cancellation, got %v %v", results, err)
+	}
+}
+
+func TestSuiteGCDisabled(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("alloc", 8, func() { _ = make([]byte, 1024) })
+
+	results, err := suite.Run(context.Background(), hrtime.WithGCDisabled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata := results[0].Metadata; metadata["gc"] != "off" || metadata["gc.collect"] != "true" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+	percent := debug.SetGCPercent(-1)
+	debug.SetGCPercent(percent)
+	if percent < 0 {
+		t.Errorf("garbage collector was not restored")
+	}
+}
+
+func TestSuiteGCPacing(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("empty", 8, func() {})
+
+	before := debug.SetGCPercent(-1)
+	debug.SetGCPercent(before)
+
+	results, err := suite.Run(context.Background(), hrtime.WithGCPercent(400), hrtime.WithBallast(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata := results[0].Metadata; metadata["gc.percent"] != "400" || metadata["gc.ballast"] != "1048576" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+
+	after := debug.SetGCPercent(-1)
+	debug.SetGCPercent(after)
+	if after != before {
+		t.Errorf("gc percent not restored: %d != %d", after, before)
+	}
+}
+
+func TestSuiteLoad(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("empty", 8, func() {})
+
+	load := hrtime.LoadOptions{Goroutines: 2, Duty: 0.5, Memory: 1 << 16, Period: time.Millisecond}
+	results, err := suite.Run(context.Background(), hrtime.WithLoad(load), hrtime.WithGCPercent(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := results[0].Metadata
+	if metadata["load.goroutines"] != "2" || metadata["load.duty"] != "0.5" || metadata["gc.percent"] != "200" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+}
+
+func TestSuiteFilter(t *testing.T) {
+	suite := hrtime.NewSuite()
+	codec := suite.Group("codec")
+	codec.Add("encode/json", 2, func() {})
+	codec.Add("encode/gob", 2, func() {})
+	codec.Add("decode/json", 2, func() {})
+	suite.Add("codec", 2, func() {})
+
+	run := func(pattern string) []string {
+		t.Helper()
+		results, err := suite.Run(context.Background(), hrtime.WithFilter(pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Name)
+		}
+		return names
+	}
+
+	if got := run("codec/encode/.*"); !slices.Equal(got, []string{"codec/encode/json", "codec/encode/gob"}) {
+		t.Errorf("unexpected benchmarks %v", got)
+	}
+	if got := run("codec/(en|de)code/json"); !slices.Equal(got, []string{"codec/encode/json", "codec/decode/json"}) {
+		t.Errorf("unexpected benchmarks %v", got)
+	}
+	if got := run("//gob"); !slices.Equal(got, []string{"codec/encode/gob"}) {
+		t.Errorf("unexpected benchmarks %v", got)
+	}
+	if got := run(""); len(got) != 4 {
+		t.Errorf("expected all benchmarks, got %v", got)
+	}
+
+	if _, err := suite.Run(context.Background(), hrtime.WithFilter("codec/(")); err == nil {
+		t.Errorf("expected error for invalid filter")
+	}
+}
+
+func TestSuiteSetup(t *testing.T) {
+	setups, teardowns, calls := 0, 0, 0
+	suite := hrtime.NewSuite()
+	suite.AddSetup("setup", 4, func() (fn, teardown func()) {
+		setups++
+		return func() { calls++ }, func() { teardowns++ }
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := suite.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if setups != 2 || teardowns != 2 || calls != 8 {
+		t.Errorf("expected setup and teardown every run, got %d setups, %d teardowns, %d calls", setups, teardowns, calls)
+	}
+}
+
+func TestSuiteGCDisabledPanic(t *testing.T) {
+	suite := hrtime.NewSuite()
+	suite.Add("panic", 4, func() { panic("failed") })
+
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = suite.Run(context.Background(), hrtime.WithGCDisabled(false))
+	}()
+	percent := debug.SetGCPercent(100)
+	debug.SetGCPercent(percent)
+	if percent < 0 {
+		t.Errorf("GC left disabled after panic")
+	}
+}
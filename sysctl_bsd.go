@@ -0,0 +1,36 @@
+//go:build freebsd || netbsd || openbsd
+// +build freebsd netbsd openbsd
+
+package hrtime
+
+import (
+	"encoding/binary"
+	"syscall"
+)
+
+// sysctlUint64 reads a numeric sysctl value.
+//
+// syscall.Sysctl returns raw bytes and strips a trailing zero byte,
+// hence the value is zero-padded before decoding.
+func sysctlUint64(name string) (uint64, bool) {
+	value, err := syscall.Sysctl(name)
+	if err != nil || len(value) == 0 || len(value) > 8 {
+		return 0, false
+	}
+
+	var buf [8]byte
+	copy(buf[:], value)
+	return binary.LittleEndian.Uint64(buf[:]), true
+}
+
+// kernelTSCFrequency returns TSC frequency in Hz as calibrated by the kernel.
+//
+// FreeBSD and NetBSD expose it as machdep.tsc_freq, OpenBSD as machdep.tscfreq.
+func kernelTSCFrequency() uint64 {
+	for _, name := range []string{"machdep.tsc_freq", "machdep.tscfreq"} {
+		if freq, ok := sysctlUint64(name); ok && freq > 0 {
+			return freq
+		}
+	}
+	return 0
+}
@@ -0,0 +1,7 @@
+//go:build !freebsd && !netbsd && !openbsd
+// +build !freebsd,!netbsd,!openbsd
+
+package hrtime
+
+// kernelTSCFrequency returns 0, since the kernel doesn't expose TSC frequency.
+func kernelTSCFrequency() uint64 { return 0 }
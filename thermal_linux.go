@@ -0,0 +1,31 @@
+package hrtime
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cpuTemperature returns the highest thermal zone temperature in Celsius.
+func cpuTemperature() (float64, bool) {
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*/temp")
+
+	found := false
+	highest := 0.0
+	for _, zone := range zones {
+		data, err := os.ReadFile(zone)
+		if err != nil {
+			continue
+		}
+		millis, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			continue
+		}
+		if celsius := millis / 1000; !found || celsius > highest {
+			highest = celsius
+			found = true
+		}
+	}
+	return highest, found
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+// +build !linux
+
+package hrtime
+
+// cpuTemperature returns false, since temperature is not readable.
+func cpuTemperature() (float64, bool) { return 0, false }
@@ -0,0 +1,68 @@
+package hrtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// Throttling contains cgroup CPU throttling counters.
+type Throttling struct {
+	// Periods is the number of enforcement periods that have elapsed.
+	Periods int64
+	// Throttled is the number of periods the cgroup was throttled.
+	Throttled int64
+	// ThrottledTime is the total time the cgroup was throttled.
+	ThrottledTime time.Duration
+}
+
+// ReadThrottling reads current cgroup CPU throttling counters.
+//
+// It returns false when counters are not available, e.g. on non-Linux
+// platforms or when the cpu controller is not mounted.
+func ReadThrottling() (Throttling, bool) { return readThrottling() }
+
+// Sub returns the difference of counters between throttling and prev.
+func (throttling Throttling) Sub(prev Throttling) Throttling {
+	return Throttling{
+		Periods:       throttling.Periods - prev.Periods,
+		Throttled:     throttling.Throttled - prev.Throttled,
+		ThrottledTime: throttling.ThrottledTime - prev.ThrottledTime,
+	}
+}
+
+// Occurred returns whether any throttling occurred.
+func (throttling Throttling) Occurred() bool {
+	return throttling.Throttled > 0 || throttling.ThrottledTime > 0
+}
+
+// String returns a short annotation of throttling.
+func (throttling Throttling) String() string {
+	return fmt.Sprintf("throttled %d of %d periods (%v)", throttling.Throttled, throttling.Periods, throttling.ThrottledTime)
+}
+
+// NewThrottlingSampler creates a sampler recording cumulative
+// throttled time in nanoseconds.
+//
+// The samples can be used with Correlate or ThrottledWindows
+// to find which laps were affected by throttling.
+func NewThrottlingSampler(interval time.Duration) *Sampler {
+	return NewSampler(interval, func() float64 {
+		throttling, _ := readThrottling()
+		return float64(throttling.ThrottledTime)
+	})
+}
+
+// ThrottledWindows returns time windows between consecutive samples
+// where cumulative throttled time increased.
+func ThrottledWindows(samples []Sample) []Span {
+	var windows []Span
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Value > samples[i-1].Value {
+			windows = append(windows, Span{
+				Start:  samples[i-1].Time,
+				Finish: samples[i].Time,
+			})
+		}
+	}
+	return windows
+}
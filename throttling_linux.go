@@ -0,0 +1,45 @@
+package hrtime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readThrottling reads throttling counters from cgroup cpu.stat.
+func readThrottling() (Throttling, bool) {
+	content, v2, ok := readCgroupCPUFile("cpu.stat", "cpu.stat")
+	if !ok {
+		return Throttling{}, false
+	}
+
+	var throttling Throttling
+	found := false
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "nr_periods":
+			throttling.Periods = value
+			found = true
+		case "nr_throttled":
+			throttling.Throttled = value
+		case "throttled_usec":
+			if v2 {
+				throttling.ThrottledTime = time.Duration(value) * time.Microsecond
+			}
+		case "throttled_time":
+			if !v2 {
+				throttling.ThrottledTime = time.Duration(value)
+			}
+		}
+	}
+	return throttling, found
+}
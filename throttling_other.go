@@ -0,0 +1,7 @@
+//go:build !linux
+// +build !linux
+
+package hrtime
+
+// readThrottling returns false, since cgroups are not available.
+func readThrottling() (Throttling, bool) { return Throttling{}, false }
@@ -0,0 +1,30 @@
+package hrtime_test
+
+import (
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestThrottledWindows(t *testing.T) {
+	samples := []hrtime.Sample{
+		{Time: 0, Value: 0},
+		{Time: 10, Value: 0},
+		{Time: 20, Value: 5},
+		{Time: 30, Value: 5},
+	}
+
+	windows := hrtime.ThrottledWindows(samples)
+	if len(windows) != 1 || windows[0].Start != 10 || windows[0].Finish != 20 {
+		t.Errorf("unexpected windows %v", windows)
+	}
+}
+
+func TestReadThrottling(t *testing.T) {
+	before, ok := hrtime.ReadThrottling()
+	if !ok {
+		t.Skip("throttling counters not available")
+	}
+	after, _ := hrtime.ReadThrottling()
+	t.Log(after.Sub(before))
+}
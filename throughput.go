@@ -0,0 +1,76 @@
+package hrtime
+
+import (
+	"fmt"
+	"time"
+)
+
+// Throughput describes the rate of laps and processed bytes.
+//
+// It's computed from the total elapsed time of the benchmark,
+// similarly to go test -bench.
+type Throughput struct {
+	// Laps is the number of laps.
+	Laps int
+	// Bytes is the number of bytes processed by a single lap.
+	Bytes int64
+	// Elapsed is the total elapsed time.
+	Elapsed time.Duration
+}
+
+// OpsPerSecond returns the number of laps per second.
+func (throughput Throughput) OpsPerSecond() float64 {
+	if throughput.Elapsed <= 0 {
+		return 0
+	}
+	return float64(throughput.Laps) / throughput.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns the number of processed bytes per second.
+func (throughput Throughput) BytesPerSecond() float64 {
+	return float64(throughput.Bytes) * throughput.OpsPerSecond()
+}
+
+// MBPerSecond returns the number of processed megabytes (1e6 bytes) per second.
+func (throughput Throughput) MBPerSecond() float64 {
+	return throughput.BytesPerSecond() / 1e6
+}
+
+// String returns throughput as "ops/s" and "MB/s", when bytes are set.
+func (throughput Throughput) String() string {
+	if throughput.Bytes > 0 {
+		return fmt.Sprintf("%.0f ops/s\t%.2f MB/s", throughput.OpsPerSecond(), throughput.MBPerSecond())
+	}
+	return fmt.Sprintf("%.0f ops/s", throughput.OpsPerSecond())
+}
+
+// SetBytes records the number of bytes processed by a single lap,
+// similarly to testing.B.SetBytes.
+func (bench *Benchmark) SetBytes(n int64) { bench.bytes = n }
+
+// Throughput returns the rate of laps and bytes over the total elapsed time.
+func (bench *Benchmark) Throughput() Throughput {
+	bench = bench.mustBeCompleted()
+	return bench.analysis().Throughput()
+}
+
+// SetBytes records the number of bytes processed by a single lap,
+// similarly to testing.B.SetBytes.
+func (bench *BenchmarkTSC) SetBytes(n int64) { bench.bytes = n }
+
+// Throughput returns the rate of laps and bytes over the total elapsed time.
+//
+// Elapsed time is converted using the approximate TSC frequency.
+func (bench *BenchmarkTSC) Throughput() Throughput {
+	bench = bench.mustBeCompleted()
+	return bench.Result().Throughput()
+}
+
+// Throughput returns the rate of laps and bytes over the elapsed time.
+func (result *Result) Throughput() Throughput {
+	return Throughput{
+		Laps:    len(result.laps),
+		Bytes:   result.bytes,
+		Elapsed: result.stop - result.start,
+	}
+}
@@ -0,0 +1,31 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestBenchmarkThroughput(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(100, hrtime.WithClock(clock))
+	bench.SetBytes(1000)
+	for bench.Next() {
+		clock.Advance(time.Millisecond)
+	}
+
+	throughput := bench.Throughput()
+	if throughput.OpsPerSecond() != 1000 || throughput.MBPerSecond() != 1 {
+		t.Errorf("expected 1000 ops/s and 1 MB/s, got %v", throughput)
+	}
+
+	var line strings.Builder
+	if err := bench.WriteGoBench(&line, "Copy"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line.String(), "\t1.00 MB/s") {
+		t.Errorf("expected MB/s in %q", line.String())
+	}
+}
@@ -0,0 +1,172 @@
+package hrtime
+
+import (
+	"sync"
+	"time"
+)
+
+// TieredOptions configures TieredRecorder.
+type TieredOptions struct {
+	// Exact is how long laps are kept exactly, 5 minutes when zero.
+	Exact time.Duration
+	// Period is the time covered by a single sketch, 1 minute when zero.
+	Period time.Duration
+	// Retention is the maximum number of sketches, 60 when zero.
+	// Older sketches are merged into a single sketch.
+	Retention int
+	// SignificantDigits is the precision of sketches, 2 when zero.
+	SignificantDigits int
+}
+
+// TieredSketch contains laps of a single period collapsed into a histogram.
+type TieredSketch struct {
+	// Start is the beginning of the period.
+	Start time.Time
+	// Histogram contains laps recorded within the period.
+	Histogram *HDRHistogram
+}
+
+// TieredRecorder keeps exact laps for the recent past and collapses
+// older laps into per-period HDR histograms.
+//
+// It balances forensic detail of recent laps with bounded memory for
+// always-on recording: memory depends on Exact, Retention and the
+// recording rate, but not on the total recording time.
+type TieredRecorder struct {
+	mu   sync.Mutex
+	opts TieredOptions
+
+	// recent contains exact laps in order of recording, starting at head.
+	recent []tieredLap
+	head   int
+
+	sketches []TieredSketch
+	// expired contains laps of sketches beyond retention.
+	expired *HDRHistogram
+}
+
+// tieredLap is a lap with its finish time.
+type tieredLap struct {
+	at  time.Time
+	lap time.Duration
+}
+
+// NewTieredRecorder creates a recorder, when opts is nil default options are used.
+func NewTieredRecorder(opts *TieredOptions) *TieredRecorder {
+	var config TieredOptions
+	if opts != nil {
+		config = *opts
+	}
+	if config.Exact <= 0 {
+		config.Exact = 5 * time.Minute
+	}
+	if config.Period <= 0 {
+		config.Period = time.Minute
+	}
+	if config.Retention <= 0 {
+		config.Retention = 60
+	}
+	if config.SignificantDigits == 0 {
+		config.SignificantDigits = 2
+	}
+	return &TieredRecorder{
+		opts:    config,
+		expired: NewHDRHistogram(config.SignificantDigits),
+	}
+}
+
+// Record adds a duration that finished now.
+func (rec *TieredRecorder) Record(duration time.Duration) {
+	rec.RecordAt(time.Now(), duration)
+}
+
+// RecordAt adds a duration that finished at time at.
+//
+// Times are expected to be non-decreasing, laps older than Exact
+// relative to at are collapsed into sketches.
+func (rec *TieredRecorder) RecordAt(at time.Time, duration time.Duration) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.recent = append(rec.recent, tieredLap{at: at, lap: duration})
+	rec.collapse(at.Add(-rec.opts.Exact))
+}
+
+// collapse moves laps before cutoff into sketches, must be called with mu held.
+func (rec *TieredRecorder) collapse(cutoff time.Time) {
+	for rec.head < len(rec.recent) && rec.recent[rec.head].at.Before(cutoff) {
+		lap := rec.recent[rec.head]
+		rec.sketchAt(lap.at).RecordDuration(lap.lap)
+		rec.head++
+	}
+	// compact when more than half of the storage has been collapsed
+	if rec.head > 0 && rec.head >= len(rec.recent)/2 {
+		n := copy(rec.recent, rec.recent[rec.head:])
+		rec.recent = rec.recent[:n]
+		rec.head = 0
+	}
+
+	for len(rec.sketches) > rec.opts.Retention {
+		rec.expired.Merge(rec.sketches[0].Histogram)
+		rec.sketches = rec.sketches[1:]
+	}
+}
+
+// sketchAt returns the sketch for the period containing at.
+func (rec *TieredRecorder) sketchAt(at time.Time) *HDRHistogram {
+	start := at.Truncate(rec.opts.Period)
+	if n := len(rec.sketches); n > 0 && !start.After(rec.sketches[n-1].Start) {
+		return rec.sketches[n-1].Histogram
+	}
+	rec.sketches = append(rec.sketches, TieredSketch{
+		Start:     start,
+		Histogram: NewHDRHistogram(rec.opts.SignificantDigits),
+	})
+	return rec.sketches[len(rec.sketches)-1].Histogram
+}
+
+// Recent returns a copy of exactly kept laps, ordered from the oldest.
+func (rec *TieredRecorder) Recent() []time.Duration {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	laps := make([]time.Duration, 0, len(rec.recent)-rec.head)
+	for _, lap := range rec.recent[rec.head:] {
+		laps = append(laps, lap.lap)
+	}
+	return laps
+}
+
+// Sketches returns copies of retained sketches, ordered from the oldest.
+func (rec *TieredRecorder) Sketches() []TieredSketch {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	sketches := make([]TieredSketch, len(rec.sketches))
+	for i, sketch := range rec.sketches {
+		sketches[i] = TieredSketch{Start: sketch.Start, Histogram: NewHDRHistogram(rec.opts.SignificantDigits)}
+		sketches[i].Histogram.Merge(sketch.Histogram)
+	}
+	return sketches
+}
+
+// Total returns a histogram of all recorded laps, including the exact ones.
+func (rec *TieredRecorder) Total() *HDRHistogram {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	total := NewHDRHistogram(rec.opts.SignificantDigits)
+	total.Merge(rec.expired)
+	for _, sketch := range rec.sketches {
+		total.Merge(sketch.Histogram)
+	}
+	for _, lap := range rec.recent[rec.head:] {
+		total.RecordDuration(lap.lap)
+	}
+	return total
+}
+
+// Stats calculates summary statistics of exactly kept laps.
+func (rec *TieredRecorder) Stats() *Stats {
+	return NewStats(rec.Recent())
+}
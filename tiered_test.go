@@ -0,0 +1,39 @@
+package hrtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestTieredRecorder(t *testing.T) {
+	rec := hrtime.NewTieredRecorder(&hrtime.TieredOptions{
+		Exact:     time.Minute,
+		Period:    time.Minute,
+		Retention: 3,
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// one lap per second for 10 minutes
+	for i := 0; i < 600; i++ {
+		rec.RecordAt(start.Add(time.Duration(i)*time.Second), time.Duration(i+1)*time.Microsecond)
+	}
+
+	if recent := rec.Recent(); len(recent) != 61 || recent[60] != 600*time.Microsecond {
+		t.Errorf("expected laps of the last minute, got %d", len(recent))
+	}
+
+	sketches := rec.Sketches()
+	if len(sketches) != 3 {
+		t.Fatalf("expected 3 retained sketches, got %d", len(sketches))
+	}
+	if last := sketches[2]; !last.Start.Equal(start.Add(8*time.Minute)) || last.Histogram.Count() != 59 {
+		t.Errorf("unexpected last sketch at %v with %d laps", last.Start, last.Histogram.Count())
+	}
+
+	total := rec.Total()
+	if total.Count() != 600 || total.Max() != (600*time.Microsecond).Nanoseconds() {
+		t.Errorf("expected all laps in total, got %d", total.Count())
+	}
+}
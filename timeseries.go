@@ -0,0 +1,241 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TimePoint is a lap at the time it started.
+type TimePoint struct {
+	// Elapsed is the time since the start of the benchmark.
+	Elapsed time.Duration
+	Lap     time.Duration
+}
+
+// TimeSeries contains laps in the order they were measured.
+//
+// Aggregate histograms hide whether latency degrades during the run,
+// e.g. due to thermal throttling, cache pollution or growing GC pressure.
+type TimeSeries struct {
+	// Start is the wall-clock time of the benchmark start, it's zero
+	// unless the benchmark was created WithTimestamps.
+	Start  time.Time
+	Points []TimePoint
+}
+
+// TimeBucket summarizes laps started in [Start, End) of a time series.
+type TimeBucket struct {
+	Start time.Duration
+	End   time.Duration
+	Count int
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// NewTimeSeries creates a time series of consecutive laps.
+func NewTimeSeries(laps []time.Duration) *TimeSeries {
+	series := &TimeSeries{Points: make([]TimePoint, len(laps))}
+	var elapsed time.Duration
+	for i, lap := range laps {
+		series.Points[i] = TimePoint{Elapsed: elapsed, Lap: lap}
+		elapsed += lap
+	}
+	return series
+}
+
+// TimeSeries returns laps of a completed benchmark over time.
+//
+// Lap start times are exact when the benchmark was created WithTimestamps,
+// otherwise they are derived from the laps, see Spans.
+func (bench *Benchmark) TimeSeries() *TimeSeries {
+	bench = bench.mustBeCompleted()
+	series := &TimeSeries{Points: make([]TimePoint, len(bench.laps))}
+	if bench.timestamps != nil {
+		series.Start = bench.wallStop.Add(bench.start - bench.stop)
+	}
+	for i, span := range bench.Spans() {
+		series.Points[i] = TimePoint{Elapsed: span.Start - bench.start, Lap: bench.laps[i]}
+	}
+	return series
+}
+
+// TimeSeries returns laps of a completed benchmark over time, see Benchmark.TimeSeries.
+//
+// Counts are converted using Count.ApproxDuration.
+func (bench *BenchmarkTSC) TimeSeries() *TimeSeries {
+	bench = bench.mustBeCompleted()
+	series := &TimeSeries{Points: make([]TimePoint, len(bench.laps))}
+	if bench.timestamps != nil {
+		series.Start = bench.wallStop.Add((bench.start - bench.stop).ApproxDuration())
+	}
+	start := bench.start.ApproxDuration()
+	for i, span := range bench.Spans() {
+		series.Points[i] = TimePoint{Elapsed: span.Start - start, Lap: span.Duration()}
+	}
+	return series
+}
+
+// Duration returns the time from the start of the first lap to the end of the last lap.
+func (series *TimeSeries) Duration() time.Duration {
+	if len(series.Points) == 0 {
+		return 0
+	}
+	last := series.Points[len(series.Points)-1]
+	return last.Elapsed + max(last.Lap, 0)
+}
+
+// Buckets splits the series into count windows of equal duration.
+//
+// Windows without laps have zero Count.
+func (series *TimeSeries) Buckets(count int) []TimeBucket {
+	buckets, _ := series.buckets(count)
+	return buckets
+}
+
+// buckets returns windows of the series together with their laps.
+func (series *TimeSeries) buckets(count int) ([]TimeBucket, [][]time.Duration) {
+	if count <= 0 {
+		panic("must have count at least 1")
+	}
+
+	width := max(series.Duration()/time.Duration(count), 1)
+	buckets := make([]TimeBucket, count)
+	laps := make([][]time.Duration, count)
+	for i := range buckets {
+		buckets[i].Start = time.Duration(i) * width
+		buckets[i].End = buckets[i].Start + width
+	}
+	for _, point := range series.Points {
+		index := min(max(int(point.Elapsed/width), 0), count-1)
+		laps[index] = append(laps[index], point.Lap)
+	}
+	for i := range laps {
+		if len(laps[i]) == 0 {
+			continue
+		}
+		laps[i] = sortedDurations(laps[i])
+		buckets[i].Count = len(laps[i])
+		buckets[i].P50 = quantile(laps[i], 0.5)
+		buckets[i].P99 = quantile(laps[i], 0.99)
+		buckets[i].Max = laps[i][len(laps[i])-1]
+	}
+	return buckets, laps
+}
+
+// Drift returns the relative change of the median lap between
+// the first and the last tenth of the series, where 0.1 means that
+// laps at the end are 10% slower.
+func (series *TimeSeries) Drift() float64 {
+	buckets := series.Buckets(10)
+	first, last := buckets[0], buckets[len(buckets)-1]
+	if first.Count == 0 || last.Count == 0 {
+		return 0
+	}
+	return newDelta("p50", first.P50, last.P50).Change
+}
+
+// WriteTo writes an ASCII plot of the median and the 99th percentile
+// over time to w, using 20 rows.
+func (series *TimeSeries) WriteTo(w io.Writer) (int64, error) {
+	const rows, width = 20, 40
+
+	buckets := series.Buckets(rows)
+	var scale time.Duration
+	for _, bucket := range buckets {
+		scale = max(scale, bucket.P99)
+	}
+	scale = max(scale, 1)
+
+	var written int64
+	n, err := fmt.Fprintf(w, "%10s %10s %10s %6s\n", "elapsed", "p50", "p99", "laps")
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, bucket := range buckets {
+		var bar string
+		if bucket.Count > 0 {
+			p50 := int(int64(width) * int64(max(bucket.P50, 0)) / int64(scale))
+			p99 := int(int64(width) * int64(max(bucket.P99, 0)) / int64(scale))
+			bar = strings.Repeat("#", p50) + strings.Repeat("-", max(p99-p50, 0))
+		}
+		n, err = fmt.Fprintf(w, "%10v %10v %10v %6d |%-*s|\n",
+			RoundDuration(bucket.Start), formatStat(float64(bucket.P50)), formatStat(float64(bucket.P99)),
+			bucket.Count, width, bar)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	n, err = fmt.Fprintf(w, "drift %+.2f%%\n", series.Drift()*100)
+	written += int64(n)
+	return written, err
+}
+
+// String returns an ASCII plot of the series.
+func (series *TimeSeries) String() string {
+	var buffer strings.Builder
+	_, _ = series.WriteTo(&buffer)
+	return buffer.String()
+}
+
+// WriteSVG writes the median and the 99th percentile over time as
+// a line chart in SVG format to w, which can be embedded in HTML.
+func (series *TimeSeries) WriteSVG(w io.Writer) (int64, error) {
+	const (
+		columns     = 100
+		width       = 600
+		height      = 200
+		labelHeight = 20
+	)
+
+	buckets := series.Buckets(columns)
+	var scale time.Duration
+	for _, bucket := range buckets {
+		scale = max(scale, bucket.P99)
+	}
+	scale = max(scale, 1)
+
+	line := func(value func(TimeBucket) time.Duration) string {
+		var points strings.Builder
+		for i, bucket := range buckets {
+			if bucket.Count == 0 {
+				continue
+			}
+			x := (float64(i) + 0.5) * width / columns
+			y := height * (1 - float64(max(value(bucket), 0))/float64(scale))
+			fmt.Fprintf(&points, "%.1f,%.1f ", x, y)
+		}
+		return strings.TrimSpace(points.String())
+	}
+
+	var written int64
+	n, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n",
+		width, height+labelHeight)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, series := range []struct {
+		name  string
+		color string
+		value func(TimeBucket) time.Duration
+	}{
+		{"p99", "#e45756", func(bucket TimeBucket) time.Duration { return bucket.P99 }},
+		{"p50", "#4c78a8", func(bucket TimeBucket) time.Duration { return bucket.P50 }},
+	} {
+		n, err = fmt.Fprintf(w, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"><title>%s</title></polyline>`+"\n",
+			series.color, line(series.value), series.name)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	n, err = fmt.Fprintf(w, `<text x="2" y="12">%v</text><text x="2" y="%d">0s</text><text x="%d" y="%d" text-anchor="end">%v</text>`+"\n</svg>\n",
+		formatStat(float64(scale)), height+labelHeight-5, width-2, height+labelHeight-5, RoundDuration(series.Duration()))
+	written += int64(n)
+	return written, err
+}
@@ -0,0 +1,57 @@
+package hrtime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestTimeSeries(t *testing.T) {
+	clock := hrtime.NewManualClock(0)
+	bench := hrtime.NewBenchmark(100, hrtime.WithClock(clock), hrtime.WithTimestamps())
+	lap := time.Duration(100)
+	for bench.Next() {
+		clock.Advance(lap)
+		lap += 2
+	}
+
+	series := bench.TimeSeries()
+	if len(series.Points) != 100 || series.Start.IsZero() {
+		t.Fatalf("unexpected series %d %v", len(series.Points), series.Start)
+	}
+	if point := series.Points[1]; point.Elapsed != 100 || point.Lap != 102 {
+		t.Errorf("unexpected point %+v", point)
+	}
+
+	buckets := series.Buckets(10)
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	if total != 100 || buckets[0].P50 >= buckets[9].P50 {
+		t.Errorf("unexpected buckets %+v", buckets)
+	}
+	if drift := series.Drift(); drift < 1 {
+		t.Errorf("expected laps to degrade over time, got drift %v", drift)
+	}
+
+	if plot := series.String(); strings.Count(plot, "\n") != 22 || !strings.Contains(plot, "#") {
+		t.Errorf("unexpected plot:\n%s", plot)
+	}
+	var svg strings.Builder
+	if _, err := series.WriteSVG(&svg); err != nil || !strings.Contains(svg.String(), "<polyline") {
+		t.Errorf("unexpected svg %v:\n%s", err, svg.String())
+	}
+}
+
+func TestNewTimeSeries(t *testing.T) {
+	series := hrtime.NewTimeSeries([]time.Duration{10, 20, 30})
+	if series.Duration() != 60 || series.Points[2].Elapsed != 30 {
+		t.Errorf("unexpected series %+v", series)
+	}
+	if drift := hrtime.NewTimeSeries(nil).Drift(); drift != 0 {
+		t.Errorf("expected no drift for empty series, got %v", drift)
+	}
+}
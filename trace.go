@@ -0,0 +1,81 @@
+package hrtime
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tracer writes debug events, when enabled by SetTrace.
+type tracer struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+// activeTracer is the tracer set by SetTrace.
+var activeTracer atomic.Pointer[tracer]
+
+// SetTrace writes debug events of the library to w, nil disables tracing.
+//
+// Events describe what the library did during a run: calibrations,
+// finalization of benchmarks, merges, clock fallbacks and anomalies.
+// Every event is a line "hrtime +<elapsed> <event>: <details>", where
+// elapsed is the time since SetTrace. It's intended for diagnosing
+// unexpected results, not for use in production. The clocks chosen at
+// startup are written immediately.
+func SetTrace(w io.Writer) {
+	if w == nil {
+		activeTracer.Store(nil)
+		return
+	}
+	activeTracer.Store(&tracer{w: w, start: time.Now()})
+
+	clocks := ClockInfo()
+	for _, backend := range []struct {
+		name string
+		info BackendInfo
+	}{{"now", clocks.Now}, {"tsc", clocks.TSC}} {
+		if backend.info.Fallback != "" {
+			tracef("clock", "%s uses %s, fallback: %s", backend.name, quoteOrUnknown(backend.info.Name), backend.info.Fallback)
+		} else {
+			tracef("clock", "%s uses %s, overhead %v", backend.name, backend.info.Name, backend.info.Overhead)
+		}
+	}
+}
+
+// tracing returns whether tracing is enabled.
+func tracing() bool { return activeTracer.Load() != nil }
+
+// tracef writes an event, when tracing is enabled.
+func tracef(event, format string, args ...any) {
+	t := activeTracer.Load()
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = fmt.Fprintf(t.w, "hrtime +%v %s: %s\n", RoundDuration(time.Since(t.start)), event, fmt.Sprintf(format, args...))
+}
+
+// traceFinalize writes a finalization event, unless the benchmark is untraced.
+func traceFinalize(kind string, opts *options, laps, warmup, nonMonotonic int, err error) {
+	if opts.untraced || !tracing() {
+		return
+	}
+	details := fmt.Sprintf("%d laps, %d warmup dropped, %d non-monotonic", laps, warmup, nonMonotonic)
+	if opts.overhead != nil {
+		details += ", overhead subtracted"
+	}
+	if err != nil {
+		details += ", error: " + err.Error()
+	}
+	tracef("finalize", "%s %s", kind, details)
+}
+
+// withoutTrace disables finalization events of internal benchmarks, e.g. during calibration.
+func withoutTrace() Option {
+	return func(opts *options) { opts.untraced = true }
+}
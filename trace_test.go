@@ -0,0 +1,39 @@
+package hrtime_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/loov/hrtime"
+)
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	hrtime.SetTrace(&buf)
+	defer hrtime.SetTrace(nil)
+
+	bench := hrtime.NewBenchmark(3, hrtime.WithWarmup(1))
+	for bench.Next() {
+	}
+	hrtime.MergeBenchmarks(bench, bench)
+	hrtime.SetTrace(nil)
+
+	other := hrtime.NewBenchmark(1)
+	for other.Next() {
+	}
+
+	trace := buf.String()
+	for _, expected := range []string{
+		"clock: now uses",
+		"finalize: benchmark 3 laps, 1 warmup dropped, 0 non-monotonic\n",
+		"merge: 2 benchmarks, 6 laps",
+	} {
+		if !strings.Contains(trace, expected) {
+			t.Errorf("trace missing %q:\n%s", expected, trace)
+		}
+	}
+	if strings.Count(trace, "finalize:") != 1 {
+		t.Errorf("expected no events after disabling trace:\n%s", trace)
+	}
+}
@@ -1,6 +1,11 @@
 package hrtime
 
-import "time"
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
 
 // Count represents represents Time Stamp Counter value, when available.
 //
@@ -12,17 +17,21 @@ type Count int64
 //
 // First call to this function will do calibration and can take several milliseconds.
 func (count Count) ApproxDuration() time.Duration {
-	if ratioCount == 0 {
-		calculateTSCConversion()
-	}
-	return time.Duration(count) * ratioNano / time.Duration(ratioCount)
+	return loadTSCRatio().duration(count)
 }
 
 // TSC reads the current Time Stamp Counter value.
 //
 // Reminder: Time Stamp Count are processor specific and need to be converted to
 // time.Duration with Count.ApproxDuration.
-func TSC() Count { return Count(RDTSC()) }
+//
+// After CalibrateTSC has found the counter unreliable, it returns Now instead.
+func TSC() Count {
+	if tscFallback.Load() {
+		return Count(Now())
+	}
+	return Count(RDTSC())
+}
 
 // TSCSince returns count since start.
 //
@@ -42,10 +51,75 @@ var (
 
 	cpuid func(op1, op2 uint32) (eax, ebx, ecx, edx uint32)
 
-	ratioNano  time.Duration
-	ratioCount Count
+	// tscConversion converts counts to durations, see loadTSCRatio.
+	tscConversion atomic.Pointer[tscRatio]
 )
 
+// tscRatio is the conversion of count counter ticks to nano nanoseconds.
+type tscRatio struct {
+	nano  time.Duration
+	count Count
+}
+
+// loadTSCRatio returns the conversion ratio, calculating it on first use.
+func loadTSCRatio() *tscRatio {
+	if current := tscConversion.Load(); current != nil {
+		return current
+	}
+	tscConversion.CompareAndSwap(nil, calculateTSCConversion())
+	return tscConversion.Load()
+}
+
+// storeTSCRatio sets the conversion of count ticks to nano nanoseconds.
+func storeTSCRatio(nano time.Duration, count Count) {
+	tscConversion.Store(newTSCRatio(nano, count))
+}
+
+// newTSCRatio creates a ratio converting count ticks to nano nanoseconds.
+func newTSCRatio(nano time.Duration, count Count) *tscRatio {
+	if nano <= 0 || count <= 0 {
+		// calibration failed, counts are treated as nanoseconds
+		return &tscRatio{nano: 1, count: 1}
+	}
+	return &tscRatio{nano: nano, count: count}
+}
+
+// duration converts count to a duration.
+func (ratio *tscRatio) duration(count Count) time.Duration {
+	return time.Duration(mulDiv(int64(count), int64(ratio.nano), int64(ratio.count)))
+}
+
+// approxCount converts duration to a count, see duration.
+func (ratio *tscRatio) approxCount(duration time.Duration) Count {
+	return Count(mulDiv(int64(duration), int64(ratio.count), int64(ratio.nano)))
+}
+
+// mulDiv returns v * mul / div for positive mul and div.
+//
+// The product is computed with 128 bits, since absolute counter values
+// times the ratio overflow int64. Results outside of int64 range saturate.
+func mulDiv(v, mul, div int64) int64 {
+	magnitude := uint64(v)
+	if v < 0 {
+		magnitude = -magnitude
+	}
+	hi, lo := bits.Mul64(magnitude, uint64(mul))
+	result := uint64(math.MaxInt64)
+	if hi < uint64(div) {
+		result, _ = bits.Div64(hi, lo, uint64(div))
+		result = min(result, math.MaxInt64)
+	}
+	if v < 0 {
+		return -int64(result)
+	}
+	return int64(result)
+}
+
+// frequency returns the counter frequency in Hz.
+func (ratio *tscRatio) frequency() float64 {
+	return float64(ratio.count) * float64(time.Second) / float64(ratio.nano)
+}
+
 func calculateTSCOverhead() {
 	if !rdtscpInvariant {
 		return
@@ -60,7 +134,12 @@ func calculateTSCOverhead() {
 	readTSCOverhead = (stop - start) / (calibrationCalls + 1)
 }
 
-func calculateTSCConversion() {
+func calculateTSCConversion() *tscRatio {
+	// prefer architectural or kernel calibrated frequency, when available
+	if freq := counterFrequency(); freq >= 1000 {
+		return newTSCRatio(time.Millisecond, Count(freq/1000))
+	}
+
 	// warmup
 	for i := 0; i < 64*calibrationCalls; i++ {
 		empty()
@@ -75,8 +154,15 @@ func calculateTSCConversion() {
 	countstop := TSC()
 
 	// TODO: figure out a better way to calculate this
-	ratioNano = nanoend - nanostart - Overhead()
-	ratioCount = countstop - countstart - TSCOverhead()
+	return newTSCRatio(nanoend-nanostart-Overhead(), countstop-countstart-TSCOverhead())
+}
+
+// counterFrequency returns counter frequency in Hz, or 0 when unknown.
+func counterFrequency() uint64 {
+	if freq := archCounterFrequency(); freq > 0 {
+		return freq
+	}
+	return kernelTSCFrequency()
 }
 
 //go:noinline
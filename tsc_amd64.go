@@ -1,13 +1,28 @@
+//go:build !gccgo && !purego
+// +build !gccgo,!purego
+
 package hrtime
 
 func rdtscpAsm() uint64
 func rdtscAsm() uint64
 func cpuidAsm(op1, op2 uint32) (eax, ebx, ecx, edx uint32)
 
+// counterName is the name of the hardware counter used by TSC.
+const counterName = "TSC"
+
 func initCPU() {
 	cpuid = cpuidAsm
 }
 
+// counterInvariant returns whether processor has invariant TSC.
+func counterInvariant() bool {
+	_, _, _, edx := cpuid(0x80000007, 0x0)
+	return edx&(1<<8) != 0
+}
+
+// archCounterFrequency returns 0, since TSC frequency is not architecturally exposed.
+func archCounterFrequency() uint64 { return 0 }
+
 // RDTSCP returns Read Time-Stamp Counter value using RDTSCP asm instruction.
 //
 // If a platform doesn't support the instruction it returns 0.
@@ -1,4 +1,5 @@
-// +build amd64,!gccgo
+//go:build amd64 && !gccgo && !purego
+// +build amd64,!gccgo,!purego
 
 #include "textflag.h"
 
@@ -0,0 +1,35 @@
+//go:build !gccgo && !purego
+// +build !gccgo,!purego
+
+package hrtime
+
+func cntvctAsm() uint64
+func cntvctOrderedAsm() uint64
+func cntfrqAsm() uint64
+
+// counterName is the name of the hardware counter used by TSC.
+const counterName = "CNTVCT_EL0"
+
+func initCPU() {
+	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
+		return 0, 0, 0, 0
+	}
+}
+
+// counterInvariant returns whether the counter runs at a constant rate.
+//
+// The generic timer virtual count is architecturally defined to run at
+// a constant rate, which is independent of the core frequency.
+func counterInvariant() bool { return true }
+
+// archCounterFrequency returns the generic timer frequency from CNTFRQ_EL0.
+func archCounterFrequency() uint64 { return cntfrqAsm() }
+
+// RDTSCP returns the virtual count of the generic timer using MRS CNTVCT_EL0.
+//
+// It's preceded by an instruction barrier, so the counter isn't read
+// before the preceding instructions complete.
+func RDTSCP() uint64 { return cntvctOrderedAsm() }
+
+// RDTSC returns the virtual count of the generic timer using MRS CNTVCT_EL0.
+func RDTSC() uint64 { return cntvctAsm() }
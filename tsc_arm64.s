@@ -0,0 +1,23 @@
+//go:build arm64 && !gccgo && !purego
+// +build arm64,!gccgo,!purego
+
+#include "textflag.h"
+
+// func cntvctAsm() uint64
+TEXT ·cntvctAsm(SB),NOSPLIT,$0-8
+	MRS  CNTVCT_EL0, R0
+	MOVD R0, ret+0(FP)
+	RET
+
+// func cntvctOrderedAsm() uint64
+TEXT ·cntvctOrderedAsm(SB),NOSPLIT,$0-8
+	ISB  $15
+	MRS  CNTVCT_EL0, R0
+	MOVD R0, ret+0(FP)
+	RET
+
+// func cntfrqAsm() uint64
+TEXT ·cntfrqAsm(SB),NOSPLIT,$0-8
+	MRS  CNTFRQ_EL0, R0
+	MOVD R0, ret+0(FP)
+	RET
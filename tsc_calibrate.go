@@ -0,0 +1,139 @@
+package hrtime
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// TSCCalibration is the result of measuring TSC against Now.
+type TSCCalibration struct {
+	// Window is the total duration of the measurement.
+	Window time.Duration
+	// Frequency is the measured counter frequency in Hz, median of rounds.
+	Frequency float64
+	// Reported is the architectural or kernel reported frequency in Hz, zero when unknown.
+	Reported float64
+	// Spread is the relative difference between the slowest and fastest round.
+	Spread float64
+	// Invariant reports whether the processor advertises an invariant counter.
+	Invariant bool
+	// Stable reports whether the counter passed all checks.
+	Stable bool
+	// Reason describes why the counter is not stable, empty otherwise.
+	Reason string
+}
+
+// tscCalibrationRounds is the number of sub-windows measured by CalibrateTSC.
+const tscCalibrationRounds = 8
+
+// maximum relative errors tolerated by CalibrateTSC
+const (
+	maxTSCSpread   = 0.01
+	maxTSCMismatch = 0.02
+)
+
+// tscFallback makes TSC use Now, when the counter is not reliable.
+var tscFallback atomic.Bool
+
+// CalibrateTSC measures TSC frequency against Now over window and
+// checks whether the counter is usable.
+//
+// The counter is considered unstable when it's not invariant, goes
+// backwards, its frequency varies between rounds by more than 1% or differs
+// from the reported frequency by more than 2%. When stable, the measured
+// frequency is used by Count.ApproxDuration. Otherwise TSC falls back to
+// using Now, see TSCSource. Counts taken before and after calibration must
+// not be mixed, hence it should be called before measuring.
+func CalibrateTSC(window time.Duration) TSCCalibration {
+	if window <= 0 {
+		panic("calibration window must be positive")
+	}
+
+	calibration := TSCCalibration{
+		Window:    window,
+		Reported:  float64(counterFrequency()),
+		Invariant: rdtscpInvariant,
+	}
+
+	if counterName == "" || counterName == "Now" {
+		calibration.Reason = "no hardware counter"
+		storeTSCRatio(1, 1)
+		tscFallback.Store(true)
+		tscFallbackReason.Store(&calibration.Reason)
+		tracef("calibrate", "tsc falls back to Now: %s", calibration.Reason)
+		return calibration
+	}
+
+	tscFallback.Store(false)
+	frequencies := make([]float64, tscCalibrationRounds)
+	backwards := false
+	round := window / tscCalibrationRounds
+	for i := range frequencies {
+		nanostart, countstart := Now(), TSC()
+		nanoend := nanostart
+		for nanoend-nanostart < round {
+			nanoend = Now()
+		}
+		countend := TSC()
+		if countend <= countstart {
+			backwards = true
+		}
+		frequencies[i] = float64(countend-countstart) * float64(time.Second) / float64(nanoend-nanostart)
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, freq := range frequencies {
+		low, high = math.Min(low, freq), math.Max(high, freq)
+	}
+	sort.Float64s(frequencies)
+	calibration.Frequency = frequencies[len(frequencies)/2]
+	if calibration.Frequency > 0 {
+		calibration.Spread = (high - low) / calibration.Frequency
+	}
+
+	switch {
+	case !calibration.Invariant:
+		calibration.Reason = counterName + " is not invariant"
+	case backwards:
+		calibration.Reason = counterName + " went backwards"
+	case !(calibration.Frequency > 0):
+		calibration.Reason = counterName + " is not advancing"
+	case calibration.Spread > maxTSCSpread:
+		calibration.Reason = fmt.Sprintf("%s frequency varies by %.1f%%", counterName, calibration.Spread*100)
+	case calibration.Reported > 0 && math.Abs(calibration.Frequency-calibration.Reported)/calibration.Reported > maxTSCMismatch:
+		calibration.Reason = fmt.Sprintf("%s measured frequency %.0f Hz differs from reported %.0f Hz",
+			counterName, calibration.Frequency, calibration.Reported)
+	default:
+		calibration.Stable = true
+	}
+
+	if calibration.Stable {
+		storeTSCRatio(time.Second, Count(calibration.Frequency))
+	} else {
+		tscFallback.Store(true)
+		storeTSCRatio(1, 1)
+	}
+	tscFallbackReason.Store(&calibration.Reason)
+	if calibration.Stable {
+		tracef("calibrate", "tsc %s at %.0f Hz, spread %.2f%%", counterName, calibration.Frequency, calibration.Spread*100)
+	} else {
+		tracef("calibrate", "tsc falls back to Now: %s", calibration.Reason)
+	}
+	return calibration
+}
+
+// tscFallbackReason is the reason of the last calibration.
+var tscFallbackReason atomic.Pointer[string]
+
+// TSCSource returns the name of the source used by TSC,
+// either the hardware counter name, e.g. "TSC", or "Now" after
+// CalibrateTSC found the counter unreliable.
+func TSCSource() string {
+	if tscFallback.Load() {
+		return "Now"
+	}
+	return counterName
+}
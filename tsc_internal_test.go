@@ -1,7 +1,9 @@
 package hrtime
 
 import (
+	"math"
 	"testing"
+	"time"
 )
 
 func BenchmarkRDTSCP(b *testing.B) {
@@ -22,3 +24,66 @@ func BenchmarkRDTSC(b *testing.B) {
 		RDTSC()
 	}
 }
+
+func TestCalibrateTSC(t *testing.T) {
+	saved := tscConversion.Load()
+	defer func() {
+		tscConversion.Store(saved)
+		tscFallback.Store(false)
+	}()
+
+	calibration := CalibrateTSC(16 * time.Millisecond)
+	t.Logf("%+v", calibration)
+
+	if calibration.Stable {
+		if TSCSource() != counterName || calibration.Frequency <= 0 {
+			t.Errorf("stable counter not in use: %+v", calibration)
+		}
+		return
+	}
+
+	if TSCSource() != "Now" || calibration.Reason == "" {
+		t.Errorf("unstable counter without fallback: %+v", calibration)
+	}
+	start := TSC()
+	time.Sleep(time.Millisecond)
+	if elapsed := (TSC() - start).ApproxDuration(); elapsed < time.Millisecond {
+		t.Errorf("fallback counter measured %v", elapsed)
+	}
+	if info := ClockInfo(); info.TSC.Name != "Now" {
+		t.Errorf("clock info does not report fallback: %+v", info.TSC)
+	}
+}
+
+func TestAvailableClocksFallback(t *testing.T) {
+	saved := tscFallback.Load()
+	defer tscFallback.Store(saved)
+
+	tscFallback.Store(true)
+	for _, clock := range availableClocks() {
+		if clock.name != "hrtime.Now" && clock.name != "time.Now" {
+			t.Errorf("hardware counter %q listed after fallback", clock.name)
+		}
+	}
+}
+
+func TestTSCRatioOverflow(t *testing.T) {
+	ratio := newTSCRatio(time.Second, 3_000_000_000)
+	tests := []struct {
+		count  Count
+		expect time.Duration
+	}{
+		{3_000_000_000, time.Second},
+		{30_000_000_000, 10 * time.Second},
+		{-30_000_000_000, -10 * time.Second},
+		{14704739718332, 4901579906110},
+	}
+	for _, test := range tests {
+		if got := ratio.duration(test.count); got != test.expect {
+			t.Errorf("duration(%d) = %v, expected %v", test.count, got, test.expect)
+		}
+	}
+	if got := newTSCRatio(time.Second, 1).duration(math.MaxInt64); got != math.MaxInt64 {
+		t.Errorf("expected saturation, got %v", got)
+	}
+}
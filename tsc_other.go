@@ -1,13 +1,24 @@
-// +build !amd64 gccgo
+//go:build ((!amd64 && !arm64 && !riscv64 && !s390x && !ppc64 && !ppc64le) || gccgo) && !purego
+// +build !amd64,!arm64,!riscv64,!s390x,!ppc64,!ppc64le gccgo
+// +build !purego
 
 package hrtime
 
+// counterName is empty, since there is no supported hardware counter.
+const counterName = ""
+
 func initCPU() {
 	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
 		return 0, 0, 0, 0
 	}
 }
 
+// counterInvariant returns false for unsupported configuration.
+func counterInvariant() bool { return false }
+
+// archCounterFrequency returns 0 for unsupported configuration.
+func archCounterFrequency() uint64 { return 0 }
+
 // RDTSCP returns 0 for unsupported configuration
 //
 // If a given OS doesn't support the instruction it returns 0.
@@ -0,0 +1,60 @@
+//go:build (ppc64 || ppc64le) && !gccgo && !purego
+// +build ppc64 ppc64le
+// +build !gccgo
+// +build !purego
+
+package hrtime
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func mftbAsm() uint64
+
+// counterName is the name of the hardware counter used by TSC.
+const counterName = "MFTB"
+
+func initCPU() {
+	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
+		return 0, 0, 0, 0
+	}
+}
+
+// counterInvariant returns whether the counter runs at a constant rate.
+//
+// The timebase register is architecturally defined to run at a constant rate.
+func counterInvariant() bool { return true }
+
+// archCounterFrequency returns timebase frequency reported by Linux.
+func archCounterFrequency() uint64 {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "timebase" {
+			continue
+		}
+		freq, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return freq
+	}
+	return 0
+}
+
+// RDTSCP returns the timebase register value using MFTB asm instruction.
+//
+// PowerPC doesn't have a serializing variant, hence it's the same as RDTSC.
+func RDTSCP() uint64 { return mftbAsm() }
+
+// RDTSC returns the timebase register value using MFTB asm instruction.
+func RDTSC() uint64 { return mftbAsm() }
@@ -0,0 +1,11 @@
+//go:build (ppc64 || ppc64le) && !gccgo && !purego
+// +build ppc64 ppc64le
+// +build !gccgo,!purego
+
+#include "textflag.h"
+
+// func mftbAsm() uint64
+TEXT ·mftbAsm(SB),NOSPLIT,$0-8
+	MOVD SPR(268), R3 // MFTB
+	MOVD R3, ret+0(FP)
+	RET
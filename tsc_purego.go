@@ -0,0 +1,31 @@
+//go:build purego
+// +build purego
+
+package hrtime
+
+// counterName is "Now", since TSC uses Now instead of a hardware counter.
+const counterName = "Now"
+
+func initCPU() {
+	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
+		return 0, 0, 0, 0
+	}
+}
+
+// counterInvariant returns false, since there is no hardware counter.
+func counterInvariant() bool { return false }
+
+// archCounterFrequency returns the frequency of Now in Hz.
+func archCounterFrequency() uint64 { return 1e9 }
+
+// RDTSCP returns Now in nanoseconds when building with purego tag.
+//
+// It allows using BenchmarkTSC without assembly, however the values
+// have the precision of Now.
+func RDTSCP() uint64 { return uint64(Now()) }
+
+// RDTSC returns Now in nanoseconds when building with purego tag.
+//
+// It allows using BenchmarkTSC without assembly, however the values
+// have the precision of Now.
+func RDTSC() uint64 { return uint64(Now()) }
@@ -0,0 +1,45 @@
+//go:build !gccgo && !purego
+// +build !gccgo,!purego
+
+package hrtime
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+func rdtimeAsm() uint64
+
+// counterName is the name of the hardware counter used by TSC.
+const counterName = "RDTIME"
+
+func initCPU() {
+	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
+		return 0, 0, 0, 0
+	}
+}
+
+// counterInvariant returns whether the counter runs at a constant rate.
+//
+// The time CSR is a fixed frequency timebase on all riscv64 platforms.
+func counterInvariant() bool { return true }
+
+// archCounterFrequency returns timebase frequency from the device tree.
+func archCounterFrequency() uint64 {
+	data, err := os.ReadFile("/proc/device-tree/cpus/timebase-frequency")
+	if err != nil || len(data) != 4 {
+		return 0
+	}
+	return uint64(binary.BigEndian.Uint32(data))
+}
+
+// RDTSCP returns the time CSR value using RDTIME asm instruction.
+//
+// RISC-V doesn't have a serializing variant, hence it's the same as RDTSC.
+func RDTSCP() uint64 { return rdtimeAsm() }
+
+// RDTSC returns the time CSR value using RDTIME asm instruction.
+//
+// The cycle CSR is not used, because recent Linux kernels trap
+// user-space access to it.
+func RDTSC() uint64 { return rdtimeAsm() }
@@ -0,0 +1,10 @@
+//go:build riscv64 && !gccgo && !purego
+// +build riscv64,!gccgo,!purego
+
+#include "textflag.h"
+
+// func rdtimeAsm() uint64
+TEXT ·rdtimeAsm(SB),NOSPLIT,$0-8
+	RDTIME X10
+	MOV    X10, ret+0(FP)
+	RET
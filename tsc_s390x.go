@@ -0,0 +1,33 @@
+//go:build !gccgo && !purego
+// +build !gccgo,!purego
+
+package hrtime
+
+func stckAsm() uint64
+
+// counterName is the name of the hardware counter used by TSC.
+const counterName = "STCK"
+
+func initCPU() {
+	cpuid = func(op1, op2 uint32) (eax, ebx, ecx, edx uint32) {
+		return 0, 0, 0, 0
+	}
+}
+
+// counterInvariant returns whether the counter runs at a constant rate.
+//
+// The TOD clock is architecturally defined to run at a constant rate.
+func counterInvariant() bool { return true }
+
+// archCounterFrequency returns the TOD clock frequency.
+//
+// Bit 51 of the TOD clock is incremented every microsecond.
+func archCounterFrequency() uint64 { return 4096 * 1000 * 1000 }
+
+// RDTSCP returns the TOD clock value using STCK asm instruction.
+//
+// STCK is serializing, hence it's the same as RDTSC.
+func RDTSCP() uint64 { return stckAsm() }
+
+// RDTSC returns the TOD clock value using STCK asm instruction.
+func RDTSC() uint64 { return stckAsm() }
@@ -0,0 +1,15 @@
+//go:build s390x && !gccgo && !purego
+// +build s390x,!gccgo,!purego
+
+#include "textflag.h"
+
+// func stckAsm() uint64
+TEXT ·stckAsm(SB),NOSPLIT,$0-8
+	// The TOD clock counts from year 1900, which means the msb is set.
+	// Clear it to keep the value positive.
+	STCK ret+0(FP)
+	MOVD ret+0(FP), R3
+	SLD  $1, R3
+	SRD  $1, R3
+	MOVD R3, ret+0(FP)
+	RET
@@ -0,0 +1,186 @@
+package hrtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// VerifyClockOptions configures VerifyClock.
+type VerifyClockOptions struct {
+	// Duration is how long to read the clock, 1 second when zero.
+	Duration time.Duration
+	// Threads is the number of threads reading the clock, GOMAXPROCS when zero.
+	Threads int
+	// Clock is the clock to verify, SystemClock when nil.
+	Clock Clock
+	// TSC verifies Clock.TSC instead of Clock.Now.
+	TSC bool
+	// JumpThreshold is the forward step reported as a jump, 1ms when zero.
+	// Forward jumps are also caused by preemption, hence they are not errors.
+	JumpThreshold time.Duration
+}
+
+// ClockReport contains the results of VerifyClock.
+//
+// Durations of TSC verification are approximate, see Count.ApproxDuration.
+type ClockReport struct {
+	Backend  string
+	Threads  int
+	Duration time.Duration
+	// Reads is the total number of clock reads.
+	Reads int64
+
+	// Backward is the number of reads earlier than the previous read on the same thread.
+	Backward    int64
+	MaxBackward time.Duration
+	// Skewed is the number of reads earlier than a read already published by another
+	// thread, which happens when counters on cores are not synchronized.
+	// Reads counted as Backward are not counted as Skewed.
+	Skewed  int64
+	MaxSkew time.Duration
+	// Jumps is the number of forward steps larger than JumpThreshold.
+	Jumps   int64
+	MaxJump time.Duration
+}
+
+// OK returns whether the clock never went backwards.
+func (report *ClockReport) OK() bool {
+	return report.Backward == 0 && report.Skewed == 0
+}
+
+// VerifyClock reads the clock from multiple threads, locked to OS threads,
+// and reports backward steps, skew between threads and forward jumps.
+//
+// It's a diagnostic for platforms with a suspect timer. VerifyClock
+// stops early when ctx is canceled and returns the report so far with ctx.Err().
+func VerifyClock(ctx context.Context, opts VerifyClockOptions) (*ClockReport, error) {
+	if opts.Duration <= 0 {
+		opts.Duration = time.Second
+	}
+	if opts.Threads <= 0 {
+		opts.Threads = runtime.GOMAXPROCS(0)
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	if opts.JumpThreshold <= 0 {
+		opts.JumpThreshold = time.Millisecond
+	}
+
+	read := func() int64 { return int64(opts.Clock.Now()) }
+	threshold := int64(opts.JumpThreshold)
+	report := &ClockReport{Backend: nowBackend, Threads: opts.Threads}
+	if opts.TSC {
+		read = func() int64 { return int64(opts.Clock.TSC()) }
+		threshold = int64(float64(opts.JumpThreshold) / float64(Count(1e9).ApproxDuration()) * 1e9)
+		report.Backend = counterName
+		if report.Backend == "" {
+			report.Backend = "TSC"
+		}
+	}
+	if opts.Clock != SystemClock {
+		report.Backend = fmt.Sprintf("%T", opts.Clock)
+	}
+
+	var stop atomic.Bool
+	var published atomic.Int64
+	published.Store(math.MinInt64)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range opts.Threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			var local ClockReport
+			var maxBackward, maxSkew, maxJump int64
+			previous := read()
+			// check stop after every batch of reads
+			for done := false; !done; done = stop.Load() {
+				for range 1024 {
+					seen := published.Load()
+					now := read()
+					local.Reads++
+
+					step := now - previous
+					if step < 0 {
+						local.Backward++
+						maxBackward = max(maxBackward, -step)
+					} else if step > threshold {
+						local.Jumps++
+						maxJump = max(maxJump, step)
+					}
+					// reads behind the own previous read are already counted as backward
+					if now < seen && step >= 0 {
+						local.Skewed++
+						maxSkew = max(maxSkew, seen-now)
+					} else if now > seen {
+						published.CompareAndSwap(seen, now)
+					}
+					previous = now
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Reads += local.Reads
+			report.Backward += local.Backward
+			report.Skewed += local.Skewed
+			report.Jumps += local.Jumps
+			report.MaxBackward = max(report.MaxBackward, time.Duration(maxBackward))
+			report.MaxSkew = max(report.MaxSkew, time.Duration(maxSkew))
+			report.MaxJump = max(report.MaxJump, time.Duration(maxJump))
+		}()
+	}
+
+	timer := time.NewTimer(opts.Duration)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	stop.Store(true)
+	wg.Wait()
+	report.Duration = time.Since(start)
+
+	if opts.TSC {
+		report.MaxBackward = Count(report.MaxBackward).ApproxDuration()
+		report.MaxSkew = Count(report.MaxSkew).ApproxDuration()
+		report.MaxJump = Count(report.MaxJump).ApproxDuration()
+	}
+	return report, err
+}
+
+// WriteTo writes the report to w.
+func (report *ClockReport) WriteTo(w io.Writer) (int64, error) {
+	status := "ok"
+	if !report.OK() {
+		status = "NOT MONOTONIC"
+	}
+	n, err := fmt.Fprintf(w, "%s: %s, %d reads from %d threads in %v\n"+
+		"  backward %d (max %v);  skew %d (max %v);  jumps %d (max %v)\n",
+		report.Backend, status, report.Reads, report.Threads, RoundDuration(report.Duration),
+		report.Backward, report.MaxBackward, report.Skewed, report.MaxSkew, report.Jumps, report.MaxJump,
+	)
+	return int64(n), err
+}
+
+// String returns the formatted report.
+func (report *ClockReport) String() string {
+	var s strings.Builder
+	_, _ = report.WriteTo(&s)
+	return s.String()
+}
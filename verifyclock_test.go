@@ -0,0 +1,46 @@
+package hrtime_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loov/hrtime"
+)
+
+func TestVerifyClock(t *testing.T) {
+	var reads atomic.Int64
+	// the clock steps by 1µs, every 100th read goes back by 1µs
+	// and every 1000th read jumps forward by 10ms
+	clock := hrtime.ClockFunc(func() time.Duration {
+		n := reads.Add(1)
+		now := time.Duration(n*1000) + time.Duration(n/1000)*10*time.Millisecond
+		if n%100 == 0 {
+			now -= 2 * time.Microsecond
+		}
+		return now
+	})
+
+	report, err := hrtime.VerifyClock(context.Background(), hrtime.VerifyClockOptions{
+		Duration: 10 * time.Millisecond,
+		Threads:  1,
+		Clock:    clock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Backward == 0 || report.MaxBackward != time.Microsecond {
+		t.Errorf("expected backward steps of 1µs, got %v", report)
+	}
+	if report.Jumps == 0 {
+		t.Errorf("expected forward jumps, got %v", report)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = hrtime.VerifyClock(ctx, hrtime.VerifyClockOptions{Duration: time.Minute, Threads: 2})
+	if err == nil || report.Reads == 0 {
+		t.Errorf("expected canceled verification with reads, got %v %v", report, err)
+	}
+}